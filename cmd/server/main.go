@@ -19,6 +19,7 @@ import (
 	"github.com/joho/godotenv"
 	configaccess "github.com/router-for-me/CLIProxyAPI/v6/internal/access/config_access"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/buildinfo"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/cmd"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
@@ -450,6 +451,17 @@ func main() {
 		sdkAuth.RegisterTokenStore(sdkAuth.NewFileTokenStore())
 	}
 
+	// Select the thinking signature cache backend.
+	if usePostgresStore {
+		cache.SetSignatureStoreBackend(pgStoreInst)
+	} else if useObjectStore {
+		cache.SetSignatureStoreBackend(objectStoreInst)
+	}
+	if errCache := cache.ApplySignatureCacheConfig(cfg.SignatureCache); errCache != nil {
+		log.Errorf("failed to initialize signature cache: %v", errCache)
+		return
+	}
+
 	// Register built-in access providers before constructing services.
 	configaccess.Register(&cfg.SDKConfig)
 
@@ -820,6 +820,9 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 		Prefix   *string `json:"prefix"`
 		ProxyURL *string `json:"proxy_url"`
 		Priority *int    `json:"priority"`
+		// MaxConcurrency sets the auth's concurrent request limit; a negative value restores
+		// the provider default.
+		MaxConcurrency *int `json:"max_concurrency"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
@@ -873,6 +876,18 @@ func (h *Handler) PatchAuthFileFields(c *gin.Context) {
 		}
 		changed = true
 	}
+	if req.MaxConcurrency != nil {
+		if targetAuth.Metadata == nil {
+			targetAuth.Metadata = make(map[string]any)
+		}
+		delete(targetAuth.Metadata, "max-concurrency")
+		if *req.MaxConcurrency < 0 {
+			delete(targetAuth.Metadata, "max_concurrency")
+		} else {
+			targetAuth.Metadata["max_concurrency"] = *req.MaxConcurrency
+		}
+		changed = true
+	}
 
 	if !changed {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
@@ -0,0 +1,32 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCircuitBreakers returns the state of the upstream endpoint circuit breakers.
+func (h *Handler) GetCircuitBreakers(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"circuit-breakers": h.authManager.CircuitBreakers()})
+}
+
+// DeleteCircuitBreakers closes the breaker named by the "key" query parameter, or every
+// breaker when it is omitted.
+func (h *Handler) DeleteCircuitBreakers(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	key := strings.TrimSpace(c.Query("key"))
+	if !h.authManager.ResetCircuitBreaker(key) && key != "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "circuit breaker not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -0,0 +1,16 @@
+package management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetConcurrency returns per-credential in-flight requests, queue depth and wait times.
+func (h *Handler) GetConcurrency(c *gin.Context) {
+	if h.authManager == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "core auth manager unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"concurrency": h.authManager.ConcurrencyStats()})
+}
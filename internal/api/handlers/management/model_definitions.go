@@ -3,12 +3,15 @@ package management
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 )
 
 // GetStaticModelDefinitions returns static model metadata for a given channel.
+// For openai-compatibility providers with discover-models enabled, the channel is the
+// provider name and the last discovered model list is returned.
 // Channel is provided via path param (:channel) or query param (?channel=...).
 func (h *Handler) GetStaticModelDefinitions(c *gin.Context) {
 	channel := strings.TrimSpace(c.Param("channel"))
@@ -22,7 +25,21 @@ func (h *Handler) GetStaticModelDefinitions(c *gin.Context) {
 
 	models := registry.GetStaticModelDefinitionsByChannel(channel)
 	if models == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown channel", "channel": channel})
+		// Fall back to models discovered from an openai-compatibility provider's /models endpoint.
+		discovered, fetchedAt, ok := registry.GetDiscoveredModels(channel)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown channel", "channel": channel})
+			return
+		}
+		if discovered == nil {
+			discovered = []*registry.ModelInfo{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"channel":    strings.ToLower(strings.TrimSpace(channel)),
+			"models":     discovered,
+			"discovered": true,
+			"fetched_at": fetchedAt.UTC().Format(time.RFC3339),
+		})
 		return
 	}
 
@@ -31,3 +48,17 @@ func (h *Handler) GetStaticModelDefinitions(c *gin.Context) {
 		"models":  models,
 	})
 }
+
+// GetModelDefinitions returns the effective model definitions for every channel: the
+// embedded catalog merged with any external sources from the model-definitions config.
+func (h *Handler) GetModelDefinitions(c *gin.Context) {
+	channels, sources, updatedAt := registry.ModelDefinitionsSnapshot()
+	if sources == nil {
+		sources = []string{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"channels":   channels,
+		"sources":    sources,
+		"updated_at": updatedAt.UTC().Format(time.RFC3339),
+	})
+}
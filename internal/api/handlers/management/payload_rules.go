@@ -0,0 +1,156 @@
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// GetPayloadRules returns every payload rule section.
+func (h *Handler) GetPayloadRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"payload": h.cfg.Payload, "response-payload": h.cfg.ResponsePayload})
+}
+
+// PutPayloadRules replaces all payload rule sections at once.
+func (h *Handler) PutPayloadRules(c *gin.Context) {
+	var payload config.PayloadConfig
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	previous := h.payloadRulesSnapshot()
+	h.cfg.Payload = payload
+	h.applyPayloadRules(c, previous)
+}
+
+// PayloadRuleSection serves CRUD for a single payload rule section:
+// GET lists it, PUT replaces it, POST appends one rule, PATCH replaces the rule at
+// {"index": n, "value": rule}, and DELETE removes the rule at ?index=n.
+func (h *Handler) PayloadRuleSection(c *gin.Context) {
+	section := c.Param("section")
+	switch section {
+	case "default":
+		editPayloadSection(h, c, section, &h.cfg.Payload.Default)
+	case "default-raw":
+		editPayloadSection(h, c, section, &h.cfg.Payload.DefaultRaw)
+	case "override":
+		editPayloadSection(h, c, section, &h.cfg.Payload.Override)
+	case "override-raw":
+		editPayloadSection(h, c, section, &h.cfg.Payload.OverrideRaw)
+	case "filter":
+		editPayloadSection(h, c, section, &h.cfg.Payload.Filter)
+	case "operations":
+		editPayloadSection(h, c, section, &h.cfg.Payload.Operations)
+	case "response-payload":
+		editPayloadSection(h, c, section, &h.cfg.ResponsePayload)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown payload section"})
+	}
+}
+
+func editPayloadSection[T any](h *Handler, c *gin.Context, section string, target *[]T) {
+	if c.Request.Method == http.MethodGet {
+		c.JSON(http.StatusOK, gin.H{section: *target})
+		return
+	}
+	previous := h.payloadRulesSnapshot()
+	switch c.Request.Method {
+	case http.MethodPut:
+		data, err := c.GetRawData()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+			return
+		}
+		var rules []T
+		if err = json.Unmarshal(data, &rules); err != nil {
+			var obj struct {
+				Items []T `json:"items"`
+			}
+			if err2 := json.Unmarshal(data, &obj); err2 != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+				return
+			}
+			rules = obj.Items
+		}
+		*target = rules
+	case http.MethodPost:
+		var rule T
+		if err := c.ShouldBindJSON(&rule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+		*target = append(*target, rule)
+	case http.MethodPatch:
+		var body struct {
+			Index *int `json:"index"`
+			Value *T   `json:"value"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || body.Index == nil || body.Value == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+			return
+		}
+		if *body.Index < 0 || *body.Index >= len(*target) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+		rules := append([]T(nil), (*target)...)
+		rules[*body.Index] = *body.Value
+		*target = rules
+	case http.MethodDelete:
+		var idx int
+		if _, err := fmt.Sscanf(c.Query("index"), "%d", &idx); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing index"})
+			return
+		}
+		if idx < 0 || idx >= len(*target) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+		rules := append([]T(nil), (*target)[:idx]...)
+		*target = append(rules, (*target)[idx+1:]...)
+	default:
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "method not allowed"})
+		return
+	}
+	h.applyPayloadRules(c, previous)
+}
+
+// applyPayloadRules validates the edited payload rules and persists them. When validation
+// drops a rule the edit is rejected and the previous rules are restored, so a typo never
+// silently disappears from the config file.
+func (h *Handler) applyPayloadRules(c *gin.Context, previous payloadRules) {
+	before := payloadRuleCounts(h.payloadRulesSnapshot())
+	h.cfg.SanitizePayloadRules()
+	if after := payloadRuleCounts(h.payloadRulesSnapshot()); after != before {
+		h.cfg.Payload = previous.request
+		h.cfg.ResponsePayload = previous.response
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload rule, see server log for details"})
+		return
+	}
+	h.persist(c)
+}
+
+// payloadRules holds the request and response payload rules restored when an edit is rejected.
+type payloadRules struct {
+	request  config.PayloadConfig
+	response []config.PayloadOperationRule
+}
+
+func (h *Handler) payloadRulesSnapshot() payloadRules {
+	return payloadRules{request: h.cfg.Payload, response: h.cfg.ResponsePayload}
+}
+
+func payloadRuleCounts(rules payloadRules) [7]int {
+	countOps := func(rules []config.PayloadOperationRule) int {
+		ops := 0
+		for _, rule := range rules {
+			ops += len(rule.Ops)
+		}
+		return ops
+	}
+	payload := rules.request
+	return [7]int{len(payload.Default), len(payload.DefaultRaw), len(payload.Override), len(payload.OverrideRaw), len(payload.Filter), countOps(payload.Operations), countOps(rules.response)}
+}
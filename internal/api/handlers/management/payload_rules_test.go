@@ -0,0 +1,65 @@
+package management
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+func TestPayloadRuleSectionCRUD(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: 8317\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	h := &Handler{cfg: &config.Config{}, configFilePath: configPath}
+	router := gin.New()
+	router.Any("/payload/:section", h.PayloadRuleSection)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	rule := `{"models":[{"name":"gpt-*"}],"match":{"api-keys":["k1"]},"params":{"temperature":0.2}}`
+	if rec := do(http.MethodPost, "/payload/override", rule); rec.Code != http.StatusOK {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+	}
+	if len(h.cfg.Payload.Override) != 1 || h.cfg.Payload.Override[0].Match.APIKeys[0] != "k1" {
+		t.Fatalf("rule not stored: %+v", h.cfg.Payload.Override)
+	}
+	saved, _ := os.ReadFile(configPath)
+	if !strings.Contains(string(saved), "override:") {
+		t.Fatalf("rule not persisted:\n%s", saved)
+	}
+
+	patch := `{"index":0,"value":{"models":[{"name":"gpt-5"}],"params":{"temperature":1}}}`
+	if rec := do(http.MethodPatch, "/payload/override", patch); rec.Code != http.StatusOK || h.cfg.Payload.Override[0].Models[0].Name != "gpt-5" {
+		t.Fatalf("update: %d %+v", rec.Code, h.cfg.Payload.Override)
+	}
+
+	// Invalid operations are rejected instead of being dropped silently.
+	bad := `{"models":[{"name":"*"}],"ops":[{"op":"explode","path":"x"}]}`
+	if rec := do(http.MethodPost, "/payload/operations", bad); rec.Code != http.StatusBadRequest || len(h.cfg.Payload.Operations) != 0 {
+		t.Fatalf("invalid op: %d %+v", rec.Code, h.cfg.Payload.Operations)
+	}
+
+	if rec := do(http.MethodGet, "/payload/override", ""); !strings.Contains(rec.Body.String(), `"gpt-5"`) {
+		t.Fatalf("list: %s", rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, "/payload/override?index=0", ""); rec.Code != http.StatusOK || len(h.cfg.Payload.Override) != 0 {
+		t.Fatalf("delete: %d %+v", rec.Code, h.cfg.Payload.Override)
+	}
+	if rec := do(http.MethodGet, "/payload/unknown", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown section: %d", rec.Code)
+	}
+}
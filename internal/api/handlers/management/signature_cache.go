@@ -0,0 +1,21 @@
+package management
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+)
+
+// GetSignatureCache returns the thinking signature cache size and hit/miss counters.
+func (h *Handler) GetSignatureCache(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"signature-cache": cache.GetSignatureCacheStats()})
+}
+
+// DeleteSignatureCache clears cached signatures for the model group of the "model" query
+// parameter, or all of them when it is omitted. Persistent backends are cleared as well.
+func (h *Handler) DeleteSignatureCache(c *gin.Context) {
+	cache.ClearSignatureCache(strings.TrimSpace(c.Query("model")))
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/middleware"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules"
 	ampmodule "github.com/router-for-me/CLIProxyAPI/v6/internal/api/modules/amp"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/managementasset"
@@ -327,6 +328,7 @@ func (s *Server) setupRoutes() {
 		v1.GET("/responses", openaiResponsesHandlers.ResponsesWebsocket)
 		v1.POST("/responses", openaiResponsesHandlers.Responses)
 		v1.POST("/responses/compact", openaiResponsesHandlers.Compact)
+		v1.GET("/streams/:id", s.handlers.ResumeStream)
 	}
 
 	// Gemini compatible API routes
@@ -480,6 +482,11 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/usage", s.mgmt.GetUsageStatistics)
 		mgmt.GET("/usage/export", s.mgmt.ExportUsageStatistics)
 		mgmt.POST("/usage/import", s.mgmt.ImportUsageStatistics)
+		mgmt.GET("/signature-cache", s.mgmt.GetSignatureCache)
+		mgmt.DELETE("/signature-cache", s.mgmt.DeleteSignatureCache)
+		mgmt.GET("/circuit-breakers", s.mgmt.GetCircuitBreakers)
+		mgmt.DELETE("/circuit-breakers", s.mgmt.DeleteCircuitBreakers)
+		mgmt.GET("/concurrency", s.mgmt.GetConcurrency)
 		mgmt.GET("/config", s.mgmt.GetConfig)
 		mgmt.GET("/config.yaml", s.mgmt.GetConfigYAML)
 		mgmt.PUT("/config.yaml", s.mgmt.PutConfigYAML)
@@ -606,6 +613,14 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.PATCH("/oauth-excluded-models", s.mgmt.PatchOAuthExcludedModels)
 		mgmt.DELETE("/oauth-excluded-models", s.mgmt.DeleteOAuthExcludedModels)
 
+		mgmt.GET("/payload", s.mgmt.GetPayloadRules)
+		mgmt.PUT("/payload", s.mgmt.PutPayloadRules)
+		mgmt.GET("/payload/:section", s.mgmt.PayloadRuleSection)
+		mgmt.PUT("/payload/:section", s.mgmt.PayloadRuleSection)
+		mgmt.POST("/payload/:section", s.mgmt.PayloadRuleSection)
+		mgmt.PATCH("/payload/:section", s.mgmt.PayloadRuleSection)
+		mgmt.DELETE("/payload/:section", s.mgmt.PayloadRuleSection)
+
 		mgmt.GET("/oauth-model-alias", s.mgmt.GetOAuthModelAlias)
 		mgmt.PUT("/oauth-model-alias", s.mgmt.PutOAuthModelAlias)
 		mgmt.PATCH("/oauth-model-alias", s.mgmt.PatchOAuthModelAlias)
@@ -614,6 +629,7 @@ func (s *Server) registerManagementRoutes() {
 		mgmt.GET("/auth-files", s.mgmt.ListAuthFiles)
 		mgmt.GET("/auth-files/models", s.mgmt.GetAuthFileModels)
 		mgmt.GET("/auth-files/quota", s.mgmt.GetAuthFileQuota)
+		mgmt.GET("/model-definitions", s.mgmt.GetModelDefinitions)
 		mgmt.GET("/model-definitions/:channel", s.mgmt.GetStaticModelDefinitions)
 		mgmt.GET("/auth-files/download", s.mgmt.DownloadAuthFile)
 		mgmt.POST("/auth-files", s.mgmt.UploadAuthFile)
@@ -906,6 +922,14 @@ func (s *Server) UpdateClients(cfg *config.Config) {
 		auth.SetQuotaCooldownDisabled(cfg.DisableCooling)
 	}
 
+	if oldCfg != nil && (oldCfg.SignatureCache.Backend != cfg.SignatureCache.Backend || oldCfg.SignatureCache.Path != cfg.SignatureCache.Path) {
+		if errCache := cache.ApplySignatureCacheConfig(cfg.SignatureCache); errCache != nil {
+			log.Errorf("signature-cache: keeping the current backend: %v", errCache)
+		}
+	} else if oldCfg != nil && oldCfg.SignatureCache.MaxEntries != cfg.SignatureCache.MaxEntries {
+		cache.ResizeSignatureCache(cfg.SignatureCache.MaxEntries)
+	}
+
 	if s.handlers != nil && s.handlers.AuthManager != nil {
 		s.handlers.AuthManager.SetRetryConfig(cfg.RequestRetry, time.Duration(cfg.MaxRetryInterval)*time.Second)
 	}
@@ -0,0 +1,112 @@
+// Package bedrock provides AWS credential resolution and Signature Version 4 request
+// signing for the AWS Bedrock runtime API.
+package bedrock
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Credentials holds the AWS key material used to sign Bedrock requests.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Valid reports whether both the access key ID and the secret access key are present.
+func (c Credentials) Valid() bool {
+	return strings.TrimSpace(c.AccessKeyID) != "" && strings.TrimSpace(c.SecretAccessKey) != ""
+}
+
+// DefaultCredentialsFile returns the AWS shared credentials file path, honoring
+// AWS_SHARED_CREDENTIALS_FILE before falling back to ~/.aws/credentials.
+func DefaultCredentialsFile() string {
+	if override := strings.TrimSpace(os.Getenv("AWS_SHARED_CREDENTIALS_FILE")); override != "" {
+		return override
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".aws", "credentials")
+}
+
+// LoadProfileCredentials reads the named profile from an AWS shared credentials file.
+// An empty path resolves to DefaultCredentialsFile and an empty profile to "default"; a leading ~ expands to the home directory.
+// The file is read on every call so rotated credentials are picked up without a restart.
+func LoadProfileCredentials(path, profile string) (Credentials, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = DefaultCredentialsFile()
+	}
+	if path == "" {
+		return Credentials{}, fmt.Errorf("bedrock: unable to resolve AWS credentials file")
+	}
+	if strings.HasPrefix(path, "~") {
+		if home, errHome := os.UserHomeDir(); errHome == nil {
+			path = filepath.Join(home, strings.TrimLeft(strings.TrimPrefix(path, "~"), "/\\"))
+		}
+	}
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		profile = "default"
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("bedrock: read credentials file: %w", err)
+	}
+	creds, found := parseCredentialsProfile(data, profile)
+	if !found {
+		return Credentials{}, fmt.Errorf("bedrock: profile %q not found in %s", profile, path)
+	}
+	if !creds.Valid() {
+		return Credentials{}, fmt.Errorf("bedrock: profile %q in %s is missing access keys", profile, path)
+	}
+	return creds, nil
+}
+
+// parseCredentialsProfile extracts the key material of a single profile from INI-formatted data.
+// Both "[name]" and "[profile name]" section headers are accepted.
+func parseCredentialsProfile(data []byte, profile string) (Credentials, bool) {
+	var creds Credentials
+	found := false
+	inProfile := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			name = strings.TrimSpace(strings.TrimPrefix(name, "profile "))
+			inProfile = name == profile
+			if inProfile {
+				found = true
+			}
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = value
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = value
+		case "aws_session_token":
+			creds.SessionToken = value
+		}
+	}
+	return creds, found
+}
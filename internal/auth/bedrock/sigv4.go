@@ -0,0 +1,175 @@
+package bedrock
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ServiceName is the SigV4 service identifier for the Bedrock runtime API.
+	ServiceName = "bedrock"
+
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+	amzDateOnly     = "20060102"
+	headerAmzDate   = "X-Amz-Date"
+	headerAmzToken  = "X-Amz-Security-Token"
+	headerAuthorize = "Authorization"
+)
+
+// SignRequest signs req in place with AWS Signature Version 4.
+// The signature covers the host, content-type and all x-amz-* headers present at signing time;
+// headers added afterwards are sent unsigned. body must be the exact payload sent upstream.
+func SignRequest(req *http.Request, body []byte, creds Credentials, region, service string, now time.Time) error {
+	if req == nil || req.URL == nil {
+		return fmt.Errorf("bedrock: request is nil")
+	}
+	if !creds.Valid() {
+		return fmt.Errorf("bedrock: missing AWS credentials")
+	}
+	region = strings.TrimSpace(region)
+	if region == "" {
+		return fmt.Errorf("bedrock: missing AWS region")
+	}
+	if service == "" {
+		service = ServiceName
+	}
+
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	dateStamp := now.Format(amzDateOnly)
+
+	req.Header.Del(headerAuthorize)
+	req.Header.Set(headerAmzDate, amzDate)
+	if token := strings.TrimSpace(creds.SessionToken); token != "" {
+		req.Header.Set(headerAmzToken, token)
+	} else {
+		req.Header.Del(headerAmzToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	canonicalHeaders, signedHeaders := canonicalizeHeaders(req.Header, host)
+	payloadHash := sha256Hex(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set(headerAuthorize, fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+// canonicalizeHeaders returns the canonical header block and the signed header list.
+func canonicalizeHeaders(headers http.Header, host string) (string, string) {
+	values := map[string]string{"host": strings.TrimSpace(host)}
+	for key, vals := range headers {
+		lower := strings.ToLower(key)
+		if lower != "content-type" && !strings.HasPrefix(lower, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, 0, len(vals))
+		for _, v := range vals {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+		}
+		values[lower] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteByte(':')
+		builder.WriteString(values[name])
+		builder.WriteByte('\n')
+	}
+	return builder.String(), strings.Join(names, ";")
+}
+
+// canonicalURI encodes each path segment of the already-escaped request path again,
+// as required by SigV4 for every service except S3.
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery sorts query parameters by key and value and URI-encodes both.
+func canonicalQuery(u *url.URL) string {
+	if u.RawQuery == "" {
+		return ""
+	}
+	query := u.Query()
+	pairs := make([]string, 0, len(query))
+	for key, vals := range query {
+		encodedKey := uriEncode(key)
+		for _, v := range vals {
+			pairs = append(pairs, encodedKey+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode applies the RFC 3986 encoding mandated by SigV4, leaving only unreserved characters as-is.
+func uriEncode(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			builder.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", c)
+	}
+	return builder.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package bedrock
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignRequest_GetVanilla uses the "get-vanilla" case from the AWS SigV4 test suite.
+func TestSignRequest_GetVanilla(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	creds := Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	if err = SignRequest(req, nil, creds, "us-east-1", "service", now); err != nil {
+		t.Fatalf("SignRequest() error = %v", err)
+	}
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Fatalf("X-Amz-Date = %q, want %q", got, "20150830T123600Z")
+	}
+}
+
+func TestSignRequest_SessionTokenIsSigned(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-v2%3A1/invoke", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	creds := Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}
+	if err = SignRequest(req, []byte(`{}`), creds, "us-east-1", ServiceName, time.Now()); err != nil {
+		t.Fatalf("SignRequest() error = %v", err)
+	}
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+		t.Fatalf("X-Amz-Security-Token = %q, want %q", got, "token")
+	}
+	if got := req.Header.Get("Authorization"); !strings.Contains(got, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Fatalf("Authorization = %q, want session token in signed headers", got)
+	}
+	if got := canonicalURI(req.URL); got != "/model/anthropic.claude-v2%253A1/invoke" {
+		t.Fatalf("canonicalURI() = %q, want double-encoded model segment", got)
+	}
+}
+
+func TestLoadProfileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = default-secret\n\n" +
+		"[profile team]\naws_access_key_id=AKIDTEAM\naws_secret_access_key=team-secret\naws_session_token=team-token\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	creds, err := LoadProfileCredentials(path, "team")
+	if err != nil {
+		t.Fatalf("LoadProfileCredentials() error = %v", err)
+	}
+	if creds.AccessKeyID != "AKIDTEAM" || creds.SecretAccessKey != "team-secret" || creds.SessionToken != "team-token" {
+		t.Fatalf("LoadProfileCredentials() = %+v, want team profile", creds)
+	}
+
+	creds, err = LoadProfileCredentials(path, "")
+	if err != nil {
+		t.Fatalf("LoadProfileCredentials(default) error = %v", err)
+	}
+	if creds.AccessKeyID != "AKIDDEFAULT" {
+		t.Fatalf("AccessKeyID = %q, want %q", creds.AccessKeyID, "AKIDDEFAULT")
+	}
+
+	if _, err = LoadProfileCredentials(path, "missing"); err == nil {
+		t.Fatalf("LoadProfileCredentials(missing) error = nil, want error")
+	}
+}
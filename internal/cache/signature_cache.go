@@ -1,17 +1,23 @@
 package cache
 
 import (
+	"container/list"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
 )
 
 // SignatureEntry holds a cached thinking signature with timestamp
 type SignatureEntry struct {
-	Signature string
-	Timestamp time.Time
+	Signature string    `json:"signature"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 const (
@@ -26,41 +32,198 @@ const (
 
 	// CacheCleanupInterval controls how often stale entries are purged
 	CacheCleanupInterval = 10 * time.Minute
+
+	// DefaultSignatureCacheMaxEntries bounds the in-memory cache when no size is configured
+	DefaultSignatureCacheMaxEntries = 10000
+
+	// signatureBackendTimeout bounds each call into a persistent backend
+	signatureBackendTimeout = 2 * time.Second
+
+	// signaturePurgeTimeout bounds a bulk purge of expired backend entries
+	signaturePurgeTimeout = time.Minute
+
+	// signatureAbsentTTL is how long a backend miss is remembered before the backend is asked
+	// again, so repeated lookups of unknown text do not each pay a backend round trip
+	signatureAbsentTTL = 30 * time.Second
 )
 
-// signatureCache stores signatures by model group -> textHash -> SignatureEntry
-var signatureCache sync.Map
+// SignatureBackend persists signatures beyond the in-memory cache so they survive restarts
+// and can be shared between replicas. Entries are addressed by model group and text hash.
+// Implementations must be safe for concurrent use.
+type SignatureBackend interface {
+	// LoadSignature returns the entry stored for group and hash, if any.
+	LoadSignature(ctx context.Context, group, hash string) (SignatureEntry, bool, error)
+	// SaveSignature stores or replaces the entry for group and hash.
+	SaveSignature(ctx context.Context, group, hash string, entry SignatureEntry) error
+	// DeleteSignatures removes every entry of group, or all entries when group is empty.
+	DeleteSignatures(ctx context.Context, group string) error
+}
+
+// SignaturePurger is implemented by backends that can drop expired entries in bulk. The
+// periodic cleanup calls it so persistent backends do not grow without bound.
+type SignaturePurger interface {
+	// PurgeSignatures removes entries last written before the cutoff.
+	PurgeSignatures(ctx context.Context, before time.Time) error
+}
+
+// SignatureCacheStats reports the activity of the signature cache since startup.
+type SignatureCacheStats struct {
+	Backend     string `json:"backend"`
+	Entries     int    `json:"entries"`
+	MaxEntries  int    `json:"max-entries"`
+	Hits        int64  `json:"hits"`
+	Misses      int64  `json:"misses"`
+	BackendHits int64  `json:"backend-hits"`
+	Evictions   int64  `json:"evictions"`
+	Errors      int64  `json:"backend-errors"`
+}
+
+// signatureItem is the value of a node in the LRU list.
+type signatureItem struct {
+	group string
+	hash  string
+	entry SignatureEntry
+	// savedAt is when the entry was last written to the backend.
+	savedAt time.Time
+}
+
+// signatureStore is a size-bounded LRU cache in front of an optional persistent backend.
+type signatureStore struct {
+	mu          sync.Mutex
+	order       *list.List
+	items       map[string]*list.Element
+	maxEntries  int
+	backend     SignatureBackend
+	backendName string
+	// absent maps keys the backend did not have to when that answer expires.
+	absent map[string]time.Time
+
+	hits        atomic.Int64
+	misses      atomic.Int64
+	backendHits atomic.Int64
+	evictions   atomic.Int64
+	errors      atomic.Int64
+}
+
+// signatureCache is the process-wide signature store.
+var signatureCache = newSignatureStore(DefaultSignatureCacheMaxEntries)
 
 // cacheCleanupOnce ensures the background cleanup goroutine starts only once
 var cacheCleanupOnce sync.Once
 
-// groupCache is the inner map type
-type groupCache struct {
-	mu      sync.RWMutex
-	entries map[string]SignatureEntry
+// signatureBackendMu serializes backend selection and guards signatureStoreBackend.
+var signatureBackendMu sync.Mutex
+
+// signatureStoreBackend is the Postgres or object store used by the "store" backend, if any.
+var signatureStoreBackend SignatureBackend
+
+func newSignatureStore(maxEntries int) *signatureStore {
+	return &signatureStore{
+		order:       list.New(),
+		items:       make(map[string]*list.Element),
+		maxEntries:  maxEntries,
+		backendName: "memory",
+		absent:      make(map[string]time.Time),
+	}
 }
 
-// hashText creates a stable, Unicode-safe key from text content
-func hashText(text string) string {
-	h := sha256.Sum256([]byte(text))
-	return hex.EncodeToString(h[:])[:SignatureTextHashLen]
+// SetSignatureStoreBackend registers the Postgres or object store that the "store" signature
+// cache backend writes to.
+func SetSignatureStoreBackend(backend SignatureBackend) {
+	signatureBackendMu.Lock()
+	signatureStoreBackend = backend
+	signatureBackendMu.Unlock()
 }
 
-// getOrCreateGroupCache gets or creates a cache bucket for a model group
-func getOrCreateGroupCache(groupKey string) *groupCache {
-	// Start background cleanup on first access
-	cacheCleanupOnce.Do(startCacheCleanup)
+// ApplySignatureCacheConfig opens the backend selected by cfg and installs it together with
+// the in-memory size bound. It runs at startup and on config reload; when the backend cannot
+// be opened the current one is kept and the error returned.
+func ApplySignatureCacheConfig(cfg config.SignatureCacheConfig) error {
+	signatureBackendMu.Lock()
+	defer signatureBackendMu.Unlock()
+	var backend SignatureBackend
+	switch cfg.Backend {
+	case config.SignatureCacheFile:
+		fileBackend, err := NewFileSignatureBackend(cfg.Path)
+		if err != nil {
+			return err
+		}
+		backend = fileBackend
+	case config.SignatureCacheStore:
+		if signatureStoreBackend == nil {
+			log.Warn("signature-cache: backend \"store\" requires a Postgres or object store, keeping signatures in memory")
+		}
+		backend = signatureStoreBackend
+	}
+	ConfigureSignatureCache(cfg.MaxEntries, cfg.Backend, backend)
+	return nil
+}
+
+// ConfigureSignatureCache sets the in-memory size bound and the persistent backend. A nil
+// backend keeps signatures in memory only. Cached entries are kept when the backend changes.
+func ConfigureSignatureCache(maxEntries int, name string, backend SignatureBackend) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultSignatureCacheMaxEntries
+	}
+	if backend == nil || name == "" {
+		name = "memory"
+	}
+	s := signatureCache
+	s.mu.Lock()
+	s.maxEntries = maxEntries
+	s.backend = backend
+	s.backendName = name
+	s.absent = make(map[string]time.Time)
+	s.evictLocked()
+	s.mu.Unlock()
+	if backend != nil {
+		cacheCleanupOnce.Do(startCacheCleanup)
+	}
+}
 
-	if val, ok := signatureCache.Load(groupKey); ok {
-		return val.(*groupCache)
+// ResizeSignatureCache changes the in-memory size bound, evicting the least recently used
+// entries when the cache shrinks.
+func ResizeSignatureCache(maxEntries int) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultSignatureCacheMaxEntries
 	}
-	sc := &groupCache{entries: make(map[string]SignatureEntry)}
-	actual, _ := signatureCache.LoadOrStore(groupKey, sc)
-	return actual.(*groupCache)
+	s := signatureCache
+	s.mu.Lock()
+	s.maxEntries = maxEntries
+	s.evictLocked()
+	s.mu.Unlock()
+}
+
+// GetSignatureCacheStats returns a snapshot of the signature cache counters.
+func GetSignatureCacheStats() SignatureCacheStats {
+	s := signatureCache
+	s.mu.Lock()
+	stats := SignatureCacheStats{
+		Backend:    s.backendName,
+		Entries:    s.order.Len(),
+		MaxEntries: s.maxEntries,
+	}
+	s.mu.Unlock()
+	stats.Hits = s.hits.Load()
+	stats.Misses = s.misses.Load()
+	stats.BackendHits = s.backendHits.Load()
+	stats.Evictions = s.evictions.Load()
+	stats.Errors = s.errors.Load()
+	return stats
+}
+
+func signatureKey(group, hash string) string {
+	return group + "\x00" + hash
+}
+
+// hashText creates a stable, Unicode-safe key from text content
+func hashText(text string) string {
+	h := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(h[:])[:SignatureTextHashLen]
 }
 
 // startCacheCleanup launches a background goroutine that periodically
-// removes caches where all entries have expired.
+// removes expired entries.
 func startCacheCleanup() {
 	go func() {
 		ticker := time.NewTicker(CacheCleanupInterval)
@@ -71,26 +234,184 @@ func startCacheCleanup() {
 	}()
 }
 
-// purgeExpiredCaches removes caches with no valid (non-expired) entries.
+// purgeExpiredCaches removes expired entries from the in-memory cache and from backends
+// that support bulk purging. Other backends are checked for expiry when read.
 func purgeExpiredCaches() {
+	s := signatureCache
+	now := time.Now()
+	s.mu.Lock()
+	for el := s.order.Back(); el != nil; {
+		prev := el.Prev()
+		item := el.Value.(*signatureItem)
+		if now.Sub(item.entry.Timestamp) > SignatureCacheTTL {
+			s.order.Remove(el)
+			delete(s.items, signatureKey(item.group, item.hash))
+		}
+		el = prev
+	}
+	for key, until := range s.absent {
+		if now.After(until) {
+			delete(s.absent, key)
+		}
+	}
+	backend := s.backend
+	s.mu.Unlock()
+
+	if purger, ok := backend.(SignaturePurger); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), signaturePurgeTimeout)
+		defer cancel()
+		if err := purger.PurgeSignatures(ctx, now.Add(-SignatureCacheTTL)); err != nil {
+			s.errors.Add(1)
+			log.Debugf("signature cache: purge backend failed: %v", err)
+		}
+	}
+}
+
+// putLocked inserts or refreshes an entry as the most recently used one.
+func (s *signatureStore) putLocked(item *signatureItem) {
+	key := signatureKey(item.group, item.hash)
+	if el, ok := s.items[key]; ok {
+		el.Value = item
+		s.order.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.order.PushFront(item)
+	s.evictLocked()
+}
+
+func (s *signatureStore) evictLocked() {
+	for s.order.Len() > s.maxEntries {
+		el := s.order.Back()
+		item := el.Value.(*signatureItem)
+		s.order.Remove(el)
+		delete(s.items, signatureKey(item.group, item.hash))
+		s.evictions.Add(1)
+	}
+}
+
+// save writes an entry to the backend without holding up the caller.
+func (s *signatureStore) save(backend SignatureBackend, group, hash string, entry SignatureEntry) {
+	if backend == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), signatureBackendTimeout)
+		defer cancel()
+		if err := backend.SaveSignature(ctx, group, hash, entry); err != nil {
+			s.errors.Add(1)
+			log.Debugf("signature cache: save to backend failed: %v", err)
+		}
+	}()
+}
+
+func (s *signatureStore) set(group, hash, signature string) {
 	now := time.Now()
-	signatureCache.Range(func(key, value any) bool {
-		sc := value.(*groupCache)
-		sc.mu.Lock()
-		// Remove expired entries
-		for k, entry := range sc.entries {
-			if now.Sub(entry.Timestamp) > SignatureCacheTTL {
-				delete(sc.entries, k)
+	entry := SignatureEntry{Signature: signature, Timestamp: now}
+	s.mu.Lock()
+	s.putLocked(&signatureItem{group: group, hash: hash, entry: entry, savedAt: now})
+	delete(s.absent, signatureKey(group, hash))
+	backend := s.backend
+	s.mu.Unlock()
+	s.save(backend, group, hash, entry)
+}
+
+func (s *signatureStore) get(group, hash string) (string, bool) {
+	now := time.Now()
+	key := signatureKey(group, hash)
+
+	s.mu.Lock()
+	if el, ok := s.items[key]; ok {
+		item := el.Value.(*signatureItem)
+		if now.Sub(item.entry.Timestamp) <= SignatureCacheTTL {
+			// Refresh TTL on access (sliding expiration), and push the refreshed
+			// timestamp to the backend once half of its copy's lifetime has passed.
+			item.entry.Timestamp = now
+			s.order.MoveToFront(el)
+			backend := s.backend
+			resave := backend != nil && now.Sub(item.savedAt) > SignatureCacheTTL/2
+			if resave {
+				item.savedAt = now
+			}
+			entry := item.entry
+			s.mu.Unlock()
+			if resave {
+				s.save(backend, group, hash, entry)
+			}
+			s.hits.Add(1)
+			return entry.Signature, true
+		}
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+	backend := s.backend
+	if until, ok := s.absent[key]; ok && now.Before(until) {
+		backend = nil
+	}
+	s.mu.Unlock()
+
+	if backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), signatureBackendTimeout)
+		entry, ok, err := backend.LoadSignature(ctx, group, hash)
+		cancel()
+		if err != nil {
+			s.errors.Add(1)
+			log.Debugf("signature cache: load from backend failed: %v", err)
+			s.markAbsent(key, now)
+		} else if !ok || now.Sub(entry.Timestamp) > SignatureCacheTTL {
+			s.markAbsent(key, now)
+		} else {
+			savedAt := entry.Timestamp
+			entry.Timestamp = now
+			s.mu.Lock()
+			s.putLocked(&signatureItem{group: group, hash: hash, entry: entry, savedAt: savedAt})
+			s.mu.Unlock()
+			s.hits.Add(1)
+			s.backendHits.Add(1)
+			return entry.Signature, true
+		}
+	}
+	s.misses.Add(1)
+	return "", false
+}
+
+// markAbsent remembers that the backend had no entry for key. The set is bounded like the
+// cache itself and simply starts over when full.
+func (s *signatureStore) markAbsent(key string, now time.Time) {
+	s.mu.Lock()
+	if len(s.absent) >= s.maxEntries {
+		s.absent = make(map[string]time.Time)
+	}
+	s.absent[key] = now.Add(signatureAbsentTTL)
+	s.mu.Unlock()
+}
+
+func (s *signatureStore) clear(group string) {
+	s.mu.Lock()
+	s.absent = make(map[string]time.Time)
+	if group == "" {
+		s.order.Init()
+		s.items = make(map[string]*list.Element)
+	} else {
+		for el := s.order.Front(); el != nil; {
+			next := el.Next()
+			if item := el.Value.(*signatureItem); item.group == group {
+				s.order.Remove(el)
+				delete(s.items, signatureKey(item.group, item.hash))
 			}
+			el = next
 		}
-		isEmpty := len(sc.entries) == 0
-		sc.mu.Unlock()
-		// Remove cache bucket if empty
-		if isEmpty {
-			signatureCache.Delete(key)
+	}
+	backend := s.backend
+	s.mu.Unlock()
+
+	if backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), signatureBackendTimeout)
+		defer cancel()
+		if err := backend.DeleteSignatures(ctx, group); err != nil {
+			s.errors.Add(1)
+			log.Warnf("signature cache: clear backend failed: %v", err)
 		}
-		return true
-	})
+	}
 }
 
 // CacheSignature stores a thinking signature for a given model group and text.
@@ -102,17 +423,10 @@ func CacheSignature(modelName, text, signature string) {
 	if len(signature) < MinValidSignatureLen {
 		return
 	}
+	// Start background cleanup on first use
+	cacheCleanupOnce.Do(startCacheCleanup)
 
-	groupKey := GetModelGroup(modelName)
-	textHash := hashText(text)
-	sc := getOrCreateGroupCache(groupKey)
-	sc.mu.Lock()
-	defer sc.mu.Unlock()
-
-	sc.entries[textHash] = SignatureEntry{
-		Signature: signature,
-		Timestamp: time.Now(),
-	}
+	signatureCache.set(GetModelGroup(modelName), hashText(text), signature)
 }
 
 // GetCachedSignature retrieves a cached signature for a given model group and text.
@@ -120,62 +434,25 @@ func CacheSignature(modelName, text, signature string) {
 func GetCachedSignature(modelName, text string) string {
 	groupKey := GetModelGroup(modelName)
 
-	if text == "" {
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
+	if text != "" {
+		if signature, ok := signatureCache.get(groupKey, hashText(text)); ok {
+			return signature
 		}
-		return ""
 	}
-	val, ok := signatureCache.Load(groupKey)
-	if !ok {
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
+	if groupKey == "gemini" {
+		return "skip_thought_signature_validator"
 	}
-	sc := val.(*groupCache)
-
-	textHash := hashText(text)
-
-	now := time.Now()
-
-	sc.mu.Lock()
-	entry, exists := sc.entries[textHash]
-	if !exists {
-		sc.mu.Unlock()
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
-	}
-	if now.Sub(entry.Timestamp) > SignatureCacheTTL {
-		delete(sc.entries, textHash)
-		sc.mu.Unlock()
-		if groupKey == "gemini" {
-			return "skip_thought_signature_validator"
-		}
-		return ""
-	}
-
-	// Refresh TTL on access (sliding expiration).
-	entry.Timestamp = now
-	sc.entries[textHash] = entry
-	sc.mu.Unlock()
-
-	return entry.Signature
+	return ""
 }
 
-// ClearSignatureCache clears signature cache for a specific model group or all groups.
+// ClearSignatureCache clears signature cache for a specific model group or all groups,
+// including the copies held by the persistent backend.
 func ClearSignatureCache(modelName string) {
 	if modelName == "" {
-		signatureCache.Range(func(key, _ any) bool {
-			signatureCache.Delete(key)
-			return true
-		})
+		signatureCache.clear("")
 		return
 	}
-	groupKey := GetModelGroup(modelName)
-	signatureCache.Delete(groupKey)
+	signatureCache.clear(GetModelGroup(modelName))
 }
 
 // HasValidSignature checks if a signature is valid (non-empty and long enough)
@@ -1,8 +1,13 @@
 package cache
 
 import (
+	"container/list"
+	"context"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 )
 
 const testModelName = "claude-sonnet-4-5"
@@ -208,3 +213,136 @@ func TestCacheSignature_ExpirationLogic(t *testing.T) {
 	// but the logic is verified by the implementation
 	_ = time.Now() // Acknowledge we're not testing time passage
 }
+
+func TestSignatureCache_LRUEviction(t *testing.T) {
+	ClearSignatureCache("")
+	t.Cleanup(func() { ConfigureSignatureCache(0, "", nil) })
+	ConfigureSignatureCache(2, "", nil)
+
+	sig := "validSig1234567890123456789012345678901234567890123456"
+	before := GetSignatureCacheStats()
+	CacheSignature(testModelName, "a", sig)
+	CacheSignature(testModelName, "b", sig)
+	// Touching "a" makes "b" the least recently used entry.
+	GetCachedSignature(testModelName, "a")
+	CacheSignature(testModelName, "c", sig)
+
+	if GetCachedSignature(testModelName, "b") != "" {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if GetCachedSignature(testModelName, "a") != sig || GetCachedSignature(testModelName, "c") != sig {
+		t.Error("recent entries should remain cached")
+	}
+	stats := GetSignatureCacheStats()
+	if stats.Entries != 2 || stats.Evictions-before.Evictions != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.Hits-before.Hits != 3 || stats.Misses-before.Misses != 1 {
+		t.Errorf("hits/misses = %d/%d, want 3/1", stats.Hits-before.Hits, stats.Misses-before.Misses)
+	}
+}
+
+func TestSignatureCache_FileBackendSurvivesRestart(t *testing.T) {
+	backend, err := NewFileSignatureBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	t.Cleanup(func() { ConfigureSignatureCache(0, "", nil) })
+	ConfigureSignatureCache(0, "file", backend)
+	ClearSignatureCache("")
+
+	sig := "fileSig12345678901234567890123456789012345678901234567"
+	CacheSignature(testModelName, "persisted text", sig)
+
+	// Writes reach the backend asynchronously.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok, _ := backend.LoadSignature(context.Background(), "claude", hashText("persisted text")); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("signature was not written to the file backend")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Simulate a restart by dropping the in-memory entries only.
+	signatureCache.mu.Lock()
+	signatureCache.order.Init()
+	signatureCache.items = make(map[string]*list.Element)
+	signatureCache.mu.Unlock()
+
+	before := GetSignatureCacheStats()
+	if got := GetCachedSignature(testModelName, "persisted text"); got != sig {
+		t.Fatalf("expected signature from the file backend, got %q", got)
+	}
+	if stats := GetSignatureCacheStats(); stats.BackendHits-before.BackendHits != 1 || stats.Backend != "file" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	ClearSignatureCache(testModelName)
+	if _, ok, _ := backend.LoadSignature(context.Background(), "claude", hashText("persisted text")); ok {
+		t.Error("clearing the cache should clear the backend")
+	}
+}
+
+// countingBackend counts LoadSignature calls and never has an entry.
+type countingBackend struct {
+	loads atomic.Int32
+}
+
+func (b *countingBackend) LoadSignature(context.Context, string, string) (SignatureEntry, bool, error) {
+	b.loads.Add(1)
+	return SignatureEntry{}, false, nil
+}
+
+func (b *countingBackend) SaveSignature(context.Context, string, string, SignatureEntry) error {
+	return nil
+}
+
+func (b *countingBackend) DeleteSignatures(context.Context, string) error {
+	return nil
+}
+
+func TestSignatureCache_RemembersBackendMisses(t *testing.T) {
+	backend := &countingBackend{}
+	t.Cleanup(func() { ConfigureSignatureCache(0, "", nil) })
+	ConfigureSignatureCache(0, "store", backend)
+
+	for range 3 {
+		if got := GetCachedSignature(testModelName, "unknown text"); got != "" {
+			t.Fatalf("expected a miss, got %q", got)
+		}
+	}
+	if n := backend.loads.Load(); n != 1 {
+		t.Fatalf("backend loads = %d, want 1", n)
+	}
+
+	// Caching the text locally replaces the remembered miss.
+	sig := "validSig1234567890123456789012345678901234567890123456"
+	CacheSignature(testModelName, "unknown text", sig)
+	if got := GetCachedSignature(testModelName, "unknown text"); got != sig {
+		t.Fatalf("expected the cached signature, got %q", got)
+	}
+}
+
+func TestApplySignatureCacheConfig_SwitchesBackend(t *testing.T) {
+	t.Cleanup(func() { ConfigureSignatureCache(0, "", nil) })
+
+	dir := t.TempDir()
+	if err := ApplySignatureCacheConfig(config.SignatureCacheConfig{Backend: config.SignatureCacheFile, Path: dir, MaxEntries: 5}); err != nil {
+		t.Fatalf("apply file backend: %v", err)
+	}
+	if stats := GetSignatureCacheStats(); stats.Backend != "file" || stats.MaxEntries != 5 {
+		t.Fatalf("unexpected stats after switching to file: %+v", stats)
+	}
+
+	// A missing store falls back to memory.
+	SetSignatureStoreBackend(nil)
+	if err := ApplySignatureCacheConfig(config.SignatureCacheConfig{Backend: config.SignatureCacheStore}); err != nil {
+		t.Fatalf("apply store backend: %v", err)
+	}
+	if stats := GetSignatureCacheStats(); stats.Backend != "memory" {
+		t.Fatalf("expected memory without a store, got %+v", stats)
+	}
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSignatureBackend stores each signature as a small JSON file below a directory, one
+// sub-directory per model group. Pointing several replicas at a shared volume shares the cache.
+type FileSignatureBackend struct {
+	dir string
+}
+
+// NewFileSignatureBackend creates the directory if needed and returns a backend rooted at it.
+func NewFileSignatureBackend(dir string) (*FileSignatureBackend, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return nil, fmt.Errorf("signature cache: file backend requires a path")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("signature cache: resolve path: %w", err)
+	}
+	if err = os.MkdirAll(abs, 0o700); err != nil {
+		return nil, fmt.Errorf("signature cache: create directory: %w", err)
+	}
+	return &FileSignatureBackend{dir: abs}, nil
+}
+
+func (b *FileSignatureBackend) groupDir(group string) string {
+	return filepath.Join(b.dir, url.PathEscape(group))
+}
+
+// LoadSignature implements SignatureBackend.
+func (b *FileSignatureBackend) LoadSignature(_ context.Context, group, hash string) (SignatureEntry, bool, error) {
+	var entry SignatureEntry
+	data, err := os.ReadFile(filepath.Join(b.groupDir(group), hash+".json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return entry, false, nil
+		}
+		return entry, false, err
+	}
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return entry, false, fmt.Errorf("signature cache: decode %s/%s: %w", group, hash, err)
+	}
+	return entry, entry.Signature != "", nil
+}
+
+// SaveSignature implements SignatureBackend. Files are replaced atomically so concurrent
+// readers never observe a partial entry.
+func (b *FileSignatureBackend) SaveSignature(_ context.Context, group, hash string, entry SignatureEntry) error {
+	dir := b.groupDir(group)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, hash+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, hash+".json"))
+}
+
+// DeleteSignatures implements SignatureBackend.
+func (b *FileSignatureBackend) DeleteSignatures(_ context.Context, group string) error {
+	if group != "" {
+		return os.RemoveAll(b.groupDir(group))
+	}
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if errRemove := os.RemoveAll(filepath.Join(b.dir, entry.Name())); errRemove != nil {
+			return errRemove
+		}
+	}
+	return nil
+}
+
+// PurgeSignatures implements SignaturePurger.
+func (b *FileSignatureBackend) PurgeSignatures(_ context.Context, before time.Time) error {
+	return filepath.WalkDir(b.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if info, errInfo := d.Info(); errInfo == nil && info.ModTime().Before(before) {
+			_ = os.Remove(path)
+		}
+		return nil
+	})
+}
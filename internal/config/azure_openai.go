@@ -0,0 +1,106 @@
+package config
+
+import "strings"
+
+// DefaultAzureOpenAIAPIVersion is the api-version query parameter used for Azure OpenAI
+// deployment endpoints when a credential does not configure one.
+const DefaultAzureOpenAIAPIVersion = "2024-10-21"
+
+// AzureOpenAIKey represents an Azure OpenAI resource credential.
+// Chat requests are sent to "/openai/deployments/{deployment}/chat/completions?api-version=..."
+// and Responses requests to "/openai/v1/responses", both authenticated with the api-key header.
+type AzureOpenAIKey struct {
+	// APIKey is the Azure OpenAI resource key sent in the api-key header.
+	APIKey string `yaml:"api-key" json:"api-key"`
+
+	// BaseURL is the resource endpoint, e.g. "https://my-resource.openai.azure.com".
+	BaseURL string `yaml:"base-url" json:"base-url"`
+
+	// APIVersion is the api-version query parameter for deployment endpoints.
+	// Defaults to DefaultAzureOpenAIAPIVersion when empty.
+	APIVersion string `yaml:"api-version,omitempty" json:"api-version,omitempty"`
+
+	// Priority controls selection preference when multiple credentials match.
+	// Higher values are preferred; defaults to 0.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Prefix optionally namespaces models for this credential (e.g., "azure/gpt-4o").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// ProxyURL overrides the global proxy setting for this credential if provided.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Models maps client-facing model names (Alias) to deployment names (Name).
+	// When empty, the built-in OpenAI models are exposed and used as deployment names.
+	Models []AzureOpenAIModel `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Headers optionally adds extra HTTP headers for requests sent with this credential.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// ExcludedModels lists model IDs that should be excluded for this provider.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+}
+
+func (k AzureOpenAIKey) GetAPIKey() string  { return k.APIKey }
+func (k AzureOpenAIKey) GetBaseURL() string { return k.BaseURL }
+
+// AzureOpenAIModel maps a client-facing model name to an Azure OpenAI deployment.
+type AzureOpenAIModel struct {
+	// Name is the Azure deployment name used when issuing requests.
+	Name string `yaml:"name" json:"name"`
+
+	// Alias is the client-facing model name that maps to Name.
+	Alias string `yaml:"alias" json:"alias"`
+
+	// Capabilities lists the request features the deployment accepts.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
+}
+
+func (m AzureOpenAIModel) GetName() string           { return m.Name }
+func (m AzureOpenAIModel) GetAlias() string          { return m.Alias }
+func (m AzureOpenAIModel) GetCapabilities() []string { return m.Capabilities }
+
+// SanitizeAzureOpenAIKeys normalizes Azure OpenAI credentials and drops entries
+// without an API key or resource endpoint.
+func (cfg *Config) SanitizeAzureOpenAIKeys() {
+	if cfg == nil || len(cfg.AzureOpenAIKey) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.AzureOpenAIKey))
+	out := make([]AzureOpenAIKey, 0, len(cfg.AzureOpenAIKey))
+	for i := range cfg.AzureOpenAIKey {
+		entry := cfg.AzureOpenAIKey[i]
+		entry.APIKey = strings.TrimSpace(entry.APIKey)
+		entry.BaseURL = strings.TrimSuffix(strings.TrimSpace(entry.BaseURL), "/")
+		if entry.APIKey == "" || entry.BaseURL == "" {
+			continue
+		}
+		entry.APIVersion = strings.TrimSpace(entry.APIVersion)
+		if entry.APIVersion == "" {
+			entry.APIVersion = DefaultAzureOpenAIAPIVersion
+		}
+		entry.Prefix = normalizeModelPrefix(entry.Prefix)
+		entry.ProxyURL = strings.TrimSpace(entry.ProxyURL)
+		entry.Headers = NormalizeHeaders(entry.Headers)
+		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
+
+		sanitizedModels := make([]AzureOpenAIModel, 0, len(entry.Models))
+		for _, model := range entry.Models {
+			model.Name = strings.TrimSpace(model.Name)
+			model.Alias = strings.TrimSpace(model.Alias)
+			if model.Name == "" {
+				continue
+			}
+			sanitizedModels = append(sanitizedModels, model)
+		}
+		entry.Models = sanitizedModels
+
+		uniqueKey := entry.APIKey + "|" + strings.ToLower(entry.BaseURL)
+		if _, exists := seen[uniqueKey]; exists {
+			continue
+		}
+		seen[uniqueKey] = struct{}{}
+		out = append(out, entry)
+	}
+	cfg.AzureOpenAIKey = out
+}
@@ -0,0 +1,133 @@
+package config
+
+import "strings"
+
+// BedrockKey represents an AWS Bedrock credential used to serve Anthropic Claude models.
+// Requests are signed with AWS Signature Version 4 using either static access keys
+// or a named profile from an AWS shared credentials file.
+type BedrockKey struct {
+	// AccessKeyID is the AWS access key identifier used for request signing.
+	AccessKeyID string `yaml:"access-key-id,omitempty" json:"access-key-id,omitempty"`
+
+	// SecretAccessKey is the AWS secret access key paired with AccessKeyID.
+	SecretAccessKey string `yaml:"secret-access-key,omitempty" json:"secret-access-key,omitempty"`
+
+	// SessionToken is an optional AWS session token for temporary credentials.
+	SessionToken string `yaml:"session-token,omitempty" json:"session-token,omitempty"`
+
+	// Profile selects a profile from the AWS shared credentials file when static keys are not set.
+	// Defaults to "default" when CredentialsFile is provided without a profile.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+
+	// CredentialsFile overrides the AWS shared credentials file path (defaults to ~/.aws/credentials).
+	CredentialsFile string `yaml:"credentials-file,omitempty" json:"credentials-file,omitempty"`
+
+	// Region is the AWS region hosting the Bedrock runtime endpoint (e.g., "us-east-1").
+	Region string `yaml:"region" json:"region"`
+
+	// Priority controls selection preference when multiple credentials match.
+	// Higher values are preferred; defaults to 0.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// Prefix optionally namespaces models for this credential (e.g., "aws/claude-sonnet-4").
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+
+	// BaseURL optionally overrides the Bedrock runtime endpoint.
+	// If empty, "https://bedrock-runtime.{region}.amazonaws.com" is used.
+	BaseURL string `yaml:"base-url,omitempty" json:"base-url,omitempty"`
+
+	// ProxyURL overrides the global proxy setting for this credential if provided.
+	ProxyURL string `yaml:"proxy-url,omitempty" json:"proxy-url,omitempty"`
+
+	// Models maps client-facing Claude model names (Alias) to Bedrock model or
+	// inference profile IDs (Name), e.g. "us.anthropic.claude-sonnet-4-20250514-v1:0".
+	Models []ClaudeModel `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// Headers optionally adds extra HTTP headers for requests sent with this credential.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// ExcludedModels lists model IDs that should be excluded for this provider.
+	ExcludedModels []string `yaml:"excluded-models,omitempty" json:"excluded-models,omitempty"`
+}
+
+// SanitizeBedrockKeys normalizes Bedrock credentials and drops entries that cannot be used,
+// namely those without a region or without either static keys or a profile source.
+func (cfg *Config) SanitizeBedrockKeys() {
+	if cfg == nil || len(cfg.BedrockKey) == 0 {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.BedrockKey))
+	out := make([]BedrockKey, 0, len(cfg.BedrockKey))
+	for i := range cfg.BedrockKey {
+		entry := cfg.BedrockKey[i]
+		entry.AccessKeyID = strings.TrimSpace(entry.AccessKeyID)
+		entry.SecretAccessKey = strings.TrimSpace(entry.SecretAccessKey)
+		entry.SessionToken = strings.TrimSpace(entry.SessionToken)
+		entry.Profile = strings.TrimSpace(entry.Profile)
+		entry.CredentialsFile = strings.TrimSpace(entry.CredentialsFile)
+		entry.Region = strings.ToLower(strings.TrimSpace(entry.Region))
+		entry.Prefix = normalizeModelPrefix(entry.Prefix)
+		entry.BaseURL = strings.TrimSpace(entry.BaseURL)
+		entry.ProxyURL = strings.TrimSpace(entry.ProxyURL)
+		entry.Headers = NormalizeHeaders(entry.Headers)
+		entry.ExcludedModels = NormalizeExcludedModels(entry.ExcludedModels)
+		if entry.Region == "" {
+			continue
+		}
+		hasStatic := entry.AccessKeyID != "" && entry.SecretAccessKey != ""
+		if !hasStatic && entry.Profile == "" && entry.CredentialsFile == "" {
+			continue
+		}
+		if !hasStatic && entry.Profile == "" {
+			entry.Profile = "default"
+		}
+
+		sanitizedModels := make([]ClaudeModel, 0, len(entry.Models))
+		for _, model := range entry.Models {
+			model.Name = strings.TrimSpace(model.Name)
+			model.Alias = strings.TrimSpace(model.Alias)
+			if model.Name == "" {
+				continue
+			}
+			sanitizedModels = append(sanitizedModels, model)
+		}
+		entry.Models = sanitizedModels
+
+		uniqueKey := entry.AccessKeyID + "|" + entry.Profile + "|" + entry.CredentialsFile + "|" + entry.Region + "|" + entry.BaseURL
+		if _, exists := seen[uniqueKey]; exists {
+			continue
+		}
+		seen[uniqueKey] = struct{}{}
+		out = append(out, entry)
+	}
+	cfg.BedrockKey = out
+}
+
+// BedrockKeyFor returns the Bedrock entry an auth was synthesized from, matched on the region,
+// base URL and credential source recorded in its attributes.
+func (cfg *Config) BedrockKeyFor(attrs map[string]string) *BedrockKey {
+	if cfg == nil || attrs == nil {
+		return nil
+	}
+	attrKeyID := strings.TrimSpace(attrs["access_key_id"])
+	attrProfile := strings.TrimSpace(attrs["profile"])
+	attrFile := strings.TrimSpace(attrs["credentials_file"])
+	attrRegion := strings.TrimSpace(attrs["region"])
+	attrBase := strings.TrimSpace(attrs["base_url"])
+	for i := range cfg.BedrockKey {
+		entry := &cfg.BedrockKey[i]
+		if !strings.EqualFold(strings.TrimSpace(entry.Region), attrRegion) || !strings.EqualFold(strings.TrimSpace(entry.BaseURL), attrBase) {
+			continue
+		}
+		if attrKeyID != "" {
+			if strings.TrimSpace(entry.AccessKeyID) == attrKeyID {
+				return entry
+			}
+			continue
+		}
+		if strings.TrimSpace(entry.AccessKeyID) == "" && strings.TrimSpace(entry.Profile) == attrProfile && strings.TrimSpace(entry.CredentialsFile) == attrFile {
+			return entry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Circuit breaker keys.
+const (
+	CircuitBreakerKeyBaseURL = "base-url"
+	CircuitBreakerKeyHost    = "host"
+)
+
+// CircuitBreakerConfig configures breakers over the upstream endpoints of credentials with a
+// custom base URL (openai-compatibility providers and API keys with base-url).
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive upstream failures (5xx, 408, network errors
+	// and stalled streams) that opens the breaker of an endpoint. <= 0 disables breakers.
+	FailureThreshold int `yaml:"failure-threshold,omitempty" json:"failure-threshold,omitempty"`
+
+	// OpenSeconds is how long an open breaker skips its credentials before letting a probe
+	// through. Default is 30.
+	OpenSeconds int `yaml:"open-seconds,omitempty" json:"open-seconds,omitempty"`
+
+	// HalfOpenProbes is the number of successful probes that close a half-open breaker.
+	// Default is 1.
+	HalfOpenProbes int `yaml:"half-open-probes,omitempty" json:"half-open-probes,omitempty"`
+
+	// Key groups credentials by their full base URL ("base-url", default) or by host ("host").
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+}
+
+// SanitizeCircuitBreaker applies circuit breaker defaults.
+func (cfg *Config) SanitizeCircuitBreaker() {
+	if cfg == nil {
+		return
+	}
+	cb := &cfg.CircuitBreaker
+	cb.Key = strings.ToLower(strings.TrimSpace(cb.Key))
+	switch cb.Key {
+	case "", CircuitBreakerKeyBaseURL, CircuitBreakerKeyHost:
+	default:
+		log.Warnf("circuit-breaker: unknown key %q, using %s", cb.Key, CircuitBreakerKeyBaseURL)
+		cb.Key = CircuitBreakerKeyBaseURL
+	}
+}
+
+// Enabled reports whether breakers are active.
+func (c CircuitBreakerConfig) Enabled() bool {
+	return c.FailureThreshold > 0
+}
+
+// OpenDuration returns how long a breaker stays open.
+func (c CircuitBreakerConfig) OpenDuration() time.Duration {
+	if c.OpenSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.OpenSeconds) * time.Second
+}
+
+// ProbesToClose returns the successful probes needed to close a half-open breaker.
+func (c CircuitBreakerConfig) ProbesToClose() int {
+	return max(c.HalfOpenProbes, 1)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// ConcurrencyConfig limits how many requests run at once on a single credential.
+type ConcurrencyConfig struct {
+	// Defaults maps provider keys (e.g., "claude", "codex", "gemini-cli") to the maximum number
+	// of concurrent requests per credential. Auth files override it with "max_concurrency".
+	// Missing or <= 0 means unlimited.
+	Defaults map[string]int `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+
+	// QueueSize bounds the requests waiting for a free credential when all are saturated.
+	// Default is 100; a negative value rejects such requests at once.
+	QueueSize int `yaml:"queue-size,omitempty" json:"queue-size,omitempty"`
+
+	// QueueTimeoutSeconds is how long a request waits in the queue before failing. Default is 30.
+	QueueTimeoutSeconds int `yaml:"queue-timeout-seconds,omitempty" json:"queue-timeout-seconds,omitempty"`
+}
+
+// SanitizeConcurrency normalizes the provider keys of the concurrency defaults.
+func (cfg *Config) SanitizeConcurrency() {
+	if cfg == nil || len(cfg.Concurrency.Defaults) == 0 {
+		return
+	}
+	defaults := make(map[string]int, len(cfg.Concurrency.Defaults))
+	for provider, limit := range cfg.Concurrency.Defaults {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		if provider == "" || limit <= 0 {
+			continue
+		}
+		defaults[provider] = limit
+	}
+	cfg.Concurrency.Defaults = defaults
+}
+
+// QueueCapacity returns the maximum number of waiting requests.
+func (c ConcurrencyConfig) QueueCapacity() int {
+	switch {
+	case c.QueueSize < 0:
+		return 0
+	case c.QueueSize == 0:
+		return 100
+	default:
+		return c.QueueSize
+	}
+}
+
+// QueueTimeout returns how long a request may wait for a free credential.
+func (c ConcurrencyConfig) QueueTimeout() time.Duration {
+	if c.QueueTimeoutSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.QueueTimeoutSeconds) * time.Second
+}
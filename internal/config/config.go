@@ -9,7 +9,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"syscall"
 
@@ -71,6 +73,26 @@ type Config struct {
 	RequestRetry int `yaml:"request-retry" json:"request-retry"`
 	// MaxRetryInterval defines the maximum wait time in seconds before retrying a cooled-down credential.
 	MaxRetryInterval int `yaml:"max-retry-interval" json:"max-retry-interval"`
+	// RetryPolicies tune retries per provider: retryable statuses and error bodies, backoff,
+	// attempt limits and whether to rotate credentials. The first matching policy wins.
+	RetryPolicies []RetryPolicy `yaml:"retry-policy,omitempty" json:"retry-policy,omitempty"`
+
+	// CircuitBreaker skips every credential behind an upstream endpoint that keeps failing.
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker,omitempty" json:"circuit-breaker,omitempty"`
+
+	// Concurrency caps concurrent requests per credential and queues requests while every
+	// credential is busy.
+	Concurrency ConcurrencyConfig `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+
+	// Pacing spaces requests per credential and model to stay under upstream rate limits.
+	Pacing PacingConfig `yaml:"pacing,omitempty" json:"pacing,omitempty"`
+
+	// Priority assigns client API keys to priority classes and reserves credentials for them.
+	Priority PriorityConfig `yaml:"priority-classes,omitempty" json:"priority-classes,omitempty"`
+
+	// Hedging sends a duplicate non-streaming request on another credential when the upstream
+	// is slow to respond. The first matching rule wins.
+	Hedging []HedgingRule `yaml:"hedging,omitempty" json:"hedging,omitempty"`
 
 	// QuotaExceeded defines the behavior when a quota is exceeded.
 	QuotaExceeded QuotaExceeded `yaml:"quota-exceeded" json:"quota-exceeded"`
@@ -90,6 +112,12 @@ type Config struct {
 	// ClaudeKey defines a list of Claude API key configurations as specified in the YAML configuration file.
 	ClaudeKey []ClaudeKey `yaml:"claude-api-key" json:"claude-api-key"`
 
+	// BedrockKey defines AWS Bedrock credentials used to serve Claude models.
+	BedrockKey []BedrockKey `yaml:"bedrock" json:"bedrock"`
+
+	// AzureOpenAIKey defines Azure OpenAI resources with per-model deployment mappings.
+	AzureOpenAIKey []AzureOpenAIKey `yaml:"azure-openai" json:"azure-openai"`
+
 	// ClaudeHeaderDefaults configures default header values for Claude API requests.
 	// These are used as fallbacks when the client does not send its own headers.
 	ClaudeHeaderDefaults ClaudeHeaderDefaults `yaml:"claude-header-defaults" json:"claude-header-defaults"`
@@ -118,6 +146,22 @@ type Config struct {
 	// Payload defines default and override rules for provider payload parameters.
 	Payload PayloadConfig `yaml:"payload" json:"payload"`
 
+	// ResponsePayload lists operation rules applied to upstream responses after translation,
+	// to non-streaming bodies and to every streamed SSE event.
+	ResponsePayload []PayloadOperationRule `yaml:"response-payload,omitempty" json:"response-payload,omitempty"`
+
+	// ModelDefinitions configures external sources that override the embedded model catalog.
+	ModelDefinitions ModelDefinitionsConfig `yaml:"model-definitions" json:"model-definitions"`
+
+	// RemoteMedia controls downloading remote media URLs for providers that need inline data.
+	RemoteMedia RemoteMediaConfig `yaml:"remote-media" json:"remote-media"`
+
+	// ContextCache controls explicit Gemini context caching of long prompt prefixes.
+	ContextCache ContextCacheConfig `yaml:"context-cache" json:"context-cache"`
+
+	// SignatureCache selects where thinking signatures are cached and how many are kept in memory.
+	SignatureCache SignatureCacheConfig `yaml:"signature-cache" json:"signature-cache"`
+
 	legacyMigrationPending bool `yaml:"-" json:"-"`
 }
 
@@ -257,12 +301,17 @@ type PayloadConfig struct {
 	OverrideRaw []PayloadRule `yaml:"override-raw" json:"override-raw"`
 	// Filter defines rules that remove parameters from the payload by JSON path.
 	Filter []PayloadFilterRule `yaml:"filter" json:"filter"`
+	// Operations defines rules that apply ordered edits such as array appends and key renames.
+	// They run after all other sections.
+	Operations []PayloadOperationRule `yaml:"operations,omitempty" json:"operations,omitempty"`
 }
 
 // PayloadFilterRule describes a rule to remove specific JSON paths from matching model payloads.
 type PayloadFilterRule struct {
 	// Models lists model entries with name pattern and protocol constraint.
 	Models []PayloadModelRule `yaml:"models" json:"models"`
+	// Match narrows the rule to specific clients, headers, formats, credentials or payloads.
+	Match PayloadMatch `yaml:"match,omitempty" json:"match,omitempty"`
 	// Params lists JSON paths (gjson/sjson syntax) to remove from the payload.
 	Params []string `yaml:"params" json:"params"`
 }
@@ -271,11 +320,78 @@ type PayloadFilterRule struct {
 type PayloadRule struct {
 	// Models lists model entries with name pattern and protocol constraint.
 	Models []PayloadModelRule `yaml:"models" json:"models"`
+	// Match narrows the rule to specific clients, headers, formats, credentials or payloads.
+	Match PayloadMatch `yaml:"match,omitempty" json:"match,omitempty"`
 	// Params maps JSON paths (gjson/sjson syntax) to values written into the payload.
 	// For *-raw rules, values are treated as raw JSON fragments (strings are used as-is).
 	Params map[string]any `yaml:"params" json:"params"`
 }
 
+// PayloadMatch lists request conditions a payload rule requires in addition to its model
+// entries. Every non-empty field must match; empty fields match any request.
+type PayloadMatch struct {
+	// APIKeys lists the client API keys the rule applies to.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+	// Headers maps client request header names to value patterns ("*" wildcards). An empty
+	// pattern only requires the header to be present.
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	// SourceFormats lists the client request formats (e.g., "openai", "claude", "gemini").
+	SourceFormats []string `yaml:"source-formats,omitempty" json:"source-formats,omitempty"`
+	// Prefixes lists credential prefixes; the rule applies only to requests served by a
+	// credential configured with one of them.
+	Prefixes []string `yaml:"prefixes,omitempty" json:"prefixes,omitempty"`
+	// When maps JSON paths, relative to the payload root, to the values they must hold.
+	// "*" requires the path to exist, null requires it to be absent or null, and other strings
+	// are matched as "*" wildcard patterns.
+	When map[string]any `yaml:"when,omitempty" json:"when,omitempty"`
+}
+
+// Payload operation kinds.
+const (
+	// PayloadOpSet writes Value at Path.
+	PayloadOpSet = "set"
+	// PayloadOpSetRaw writes the raw JSON fragment in Value at Path.
+	PayloadOpSetRaw = "set-raw"
+	// PayloadOpDelete removes Path.
+	PayloadOpDelete = "delete"
+	// PayloadOpAppend appends Value to the array at Path, creating it when missing.
+	PayloadOpAppend = "append"
+	// PayloadOpPrepend inserts Value at the start of the array at Path, creating it when missing.
+	PayloadOpPrepend = "prepend"
+	// PayloadOpRename moves the value at Path to To.
+	PayloadOpRename = "rename"
+	// PayloadOpPrependSystem places the text in Value before the existing system prompt,
+	// using the system prompt field of the payload's protocol.
+	PayloadOpPrependSystem = "prepend-system"
+	// PayloadOpRemoveItems removes the items of the array at Path whose fields match every
+	// entry of the Value map (same matching as PayloadMatch.When).
+	PayloadOpRemoveItems = "remove-items"
+	// PayloadOpDropEvent discards the whole streamed event. Only valid in response-payload rules.
+	PayloadOpDropEvent = "drop-event"
+)
+
+// PayloadOperationRule applies a list of operations to matching payloads, in order.
+type PayloadOperationRule struct {
+	// Models lists model entries with name pattern and protocol constraint.
+	Models []PayloadModelRule `yaml:"models" json:"models"`
+	// Match narrows the rule to specific clients, headers, formats, credentials or payloads.
+	Match PayloadMatch `yaml:"match,omitempty" json:"match,omitempty"`
+	// Ops lists the operations to apply.
+	Ops []PayloadOperation `yaml:"ops" json:"ops"`
+}
+
+// PayloadOperation is a single payload edit.
+type PayloadOperation struct {
+	// Op is the operation kind, one of the PayloadOp* constants.
+	Op string `yaml:"op" json:"op"`
+	// Path is the JSON path (gjson/sjson syntax) the operation targets. Unused by prepend-system.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// To is the destination path of a rename.
+	To string `yaml:"to,omitempty" json:"to,omitempty"`
+	// Value is the value written, appended or prepended.
+	Value any `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
 // PayloadModelRule ties a model name pattern to a specific translator protocol.
 type PayloadModelRule struct {
 	// Name is the model name or wildcard pattern (e.g., "gpt-*", "*-5", "gemini-*-pro").
@@ -346,10 +462,16 @@ type ClaudeModel struct {
 
 	// Alias is the client-facing model name that maps to Name.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Capabilities optionally declares the request features the model accepts
+	// (image_input, pdf_input, tools, json_schema, system_prompt). When empty,
+	// capabilities are inherited from the built-in definition of the upstream model.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 }
 
-func (m ClaudeModel) GetName() string  { return m.Name }
-func (m ClaudeModel) GetAlias() string { return m.Alias }
+func (m ClaudeModel) GetName() string           { return m.Name }
+func (m ClaudeModel) GetAlias() string          { return m.Alias }
+func (m ClaudeModel) GetCapabilities() []string { return m.Capabilities }
 
 // CodexKey represents the configuration for a Codex API key,
 // including the API key itself and an optional base URL for the API endpoint.
@@ -394,10 +516,14 @@ type CodexModel struct {
 
 	// Alias is the client-facing model name that maps to Name.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Capabilities lists the request features the Codex model accepts; empty keeps the built-in set.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 }
 
-func (m CodexModel) GetName() string  { return m.Name }
-func (m CodexModel) GetAlias() string { return m.Alias }
+func (m CodexModel) GetName() string           { return m.Name }
+func (m CodexModel) GetAlias() string          { return m.Alias }
+func (m CodexModel) GetCapabilities() []string { return m.Capabilities }
 
 // GeminiKey represents the configuration for a Gemini API key,
 // including optional overrides for upstream base URL, proxy routing, and headers.
@@ -438,10 +564,14 @@ type GeminiModel struct {
 
 	// Alias is the client-facing model name that maps to Name.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Capabilities lists the request features the Gemini model accepts; empty keeps the built-in set.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 }
 
-func (m GeminiModel) GetName() string  { return m.Name }
-func (m GeminiModel) GetAlias() string { return m.Alias }
+func (m GeminiModel) GetName() string           { return m.Name }
+func (m GeminiModel) GetAlias() string          { return m.Alias }
+func (m GeminiModel) GetCapabilities() []string { return m.Capabilities }
 
 // OpenAICompatibility represents the configuration for OpenAI API compatibility
 // with external providers, allowing model aliases to be routed through OpenAI API format.
@@ -467,8 +597,27 @@ type OpenAICompatibility struct {
 
 	// Headers optionally adds extra HTTP headers for requests sent to this provider.
 	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// DiscoverModels enables polling the upstream "GET {base-url}/models" endpoint and
+	// registering the returned models in addition to the configured Models.
+	DiscoverModels bool `yaml:"discover-models,omitempty" json:"discover-models,omitempty"`
+
+	// DiscoverInclude keeps only discovered model IDs matching one of these wildcard patterns.
+	// An empty list keeps every discovered model.
+	DiscoverInclude []string `yaml:"discover-include,omitempty" json:"discover-include,omitempty"`
+
+	// DiscoverExclude drops discovered model IDs matching any of these wildcard patterns.
+	DiscoverExclude []string `yaml:"discover-exclude,omitempty" json:"discover-exclude,omitempty"`
+
+	// DiscoverRefreshInterval is the number of seconds between model list refreshes.
+	// Defaults to DefaultModelDiscoveryRefreshInterval; values below 60 are raised to 60.
+	DiscoverRefreshInterval int `yaml:"discover-refresh-interval,omitempty" json:"discover-refresh-interval,omitempty"`
 }
 
+// DefaultModelDiscoveryRefreshInterval is the default number of seconds between
+// upstream model list refreshes for openai-compatibility providers.
+const DefaultModelDiscoveryRefreshInterval = 3600
+
 // OpenAICompatibilityAPIKey represents an API key configuration with optional proxy setting.
 type OpenAICompatibilityAPIKey struct {
 	// APIKey is the authentication key for accessing the external API services.
@@ -486,10 +635,15 @@ type OpenAICompatibilityModel struct {
 
 	// Alias is the model name alias that clients will use to reference this model.
 	Alias string `yaml:"alias" json:"alias"`
+
+	// Capabilities optionally declares the request features the model accepts. When empty,
+	// the model's capabilities are unknown and requests are not validated against them.
+	Capabilities []string `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
 }
 
-func (m OpenAICompatibilityModel) GetName() string  { return m.Name }
-func (m OpenAICompatibilityModel) GetAlias() string { return m.Alias }
+func (m OpenAICompatibilityModel) GetName() string           { return m.Name }
+func (m OpenAICompatibilityModel) GetAlias() string          { return m.Alias }
+func (m OpenAICompatibilityModel) GetCapabilities() []string { return m.Capabilities }
 
 // LoadConfig reads a YAML configuration file from the given path,
 // unmarshals it into a Config struct, applies environment variable overrides,
@@ -617,6 +771,12 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Sanitize Claude key headers
 	cfg.SanitizeClaudeKeys()
 
+	// Sanitize Bedrock credentials: drop entries without region or credential source
+	cfg.SanitizeBedrockKeys()
+
+	// Sanitize Azure OpenAI credentials: drop entries without api-key or base-url
+	cfg.SanitizeAzureOpenAIKeys()
+
 	// Sanitize OpenAI compatibility providers: drop entries without base-url
 	cfg.SanitizeOpenAICompatibility()
 
@@ -629,6 +789,42 @@ func LoadConfigOptional(configFile string, optional bool) (*Config, error) {
 	// Validate raw payload rules and drop invalid entries.
 	cfg.SanitizePayloadRules()
 
+	// Normalize external model definition sources.
+	cfg.SanitizeModelDefinitions()
+
+	// Apply remote media download limits.
+	cfg.SanitizeRemoteMedia()
+
+	// Apply Gemini context cache defaults.
+	cfg.SanitizeContextCache()
+
+	// Normalize context window policies.
+	cfg.SanitizeContextPolicies()
+
+	// Normalize the thinking signature cache backend.
+	cfg.SanitizeSignatureCache()
+
+	// Normalize per-provider stream stall timeouts.
+	cfg.SanitizeStreamTimeouts()
+
+	// Compile retry policy error patterns.
+	cfg.SanitizeRetryPolicies()
+
+	// Normalize the upstream circuit breaker key.
+	cfg.SanitizeCircuitBreaker()
+
+	// Normalize per-credential concurrency defaults.
+	cfg.SanitizeConcurrency()
+
+	// Normalize request pacing rules.
+	cfg.SanitizePacing()
+
+	// Normalize priority classes and capacity reservations.
+	cfg.SanitizePriority()
+
+	// Drop hedging rules that would not match anything.
+	cfg.SanitizeHedging()
+
 	// NOTE: Legacy migration persistence is intentionally disabled together with
 	// startup legacy migration to keep startup read-only for config.yaml.
 	// Re-enable the block below if automatic startup migration is needed again.
@@ -655,6 +851,78 @@ func (cfg *Config) SanitizePayloadRules() {
 	}
 	cfg.Payload.DefaultRaw = sanitizePayloadRawRules(cfg.Payload.DefaultRaw, "default-raw")
 	cfg.Payload.OverrideRaw = sanitizePayloadRawRules(cfg.Payload.OverrideRaw, "override-raw")
+	cfg.Payload.Operations = sanitizePayloadOperationRules(cfg.Payload.Operations, "operations")
+	cfg.ResponsePayload = sanitizePayloadOperationRules(cfg.ResponsePayload, "response-payload")
+}
+
+// sanitizePayloadOperationRules normalizes operation kinds and drops operations that cannot
+// apply, and rules left without operations. The section decides whether the rules edit
+// requests ("operations") or responses ("response-payload").
+func sanitizePayloadOperationRules(rules []PayloadOperationRule, section string) []PayloadOperationRule {
+	if len(rules) == 0 {
+		return rules
+	}
+	response := section == "response-payload"
+	out := make([]PayloadOperationRule, 0, len(rules))
+	for i := range rules {
+		rule := rules[i]
+		ops := make([]PayloadOperation, 0, len(rule.Ops))
+		for j, op := range rule.Ops {
+			op.Op = strings.ToLower(strings.TrimSpace(op.Op))
+			op.Path = strings.TrimSpace(op.Path)
+			op.To = strings.TrimSpace(op.To)
+			reason := ""
+			switch op.Op {
+			case PayloadOpSet, PayloadOpDelete, PayloadOpAppend, PayloadOpPrepend:
+				if op.Path == "" {
+					reason = "missing path"
+				}
+			case PayloadOpSetRaw:
+				raw, ok := payloadRawString(op.Value)
+				if op.Path == "" {
+					reason = "missing path"
+				} else if !ok || !json.Valid(bytes.TrimSpace(raw)) {
+					reason = "invalid raw JSON"
+				}
+			case PayloadOpRename:
+				if op.Path == "" || op.To == "" {
+					reason = "rename requires path and to"
+				}
+			case PayloadOpPrependSystem:
+				if response {
+					reason = "prepend-system only applies to requests"
+				} else if text, ok := op.Value.(string); !ok || strings.TrimSpace(text) == "" {
+					reason = "prepend-system requires a text value"
+				}
+			case PayloadOpRemoveItems:
+				if conditions, ok := op.Value.(map[string]any); op.Path == "" || !ok || len(conditions) == 0 {
+					reason = "remove-items requires path and a value map"
+				}
+			case PayloadOpDropEvent:
+				if !response {
+					reason = "drop-event only applies to responses"
+				}
+			default:
+				reason = "unknown op"
+			}
+			if reason != "" {
+				log.WithFields(log.Fields{
+					"section":    section,
+					"rule_index": i + 1,
+					"op_index":   j + 1,
+					"op":         op.Op,
+				}).Warnf("payload operation dropped: %s", reason)
+				continue
+			}
+			ops = append(ops, op)
+		}
+		if len(ops) == 0 {
+			continue
+		}
+		rule.Ops = ops
+		out = append(out, rule)
+	}
+	return out
 }
 
 func sanitizePayloadRawRules(rules []PayloadRule, section string) []PayloadRule {
@@ -703,6 +971,38 @@ func payloadRawString(value any) ([]byte, bool) {
 	}
 }
 
+// PayloadHeaderNames returns the canonical names of the client headers that payload and
+// response-payload rules match on, sorted.
+func (cfg *Config) PayloadHeaderNames() []string {
+	if cfg == nil {
+		return nil
+	}
+	var matches []PayloadMatch
+	for _, rules := range [][]PayloadRule{cfg.Payload.Default, cfg.Payload.DefaultRaw, cfg.Payload.Override, cfg.Payload.OverrideRaw} {
+		for _, rule := range rules {
+			matches = append(matches, rule.Match)
+		}
+	}
+	for _, rule := range cfg.Payload.Filter {
+		matches = append(matches, rule.Match)
+	}
+	for _, rules := range [][]PayloadOperationRule{cfg.Payload.Operations, cfg.ResponsePayload} {
+		for _, rule := range rules {
+			matches = append(matches, rule.Match)
+		}
+	}
+	var names []string
+	for _, match := range matches {
+		for name := range match.Headers {
+			if name = http.CanonicalHeaderKey(strings.TrimSpace(name)); name != "" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
 // SanitizeOAuthModelAlias normalizes and deduplicates global OAuth model name aliases.
 // It trims whitespace, normalizes channel keys to lower-case, drops empty entries,
 // allows multiple aliases per upstream name, and ensures aliases are unique within each channel.
@@ -759,6 +1059,15 @@ func (cfg *Config) SanitizeOpenAICompatibility() {
 			// Skip providers with no base-url; treated as removed
 			continue
 		}
+		e.DiscoverInclude = NormalizeExcludedModels(e.DiscoverInclude)
+		e.DiscoverExclude = NormalizeExcludedModels(e.DiscoverExclude)
+		if e.DiscoverModels {
+			if e.DiscoverRefreshInterval <= 0 {
+				e.DiscoverRefreshInterval = DefaultModelDiscoveryRefreshInterval
+			} else if e.DiscoverRefreshInterval < 60 {
+				e.DiscoverRefreshInterval = 60
+			}
+		}
 		out = append(out, e)
 	}
 	cfg.OpenAICompatibility = out
@@ -0,0 +1,51 @@
+package config
+
+const (
+	// DefaultContextCacheTTL is the default lifetime, in seconds, of a Gemini cachedContents entry.
+	DefaultContextCacheTTL = 300
+	// DefaultContextCacheMinTokens is the smallest estimated prefix that is worth caching.
+	// Gemini rejects explicit caches below its per-model minimum, so smaller prefixes are sent inline.
+	DefaultContextCacheMinTokens = 2048
+	// DefaultContextCacheAutoTokens is the estimated size of the system prompt and tools above
+	// which a prefix is cached even without a cache_control breakpoint.
+	DefaultContextCacheAutoTokens = 16384
+)
+
+// ContextCacheConfig controls explicit context caching for the Gemini API and Vertex AI.
+// Prompt prefixes marked with Claude cache_control breakpoints, or large enough on their own,
+// are stored as cachedContents and referenced on later requests instead of being resent.
+type ContextCacheConfig struct {
+	// Disable turns explicit context caching off.
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// TTL is the number of seconds a created cache lives upstream and is reused locally.
+	// Defaults to DefaultContextCacheTTL.
+	TTL int `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MinTokens is the estimated prefix size below which no cache is created.
+	// Defaults to DefaultContextCacheMinTokens.
+	MinTokens int `yaml:"min-tokens,omitempty" json:"min-tokens,omitempty"`
+
+	// AutoTokens caches the system prompt and tools without a breakpoint once their estimated
+	// size reaches this value. Defaults to DefaultContextCacheAutoTokens; -1 disables it.
+	AutoTokens int `yaml:"auto-tokens,omitempty" json:"auto-tokens,omitempty"`
+}
+
+// SanitizeContextCache applies defaults to the Gemini context cache settings.
+func (cfg *Config) SanitizeContextCache() {
+	if cfg == nil {
+		return
+	}
+	cache := &cfg.ContextCache
+	if cache.TTL <= 0 {
+		cache.TTL = DefaultContextCacheTTL
+	}
+	if cache.MinTokens <= 0 {
+		cache.MinTokens = DefaultContextCacheMinTokens
+	}
+	if cache.AutoTokens == 0 {
+		cache.AutoTokens = DefaultContextCacheAutoTokens
+	} else if cache.AutoTokens < 0 {
+		cache.AutoTokens = -1
+	}
+}
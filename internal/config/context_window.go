@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Context policy modes.
+const (
+	// ContextPolicyReject fails over-long requests with a 400 before they are sent upstream.
+	ContextPolicyReject = "reject"
+	// ContextPolicyTruncate drops the oldest conversation turns until the request fits.
+	ContextPolicyTruncate = "truncate"
+	// ContextPolicySummarize replaces the oldest conversation turns with a summary written by
+	// SummaryModel, falling back to truncation when summarising fails.
+	ContextPolicySummarize = "summarize"
+)
+
+// ContextPolicy decides what happens to a request whose prompt exceeds the input token limit
+// of the requested model. Policies are evaluated in order and the first match applies; requests
+// matching no policy are forwarded unchanged.
+type ContextPolicy struct {
+	// Models lists model name patterns ("*" wildcards) the policy applies to. Empty matches all.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// APIKeys lists the client API keys the policy applies to. Empty matches all clients.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// Mode is one of "reject", "truncate" or "summarize".
+	Mode string `yaml:"mode" json:"mode"`
+
+	// SummaryModel is the model asked to summarise dropped turns in "summarize" mode.
+	SummaryModel string `yaml:"summary-model,omitempty" json:"summary-model,omitempty"`
+
+	// ReserveTokens is kept free below the model's input limit to absorb tokenizer error.
+	ReserveTokens int `yaml:"reserve-tokens,omitempty" json:"reserve-tokens,omitempty"`
+}
+
+// SanitizeContextPolicies normalizes context policy modes and drops policies that cannot apply.
+func (cfg *Config) SanitizeContextPolicies() {
+	if cfg == nil || len(cfg.ContextPolicies) == 0 {
+		return
+	}
+	out := make([]ContextPolicy, 0, len(cfg.ContextPolicies))
+	for _, policy := range cfg.ContextPolicies {
+		policy.Mode = strings.ToLower(strings.TrimSpace(policy.Mode))
+		policy.SummaryModel = strings.TrimSpace(policy.SummaryModel)
+		switch policy.Mode {
+		case ContextPolicyReject, ContextPolicyTruncate:
+		case ContextPolicySummarize:
+			if policy.SummaryModel == "" {
+				log.Warn("context-policies: summarize mode without summary-model, truncating instead")
+				policy.Mode = ContextPolicyTruncate
+			}
+		default:
+			log.Warnf("context-policies: unknown mode %q, policy ignored", policy.Mode)
+			continue
+		}
+		if policy.ReserveTokens < 0 {
+			policy.ReserveTokens = 0
+		}
+		out = append(out, policy)
+	}
+	cfg.ContextPolicies = out
+}
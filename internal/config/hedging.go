@@ -0,0 +1,62 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// HedgingRule enables hedged non-streaming requests for matching models or client API keys.
+// When the upstream has not started responding within DelayMs, a duplicate request is sent on
+// another credential and the first successful response wins.
+type HedgingRule struct {
+	// Models lists requested model name patterns ("*" wildcards). Empty matches all.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// APIKeys lists the client API keys (from top-level api-keys) whose requests are hedged.
+	// Empty matches all.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+
+	// DelayMs is how long to wait for the upstream response to start before hedging.
+	DelayMs int `yaml:"delay-ms" json:"delay-ms"`
+}
+
+// SanitizeHedging drops hedging rules without a delay or without a model or key to match, so
+// hedging stays opt-in.
+func (cfg *Config) SanitizeHedging() {
+	if cfg == nil {
+		return
+	}
+	rules := cfg.Hedging[:0]
+	for _, rule := range cfg.Hedging {
+		for j, model := range rule.Models {
+			rule.Models[j] = strings.TrimSpace(model)
+		}
+		for j, key := range rule.APIKeys {
+			rule.APIKeys[j] = strings.TrimSpace(key)
+		}
+		if rule.DelayMs <= 0 || (len(rule.Models) == 0 && len(rule.APIKeys) == 0) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	cfg.Hedging = rules
+}
+
+// HedgeDelay returns the hedging delay of the first rule matching model and the client API
+// key, or 0 when the request is not hedged.
+func (cfg *Config) HedgeDelay(model, apiKey string) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	for _, rule := range cfg.Hedging {
+		if len(rule.Models) > 0 && !matchAnyWildcard(rule.Models, []string{model}) {
+			continue
+		}
+		if len(rule.APIKeys) > 0 && !slices.Contains(rule.APIKeys, apiKey) {
+			continue
+		}
+		return time.Duration(rule.DelayMs) * time.Millisecond
+	}
+	return 0
+}
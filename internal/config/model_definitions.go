@@ -0,0 +1,43 @@
+package config
+
+import "strings"
+
+// DefaultModelDefinitionsRefreshInterval is the default number of seconds between
+// fetches of a remote model definitions URL.
+const DefaultModelDefinitionsRefreshInterval = 3600
+
+// ModelDefinitionsConfig configures external model definitions layered over the embedded
+// catalog. Both sources use the catalog format (channel name -> list of models) in YAML or
+// JSON; entries are merged per channel by model ID, the file first and the URL on top.
+type ModelDefinitionsConfig struct {
+	// Path is a local definitions file, watched and hot-reloaded on change.
+	// Relative paths are resolved against the directory of the config file.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// URL is a remote definitions document fetched periodically.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+
+	// RefreshInterval is the number of seconds between URL fetches.
+	// Defaults to DefaultModelDefinitionsRefreshInterval; values below 60 are raised to 60.
+	RefreshInterval int `yaml:"refresh-interval,omitempty" json:"refresh-interval,omitempty"`
+}
+
+// SanitizeModelDefinitions trims the external model definition sources and normalizes
+// the URL refresh interval.
+func (cfg *Config) SanitizeModelDefinitions() {
+	if cfg == nil {
+		return
+	}
+	defs := &cfg.ModelDefinitions
+	defs.Path = strings.TrimSpace(defs.Path)
+	defs.URL = strings.TrimSpace(defs.URL)
+	if defs.URL == "" {
+		defs.RefreshInterval = 0
+		return
+	}
+	if defs.RefreshInterval <= 0 {
+		defs.RefreshInterval = DefaultModelDefinitionsRefreshInterval
+	} else if defs.RefreshInterval < 60 {
+		defs.RefreshInterval = 60
+	}
+}
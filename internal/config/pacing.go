@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// PacingConfig spaces outbound requests per credential and model so that upstream rate limits
+// are not hit in the first place.
+type PacingConfig struct {
+	// Rules set request and token rates for matching providers and models. The first matching
+	// rule wins; auth files override its rates with "rpm" and "tpm".
+	Rules []PacingRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+
+	// MaxWaitMs bounds how long a request waits for a paced credential when none has capacity.
+	// Default is 2000.
+	MaxWaitMs int `yaml:"max-wait-ms,omitempty" json:"max-wait-ms,omitempty"`
+}
+
+// PacingRule sets the rates of one credential for matching providers and models.
+type PacingRule struct {
+	// Providers lists provider keys (e.g., "claude", "codex"). Empty matches all.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// Models lists model name patterns ("*" wildcards). Empty matches all.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// RPM is the requests per minute allowed per credential and model. <= 0 leaves requests unpaced.
+	RPM int `yaml:"rpm,omitempty" json:"rpm,omitempty"`
+
+	// TPM is the estimated input tokens per minute allowed per credential and model.
+	// <= 0 leaves tokens unpaced.
+	TPM int `yaml:"tpm,omitempty" json:"tpm,omitempty"`
+
+	// Burst is how many requests may be sent back to back before spacing applies. Default is 1.
+	Burst int `yaml:"burst,omitempty" json:"burst,omitempty"`
+}
+
+// SanitizePacing normalizes provider names and model patterns of pacing rules and drops rules
+// without rates.
+func (cfg *Config) SanitizePacing() {
+	if cfg == nil {
+		return
+	}
+	rules := cfg.Pacing.Rules[:0]
+	for _, rule := range cfg.Pacing.Rules {
+		if rule.RPM <= 0 && rule.TPM <= 0 {
+			continue
+		}
+		for j, provider := range rule.Providers {
+			rule.Providers[j] = strings.ToLower(strings.TrimSpace(provider))
+		}
+		for j, model := range rule.Models {
+			rule.Models[j] = strings.TrimSpace(model)
+		}
+		rule.Burst = max(rule.Burst, 1)
+		rules = append(rules, rule)
+	}
+	cfg.Pacing.Rules = rules
+}
+
+// RuleFor returns the first pacing rule matching provider and one of models.
+func (c PacingConfig) RuleFor(provider string, models ...string) (PacingRule, bool) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for _, rule := range c.Rules {
+		if len(rule.Providers) > 0 && !containsFold(rule.Providers, provider) {
+			continue
+		}
+		if len(rule.Models) > 0 && !matchAnyWildcard(rule.Models, models) {
+			continue
+		}
+		return rule, true
+	}
+	return PacingRule{}, false
+}
+
+// MaxWait returns how long a request may wait for a paced credential.
+func (c PacingConfig) MaxWait() time.Duration {
+	if c.MaxWaitMs <= 0 {
+		return 2 * time.Second
+	}
+	return time.Duration(c.MaxWaitMs) * time.Millisecond
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"strings"
+)
+
+// PriorityConfig assigns client API keys to priority classes and reserves part of the
+// credential pool for higher classes.
+type PriorityConfig struct {
+	// Classes lists the priority classes and the client API keys that belong to them.
+	Classes []PriorityClass `yaml:"classes,omitempty" json:"classes,omitempty"`
+
+	// DefaultClass is the class of client keys not listed in any class. Empty gives them
+	// priority 0.
+	DefaultClass string `yaml:"default-class,omitempty" json:"default-class,omitempty"`
+
+	// Reservations keep credentials free for a class and the classes above it.
+	Reservations []CapacityReservation `yaml:"reservations,omitempty" json:"reservations,omitempty"`
+}
+
+// PriorityClass is a named priority shared by a set of client API keys.
+type PriorityClass struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Priority orders classes; higher values are served first when requests queue.
+	Priority int `yaml:"priority" json:"priority"`
+
+	// APIKeys lists the client API keys (from top-level api-keys) in this class.
+	APIKeys []string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+}
+
+// CapacityReservation keeps a number of available credentials out of reach of classes below
+// Class. The reserved count is the larger of Percent of the credential pool and MinCredentials.
+type CapacityReservation struct {
+	// Class names the class the capacity is reserved for; higher classes may use it too.
+	Class string `yaml:"class" json:"class"`
+
+	// Providers limits the reservation to these provider keys. Empty applies it to all.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// Percent of the credentials serving a model that lower classes must leave available.
+	Percent int `yaml:"percent,omitempty" json:"percent,omitempty"`
+
+	// MinCredentials is how many available credentials lower classes never use.
+	MinCredentials int `yaml:"min-credentials,omitempty" json:"min-credentials,omitempty"`
+}
+
+// SanitizePriority normalizes class names and drops classes without a name and reservations
+// for unknown classes or without capacity.
+func (cfg *Config) SanitizePriority() {
+	if cfg == nil {
+		return
+	}
+	seen := make(map[string]struct{}, len(cfg.Priority.Classes))
+	classes := cfg.Priority.Classes[:0]
+	for _, class := range cfg.Priority.Classes {
+		class.Name = strings.ToLower(strings.TrimSpace(class.Name))
+		if class.Name == "" {
+			continue
+		}
+		if _, dup := seen[class.Name]; dup {
+			continue
+		}
+		seen[class.Name] = struct{}{}
+		keys := class.APIKeys[:0]
+		for _, key := range class.APIKeys {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		class.APIKeys = keys
+		classes = append(classes, class)
+	}
+	cfg.Priority.Classes = classes
+
+	cfg.Priority.DefaultClass = strings.ToLower(strings.TrimSpace(cfg.Priority.DefaultClass))
+	if _, ok := seen[cfg.Priority.DefaultClass]; !ok {
+		cfg.Priority.DefaultClass = ""
+	}
+
+	reservations := cfg.Priority.Reservations[:0]
+	for _, reservation := range cfg.Priority.Reservations {
+		reservation.Class = strings.ToLower(strings.TrimSpace(reservation.Class))
+		if _, ok := seen[reservation.Class]; !ok {
+			continue
+		}
+		reservation.Percent = min(max(reservation.Percent, 0), 100)
+		reservation.MinCredentials = max(reservation.MinCredentials, 0)
+		if reservation.Percent == 0 && reservation.MinCredentials == 0 {
+			continue
+		}
+		for j, provider := range reservation.Providers {
+			reservation.Providers[j] = strings.ToLower(strings.TrimSpace(provider))
+		}
+		reservations = append(reservations, reservation)
+	}
+	cfg.Priority.Reservations = reservations
+}
+
+// ClassFor returns the class of a client API key, falling back to the default class.
+func (c PriorityConfig) ClassFor(apiKey string) (PriorityClass, bool) {
+	if apiKey != "" {
+		for _, class := range c.Classes {
+			for _, key := range class.APIKeys {
+				if key == apiKey {
+					return class, true
+				}
+			}
+		}
+	}
+	return c.Class(c.DefaultClass)
+}
+
+// Class returns the class named name.
+func (c PriorityConfig) Class(name string) (PriorityClass, bool) {
+	if name == "" {
+		return PriorityClass{}, false
+	}
+	for _, class := range c.Classes {
+		if class.Name == name {
+			return class, true
+		}
+	}
+	return PriorityClass{}, false
+}
+
+// AppliesTo reports whether the reservation covers provider.
+func (r CapacityReservation) AppliesTo(provider string) bool {
+	return len(r.Providers) == 0 || containsFold(r.Providers, provider)
+}
+
+// Reserved returns how many of pool credentials lower classes must leave available.
+func (r CapacityReservation) Reserved(pool int) int {
+	byPercent := (pool*r.Percent + 99) / 100
+	return max(byPercent, r.MinCredentials)
+}
@@ -0,0 +1,84 @@
+package config
+
+import "strings"
+
+const (
+	// DefaultRemoteMediaMaxBytes is the default size limit of a single downloaded media file.
+	DefaultRemoteMediaMaxBytes = 20 << 20
+	// DefaultRemoteMediaTimeout is the default number of seconds allowed for one download.
+	DefaultRemoteMediaTimeout = 15
+	// DefaultRemoteMediaCacheTTL is the default number of seconds a downloaded file is reused.
+	DefaultRemoteMediaCacheTTL = 300
+)
+
+// RemoteMediaConfig controls how remote image and document URLs are downloaded and inlined as
+// base64 for providers that only accept inline media (Claude, Gemini, Gemini CLI, Vertex, Bedrock
+// and Antigravity).
+type RemoteMediaConfig struct {
+	// Disable rejects requests that reference remote media instead of downloading it.
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// MaxBytes caps the size of a single download. Defaults to DefaultRemoteMediaMaxBytes.
+	MaxBytes int64 `yaml:"max-bytes,omitempty" json:"max-bytes,omitempty"`
+
+	// Timeout is the number of seconds allowed for one download. Defaults to DefaultRemoteMediaTimeout.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// CacheTTL is the number of seconds a downloaded file is cached for reuse.
+	// Defaults to DefaultRemoteMediaCacheTTL; a negative value disables caching.
+	CacheTTL int `yaml:"cache-ttl,omitempty" json:"cache-ttl,omitempty"`
+
+	// AllowedHosts, when non-empty, restricts downloads to these hosts. Entries match the
+	// host exactly or, when written as "*.example.com", any subdomain of example.com.
+	AllowedHosts []string `yaml:"allowed-hosts,omitempty" json:"allowed-hosts,omitempty"`
+
+	// BlockedHosts lists hosts that are never downloaded from, using the same patterns as
+	// AllowedHosts. Blocked entries win over allowed ones.
+	BlockedHosts []string `yaml:"blocked-hosts,omitempty" json:"blocked-hosts,omitempty"`
+
+	// AllowPrivateNetworks permits downloads from loopback, private and link-local addresses,
+	// which are refused by default.
+	AllowPrivateNetworks bool `yaml:"allow-private-networks,omitempty" json:"allow-private-networks,omitempty"`
+}
+
+// SanitizeRemoteMedia applies defaults to the remote media limits and normalizes host lists.
+func (cfg *Config) SanitizeRemoteMedia() {
+	if cfg == nil {
+		return
+	}
+	media := &cfg.RemoteMedia
+	if media.MaxBytes <= 0 {
+		media.MaxBytes = DefaultRemoteMediaMaxBytes
+	}
+	if media.Timeout <= 0 {
+		media.Timeout = DefaultRemoteMediaTimeout
+	}
+	if media.CacheTTL == 0 {
+		media.CacheTTL = DefaultRemoteMediaCacheTTL
+	}
+	media.AllowedHosts = normalizeMediaHosts(media.AllowedHosts)
+	media.BlockedHosts = normalizeMediaHosts(media.BlockedHosts)
+}
+
+func normalizeMediaHosts(hosts []string) []string {
+	if len(hosts) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(hosts))
+	out := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		out = append(out, host)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
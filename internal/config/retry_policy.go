@@ -0,0 +1,214 @@
+package config
+
+import (
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Retry backoff strategies.
+const (
+	RetryBackoffNone        = "none"
+	RetryBackoffConstant    = "constant"
+	RetryBackoffLinear      = "linear"
+	RetryBackoffExponential = "exponential"
+)
+
+// defaultSameCredentialAttempts bounds same-credential retries without max-attempts.
+const defaultSameCredentialAttempts = 3
+
+// RetryPolicy overrides how failed upstream attempts are retried for matching providers.
+type RetryPolicy struct {
+	// Providers lists provider keys (e.g., "codex", "gemini") or openai-compatibility provider
+	// names; "openai-compatibility" matches every compatibility provider. Empty matches all.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// StatusCodes lists the upstream statuses that are retried. Statuses other than 401, 402,
+	// 404 and 429 listed here cool the credential down as transient errors.
+	StatusCodes []int `yaml:"status-codes,omitempty" json:"status-codes,omitempty"`
+
+	// ErrorPatterns are regular expressions matched against the upstream error body; a match
+	// makes the error retryable whatever its status. With neither status codes nor patterns,
+	// every error except an invalid request is retried.
+	ErrorPatterns []string `yaml:"error-patterns,omitempty" json:"error-patterns,omitempty"`
+
+	// MaxAttempts bounds the upstream attempts of one request across credentials and cooldown
+	// waits. <= 0 leaves attempts bounded by the credentials and request-retry only, or by
+	// three attempts with SameCredential.
+	MaxAttempts int `yaml:"max-attempts,omitempty" json:"max-attempts,omitempty"`
+
+	// SameCredential retries on the credential that failed instead of rotating to the next one.
+	// Errors matching StatusCodes or ErrorPatterns then do not cool the credential down.
+	SameCredential bool `yaml:"same-credential,omitempty" json:"same-credential,omitempty"`
+
+	// CooldownSeconds is how long a credential rests after a retryable transient error.
+	// 0 keeps the built-in minute; a negative value disables the cooldown.
+	CooldownSeconds int `yaml:"cooldown-seconds,omitempty" json:"cooldown-seconds,omitempty"`
+
+	// Backoff is the delay between a failed attempt and the next one.
+	Backoff RetryBackoff `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+
+	patterns []*regexp.Regexp
+}
+
+// RetryBackoff describes the delay curve between attempts.
+type RetryBackoff struct {
+	// Strategy is none (default), constant, linear or exponential.
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// InitialMs is the delay before the first retry.
+	InitialMs int `yaml:"initial-ms,omitempty" json:"initial-ms,omitempty"`
+
+	// MaxMs caps the delay; <= 0 leaves it uncapped.
+	MaxMs int `yaml:"max-ms,omitempty" json:"max-ms,omitempty"`
+
+	// Jitter randomizes the delay by up to this fraction of it (0 to 1).
+	Jitter float64 `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+}
+
+// SanitizeRetryPolicies normalizes retry policies, compiling their error patterns and dropping
+// the invalid ones.
+func (cfg *Config) SanitizeRetryPolicies() {
+	if cfg == nil {
+		return
+	}
+	for i := range cfg.RetryPolicies {
+		policy := &cfg.RetryPolicies[i]
+		for j, provider := range policy.Providers {
+			policy.Providers[j] = strings.ToLower(strings.TrimSpace(provider))
+		}
+		policy.patterns = policy.patterns[:0]
+		patterns := policy.ErrorPatterns[:0]
+		for _, pattern := range policy.ErrorPatterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				log.Warnf("retry-policy: dropping invalid error pattern %q: %v", pattern, err)
+				continue
+			}
+			patterns = append(patterns, pattern)
+			policy.patterns = append(policy.patterns, re)
+		}
+		policy.ErrorPatterns = patterns
+		backoff := &policy.Backoff
+		backoff.Strategy = strings.ToLower(strings.TrimSpace(backoff.Strategy))
+		switch backoff.Strategy {
+		case "", RetryBackoffNone, RetryBackoffConstant, RetryBackoffLinear, RetryBackoffExponential:
+		default:
+			log.Warnf("retry-policy: unknown backoff strategy %q, retrying without delay", backoff.Strategy)
+			backoff.Strategy = RetryBackoffNone
+		}
+		backoff.InitialMs = max(backoff.InitialMs, 0)
+		backoff.Jitter = min(max(backoff.Jitter, 0), 1)
+	}
+}
+
+// RetryPolicyFor returns the first policy matching any of the provider names, or nil.
+func (cfg *Config) RetryPolicyFor(providers ...string) *RetryPolicy {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.RetryPolicies {
+		policy := &cfg.RetryPolicies[i]
+		if len(policy.Providers) == 0 {
+			return policy
+		}
+		for _, provider := range providers {
+			if provider != "" && containsFold(policy.Providers, provider) {
+				return policy
+			}
+		}
+	}
+	return nil
+}
+
+// Classifies reports whether the policy defines its own retryable errors.
+func (p *RetryPolicy) Classifies() bool {
+	return p != nil && (len(p.StatusCodes) > 0 || len(p.ErrorPatterns) > 0)
+}
+
+// Retryable reports whether an upstream error with status and body is retried. It is only
+// meaningful when the policy classifies errors.
+func (p *RetryPolicy) Retryable(status int, body string) bool {
+	if p == nil {
+		return false
+	}
+	for _, code := range p.StatusCodes {
+		if code == status {
+			return true
+		}
+	}
+	if len(p.patterns) != len(p.ErrorPatterns) {
+		// Policies built in code skip sanitizing; compile on demand.
+		for _, pattern := range p.ErrorPatterns {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(body) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(body) {
+			return true
+		}
+	}
+	return false
+}
+
+// AttemptLimit returns the maximum upstream attempts per request, or 0 when unbounded.
+func (p *RetryPolicy) AttemptLimit() int {
+	switch {
+	case p == nil:
+		return 0
+	case p.MaxAttempts > 0:
+		return p.MaxAttempts
+	case p.SameCredential:
+		return defaultSameCredentialAttempts
+	default:
+		return 0
+	}
+}
+
+// Cooldown returns how long a credential rests after a retryable transient error and whether
+// the policy overrides the built-in cooldown.
+func (p *RetryPolicy) Cooldown() (time.Duration, bool) {
+	switch {
+	case p == nil:
+		return 0, false
+	case p.SameCredential || p.CooldownSeconds < 0:
+		return 0, true
+	case p.CooldownSeconds > 0:
+		return time.Duration(p.CooldownSeconds) * time.Second, true
+	default:
+		return 0, false
+	}
+}
+
+// Delay returns the backoff before retry number retry (1 for the first retry).
+func (b RetryBackoff) Delay(retry int) time.Duration {
+	if b.InitialMs <= 0 || retry < 1 {
+		return 0
+	}
+	initial := float64(b.InitialMs)
+	var ms float64
+	switch b.Strategy {
+	case RetryBackoffConstant:
+		ms = initial
+	case RetryBackoffLinear:
+		ms = initial * float64(retry)
+	case RetryBackoffExponential:
+		ms = initial * math.Pow(2, float64(min(retry-1, 30)))
+	default:
+		return 0
+	}
+	if b.MaxMs > 0 {
+		ms = min(ms, float64(b.MaxMs))
+	}
+	if b.Jitter > 0 {
+		ms += ms * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -26,6 +26,21 @@ type SDKConfig struct {
 	// NonStreamKeepAliveInterval controls how often blank lines are emitted for non-streaming responses.
 	// <= 0 disables keep-alives. Value is in seconds.
 	NonStreamKeepAliveInterval int `yaml:"nonstream-keepalive-interval,omitempty" json:"nonstream-keepalive-interval,omitempty"`
+
+	// StructuredOutput configures JSON schema emulation and response validation.
+	StructuredOutput StructuredOutputConfig `yaml:"structured-output,omitempty" json:"structured-output,omitempty"`
+
+	// ContextPolicies decide how requests exceeding a model's input token limit are handled.
+	ContextPolicies []ContextPolicy `yaml:"context-policies,omitempty" json:"context-policies,omitempty"`
+
+	// TokenCounting controls whether Claude and Gemini token count requests are answered locally.
+	TokenCounting TokenCountingConfig `yaml:"token-counting,omitempty" json:"token-counting,omitempty"`
+
+	// ReasoningOutput controls how model reasoning appears in OpenAI chat completion responses.
+	ReasoningOutput ReasoningOutputConfig `yaml:"reasoning-output,omitempty" json:"reasoning-output,omitempty"`
+
+	// Coalescing shares one upstream call among identical concurrent non-streaming requests.
+	Coalescing CoalescingConfig `yaml:"request-coalescing,omitempty" json:"request-coalescing,omitempty"`
 }
 
 // StreamingConfig holds server streaming behavior configuration.
@@ -38,4 +53,110 @@ type StreamingConfig struct {
 	// to allow auth rotation / transient recovery.
 	// <= 0 disables bootstrap retries. Default is 0.
 	BootstrapRetries int `yaml:"bootstrap-retries,omitempty" json:"bootstrap-retries,omitempty"`
+
+	// ContinuationRetries controls how many times a stream that fails after bytes were sent may be
+	// continued on another credential, with the partial answer carried over as a prefill or
+	// continuation prompt. Supported for OpenAI chat, Claude and Gemini SSE clients. Streams that
+	// already sent reasoning or tool calls are not continued. <= 0 disables continuation. Default is 0.
+	ContinuationRetries int `yaml:"continuation-retries,omitempty" json:"continuation-retries,omitempty"`
+
+	// FirstTokenTimeoutSeconds aborts an upstream stream that sends nothing for this long after
+	// it was opened. <= 0 disables the check. Default is 0.
+	FirstTokenTimeoutSeconds int `yaml:"first-token-timeout-seconds,omitempty" json:"first-token-timeout-seconds,omitempty"`
+
+	// IdleTimeoutSeconds aborts an upstream stream that goes this long between two chunks.
+	// <= 0 disables the check. Default is 0.
+	IdleTimeoutSeconds int `yaml:"idle-timeout-seconds,omitempty" json:"idle-timeout-seconds,omitempty"`
+
+	// Timeouts overrides the stall timeouts for specific providers and models. The first
+	// matching entry wins.
+	Timeouts []StreamTimeoutRule `yaml:"timeouts,omitempty" json:"timeouts,omitempty"`
+
+	// ResumeGraceSeconds makes SSE responses resumable: events carry IDs, the upstream keeps
+	// being consumed after the client disconnects, and GET /v1/streams/{id} with the stream's
+	// X-Stream-Token and Last-Event-ID replays what was missed. The stream is dropped once no
+	// client has been attached for this long. <= 0 disables resumable streams. Default is 0.
+	ResumeGraceSeconds int `yaml:"resume-grace-seconds,omitempty" json:"resume-grace-seconds,omitempty"`
+
+	// ResumeBufferBytes bounds the events kept per resumable stream; the oldest are evicted
+	// first. <= 0 uses 4 MiB.
+	ResumeBufferBytes int `yaml:"resume-buffer-bytes,omitempty" json:"resume-buffer-bytes,omitempty"`
+}
+
+// StructuredOutputConfig controls how JSON schema response formats are enforced.
+type StructuredOutputConfig struct {
+	// Disable turns off emulation and validation. Schemas are then only forwarded where a
+	// translator maps them natively.
+	Disable bool `yaml:"disable,omitempty" json:"disable,omitempty"`
+
+	// Strategy selects how providers without native structured output are asked for JSON.
+	// "auto" (default) forces a tool call when the request has no tools or reasoning of its own
+	// and adds the schema to the system prompt otherwise; "tool" and "prompt" pin one strategy.
+	// Streaming requests always use the prompt strategy.
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+
+	// RepairRetries is how many times a non-streaming response that fails schema validation is
+	// requested again with the validation error. Defaults to 1; -1 disables repair retries.
+	RepairRetries int `yaml:"repair-retries,omitempty" json:"repair-retries,omitempty"`
+}
+
+// Token counting modes.
+const (
+	// TokenCountingUpstream forwards every count request to the provider.
+	TokenCountingUpstream = "upstream"
+	// TokenCountingLocal answers count requests with the offline tokenizer unless the client
+	// asks for an exact count.
+	TokenCountingLocal = "local"
+	// TokenCountingFallback forwards count requests and answers locally when the upstream fails,
+	// for example while every credential is cooling down.
+	TokenCountingFallback = "fallback"
+)
+
+// TokenCountingConfig controls how /v1/messages/count_tokens and Gemini countTokens are served.
+type TokenCountingConfig struct {
+	// Mode is "upstream" (default), "local" or "fallback".
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+}
+
+// Reasoning output modes for OpenAI chat completion responses.
+const (
+	// ReasoningOutputContent exposes reasoning as reasoning_content.
+	ReasoningOutputContent = "reasoning_content"
+	// ReasoningOutputOmit drops reasoning from responses.
+	ReasoningOutputOmit = "omit"
+	// ReasoningOutputSummary exposes reasoning as a reasoning field with reasoning_details
+	// summary items.
+	ReasoningOutputSummary = "reasoning"
+	// ReasoningOutputThinkTags inlines reasoning into content wrapped in <think> tags.
+	ReasoningOutputThinkTags = "think"
+)
+
+// ReasoningOutputConfig selects the reasoning output mode for OpenAI chat clients. A request may
+// override it with the X-Reasoning-Output header.
+type ReasoningOutputConfig struct {
+	// Mode is the default mode: "reasoning_content", "omit", "reasoning" or "think". Empty
+	// leaves reasoning where the translators put it.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// APIKeys maps client API keys to the mode used for their requests.
+	APIKeys map[string]string `yaml:"api-keys,omitempty" json:"api-keys,omitempty"`
+}
+
+// CoalescingConfig controls request coalescing. Non-streaming requests with the same client API
+// key, source format, model, body and payload-rule headers that arrive while an identical one
+// is in flight wait for its response instead of calling the upstream again.
+type CoalescingConfig struct {
+	// Enabled turns coalescing on. Default is false.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Models limits coalescing to these requested model patterns ("*" wildcards). Empty matches all.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+}
+
+// Applies reports whether requests for model are coalesced.
+func (c CoalescingConfig) Applies(model string) bool {
+	if !c.Enabled {
+		return false
+	}
+	return len(c.Models) == 0 || matchAnyWildcard(c.Models, []string{model})
 }
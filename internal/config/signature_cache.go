@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Signature cache backends.
+const (
+	// SignatureCacheMemory keeps thinking signatures in process memory only.
+	SignatureCacheMemory = "memory"
+	// SignatureCacheFile stores signatures as files below SignatureCacheConfig.Path.
+	SignatureCacheFile = "file"
+	// SignatureCacheStore stores signatures in the configured Postgres or object store.
+	SignatureCacheStore = "store"
+)
+
+// DefaultSignatureCachePath is the file backend directory used when no path is configured.
+const DefaultSignatureCachePath = "signatures"
+
+// SignatureCacheConfig controls where Claude thinking signatures are cached between turns.
+// Persistent backends keep multi-turn thinking conversations working across restarts and
+// replicas; the in-memory LRU in front of them is bounded by MaxEntries either way.
+type SignatureCacheConfig struct {
+	// Backend is one of "memory", "file" or "store". Defaults to "memory".
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// Path is the directory used by the file backend. Relative paths resolve against the
+	// working directory. Defaults to DefaultSignatureCachePath.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// MaxEntries bounds the number of signatures held in memory; the least recently used
+	// entries are evicted first. Zero uses the built-in default.
+	MaxEntries int `yaml:"max-entries,omitempty" json:"max-entries,omitempty"`
+}
+
+// SanitizeSignatureCache normalizes the signature cache backend and applies defaults.
+func (cfg *Config) SanitizeSignatureCache() {
+	if cfg == nil {
+		return
+	}
+	sc := &cfg.SignatureCache
+	sc.Backend = strings.ToLower(strings.TrimSpace(sc.Backend))
+	switch sc.Backend {
+	case "":
+		sc.Backend = SignatureCacheMemory
+	case SignatureCacheMemory, SignatureCacheFile, SignatureCacheStore:
+	default:
+		log.Warnf("signature-cache: unknown backend %q, using memory", sc.Backend)
+		sc.Backend = SignatureCacheMemory
+	}
+	sc.Path = strings.TrimSpace(sc.Path)
+	if sc.Backend == SignatureCacheFile && sc.Path == "" {
+		sc.Path = DefaultSignatureCachePath
+	}
+	if sc.MaxEntries < 0 {
+		sc.MaxEntries = 0
+	}
+}
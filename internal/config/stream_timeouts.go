@@ -0,0 +1,102 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// StreamTimeoutRule sets stall timeouts for the streams of matching providers and models.
+type StreamTimeoutRule struct {
+	// Providers lists provider identifiers (e.g., "antigravity", "gemini-cli"). Empty matches all.
+	Providers []string `yaml:"providers,omitempty" json:"providers,omitempty"`
+
+	// Models lists model name patterns ("*" wildcards). Empty matches all.
+	Models []string `yaml:"models,omitempty" json:"models,omitempty"`
+
+	// FirstTokenTimeoutSeconds replaces the default time-to-first-chunk limit when non-zero;
+	// a negative value disables it.
+	FirstTokenTimeoutSeconds int `yaml:"first-token-timeout-seconds,omitempty" json:"first-token-timeout-seconds,omitempty"`
+
+	// IdleTimeoutSeconds replaces the default inter-chunk limit when non-zero; a negative value
+	// disables it.
+	IdleTimeoutSeconds int `yaml:"idle-timeout-seconds,omitempty" json:"idle-timeout-seconds,omitempty"`
+}
+
+// SanitizeStreamTimeouts normalizes provider names and model patterns of stream timeout rules.
+func (cfg *Config) SanitizeStreamTimeouts() {
+	if cfg == nil {
+		return
+	}
+	for i := range cfg.Streaming.Timeouts {
+		rule := &cfg.Streaming.Timeouts[i]
+		for j, provider := range rule.Providers {
+			rule.Providers[j] = strings.ToLower(strings.TrimSpace(provider))
+		}
+		for j, model := range rule.Models {
+			rule.Models[j] = strings.TrimSpace(model)
+		}
+	}
+}
+
+// StallTimeouts returns the time-to-first-chunk and inter-chunk limits for a stream served by
+// provider for one of models. Zero durations disable the corresponding check.
+func (c StreamingConfig) StallTimeouts(provider string, models ...string) (firstToken, idle time.Duration) {
+	firstSeconds, idleSeconds := c.FirstTokenTimeoutSeconds, c.IdleTimeoutSeconds
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	for _, rule := range c.Timeouts {
+		if len(rule.Providers) > 0 && !containsFold(rule.Providers, provider) {
+			continue
+		}
+		if len(rule.Models) > 0 && !matchAnyWildcard(rule.Models, models) {
+			continue
+		}
+		if rule.FirstTokenTimeoutSeconds != 0 {
+			firstSeconds = rule.FirstTokenTimeoutSeconds
+		}
+		if rule.IdleTimeoutSeconds != 0 {
+			idleSeconds = rule.IdleTimeoutSeconds
+		}
+		break
+	}
+	return max(time.Duration(firstSeconds), 0) * time.Second, max(time.Duration(idleSeconds), 0) * time.Second
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyWildcard reports whether any value matches any of the "*" wildcard patterns.
+func matchAnyWildcard(patterns, values []string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if value != "" && matchWildcard(pattern, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchWildcard(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
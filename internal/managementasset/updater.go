@@ -182,7 +182,7 @@ func EnsureLatestManagementHTML(ctx context.Context, staticDir string, proxyURL
 	// Skip auto-update if using custom static path
 	if strings.TrimSpace(os.Getenv("MANAGEMENT_STATIC_PATH")) != "" {
 		log.Debug("management asset sync skipped: custom MANAGEMENT_STATIC_PATH is set")
-		return
+		return false
 	}
 
 	staticDir = strings.TrimSpace(staticDir)
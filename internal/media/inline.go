@@ -0,0 +1,151 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// InlineRemoteMedia rewrites remote image and document URLs in a request of the given source
+// format ("openai", "openai-response" or "claude") into inline base64 data so that translators
+// targeting base64-only providers keep them. Other formats are returned unchanged.
+// A file that cannot be downloaded, or has the wrong kind of content, fails the whole request
+// with *Error.
+func InlineRemoteMedia(ctx context.Context, client *http.Client, cfg config.RemoteMediaConfig, format string, payload []byte) ([]byte, error) {
+	if len(payload) == 0 || !gjson.ValidBytes(payload) {
+		return payload, nil
+	}
+	root := gjson.ParseBytes(payload)
+
+	type rewrite struct {
+		path     string
+		url      string
+		claude   bool
+		document bool
+	}
+	var rewrites []rewrite
+	switch format {
+	case "openai":
+		root.Get("messages").ForEach(func(msgIdx, msg gjson.Result) bool {
+			msg.Get("content").ForEach(func(partIdx, part gjson.Result) bool {
+				if part.Get("type").String() != "image_url" {
+					return true
+				}
+				if imageURL := part.Get("image_url.url").String(); IsRemoteURL(imageURL) {
+					rewrites = append(rewrites, rewrite{path: "messages." + msgIdx.String() + ".content." + partIdx.String() + ".image_url.url", url: imageURL})
+				}
+				return true
+			})
+			return true
+		})
+	case "openai-response":
+		root.Get("input").ForEach(func(itemIdx, item gjson.Result) bool {
+			item.Get("content").ForEach(func(partIdx, part gjson.Result) bool {
+				if partType := part.Get("type").String(); partType != "input_image" && partType != "input_file" {
+					return true
+				}
+				base := "input." + itemIdx.String() + ".content." + partIdx.String()
+				if part.Get("type").String() == "input_file" {
+					if fileURL := part.Get("file_url").String(); IsRemoteURL(fileURL) && part.Get("file_data").String() == "" {
+						rewrites = append(rewrites, rewrite{path: base, url: fileURL, document: true})
+					}
+					return true
+				}
+				if imageURL := part.Get("image_url").String(); IsRemoteURL(imageURL) {
+					rewrites = append(rewrites, rewrite{path: base + ".image_url", url: imageURL})
+				} else if imageURL = part.Get("url").String(); part.Get("image_url").String() == "" && IsRemoteURL(imageURL) {
+					rewrites = append(rewrites, rewrite{path: base + ".url", url: imageURL})
+				}
+				return true
+			})
+			return true
+		})
+	case "claude":
+		var scan func(prefix string, blocks gjson.Result)
+		scan = func(prefix string, blocks gjson.Result) {
+			blocks.ForEach(func(idx, block gjson.Result) bool {
+				blockPath := prefix + "." + idx.String()
+				switch block.Get("type").String() {
+				case "image":
+					if block.Get("source.type").String() == "url" && IsRemoteURL(block.Get("source.url").String()) {
+						rewrites = append(rewrites, rewrite{path: blockPath + ".source", url: block.Get("source.url").String(), claude: true})
+					}
+				case "document":
+					if block.Get("source.type").String() == "url" && IsRemoteURL(block.Get("source.url").String()) {
+						rewrites = append(rewrites, rewrite{path: blockPath + ".source", url: block.Get("source.url").String(), claude: true, document: true})
+					}
+				case "tool_result":
+					if content := block.Get("content"); content.IsArray() {
+						scan(blockPath+".content", content)
+					}
+				}
+				return true
+			})
+		}
+		root.Get("messages").ForEach(func(msgIdx, msg gjson.Result) bool {
+			if content := msg.Get("content"); content.IsArray() {
+				scan("messages."+msgIdx.String()+".content", content)
+			}
+			return true
+		})
+	}
+	if len(rewrites) == 0 {
+		return payload, nil
+	}
+
+	out := payload
+	for _, rw := range rewrites {
+		file, errFetch := Fetch(ctx, client, cfg, rw.url)
+		if errFetch != nil {
+			return nil, errFetch
+		}
+		isImage := strings.HasPrefix(file.MimeType, "image/")
+		if !rw.document && !isImage {
+			return nil, &Error{URL: rw.url, Reason: "content type " + file.MimeType + " is not an image"}
+		}
+		if rw.document && (isImage || !util.IsDocumentMimeType(file.MimeType) || strings.HasPrefix(file.MimeType, "text/html")) {
+			return nil, &Error{URL: rw.url, Reason: "content type " + file.MimeType + " is not a document"}
+		}
+		var errSet error
+		switch {
+		case rw.document && !rw.claude:
+			item := gjson.GetBytes(out, rw.path)
+			filename := item.Get("filename").String()
+			if filename == "" {
+				filename = documentFilename(rw.url)
+			}
+			part := `{"type":"input_file","filename":"","file_data":""}`
+			part, _ = sjson.Set(part, "filename", filename)
+			part, _ = sjson.Set(part, "file_data", file.DataURL())
+			out, errSet = sjson.SetRawBytes(out, rw.path, []byte(part))
+		case rw.claude:
+			source := `{"type":"base64","media_type":"","data":""}`
+			source, _ = sjson.Set(source, "media_type", file.MimeType)
+			source, _ = sjson.Set(source, "data", file.Base64())
+			out, errSet = sjson.SetRawBytes(out, rw.path, []byte(source))
+		default:
+			out, errSet = sjson.SetBytes(out, rw.path, file.DataURL())
+		}
+		if errSet != nil {
+			return nil, errSet
+		}
+	}
+	return out, nil
+}
+
+// documentFilename derives a file name for a downloaded document from its URL.
+func documentFilename(rawURL string) string {
+	if parsed, errParse := url.Parse(rawURL); errParse == nil {
+		if name := path.Base(parsed.Path); name != "" && name != "/" && name != "." {
+			return name
+		}
+	}
+	return "document"
+}
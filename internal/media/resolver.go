@@ -0,0 +1,332 @@
+// Package media downloads remote media referenced by client requests so it can be inlined
+// as base64 for providers that do not fetch URLs themselves. Downloads are bounded in size
+// and time, filtered by host, sniffed for their MIME type and cached briefly.
+package media
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxCacheBytes bounds the total size of cached downloads.
+const maxCacheBytes = 128 << 20
+
+// maxRedirects bounds the redirects followed by a single download.
+const maxRedirects = 5
+
+// File is a downloaded media file.
+type File struct {
+	MimeType string
+	Data     []byte
+}
+
+// Base64 returns the file content encoded as standard base64.
+func (f *File) Base64() string {
+	return base64.StdEncoding.EncodeToString(f.Data)
+}
+
+// DataURL returns the file as a data: URL.
+func (f *File) DataURL() string {
+	return "data:" + f.MimeType + ";base64," + f.Base64()
+}
+
+// Error describes why a remote media URL could not be inlined. Callers surface it to the
+// client as an invalid request.
+type Error struct {
+	URL    string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cannot use remote media %s: %s", e.URL, e.Reason)
+}
+
+// IsRemoteURL reports whether s is an http(s) URL.
+func IsRemoteURL(s string) bool {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	return strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://")
+}
+
+type cacheEntry struct {
+	file    *File
+	expires time.Time
+}
+
+var (
+	cacheMu    sync.Mutex
+	cache      = make(map[string]cacheEntry)
+	cacheBytes int
+)
+
+// Fetch downloads rawURL with client, applying the limits in cfg. Failures are returned as *Error.
+func Fetch(ctx context.Context, client *http.Client, cfg config.RemoteMediaConfig, rawURL string) (*File, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if cfg.Disable {
+		return nil, &Error{URL: rawURL, Reason: "remote media URLs are not accepted by this server; send the content inline as base64"}
+	}
+	parsed, errParse := url.Parse(rawURL)
+	if errParse != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return nil, &Error{URL: rawURL, Reason: "not a valid http(s) URL"}
+	}
+	if errCheck := checkHost(cfg, parsed); errCheck != nil {
+		return nil, &Error{URL: rawURL, Reason: errCheck.Error()}
+	}
+	if file := cachedFile(rawURL); file != nil {
+		return file, nil
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = config.DefaultRemoteMediaTimeout * time.Second
+	}
+	maxBytes := cfg.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultRemoteMediaMaxBytes
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if errReq != nil {
+		return nil, &Error{URL: rawURL, Reason: errReq.Error()}
+	}
+	resp, errDo := guardedClient(client, cfg).Do(req)
+	if errDo != nil {
+		return nil, &Error{URL: rawURL, Reason: fmt.Sprintf("download failed: %v", errDo)}
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Debugf("remote media: close response body error: %v", errClose)
+		}
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &Error{URL: rawURL, Reason: fmt.Sprintf("download failed with status %d", resp.StatusCode)}
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, &Error{URL: rawURL, Reason: fmt.Sprintf("file exceeds the %d byte limit", maxBytes)}
+	}
+	data, errRead := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if errRead != nil {
+		return nil, &Error{URL: rawURL, Reason: fmt.Sprintf("download failed: %v", errRead)}
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, &Error{URL: rawURL, Reason: fmt.Sprintf("file exceeds the %d byte limit", maxBytes)}
+	}
+	if len(data) == 0 {
+		return nil, &Error{URL: rawURL, Reason: "file is empty"}
+	}
+
+	file := &File{MimeType: sniffMimeType(data, resp.Header.Get("Content-Type"), parsed.Path), Data: data}
+	storeFile(rawURL, file, cfg.CacheTTL)
+	return file, nil
+}
+
+// sniffMimeType identifies the content type from the data itself, falling back to the
+// response header and finally the file extension.
+func sniffMimeType(data []byte, header, urlPath string) string {
+	detected := http.DetectContentType(data)
+	if mediaType, _, errParse := mime.ParseMediaType(detected); errParse == nil {
+		detected = mediaType
+	}
+	if detected != "application/octet-stream" && !strings.HasPrefix(detected, "text/") {
+		return detected
+	}
+	if mediaType, _, errParse := mime.ParseMediaType(header); errParse == nil && mediaType != "" && mediaType != "application/octet-stream" {
+		return strings.ToLower(mediaType)
+	}
+	if ext := strings.ToLower(strings.TrimPrefix(path.Ext(urlPath), ".")); ext != "" {
+		if mimeType, ok := misc.MimeTypes[ext]; ok {
+			return mimeType
+		}
+	}
+	return detected
+}
+
+// checkHost applies the allow and block lists and, unless permitted, refuses hosts given as a
+// loopback, private or link-local address. Host names are checked when dialing.
+func checkHost(cfg config.RemoteMediaConfig, u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if matchesHost(host, cfg.BlockedHosts) {
+		return fmt.Errorf("host %s is blocked", host)
+	}
+	if len(cfg.AllowedHosts) > 0 && !matchesHost(host, cfg.AllowedHosts) {
+		return fmt.Errorf("host %s is not in the allowed host list", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !cfg.AllowPrivateNetworks && isPrivateIP(ip) {
+		return fmt.Errorf("host %s is a private network address", host)
+	}
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// guardedClient returns a copy of client that applies the host lists to every redirect and,
+// unless private networks are allowed, refuses connections to private addresses. The address
+// is checked on the connection being dialed, after resolution, so a host name that resolves
+// differently between a check and the dial cannot reach the private network. Connections to
+// an HTTP proxy are exempt; transports that dial through a SOCKS proxy or are not an
+// *http.Transport are left as is, since the proxy resolves the target.
+func guardedClient(client *http.Client, cfg config.RemoteMediaConfig) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	guarded := *client
+	guarded.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return checkHost(cfg, next.URL)
+	}
+	if cfg.AllowPrivateNetworks {
+		return &guarded
+	}
+	base := guarded.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok || (transport.DialContext != nil && base != http.DefaultTransport) {
+		return &guarded
+	}
+	transport = transport.Clone()
+	var proxies sync.Map
+	if proxy := transport.Proxy; proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			proxyURL, errProxy := proxy(req)
+			if proxyURL != nil {
+				proxies.Store(proxyAddr(proxyURL), struct{}{})
+			}
+			return proxyURL, errProxy
+		}
+	}
+	direct := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	checked := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: refusePrivateAddress}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if _, isProxy := proxies.Load(addr); isProxy {
+			return direct.DialContext(ctx, network, addr)
+		}
+		return checked.DialContext(ctx, network, addr)
+	}
+	guarded.Transport = transport
+	return &guarded
+}
+
+// refusePrivateAddress is a net.Dialer control function rejecting private network addresses.
+func refusePrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, errSplit := net.SplitHostPort(address)
+	if errSplit != nil {
+		return errSplit
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return fmt.Errorf("connection to private network address %s refused", host)
+	}
+	return nil
+}
+
+// proxyAddr returns the host:port the transport dials for proxyURL.
+func proxyAddr(proxyURL *url.URL) string {
+	if port := proxyURL.Port(); port != "" {
+		return net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	if proxyURL.Scheme == "https" {
+		return net.JoinHostPort(proxyURL.Hostname(), "443")
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), "80")
+}
+
+func matchesHost(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if strings.HasPrefix(pattern, "*.") {
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func cachedFile(rawURL string) *File {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	entry, ok := cache[rawURL]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(entry.expires) {
+		delete(cache, rawURL)
+		cacheBytes -= len(entry.file.Data)
+		return nil
+	}
+	return entry.file
+}
+
+func storeFile(rawURL string, file *File, ttlSeconds int) {
+	if ttlSeconds < 0 || len(file.Data) > maxCacheBytes {
+		return
+	}
+	ttl := time.Duration(ttlSeconds) * time.Second
+	if ttl == 0 {
+		ttl = config.DefaultRemoteMediaCacheTTL * time.Second
+	}
+	now := time.Now()
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if old, ok := cache[rawURL]; ok {
+		cacheBytes -= len(old.file.Data)
+		delete(cache, rawURL)
+	}
+	for key, entry := range cache {
+		if now.After(entry.expires) {
+			cacheBytes -= len(entry.file.Data)
+			delete(cache, key)
+		}
+	}
+	// Evict the entries closest to expiry until the new file fits.
+	for cacheBytes+len(file.Data) > maxCacheBytes && len(cache) > 0 {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range cache {
+			if oldestKey == "" || entry.expires.Before(oldest) {
+				oldestKey, oldest = key, entry.expires
+			}
+		}
+		cacheBytes -= len(cache[oldestKey].file.Data)
+		delete(cache, oldestKey)
+	}
+	cache[rawURL] = cacheEntry{file: file, expires: now.Add(ttl)}
+	cacheBytes += len(file.Data)
+}
+
+// ClearCache drops every cached download.
+func ClearCache() {
+	cacheMu.Lock()
+	cache = make(map[string]cacheEntry)
+	cacheBytes = 0
+	cacheMu.Unlock()
+}
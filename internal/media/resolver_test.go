@@ -0,0 +1,158 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/tidwall/gjson"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newImageServer(t *testing.T, body []byte, hits *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		// Deliberately wrong header: the sniffed type must win.
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestInlineRemoteMedia_OpenAIChat(t *testing.T) {
+	ClearCache()
+	pngData := testPNG(t)
+	var hits int32
+	srv := newImageServer(t, pngData, &hits)
+	cfg := config.RemoteMediaConfig{AllowPrivateNetworks: true}
+
+	payload := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image_url","image_url":{"url":"` + srv.URL + `/cat.png"}},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}]}`)
+	out, err := InlineRemoteMedia(context.Background(), srv.Client(), cfg, "openai", payload)
+	if err != nil {
+		t.Fatalf("InlineRemoteMedia error: %v", err)
+	}
+	got := gjson.GetBytes(out, "messages.0.content.1.image_url.url").String()
+	want := (&File{MimeType: "image/png", Data: pngData}).DataURL()
+	if got != want {
+		t.Fatalf("image url = %.60q, want %.60q", got, want)
+	}
+	if gjson.GetBytes(out, "messages.0.content.2.image_url.url").String() != "data:image/png;base64,AAAA" {
+		t.Fatalf("existing data URL should be untouched")
+	}
+
+	// A second request for the same URL is served from the cache.
+	if _, err = InlineRemoteMedia(context.Background(), srv.Client(), cfg, "openai", payload); err != nil {
+		t.Fatalf("second InlineRemoteMedia error: %v", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Fatalf("server hits = %d, want 1", n)
+	}
+}
+
+func TestInlineRemoteMedia_ClaudeURLSource(t *testing.T) {
+	ClearCache()
+	var hits int32
+	srv := newImageServer(t, testPNG(t), &hits)
+	cfg := config.RemoteMediaConfig{AllowPrivateNetworks: true}
+
+	payload := []byte(`{"messages":[{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"image","source":{"type":"url","url":"` + srv.URL + `/a"}}]}]}]}`)
+	out, err := InlineRemoteMedia(context.Background(), srv.Client(), cfg, "claude", payload)
+	if err != nil {
+		t.Fatalf("InlineRemoteMedia error: %v", err)
+	}
+	source := gjson.GetBytes(out, "messages.0.content.0.content.0.source")
+	if source.Get("type").String() != "base64" || source.Get("media_type").String() != "image/png" || source.Get("data").String() == "" {
+		t.Fatalf("unexpected source: %s", source.Raw)
+	}
+}
+
+func TestInlineRemoteMedia_Errors(t *testing.T) {
+	pngData := testPNG(t)
+	var hits int32
+	srv := newImageServer(t, pngData, &hits)
+	textSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>not an image</html>"))
+	}))
+	t.Cleanup(textSrv.Close)
+
+	tests := []struct {
+		name   string
+		cfg    config.RemoteMediaConfig
+		url    string
+		reason string
+	}{
+		{name: "private network", cfg: config.RemoteMediaConfig{}, url: srv.URL, reason: "private network"},
+		{name: "host name resolving to a private network", cfg: config.RemoteMediaConfig{}, url: strings.Replace(srv.URL, "127.0.0.1", "localhost", 1), reason: "private network"},
+		{name: "disabled", cfg: config.RemoteMediaConfig{Disable: true}, url: srv.URL, reason: "not accepted"},
+		{name: "blocked host", cfg: config.RemoteMediaConfig{AllowPrivateNetworks: true, BlockedHosts: []string{"127.0.0.1"}}, url: srv.URL, reason: "blocked"},
+		{name: "not allowed host", cfg: config.RemoteMediaConfig{AllowPrivateNetworks: true, AllowedHosts: []string{"*.example.com"}}, url: srv.URL, reason: "allowed host list"},
+		{name: "too large", cfg: config.RemoteMediaConfig{AllowPrivateNetworks: true, MaxBytes: int64(len(pngData) - 1), CacheTTL: -1}, url: srv.URL, reason: "byte limit"},
+		{name: "not an image", cfg: config.RemoteMediaConfig{AllowPrivateNetworks: true, CacheTTL: -1}, url: textSrv.URL, reason: "not an image"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearCache()
+			payload := []byte(`{"input":[{"role":"user","content":[{"type":"input_image","image_url":"` + tt.url + `/x"}]}]}`)
+			_, err := InlineRemoteMedia(context.Background(), &http.Client{}, tt.cfg, "openai-response", payload)
+			var mediaErr *Error
+			if !errors.As(err, &mediaErr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.reason) {
+				t.Fatalf("error %q does not mention %q", err.Error(), tt.reason)
+			}
+		})
+	}
+}
+
+func TestFetch_ProxyConnectionIsNotAddressChecked(t *testing.T) {
+	ClearCache()
+	var hits int32
+	proxy := newImageServer(t, testPNG(t), &hits)
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	file, err := Fetch(context.Background(), client, config.RemoteMediaConfig{CacheTTL: -1}, "http://media.example.com/cat.png")
+	if err != nil {
+		t.Fatalf("Fetch through proxy: %v", err)
+	}
+	if file.MimeType != "image/png" || atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("unexpected fetch result: %s, %d proxy hits", file.MimeType, hits)
+	}
+}
+
+func TestMatchesHost(t *testing.T) {
+	patterns := []string{"cdn.example.com", "*.images.test"}
+	for host, want := range map[string]bool{
+		"cdn.example.com":   true,
+		"example.com":       false,
+		"a.images.test":     true,
+		"images.test":       false,
+		"evilimages.test":   false,
+		"a.b.images.test":   true,
+		"cdn.example.com.x": false,
+	} {
+		if got := matchesHost(host, patterns); got != want {
+			t.Errorf("matchesHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discoveredModelSet is the last model list fetched from an upstream provider.
+type discoveredModelSet struct {
+	models    []*ModelInfo
+	fetchedAt time.Time
+}
+
+var (
+	discoveredModelsMu sync.RWMutex
+	discoveredModels   = make(map[string]discoveredModelSet)
+)
+
+// SetDiscoveredModels stores the model list fetched from an upstream provider's /models
+// endpoint under the given channel (the provider name, case-insensitive).
+func SetDiscoveredModels(channel string, models []*ModelInfo, fetchedAt time.Time) {
+	key := strings.ToLower(strings.TrimSpace(channel))
+	if key == "" {
+		return
+	}
+	cloned := cloneModelInfosUnique(models)
+	sort.Slice(cloned, func(i, j int) bool {
+		return strings.ToLower(cloned[i].ID) < strings.ToLower(cloned[j].ID)
+	})
+	discoveredModelsMu.Lock()
+	discoveredModels[key] = discoveredModelSet{models: cloned, fetchedAt: fetchedAt}
+	discoveredModelsMu.Unlock()
+}
+
+// GetDiscoveredModels returns a copy of the models discovered for channel and the time
+// they were fetched. ok is false when nothing has been discovered for the channel.
+func GetDiscoveredModels(channel string) (models []*ModelInfo, fetchedAt time.Time, ok bool) {
+	key := strings.ToLower(strings.TrimSpace(channel))
+	discoveredModelsMu.RLock()
+	set, ok := discoveredModels[key]
+	discoveredModelsMu.RUnlock()
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return cloneModelInfosUnique(set.models), set.fetchedAt, true
+}
+
+// ClearDiscoveredModels forgets the discovered models for channel.
+func ClearDiscoveredModels(channel string) {
+	key := strings.ToLower(strings.TrimSpace(channel))
+	discoveredModelsMu.Lock()
+	delete(discoveredModels, key)
+	discoveredModelsMu.Unlock()
+}
@@ -0,0 +1,60 @@
+package registry
+
+import "strings"
+
+// Request features a model may declare in ModelInfo.Capabilities.
+const (
+	// CapabilityImageInput marks models that accept image content.
+	CapabilityImageInput = "image_input"
+	// CapabilityPDFInput marks models that accept PDF documents.
+	CapabilityPDFInput = "pdf_input"
+	// CapabilityTools marks models that support tool/function calling.
+	CapabilityTools = "tools"
+	// CapabilityJSONSchema marks models that support schema-constrained JSON output.
+	CapabilityJSONSchema = "json_schema"
+	// CapabilitySystemPrompt marks models that accept system instructions.
+	CapabilitySystemPrompt = "system_prompt"
+)
+
+// SupportsCapability reports whether the model accepts the given capability.
+// Models without capability metadata are assumed to support everything.
+func (m *ModelInfo) SupportsCapability(capability string) bool {
+	if m == nil || m.Capabilities == nil {
+		return true
+	}
+	for _, c := range m.Capabilities {
+		if strings.EqualFold(strings.TrimSpace(c), capability) {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingCapabilities returns the entries of required that the model does not support.
+func (m *ModelInfo) MissingCapabilities(required []string) []string {
+	var missing []string
+	for _, capability := range required {
+		if !m.SupportsCapability(capability) {
+			missing = append(missing, capability)
+		}
+	}
+	return missing
+}
+
+// CapabilityDescription returns a human-readable name for a capability.
+func CapabilityDescription(capability string) string {
+	switch capability {
+	case CapabilityImageInput:
+		return "image input"
+	case CapabilityPDFInput:
+		return "PDF input"
+	case CapabilityTools:
+		return "tool calling"
+	case CapabilityJSONSchema:
+		return "JSON schema output"
+	case CapabilitySystemPrompt:
+		return "system prompts"
+	default:
+		return capability
+	}
+}
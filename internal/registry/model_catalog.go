@@ -0,0 +1,223 @@
+package registry
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// embeddedModelDefinitions is the default model catalog shipped with the binary.
+//
+//go:embed models/models.json
+var embeddedModelDefinitions []byte
+
+// modelCatalogChannels lists the channels of the model catalog in lookup order.
+var modelCatalogChannels = []string{
+	"claude",
+	"gemini",
+	"vertex",
+	"gemini-cli",
+	"aistudio",
+	"codex",
+	"qwen",
+	"iflow",
+	"kimi",
+	"antigravity",
+}
+
+// ModelDefinitionSource is an external model definitions document layered over the
+// embedded catalog. Data may be YAML or JSON and maps channel names to model lists.
+type ModelDefinitionSource struct {
+	// Name identifies the source (file path or URL) in logs and management output.
+	Name string
+	// Data holds the raw document.
+	Data []byte
+}
+
+// modelCatalog holds the effective model definitions for every channel.
+type modelCatalog struct {
+	channels  map[string][]*ModelInfo
+	sources   []string
+	updatedAt time.Time
+}
+
+var (
+	modelCatalogMu       sync.RWMutex
+	embeddedModelCatalog *modelCatalog
+	activeModelCatalog   *modelCatalog
+)
+
+func init() {
+	var channels map[string][]*ModelInfo
+	if err := json.Unmarshal(embeddedModelDefinitions, &channels); err != nil {
+		panic(fmt.Sprintf("registry: invalid embedded model definitions: %v", err))
+	}
+	embeddedModelCatalog = &modelCatalog{channels: channels, updatedAt: time.Now()}
+	activeModelCatalog = embeddedModelCatalog
+}
+
+// ApplyModelDefinitionSources rebuilds the effective catalog from the embedded defaults with
+// the given sources layered on in order. Entries are merged per channel by model ID: fields
+// present in a source replace the default values, a null field removes it, and unknown IDs
+// are added to the channel. Calling it without sources restores the embedded catalog.
+// On error the current catalog is left unchanged.
+func ApplyModelDefinitionSources(sources ...ModelDefinitionSource) error {
+	if len(sources) == 0 {
+		modelCatalogMu.Lock()
+		activeModelCatalog = &modelCatalog{channels: embeddedModelCatalog.channels, updatedAt: time.Now()}
+		modelCatalogMu.Unlock()
+		return nil
+	}
+
+	channels := make(map[string][]*ModelInfo, len(embeddedModelCatalog.channels))
+	for channel, models := range embeddedModelCatalog.channels {
+		channels[channel] = cloneModelInfosUnique(models)
+	}
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if err := mergeModelDefinitionSource(channels, source.Data); err != nil {
+			return fmt.Errorf("model definitions %s: %w", source.Name, err)
+		}
+		names = append(names, source.Name)
+	}
+
+	modelCatalogMu.Lock()
+	activeModelCatalog = &modelCatalog{channels: channels, sources: names, updatedAt: time.Now()}
+	modelCatalogMu.Unlock()
+	return nil
+}
+
+// ModelDefinitionsSnapshot returns a copy of the effective model definitions per channel,
+// the names of the external sources applied on top of the embedded catalog, and the time
+// the catalog was last rebuilt.
+func ModelDefinitionsSnapshot() (channels map[string][]*ModelInfo, sources []string, updatedAt time.Time) {
+	modelCatalogMu.RLock()
+	catalog := activeModelCatalog
+	modelCatalogMu.RUnlock()
+
+	channels = make(map[string][]*ModelInfo, len(catalog.channels))
+	for channel, models := range catalog.channels {
+		channels[channel] = cloneModelInfosUnique(models)
+	}
+	return channels, append([]string(nil), catalog.sources...), catalog.updatedAt
+}
+
+// modelDefinitions returns a copy of the effective model definitions for channel.
+func modelDefinitions(channel string) []*ModelInfo {
+	modelCatalogMu.RLock()
+	models := activeModelCatalog.channels[channel]
+	modelCatalogMu.RUnlock()
+	return cloneModelInfosUnique(models)
+}
+
+// lookupModelDefinition searches the effective catalog for modelID in channel order.
+func lookupModelDefinition(modelID string) (*ModelInfo, string) {
+	modelCatalogMu.RLock()
+	defer modelCatalogMu.RUnlock()
+	for _, channel := range modelCatalogChannels {
+		for _, model := range activeModelCatalog.channels[channel] {
+			if model != nil && model.ID == modelID {
+				return cloneModelInfo(model), channel
+			}
+		}
+	}
+	return nil, ""
+}
+
+func mergeModelDefinitionSource(channels map[string][]*ModelInfo, data []byte) error {
+	var doc map[string][]map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	for rawChannel, entries := range doc {
+		channel := strings.ToLower(strings.TrimSpace(rawChannel))
+		if !isModelCatalogChannel(channel) {
+			return fmt.Errorf("unknown channel %q", rawChannel)
+		}
+		models := channels[channel]
+		for idx, entry := range entries {
+			id, _ := entry["id"].(string)
+			id = strings.TrimSpace(id)
+			if id == "" {
+				return fmt.Errorf("%s[%d]: missing model id", channel, idx)
+			}
+			entry["id"] = id
+			pos := -1
+			for i, model := range models {
+				if model.ID == id {
+					pos = i
+					break
+				}
+			}
+			if pos >= 0 {
+				merged, err := mergeModelDefinition(models[pos], entry)
+				if err != nil {
+					return fmt.Errorf("%s/%s: %w", channel, id, err)
+				}
+				models[pos] = merged
+				continue
+			}
+			added, err := mergeModelDefinition(newModelDefinition(models), entry)
+			if err != nil {
+				return fmt.Errorf("%s/%s: %w", channel, id, err)
+			}
+			models = append(models, added)
+		}
+		channels[channel] = models
+	}
+	return nil
+}
+
+// newModelDefinition seeds a model added by an external source with the object, owner and
+// type shared by the channel's existing models.
+func newModelDefinition(models []*ModelInfo) *ModelInfo {
+	model := &ModelInfo{}
+	if len(models) > 0 && models[0].Object != "" {
+		model.Object = models[0].Object
+		model.OwnedBy = models[0].OwnedBy
+		model.Type = models[0].Type
+	}
+	return model
+}
+
+// mergeModelDefinition overlays the fields of entry onto base, removing fields set to null.
+func mergeModelDefinition(base *ModelInfo, entry map[string]any) (*ModelInfo, error) {
+	baseJSON, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]any)
+	if err = json.Unmarshal(baseJSON, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range entry {
+		if value == nil {
+			delete(fields, key)
+			continue
+		}
+		fields[key] = value
+	}
+	mergedJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var merged ModelInfo
+	if err = json.Unmarshal(mergedJSON, &merged); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+func isModelCatalogChannel(channel string) bool {
+	for _, known := range modelCatalogChannels {
+		if known == channel {
+			return true
+		}
+	}
+	return false
+}
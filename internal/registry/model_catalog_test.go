@@ -0,0 +1,104 @@
+package registry
+
+import "testing"
+
+func TestApplyModelDefinitionSources_MergesPerChannel(t *testing.T) {
+	t.Cleanup(func() { _ = ApplyModelDefinitionSources() })
+
+	base := LookupStaticModelInfo("claude-opus-4-6")
+	if base == nil || base.Thinking == nil {
+		t.Fatalf("expected embedded claude-opus-4-6 with thinking support")
+	}
+
+	overrides := []byte(`
+claude:
+  - id: claude-opus-4-6
+    max_completion_tokens: 32000
+    description: null
+  - id: claude-next
+    display_name: Claude Next
+    context_length: 400000
+    thinking: { min: 2048, max: 64000 }
+`)
+	if err := ApplyModelDefinitionSources(ModelDefinitionSource{Name: "models.yaml", Data: overrides}); err != nil {
+		t.Fatalf("ApplyModelDefinitionSources error: %v", err)
+	}
+
+	updated := LookupStaticModelInfo("claude-opus-4-6")
+	if updated == nil {
+		t.Fatalf("expected claude-opus-4-6 to remain defined")
+	}
+	if updated.MaxCompletionTokens != 32000 {
+		t.Fatalf("MaxCompletionTokens = %d, want 32000", updated.MaxCompletionTokens)
+	}
+	if updated.Description != "" {
+		t.Fatalf("Description = %q, want it removed", updated.Description)
+	}
+	if updated.ContextLength != base.ContextLength || updated.Thinking == nil || updated.Thinking.Max != base.Thinking.Max {
+		t.Fatalf("unlisted fields should keep embedded values, got %+v", updated)
+	}
+
+	added := LookupStaticModelInfo("claude-next")
+	if added == nil {
+		t.Fatalf("expected claude-next to be added")
+	}
+	if added.Type != "claude" || added.OwnedBy != "anthropic" || added.Object != "model" {
+		t.Fatalf("added model should inherit channel defaults, got type=%q owned_by=%q object=%q", added.Type, added.OwnedBy, added.Object)
+	}
+	if added.Thinking == nil || added.Thinking.Min != 2048 || added.Thinking.Max != 64000 {
+		t.Fatalf("added model thinking = %+v", added.Thinking)
+	}
+
+	_, sources, _ := ModelDefinitionsSnapshot()
+	if len(sources) != 1 || sources[0] != "models.yaml" {
+		t.Fatalf("sources = %v, want [models.yaml]", sources)
+	}
+}
+
+func TestApplyModelDefinitionSources_InvalidSourceKeepsCatalog(t *testing.T) {
+	t.Cleanup(func() { _ = ApplyModelDefinitionSources() })
+
+	valid := []byte(`{"kimi":[{"id":"kimi-k2","max_completion_tokens":1000}]}`)
+	if err := ApplyModelDefinitionSources(ModelDefinitionSource{Name: "valid.json", Data: valid}); err != nil {
+		t.Fatalf("ApplyModelDefinitionSources error: %v", err)
+	}
+
+	invalid := []byte(`{"unknown-channel":[{"id":"x"}]}`)
+	if err := ApplyModelDefinitionSources(ModelDefinitionSource{Name: "invalid.json", Data: invalid}); err == nil {
+		t.Fatalf("expected error for unknown channel")
+	}
+	if got := findModelDefinition(GetKimiModels(), "kimi-k2"); got == nil || got.MaxCompletionTokens != 1000 {
+		t.Fatalf("catalog should be unchanged after an invalid source, got %+v", got)
+	}
+
+	if err := ApplyModelDefinitionSources(); err != nil {
+		t.Fatalf("reset error: %v", err)
+	}
+	if got := findModelDefinition(GetKimiModels(), "kimi-k2"); got == nil || got.MaxCompletionTokens != 32768 {
+		t.Fatalf("expected embedded kimi-k2 after reset, got %+v", got)
+	}
+}
+
+func TestGetModelDefinitionsReturnsCopies(t *testing.T) {
+	models := GetClaudeModels()
+	if len(models) == 0 || models[0].Thinking == nil {
+		t.Fatalf("expected claude models with thinking support")
+	}
+	id := models[0].ID
+	models[0].Thinking.Max = 1
+	models[0].ContextLength = 1
+
+	again := LookupStaticModelInfo(id)
+	if again.Thinking.Max == 1 || again.ContextLength == 1 {
+		t.Fatalf("mutating returned definitions must not affect the catalog")
+	}
+}
+
+func findModelDefinition(models []*ModelInfo, id string) *ModelInfo {
+	for _, model := range models {
+		if model.ID == id {
+			return model
+		}
+	}
+	return nil
+}
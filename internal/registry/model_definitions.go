@@ -1,5 +1,6 @@
 // Package registry provides model definitions and lookup helpers for various AI providers.
-// Static model metadata is stored in model_definitions_static_data.go.
+// Model metadata is loaded from the embedded catalog in models/models.json, optionally
+// overridden by external sources (see model_catalog.go).
 package registry
 
 import (
@@ -77,33 +78,83 @@ func LookupStaticModelInfo(modelID string) *ModelInfo {
 		return nil
 	}
 
-	allModels := [][]*ModelInfo{
-		GetClaudeModels(),
-		GetGeminiModels(),
-		GetGeminiVertexModels(),
-		GetGeminiCLIModels(),
-		GetAIStudioModels(),
-		GetOpenAIModels(),
-		GetQwenModels(),
-		GetIFlowModels(),
-		GetKimiModels(),
-	}
-	for _, models := range allModels {
-		for _, m := range models {
-			if m != nil && m.ID == modelID {
-				return m
-			}
-		}
+	model, channel := lookupModelDefinition(modelID)
+	if model == nil {
+		return nil
 	}
-
-	// Check Antigravity static config
-	if cfg := GetAntigravityModelConfig()[modelID]; cfg != nil {
+	if channel == "antigravity" {
+		// Antigravity entries only carry overrides for upstream model names.
 		return &ModelInfo{
 			ID:                  modelID,
-			Thinking:            cfg.Thinking,
-			MaxCompletionTokens: cfg.MaxCompletionTokens,
+			Thinking:            model.Thinking,
+			MaxCompletionTokens: model.MaxCompletionTokens,
 		}
 	}
+	return model
+}
+
+// GetClaudeModels returns the standard Claude model definitions
+func GetClaudeModels() []*ModelInfo {
+	return modelDefinitions("claude")
+}
+
+// GetGeminiModels returns the standard Gemini model definitions
+func GetGeminiModels() []*ModelInfo {
+	return modelDefinitions("gemini")
+}
+
+// GetGeminiVertexModels returns Gemini model definitions for Vertex AI
+func GetGeminiVertexModels() []*ModelInfo {
+	return modelDefinitions("vertex")
+}
+
+// GetGeminiCLIModels returns the standard Gemini model definitions
+func GetGeminiCLIModels() []*ModelInfo {
+	return modelDefinitions("gemini-cli")
+}
 
-	return nil
+// GetAIStudioModels returns the Gemini model definitions for AI Studio integrations
+func GetAIStudioModels() []*ModelInfo {
+	return modelDefinitions("aistudio")
+}
+
+// GetOpenAIModels returns the standard OpenAI model definitions
+func GetOpenAIModels() []*ModelInfo {
+	return modelDefinitions("codex")
+}
+
+// GetQwenModels returns the standard Qwen model definitions
+func GetQwenModels() []*ModelInfo {
+	return modelDefinitions("qwen")
+}
+
+// GetIFlowModels returns supported models for iFlow OAuth accounts.
+func GetIFlowModels() []*ModelInfo {
+	return modelDefinitions("iflow")
+}
+
+// GetKimiModels returns the standard Kimi (Moonshot AI) model definitions
+func GetKimiModels() []*ModelInfo {
+	return modelDefinitions("kimi")
+}
+
+// AntigravityModelConfig captures static antigravity model overrides, including
+// Thinking budget limits and provider max completion tokens.
+type AntigravityModelConfig struct {
+	Thinking            *ThinkingSupport
+	MaxCompletionTokens int
+}
+
+// GetAntigravityModelConfig returns static configuration for antigravity models.
+// Keys use upstream model names returned by the Antigravity models endpoint.
+func GetAntigravityModelConfig() map[string]*AntigravityModelConfig {
+	models := modelDefinitions("antigravity")
+	out := make(map[string]*AntigravityModelConfig, len(models))
+	for _, model := range models {
+		out[model.ID] = &AntigravityModelConfig{
+			Thinking:            model.Thinking,
+			MaxCompletionTokens: model.MaxCompletionTokens,
+		}
+	}
+	return out
 }
@@ -47,6 +47,11 @@ type ModelInfo struct {
 	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
 	// SupportedParameters lists supported parameters
 	SupportedParameters []string `json:"supported_parameters,omitempty"`
+	// Pricing holds upstream-reported per-token prices, when the provider publishes them
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+	// Capabilities lists the request features the model accepts (see Capability* constants).
+	// Nil means unknown and disables capability checks; an empty list supports none.
+	Capabilities []string `json:"capabilities,omitempty"`
 
 	// Thinking holds provider-specific reasoning/thinking budget capabilities.
 	// This is optional and currently used for Gemini thinking budget normalization.
@@ -74,6 +79,17 @@ type ThinkingSupport struct {
 	Levels []string `json:"levels,omitempty"`
 }
 
+// ModelPricing carries the price fields reported by an upstream model listing.
+// Values are kept as the upstream's decimal strings (USD per token or per request).
+type ModelPricing struct {
+	Prompt          string `json:"prompt,omitempty"`
+	Completion      string `json:"completion,omitempty"`
+	Request         string `json:"request,omitempty"`
+	Image           string `json:"image,omitempty"`
+	InputCacheRead  string `json:"input_cache_read,omitempty"`
+	InputCacheWrite string `json:"input_cache_write,omitempty"`
+}
+
 // ModelRegistration tracks a model's availability
 type ModelRegistration struct {
 	// Info contains the model metadata
@@ -499,6 +515,20 @@ func cloneModelInfo(model *ModelInfo) *ModelInfo {
 	if len(model.SupportedParameters) > 0 {
 		copyModel.SupportedParameters = append([]string(nil), model.SupportedParameters...)
 	}
+	if model.Capabilities != nil {
+		copyModel.Capabilities = append(make([]string, 0, len(model.Capabilities)), model.Capabilities...)
+	}
+	if model.Pricing != nil {
+		pricing := *model.Pricing
+		copyModel.Pricing = &pricing
+	}
+	if model.Thinking != nil {
+		thinking := *model.Thinking
+		if len(model.Thinking.Levels) > 0 {
+			thinking.Levels = append([]string(nil), model.Thinking.Levels...)
+		}
+		copyModel.Thinking = &thinking
+	}
 	return &copyModel
 }
 
@@ -1023,6 +1053,9 @@ func (r *ModelRegistry) convertModelToMap(model *ModelInfo, handlerType string)
 		if len(model.SupportedParameters) > 0 {
 			result["supported_parameters"] = model.SupportedParameters
 		}
+		if model.Pricing != nil {
+			result["pricing"] = model.Pricing
+		}
 		return result
 
 	case "claude":
@@ -0,0 +1,351 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// AzureOpenAIExecutor serves requests from Azure OpenAI resources.
+// Responses-format requests are forwarded to the v1 Responses endpoint; every other source
+// format is translated to Chat Completions and sent to the mapped deployment.
+type AzureOpenAIExecutor struct {
+	cfg *config.Config
+}
+
+// NewAzureOpenAIExecutor creates an Azure OpenAI executor bound to the supplied configuration.
+func NewAzureOpenAIExecutor(cfg *config.Config) *AzureOpenAIExecutor {
+	return &AzureOpenAIExecutor{cfg: cfg}
+}
+
+// Identifier implements cliproxyauth.ProviderExecutor.
+func (e *AzureOpenAIExecutor) Identifier() string { return "azure-openai" }
+
+// PrepareRequest injects the Azure api-key header into the outgoing HTTP request.
+func (e *AzureOpenAIExecutor) PrepareRequest(req *http.Request, auth *cliproxyauth.Auth) error {
+	if req == nil {
+		return nil
+	}
+	_, apiKey := azureOpenAICreds(auth)
+	if apiKey != "" {
+		req.Header.Set("api-key", apiKey)
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(req, attrs)
+	return nil
+}
+
+// HttpRequest injects Azure credentials into the request and executes it.
+func (e *AzureOpenAIExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Auth, req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("azure openai executor: request is nil")
+	}
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	httpReq := req.WithContext(ctx)
+	if err := e.PrepareRequest(httpReq, auth); err != nil {
+		return nil, err
+	}
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	return httpClient.Do(httpReq)
+}
+
+func (e *AzureOpenAIExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	if opts.Alt == "responses/compact" {
+		return resp, statusErr{code: http.StatusNotImplemented, msg: "/responses/compact not supported"}
+	}
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+	reporter.estimateInputFrom(opts.SourceFormat, req.Payload)
+
+	from := opts.SourceFormat
+	to, targetURL, body, err := e.buildRequest(auth, req, opts, baseModel, false)
+	if err != nil {
+		return resp, err
+	}
+
+	httpResp, err := e.doRequest(ctx, auth, targetURL, body, false)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("azure openai executor: close response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return resp, err
+	}
+	appendAPIResponseChunk(ctx, e.cfg, data)
+	reporter.publish(ctx, parseOpenAIUsage(data))
+	reporter.ensurePublished(ctx)
+
+	var param any
+	out := sdktranslator.TranslateNonStream(ctx, to, from, req.Model, opts.OriginalRequest, body, data, &param)
+	resp = cliproxyexecutor.Response{Payload: []byte(out)}
+	return resp, nil
+}
+
+func (e *AzureOpenAIExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
+	if opts.Alt == "responses/compact" {
+		return nil, statusErr{code: http.StatusBadRequest, msg: "streaming not supported for /responses/compact"}
+	}
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+	reporter.estimateInputFrom(opts.SourceFormat, req.Payload)
+
+	from := opts.SourceFormat
+	to, targetURL, body, err := e.buildRequest(auth, req, opts, baseModel, true)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := e.doRequest(ctx, auth, targetURL, body, true)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := to == sdktranslator.FromString("openai-response")
+	out := make(chan cliproxyexecutor.StreamChunk)
+	stream = out
+	go func() {
+		defer close(out)
+		defer func() {
+			if errClose := httpResp.Body.Close(); errClose != nil {
+				log.Errorf("azure openai executor: close response body error: %v", errClose)
+			}
+		}()
+		scanner := bufio.NewScanner(httpResp.Body)
+		scanner.Buffer(nil, 52_428_800) // 50MB
+		var param any
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			appendAPIResponseChunk(ctx, e.cfg, line)
+			if responses {
+				if bytes.HasPrefix(line, dataTag) {
+					data := bytes.TrimSpace(line[5:])
+					if gjson.GetBytes(data, "type").String() == "response.completed" {
+						if detail, ok := parseCodexUsage(data); ok {
+							reporter.publish(ctx, detail)
+						}
+					}
+				}
+				// Responses streams are already in the client's format; forward every SSE line.
+				out <- cliproxyexecutor.StreamChunk{Payload: bytes.Clone(line)}
+				continue
+			}
+			if detail, ok := parseOpenAIStreamUsage(line); ok {
+				reporter.publish(ctx, detail)
+			}
+			if !bytes.HasPrefix(line, dataTag) {
+				continue
+			}
+			chunks := sdktranslator.TranslateStream(ctx, to, from, req.Model, opts.OriginalRequest, body, bytes.Clone(line), &param)
+			for i := range chunks {
+				out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+			}
+		}
+		if errScan := scanner.Err(); errScan != nil {
+			recordAPIResponseError(ctx, e.cfg, errScan)
+			reporter.publishFailure(ctx)
+			out <- cliproxyexecutor.StreamChunk{Err: errScan}
+		}
+		reporter.ensurePublished(ctx)
+	}()
+	return stream, nil
+}
+
+// CountTokens estimates prompt tokens locally, as Azure OpenAI has no counting endpoint.
+func (e *AzureOpenAIExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("openai")
+	translated := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, false)
+
+	enc, err := tokenizerForModel(baseModel)
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("azure openai executor: tokenizer init failed: %w", err)
+	}
+	count, err := countOpenAIChatTokens(enc, translated)
+	if err != nil {
+		return cliproxyexecutor.Response{}, fmt.Errorf("azure openai executor: token counting failed: %w", err)
+	}
+
+	usageJSON := buildOpenAIUsageJSON(count)
+	translatedUsage := sdktranslator.TranslateTokenCount(ctx, to, from, count, usageJSON)
+	return cliproxyexecutor.Response{Payload: []byte(translatedUsage)}, nil
+}
+
+// Refresh is a no-op for API-key based Azure credentials.
+func (e *AzureOpenAIExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
+	log.Debugf("azure openai executor: refresh called")
+	_ = ctx
+	return auth, nil
+}
+
+// buildRequest translates the payload and resolves the upstream URL. Requests from
+// Responses clients use the v1 Responses API; all others use the deployment chat endpoint.
+func (e *AzureOpenAIExecutor) buildRequest(auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, baseModel string, stream bool) (sdktranslator.Format, string, []byte, error) {
+	baseURL, _ := azureOpenAICreds(auth)
+	if baseURL == "" {
+		return "", "", nil, statusErr{code: http.StatusUnauthorized, msg: "missing azure openai base-url"}
+	}
+	deployment := e.resolveDeployment(auth, baseModel)
+
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("openai")
+	thinkingFormat := "openai"
+	responses := from == sdktranslator.FromString("openai-response")
+	if responses {
+		to = from
+		thinkingFormat = "codex"
+	}
+
+	originalPayloadSource := req.Payload
+	if len(opts.OriginalRequest) > 0 {
+		originalPayloadSource = opts.OriginalRequest
+	}
+	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayloadSource, stream)
+	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, stream)
+	requestedModel := payloadRequestedModel(opts, req.Model)
+	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, originalTranslated, requestedModel, newPayloadScope(auth, opts))
+
+	body, err := thinking.ApplyThinking(body, req.Model, from.String(), thinkingFormat, e.Identifier())
+	if err != nil {
+		return "", "", nil, err
+	}
+	body, _ = sjson.SetBytes(body, "model", deployment)
+	body, _ = sjson.SetBytes(body, "stream", stream)
+
+	if responses {
+		return to, baseURL + "/openai/v1/responses", body, nil
+	}
+	apiVersion := config.DefaultAzureOpenAIAPIVersion
+	if auth != nil && auth.Attributes != nil {
+		if v := strings.TrimSpace(auth.Attributes["api_version"]); v != "" {
+			apiVersion = v
+		}
+	}
+	targetURL := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		baseURL, url.PathEscape(deployment), url.QueryEscape(apiVersion))
+	return to, targetURL, body, nil
+}
+
+// doRequest sends the prepared request and returns the response only for 2xx statuses.
+func (e *AzureOpenAIExecutor) doRequest(ctx context.Context, auth *cliproxyauth.Auth, targetURL string, body []byte, stream bool) (*http.Response, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if err = e.PrepareRequest(httpReq, auth); err != nil {
+		return nil, err
+	}
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+		httpReq.Header.Set("Cache-Control", "no-cache")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
+		URL:       targetURL,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return nil, err
+	}
+	recordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		appendAPIResponseChunk(ctx, e.cfg, b)
+		logWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("azure openai executor: close response body error: %v", errClose)
+		}
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(b)}
+	}
+	return httpResp, nil
+}
+
+// resolveDeployment maps a client-facing model name to the configured deployment.
+// Unmapped models are assumed to be deployed under their own name.
+func (e *AzureOpenAIExecutor) resolveDeployment(auth *cliproxyauth.Auth, baseModel string) string {
+	baseModel = strings.TrimSpace(baseModel)
+	if entry := resolveAzureOpenAIConfig(e.cfg, auth); entry != nil {
+		for i := range entry.Models {
+			model := entry.Models[i]
+			if strings.EqualFold(model.Alias, baseModel) || strings.EqualFold(model.Name, baseModel) {
+				return model.Name
+			}
+		}
+	}
+	return baseModel
+}
+
+// resolveAzureOpenAIConfig finds the azure-openai configuration entry backing the given auth.
+func resolveAzureOpenAIConfig(cfg *config.Config, auth *cliproxyauth.Auth) *config.AzureOpenAIKey {
+	if cfg == nil || auth == nil || auth.Attributes == nil {
+		return nil
+	}
+	attrKey := strings.TrimSpace(auth.Attributes["api_key"])
+	attrBase := strings.TrimSpace(auth.Attributes["base_url"])
+	for i := range cfg.AzureOpenAIKey {
+		entry := &cfg.AzureOpenAIKey[i]
+		if strings.TrimSpace(entry.APIKey) == attrKey && strings.EqualFold(strings.TrimSpace(entry.BaseURL), attrBase) {
+			return entry
+		}
+	}
+	return nil
+}
+
+func azureOpenAICreds(auth *cliproxyauth.Auth) (baseURL, apiKey string) {
+	if auth == nil || auth.Attributes == nil {
+		return "", ""
+	}
+	baseURL = strings.TrimSuffix(strings.TrimSpace(auth.Attributes["base_url"]), "/")
+	apiKey = strings.TrimSpace(auth.Attributes["api_key"])
+	return
+}
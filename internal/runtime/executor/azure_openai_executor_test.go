@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func newAzureOpenAITestAuth(baseURL string) *cliproxyauth.Auth {
+	return &cliproxyauth.Auth{
+		ID:       "azure-test",
+		Provider: "azure-openai",
+		Attributes: map[string]string{
+			"api_key":     "azure-key",
+			"base_url":    baseURL,
+			"api_version": "2024-10-21",
+		},
+	}
+}
+
+func TestAzureOpenAIExecutorChatUsesDeployment(t *testing.T) {
+	var gotPath, gotQuery, gotKey, gotAuthorization string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotKey = r.Header.Get("api-key")
+		gotAuthorization = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AzureOpenAIKey: []config.AzureOpenAIKey{{
+		APIKey:  "azure-key",
+		BaseURL: server.URL,
+		Models:  []config.AzureOpenAIModel{{Name: "gpt-4o-prod", Alias: "gpt-4o"}},
+	}}}
+	executor := NewAzureOpenAIExecutor(cfg)
+	resp, err := executor.Execute(context.Background(), newAzureOpenAITestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "gpt-4o",
+		Payload: []byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if gotPath != "/openai/deployments/gpt-4o-prod/chat/completions" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if gotQuery != "api-version=2024-10-21" {
+		t.Fatalf("query = %q", gotQuery)
+	}
+	if gotKey != "azure-key" || gotAuthorization != "" {
+		t.Fatalf("api-key = %q, authorization = %q", gotKey, gotAuthorization)
+	}
+	if got := gjson.GetBytes(gotBody, "model").String(); got != "gpt-4o-prod" {
+		t.Fatalf("body model = %q", got)
+	}
+	if got := gjson.GetBytes(resp.Payload, "choices.0.message.content").String(); got != "ok" {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+}
+
+func TestAzureOpenAIExecutorResponsesStream(t *testing.T) {
+	var gotPath, gotQuery string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: response.output_text.delta\ndata: {\"type\":\"response.output_text.delta\",\"delta\":\"hi\"}\n\n"))
+		_, _ = w.Write([]byte("event: response.completed\ndata: {\"type\":\"response.completed\",\"response\":{\"usage\":{\"input_tokens\":1,\"output_tokens\":1,\"total_tokens\":2}}}\n\n"))
+	}))
+	defer server.Close()
+
+	executor := NewAzureOpenAIExecutor(&config.Config{})
+	stream, err := executor.ExecuteStream(context.Background(), newAzureOpenAITestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "gpt-5-codex",
+		Payload: []byte(`{"model":"gpt-5-codex","input":"hi","stream":true}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai-response"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var out strings.Builder
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream chunk error: %v", chunk.Err)
+		}
+		out.Write(chunk.Payload)
+		out.WriteByte('\n')
+	}
+	if gotPath != "/openai/v1/responses" || gotQuery != "" {
+		t.Fatalf("path = %q, query = %q", gotPath, gotQuery)
+	}
+	if got := gjson.GetBytes(gotBody, "input").String(); got != "hi" {
+		t.Fatalf("body input = %q", got)
+	}
+	if !strings.Contains(out.String(), `data: {"type":"response.output_text.delta","delta":"hi"}`) {
+		t.Fatalf("stream output = %q", out.String())
+	}
+}
@@ -0,0 +1,208 @@
+package executor
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// bedrockEventStreamMaxMessage caps a single AWS event-stream frame to guard against corrupt prefixes.
+const bedrockEventStreamMaxMessage = 16 << 20
+
+// bedrockEventMessage is a decoded AWS event-stream frame.
+// Only string-typed headers are retained since Bedrock uses them for all routing metadata.
+type bedrockEventMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// readBedrockEventMessage reads one binary frame in the application/vnd.amazon.eventstream encoding:
+// a 12-byte prelude (total length, headers length, prelude CRC), headers, payload and a trailing CRC.
+// It returns io.EOF when the stream ends cleanly between frames.
+func readBedrockEventMessage(r io.Reader) (bedrockEventMessage, error) {
+	var msg bedrockEventMessage
+	prelude := make([]byte, 12)
+	if _, err := io.ReadFull(r, prelude); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return msg, fmt.Errorf("bedrock event stream: truncated prelude")
+		}
+		return msg, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return msg, fmt.Errorf("bedrock event stream: prelude checksum mismatch")
+	}
+	if totalLen < 16 || totalLen > bedrockEventStreamMaxMessage || uint64(headersLen)+16 > uint64(totalLen) {
+		return msg, fmt.Errorf("bedrock event stream: invalid frame length %d (headers %d)", totalLen, headersLen)
+	}
+
+	frame := make([]byte, totalLen)
+	copy(frame, prelude)
+	if _, err := io.ReadFull(r, frame[12:]); err != nil {
+		return msg, fmt.Errorf("bedrock event stream: truncated frame: %w", err)
+	}
+	if crc32.ChecksumIEEE(frame[:totalLen-4]) != binary.BigEndian.Uint32(frame[totalLen-4:]) {
+		return msg, fmt.Errorf("bedrock event stream: message checksum mismatch")
+	}
+
+	headers, err := parseBedrockEventHeaders(frame[12 : 12+headersLen])
+	if err != nil {
+		return msg, err
+	}
+	msg.Headers = headers
+	msg.Payload = frame[12+headersLen : totalLen-4]
+	return msg, nil
+}
+
+func parseBedrockEventHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+1 {
+			return nil, fmt.Errorf("bedrock event stream: truncated header name")
+		}
+		name := string(data[1 : 1+nameLen])
+		valueType := data[1+nameLen]
+		data = data[2+nameLen:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true / false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // short
+			size = 2
+		case 4: // int
+			size = 4
+		case 5, 8: // long, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, 7: // byte array, string
+			if len(data) < 2 {
+				return nil, fmt.Errorf("bedrock event stream: truncated header %q", name)
+			}
+			valueLen := int(binary.BigEndian.Uint16(data[0:2]))
+			if len(data) < 2+valueLen {
+				return nil, fmt.Errorf("bedrock event stream: truncated header %q", name)
+			}
+			if valueType == 7 {
+				headers[name] = string(data[2 : 2+valueLen])
+			}
+			data = data[2+valueLen:]
+			continue
+		default:
+			return nil, fmt.Errorf("bedrock event stream: unknown header type %d for %q", valueType, name)
+		}
+		if len(data) < size {
+			return nil, fmt.Errorf("bedrock event stream: truncated header %q", name)
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
+
+// bedrockEventToClaudeSSE converts a Bedrock InvokeModelWithResponseStream frame into the
+// Claude SSE lines ("event: ...", "data: ...", "") the Claude translators consume.
+// Exception frames are returned as errors carrying the mapped HTTP status.
+func bedrockEventToClaudeSSE(msg bedrockEventMessage) ([][]byte, error) {
+	switch msg.Headers[":message-type"] {
+	case "exception":
+		exceptionType := msg.Headers[":exception-type"]
+		message := gjson.GetBytes(msg.Payload, "message").String()
+		if message == "" {
+			message = strings.TrimSpace(string(msg.Payload))
+		}
+		return nil, statusErr{code: bedrockExceptionStatus(exceptionType), msg: bedrockClaudeErrorBody(exceptionType, message)}
+	case "error":
+		code := msg.Headers[":error-code"]
+		return nil, statusErr{code: bedrockExceptionStatus(code), msg: bedrockClaudeErrorBody(code, msg.Headers[":error-message"])}
+	}
+	if eventType := msg.Headers[":event-type"]; eventType != "" && eventType != "chunk" {
+		return nil, nil
+	}
+	encoded := gjson.GetBytes(msg.Payload, "bytes").String()
+	if encoded == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("bedrock event stream: decode chunk: %w", err)
+	}
+	eventType := gjson.GetBytes(data, "type").String()
+	lines := make([][]byte, 0, 3)
+	if eventType != "" {
+		lines = append(lines, []byte("event: "+eventType))
+	}
+	lines = append(lines, append([]byte("data: "), data...), []byte{})
+	return lines, nil
+}
+
+// bedrockExceptionStatus maps Bedrock exception names to the HTTP status codes the API would return.
+func bedrockExceptionStatus(exceptionType string) int {
+	name := strings.ToLower(strings.TrimSpace(exceptionType))
+	name = strings.TrimSuffix(name, "exception")
+	if idx := strings.LastIndex(name, "#"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		name = name[:idx]
+	}
+	switch name {
+	case "throttling", "servicequotaexceeded":
+		return http.StatusTooManyRequests
+	case "validation":
+		return http.StatusBadRequest
+	case "accessdenied", "unrecognizedclient", "invalidsignature":
+		return http.StatusForbidden
+	case "expiredtoken":
+		return http.StatusUnauthorized
+	case "resourcenotfound":
+		return http.StatusNotFound
+	case "modeltimeout":
+		return http.StatusRequestTimeout
+	case "modelnotready", "serviceunavailable":
+		return http.StatusServiceUnavailable
+	case "modelstreamerror", "modelerror":
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bedrockClaudeErrorBody renders a Bedrock failure in the Anthropic error envelope so
+// Claude clients see a familiar payload regardless of the upstream.
+func bedrockClaudeErrorBody(exceptionType, message string) string {
+	errType := "api_error"
+	switch bedrockExceptionStatus(exceptionType) {
+	case http.StatusTooManyRequests:
+		errType = "rate_limit_error"
+	case http.StatusBadRequest:
+		errType = "invalid_request_error"
+	case http.StatusUnauthorized:
+		errType = "authentication_error"
+	case http.StatusForbidden:
+		errType = "permission_error"
+	case http.StatusNotFound:
+		errType = "not_found_error"
+	case http.StatusServiceUnavailable:
+		errType = "overloaded_error"
+	}
+	if strings.TrimSpace(message) == "" {
+		message = strings.TrimSpace(exceptionType)
+	}
+	out := []byte(`{"type":"error","error":{"type":"","message":""}}`)
+	out, _ = sjson.SetBytes(out, "error.type", errType)
+	out, _ = sjson.SetBytes(out, "error.message", message)
+	return string(out)
+}
@@ -0,0 +1,484 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	bedrockauth "github.com/router-for-me/CLIProxyAPI/v6/internal/auth/bedrock"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// BedrockExecutor serves Anthropic Claude models through the AWS Bedrock runtime API.
+// Requests are translated to the Claude messages format, signed with SigV4, and sent to
+// InvokeModel or InvokeModelWithResponseStream; streamed event-stream frames are decoded
+// back into Claude SSE so the regular Claude translators can be reused.
+type BedrockExecutor struct {
+	cfg *config.Config
+}
+
+// NewBedrockExecutor creates a Bedrock executor bound to the supplied configuration.
+func NewBedrockExecutor(cfg *config.Config) *BedrockExecutor { return &BedrockExecutor{cfg: cfg} }
+
+func (e *BedrockExecutor) Identifier() string { return "bedrock" }
+
+// PrepareRequest signs the outgoing HTTP request with the credential's AWS keys.
+func (e *BedrockExecutor) PrepareRequest(req *http.Request, auth *cliproxyauth.Auth) error {
+	if req == nil {
+		return nil
+	}
+	var body []byte
+	if req.Body != nil {
+		data, errRead := io.ReadAll(req.Body)
+		if errRead != nil {
+			return errRead
+		}
+		_ = req.Body.Close()
+		body = data
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(req, attrs)
+	return e.signRequest(req, body, auth)
+}
+
+// HttpRequest signs the request with the credential's AWS keys and executes it.
+func (e *BedrockExecutor) HttpRequest(ctx context.Context, auth *cliproxyauth.Auth, req *http.Request) (*http.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("bedrock executor: request is nil")
+	}
+	if ctx == nil {
+		ctx = req.Context()
+	}
+	httpReq := req.WithContext(ctx)
+	if err := e.PrepareRequest(httpReq, auth); err != nil {
+		return nil, err
+	}
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	return httpClient.Do(httpReq)
+}
+
+func (e *BedrockExecutor) Execute(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (resp cliproxyexecutor.Response, err error) {
+	if opts.Alt == "responses/compact" {
+		return resp, statusErr{code: http.StatusNotImplemented, msg: "/responses/compact not supported"}
+	}
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("claude")
+	// Use streaming translation to preserve function calling, except for claude.
+	stream := from != to
+
+	body, bodyForTranslation, err := e.buildBody(ctx, auth, req, opts, baseModel, stream)
+	if err != nil {
+		return resp, err
+	}
+
+	action := "invoke"
+	if stream {
+		action = "invoke-with-response-stream"
+	}
+	httpResp, err := e.doRequest(ctx, auth, baseModel, action, body, stream)
+	if err != nil {
+		return resp, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+	}()
+
+	var data []byte
+	if stream {
+		var sse bytes.Buffer
+		for {
+			msg, errRead := readBedrockEventMessage(httpResp.Body)
+			if errRead != nil {
+				if errors.Is(errRead, io.EOF) {
+					break
+				}
+				recordAPIResponseError(ctx, e.cfg, errRead)
+				return resp, errRead
+			}
+			lines, errEvent := bedrockEventToClaudeSSE(msg)
+			if errEvent != nil {
+				appendAPIResponseChunk(ctx, e.cfg, msg.Payload)
+				return resp, errEvent
+			}
+			for _, line := range lines {
+				appendAPIResponseChunk(ctx, e.cfg, line)
+				if detail, ok := parseClaudeStreamUsage(line); ok {
+					reporter.publish(ctx, detail)
+				}
+				sse.Write(line)
+				sse.WriteByte('\n')
+			}
+		}
+		data = sse.Bytes()
+	} else {
+		data, err = io.ReadAll(httpResp.Body)
+		if err != nil {
+			recordAPIResponseError(ctx, e.cfg, err)
+			return resp, err
+		}
+		appendAPIResponseChunk(ctx, e.cfg, data)
+		reporter.publish(ctx, parseClaudeUsage(data))
+	}
+
+	var param any
+	out := sdktranslator.TranslateNonStream(
+		ctx,
+		to,
+		from,
+		req.Model,
+		opts.OriginalRequest,
+		bodyForTranslation,
+		data,
+		&param,
+	)
+	resp = cliproxyexecutor.Response{Payload: []byte(out)}
+	return resp, nil
+}
+
+func (e *BedrockExecutor) ExecuteStream(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (stream <-chan cliproxyexecutor.StreamChunk, err error) {
+	if opts.Alt == "responses/compact" {
+		return nil, statusErr{code: http.StatusNotImplemented, msg: "/responses/compact not supported"}
+	}
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	reporter := newUsageReporter(ctx, e.Identifier(), baseModel, auth)
+	defer reporter.trackFailure(ctx, &err)
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("claude")
+
+	body, bodyForTranslation, err := e.buildBody(ctx, auth, req, opts, baseModel, true)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := e.doRequest(ctx, auth, baseModel, "invoke-with-response-stream", body, true)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan cliproxyexecutor.StreamChunk)
+	stream = out
+	go func() {
+		defer close(out)
+		defer func() {
+			if errClose := httpResp.Body.Close(); errClose != nil {
+				log.Errorf("response body close error: %v", errClose)
+			}
+		}()
+
+		var param any
+		for {
+			msg, errRead := readBedrockEventMessage(httpResp.Body)
+			if errRead != nil {
+				if errors.Is(errRead, io.EOF) {
+					return
+				}
+				recordAPIResponseError(ctx, e.cfg, errRead)
+				reporter.publishFailure(ctx)
+				out <- cliproxyexecutor.StreamChunk{Err: errRead}
+				return
+			}
+			lines, errEvent := bedrockEventToClaudeSSE(msg)
+			if errEvent != nil {
+				appendAPIResponseChunk(ctx, e.cfg, msg.Payload)
+				reporter.publishFailure(ctx)
+				out <- cliproxyexecutor.StreamChunk{Err: errEvent}
+				return
+			}
+			for _, line := range lines {
+				appendAPIResponseChunk(ctx, e.cfg, line)
+				if detail, ok := parseClaudeStreamUsage(line); ok {
+					reporter.publish(ctx, detail)
+				}
+				// If from == to (Claude → Claude), forward the SSE lines without translation
+				if from == to {
+					cloned := make([]byte, len(line)+1)
+					copy(cloned, line)
+					cloned[len(line)] = '\n'
+					out <- cliproxyexecutor.StreamChunk{Payload: cloned}
+					continue
+				}
+				chunks := sdktranslator.TranslateStream(
+					ctx,
+					to,
+					from,
+					req.Model,
+					opts.OriginalRequest,
+					bodyForTranslation,
+					bytes.Clone(line),
+					&param,
+				)
+				for i := range chunks {
+					out <- cliproxyexecutor.StreamChunk{Payload: []byte(chunks[i])}
+				}
+			}
+		}
+	}()
+	return stream, nil
+}
+
+// CountTokens calls the Bedrock CountTokens API with the translated InvokeModel body.
+func (e *BedrockExecutor) CountTokens(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	baseModel := thinking.ParseSuffix(req.Model).ModelName
+
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("claude")
+	// Use streaming translation to preserve function calling, except for claude.
+	stream := from != to
+	inlined, errMedia := inlineRemoteMedia(ctx, e.cfg, auth, from, to, req.Payload)
+	if errMedia != nil {
+		return cliproxyexecutor.Response{}, errMedia
+	}
+	req.Payload = inlined
+	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, stream)
+	var extraBetas []string
+	extraBetas, body = extractAndRemoveBetas(body)
+	body = prepareBedrockBody(body, extraBetas)
+	if !gjson.GetBytes(body, "max_tokens").Exists() {
+		body, _ = sjson.SetBytes(body, "max_tokens", 1)
+	}
+
+	payload := []byte(`{"input":{"invokeModel":{"body":""}}}`)
+	payload, _ = sjson.SetBytes(payload, "input.invokeModel.body", base64.StdEncoding.EncodeToString(body))
+
+	httpResp, err := e.doRequest(ctx, auth, baseModel, "count-tokens", payload, false)
+	if err != nil {
+		return cliproxyexecutor.Response{}, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return cliproxyexecutor.Response{}, err
+	}
+	appendAPIResponseChunk(ctx, e.cfg, data)
+	count := gjson.GetBytes(data, "inputTokens").Int()
+	usageJSON, _ := sjson.SetBytes([]byte(`{}`), "input_tokens", count)
+	out := sdktranslator.TranslateTokenCount(ctx, to, from, count, usageJSON)
+	return cliproxyexecutor.Response{Payload: []byte(out)}, nil
+}
+
+// Refresh is a no-op; static keys never expire and profile credentials are re-read per request.
+func (e *BedrockExecutor) Refresh(ctx context.Context, auth *cliproxyauth.Auth) (*cliproxyauth.Auth, error) {
+	log.Debugf("bedrock executor: refresh called")
+	_ = ctx
+	return auth, nil
+}
+
+// buildBody translates the client payload to a Bedrock InvokeModel body.
+// It returns the upstream body and the Claude-format body used for response translation.
+func (e *BedrockExecutor) buildBody(ctx context.Context, auth *cliproxyauth.Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, baseModel string, stream bool) ([]byte, []byte, error) {
+	from := opts.SourceFormat
+	to := sdktranslator.FromString("claude")
+	originalPayloadSource := req.Payload
+	if len(opts.OriginalRequest) > 0 {
+		originalPayloadSource = opts.OriginalRequest
+	}
+	originalTranslated := sdktranslator.TranslateRequest(from, to, baseModel, originalPayloadSource, stream)
+	inlined, errMedia := inlineRemoteMedia(ctx, e.cfg, auth, from, to, req.Payload)
+	if errMedia != nil {
+		return nil, nil, errMedia
+	}
+	req.Payload = inlined
+	body := sdktranslator.TranslateRequest(from, to, baseModel, req.Payload, stream)
+	body, _ = sjson.SetBytes(body, "model", baseModel)
+
+	body, err := thinking.ApplyThinking(body, req.Model, from.String(), to.String(), e.Identifier())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestedModel := payloadRequestedModel(opts, req.Model)
+	body = applyPayloadConfigWithRoot(e.cfg, baseModel, to.String(), "", body, originalTranslated, requestedModel, newPayloadScope(auth, opts))
+
+	// Disable thinking if tool_choice forces tool use (Anthropic API constraint)
+	body = disableThinkingIfToolChoiceForced(body)
+
+	var extraBetas []string
+	extraBetas, body = extractAndRemoveBetas(body)
+	bodyForTranslation := body
+	return prepareBedrockBody(body, extraBetas), bodyForTranslation, nil
+}
+
+// prepareBedrockBody adapts a Claude messages payload to the Bedrock InvokeModel schema:
+// the model and stream flags move to the URL, betas move into anthropic_beta, and
+// fields Bedrock rejects are dropped.
+func prepareBedrockBody(body []byte, betas []string) []byte {
+	body, _ = sjson.DeleteBytes(body, "model")
+	body, _ = sjson.DeleteBytes(body, "stream")
+	body, _ = sjson.DeleteBytes(body, "metadata")
+	body, _ = sjson.SetBytes(body, "anthropic_version", bedrockAnthropicVersion)
+	if len(betas) > 0 {
+		body, _ = sjson.SetBytes(body, "anthropic_beta", betas)
+	}
+	return body
+}
+
+// doRequest signs and sends a Bedrock runtime call, returning the response only for 2xx statuses.
+func (e *BedrockExecutor) doRequest(ctx context.Context, auth *cliproxyauth.Auth, baseModel, action string, body []byte, stream bool) (*http.Response, error) {
+	modelID := e.resolveModelID(auth, baseModel)
+	endpoint := bedrockEndpoint(auth)
+	// Bedrock model IDs contain ':' which must be percent-encoded in the path segment.
+	escapedModel := strings.ReplaceAll(url.PathEscape(modelID), ":", "%3A")
+	targetURL := fmt.Sprintf("%s/model/%s/%s", endpoint, escapedModel, action)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	} else {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+	if err = e.signRequest(httpReq, body, auth); err != nil {
+		return nil, err
+	}
+
+	var authID, authLabel, authType, authValue string
+	if auth != nil {
+		authID = auth.ID
+		authLabel = auth.Label
+		authType, authValue = auth.AccountInfo()
+	}
+	recordAPIRequest(ctx, e.cfg, upstreamRequestLog{
+		URL:       targetURL,
+		Method:    http.MethodPost,
+		Headers:   httpReq.Header.Clone(),
+		Body:      body,
+		Provider:  e.Identifier(),
+		AuthID:    authID,
+		AuthLabel: authLabel,
+		AuthType:  authType,
+		AuthValue: authValue,
+	})
+
+	httpClient := newProxyAwareHTTPClient(ctx, e.cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		recordAPIResponseError(ctx, e.cfg, err)
+		return nil, err
+	}
+	recordAPIResponseMetadata(ctx, e.cfg, httpResp.StatusCode, httpResp.Header.Clone())
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		b, _ := io.ReadAll(httpResp.Body)
+		appendAPIResponseChunk(ctx, e.cfg, b)
+		logWithRequestID(ctx).Debugf("request error, error status: %d, error message: %s", httpResp.StatusCode, summarizeErrorBody(httpResp.Header.Get("Content-Type"), b))
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("response body close error: %v", errClose)
+		}
+		errorType := httpResp.Header.Get("X-Amzn-ErrorType")
+		message := gjson.GetBytes(b, "message").String()
+		if errorType == "" || message == "" {
+			return nil, statusErr{code: httpResp.StatusCode, msg: string(b)}
+		}
+		return nil, statusErr{code: httpResp.StatusCode, msg: bedrockClaudeErrorBody(errorType, message)}
+	}
+	return httpResp, nil
+}
+
+func (e *BedrockExecutor) signRequest(req *http.Request, body []byte, auth *cliproxyauth.Auth) error {
+	creds, err := bedrockCredentials(auth)
+	if err != nil {
+		return statusErr{code: http.StatusUnauthorized, msg: err.Error()}
+	}
+	var region string
+	if auth != nil && auth.Attributes != nil {
+		region = auth.Attributes["region"]
+	}
+	return bedrockauth.SignRequest(req, body, creds, region, bedrockauth.ServiceName, time.Now())
+}
+
+// resolveModelID maps a client-facing model name to the Bedrock model or inference profile ID.
+// Configured aliases win; otherwise Anthropic model names are expanded to "anthropic.<model>-v1:0".
+func (e *BedrockExecutor) resolveModelID(auth *cliproxyauth.Auth, baseModel string) string {
+	baseModel = strings.TrimSpace(baseModel)
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	if entry := e.cfg.BedrockKeyFor(attrs); entry != nil {
+		for i := range entry.Models {
+			model := entry.Models[i]
+			if strings.EqualFold(model.Alias, baseModel) || strings.EqualFold(model.Name, baseModel) {
+				return model.Name
+			}
+		}
+	}
+	if strings.Contains(baseModel, "anthropic.") || strings.HasPrefix(baseModel, "arn:") {
+		return baseModel
+	}
+	return "anthropic." + baseModel + "-v1:0"
+}
+
+// bedrockCredentials returns static keys from the auth attributes or loads them from the
+// configured AWS shared credentials profile.
+func bedrockCredentials(auth *cliproxyauth.Auth) (bedrockauth.Credentials, error) {
+	if auth == nil || auth.Attributes == nil {
+		return bedrockauth.Credentials{}, fmt.Errorf("bedrock executor: missing credentials")
+	}
+	attrs := auth.Attributes
+	creds := bedrockauth.Credentials{
+		AccessKeyID:     strings.TrimSpace(attrs["access_key_id"]),
+		SecretAccessKey: strings.TrimSpace(attrs["secret_access_key"]),
+		SessionToken:    strings.TrimSpace(attrs["session_token"]),
+	}
+	if creds.Valid() {
+		return creds, nil
+	}
+	profile := strings.TrimSpace(attrs["profile"])
+	file := strings.TrimSpace(attrs["credentials_file"])
+	if profile == "" && file == "" {
+		return bedrockauth.Credentials{}, fmt.Errorf("bedrock executor: missing credentials")
+	}
+	return bedrockauth.LoadProfileCredentials(file, profile)
+}
+
+func bedrockEndpoint(auth *cliproxyauth.Auth) string {
+	var base, region string
+	if auth != nil && auth.Attributes != nil {
+		base = strings.TrimSpace(auth.Attributes["base_url"])
+		region = strings.TrimSpace(auth.Attributes["region"])
+	}
+	if base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+}
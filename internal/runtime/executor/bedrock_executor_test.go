@@ -0,0 +1,212 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// encodeBedrockEventFrame builds an AWS event-stream frame with string headers.
+func encodeBedrockEventFrame(headers map[string]string, payload []byte) []byte {
+	var hdr bytes.Buffer
+	for name, value := range headers {
+		hdr.WriteByte(byte(len(name)))
+		hdr.WriteString(name)
+		hdr.WriteByte(7)
+		_ = binary.Write(&hdr, binary.BigEndian, uint16(len(value)))
+		hdr.WriteString(value)
+	}
+	total := 12 + hdr.Len() + len(payload) + 4
+	frame := make([]byte, 0, total)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(total))
+	frame = binary.BigEndian.AppendUint32(frame, uint32(hdr.Len()))
+	frame = binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame[:8]))
+	frame = append(frame, hdr.Bytes()...)
+	frame = append(frame, payload...)
+	return binary.BigEndian.AppendUint32(frame, crc32.ChecksumIEEE(frame))
+}
+
+func bedrockChunkFrame(event string) []byte {
+	payload := []byte(`{"bytes":"` + base64.StdEncoding.EncodeToString([]byte(event)) + `"}`)
+	return encodeBedrockEventFrame(map[string]string{
+		":message-type": "event",
+		":event-type":   "chunk",
+		":content-type": "application/json",
+	}, payload)
+}
+
+func newBedrockTestAuth(baseURL string) *cliproxyauth.Auth {
+	return &cliproxyauth.Auth{
+		ID:       "bedrock-test",
+		Provider: "bedrock",
+		Attributes: map[string]string{
+			"region":            "us-east-1",
+			"access_key_id":     "AKIDTEST",
+			"secret_access_key": "secret",
+			"base_url":          baseURL,
+		},
+	}
+}
+
+func TestBedrockExecutorExecuteStream(t *testing.T) {
+	var gotPath, gotAuthorization, gotAccept string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotAuthorization = r.Header.Get("Authorization")
+		gotAccept = r.Header.Get("Accept")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		_, _ = w.Write(bedrockChunkFrame(`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[],"usage":{"input_tokens":3,"output_tokens":0}}}`))
+		_, _ = w.Write(bedrockChunkFrame(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hi"}}`))
+		_, _ = w.Write(bedrockChunkFrame(`{"type":"message_stop"}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{BedrockKey: []config.BedrockKey{{
+		AccessKeyID: "AKIDTEST",
+		Region:      "us-east-1",
+		BaseURL:     server.URL,
+		Models:      []config.ClaudeModel{{Name: "us.anthropic.claude-sonnet-4-20250514-v1:0", Alias: "claude-sonnet-4"}},
+	}}}
+	executor := NewBedrockExecutor(cfg)
+	payload := []byte(`{"model":"claude-sonnet-4","max_tokens":16,"stream":true,"metadata":{"user_id":"u"},"messages":[{"role":"user","content":"hi"}]}`)
+	stream, err := executor.ExecuteStream(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4",
+		Payload: payload,
+	}, cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FromString("claude"),
+		Stream:       true,
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var out strings.Builder
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("stream chunk error: %v", chunk.Err)
+		}
+		out.Write(chunk.Payload)
+	}
+
+	if gotPath != "/model/us.anthropic.claude-sonnet-4-20250514-v1%3A0/invoke-with-response-stream" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if !strings.HasPrefix(gotAuthorization, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(gotAuthorization, "/us-east-1/bedrock/aws4_request") {
+		t.Fatalf("authorization = %q", gotAuthorization)
+	}
+	if gotAccept != "application/vnd.amazon.eventstream" {
+		t.Fatalf("accept = %q", gotAccept)
+	}
+	if got := gjson.GetBytes(gotBody, "anthropic_version").String(); got != bedrockAnthropicVersion {
+		t.Fatalf("anthropic_version = %q", got)
+	}
+	for _, field := range []string{"model", "stream", "metadata"} {
+		if gjson.GetBytes(gotBody, field).Exists() {
+			t.Fatalf("unexpected %q in body: %s", field, gotBody)
+		}
+	}
+	if !strings.Contains(out.String(), "event: content_block_delta\ndata: {\"type\":\"content_block_delta\"") {
+		t.Fatalf("stream output missing content_block_delta: %q", out.String())
+	}
+}
+
+func TestBedrockExecutorExecuteStreamException(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(encodeBedrockEventFrame(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, []byte(`{"message":"Too many requests"}`)))
+	}))
+	defer server.Close()
+
+	executor := NewBedrockExecutor(&config.Config{})
+	stream, err := executor.ExecuteStream(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4-20250514",
+		Payload: []byte(`{"model":"claude-sonnet-4-20250514","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude"), Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream error: %v", err)
+	}
+	var streamErr error
+	for chunk := range stream {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+		}
+	}
+	var se statusErr
+	if !errors.As(streamErr, &se) || se.StatusCode() != http.StatusTooManyRequests {
+		t.Fatalf("stream error = %v, want 429 statusErr", streamErr)
+	}
+	if got := gjson.Get(se.Error(), "error.type").String(); got != "rate_limit_error" {
+		t.Fatalf("error type = %q, want rate_limit_error", got)
+	}
+}
+
+func TestBedrockExecutorExecuteNonStream(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	executor := NewBedrockExecutor(&config.Config{})
+	resp, err := executor.Execute(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4-20250514",
+		Payload: []byte(`{"model":"claude-sonnet-4-20250514","max_tokens":16,"messages":[{"role":"user","content":"hi"}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("claude")})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if gotPath != "/model/anthropic.claude-sonnet-4-20250514-v1%3A0/invoke" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if got := gjson.GetBytes(resp.Payload, "content.0.text").String(); got != "ok" {
+		t.Fatalf("payload = %s", resp.Payload)
+	}
+}
+
+func TestBedrockExecutorInlinesRemoteImage(t *testing.T) {
+	imageServer, imageData := newRemoteImageServer(t)
+
+	var upstreamBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		_, _ = w.Write(bedrockChunkFrame(`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude","content":[],"usage":{"input_tokens":3,"output_tokens":0}}}`))
+		_, _ = w.Write(bedrockChunkFrame(`{"type":"message_stop"}`))
+	}))
+	defer server.Close()
+
+	executor := NewBedrockExecutor(&config.Config{RemoteMedia: config.RemoteMediaConfig{AllowPrivateNetworks: true}})
+	_, err := executor.Execute(context.Background(), newBedrockTestAuth(server.URL), cliproxyexecutor.Request{
+		Model:   "claude-sonnet-4-20250514",
+		Payload: []byte(`{"model":"claude-sonnet-4-20250514","max_tokens":16,"messages":[{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image_url","image_url":{"url":"` + imageServer.URL + `/cat.png"}}]}]}`),
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	source := gjson.GetBytes(upstreamBody, `messages.0.content.#(type=="image").source`)
+	if got := source.Get("type").String(); got != "base64" {
+		t.Fatalf("image source type = %q, body = %s", got, upstreamBody)
+	}
+	if got := source.Get("data").String(); got != base64.StdEncoding.EncodeToString(imageData) {
+		t.Fatalf("image data = %.40q", got)
+	}
+}
@@ -0,0 +1,371 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"golang.org/x/sync/singleflight"
+)
+
+// geminiContextCacheExpiryMargin is subtracted from an upstream cache's lifetime so a request
+// never references a cache that expires while it is in flight.
+const geminiContextCacheExpiryMargin = 15 * time.Second
+
+// geminiContextCacheCleanupInterval controls how often expired handles are purged.
+const geminiContextCacheCleanupInterval = 5 * time.Minute
+
+// geminiContextCacheCreateTimeout bounds a shared cache creation, which outlives the request
+// that started it.
+const geminiContextCacheCreateTimeout = 30 * time.Second
+
+// geminiStaticFields are the request fields that move into a cachedContents resource. Both the
+// camelCase and snake_case spellings are accepted by the API and produced by the translators.
+var geminiStaticFields = []string{"systemInstruction", "system_instruction", "tools", "toolConfig", "tool_config"}
+
+// geminiContextCacheEntry is a cachedContents handle, or a failed creation that should not be
+// retried until Expire.
+type geminiContextCacheEntry struct {
+	Name   string
+	Failed bool
+	Expire time.Time
+}
+
+// geminiContextCacheMap stores cachedContents handles keyed by credential, model and prefix hash.
+var (
+	geminiContextCacheMap         = make(map[string]geminiContextCacheEntry)
+	geminiContextCacheMu          sync.RWMutex
+	geminiContextCacheCleanupOnce sync.Once
+	// geminiContextCacheCreates collapses concurrent creations of the same prefix into one.
+	geminiContextCacheCreates singleflight.Group
+)
+
+func startGeminiContextCacheCleanup() {
+	go func() {
+		ticker := time.NewTicker(geminiContextCacheCleanupInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			now := time.Now()
+			geminiContextCacheMu.Lock()
+			for key, entry := range geminiContextCacheMap {
+				if entry.Expire.Before(now) {
+					delete(geminiContextCacheMap, key)
+				}
+			}
+			geminiContextCacheMu.Unlock()
+		}
+	}()
+}
+
+func getGeminiContextCache(key string) (geminiContextCacheEntry, bool) {
+	geminiContextCacheCleanupOnce.Do(startGeminiContextCacheCleanup)
+	geminiContextCacheMu.RLock()
+	entry, ok := geminiContextCacheMap[key]
+	geminiContextCacheMu.RUnlock()
+	if !ok || entry.Expire.Before(time.Now()) {
+		return geminiContextCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func setGeminiContextCache(key string, entry geminiContextCacheEntry) {
+	geminiContextCacheCleanupOnce.Do(startGeminiContextCacheCleanup)
+	geminiContextCacheMu.Lock()
+	geminiContextCacheMap[key] = entry
+	geminiContextCacheMu.Unlock()
+}
+
+// forgetGeminiContextCache drops every handle pointing at name, typically after the upstream
+// reported that the cache no longer exists.
+func forgetGeminiContextCache(name string) {
+	if name == "" {
+		return
+	}
+	geminiContextCacheMu.Lock()
+	for key, entry := range geminiContextCacheMap {
+		if entry.Name == name {
+			delete(geminiContextCacheMap, key)
+		}
+	}
+	geminiContextCacheMu.Unlock()
+}
+
+// geminiContextCacheTarget describes where a credential's cachedContents are created.
+type geminiContextCacheTarget struct {
+	// endpoint is the cachedContents collection URL.
+	endpoint string
+	// model is the fully qualified model resource name stored in the cache.
+	model string
+	// prepare injects credentials into the creation request.
+	prepare func(*http.Request) error
+}
+
+// geminiCachePrefix is one cacheable prefix of a request: the static fields plus the first
+// Contents entries of contents.
+type geminiCachePrefix struct {
+	Contents int
+	Tokens   int
+	Key      string
+}
+
+// applyGeminiContextCache moves a cacheable prompt prefix of a translated Gemini request into a
+// cachedContents resource and returns the body to send together with the cache name it
+// references. Prefixes are those marked with Claude cache_control breakpoints in the source
+// request, or the system prompt and tools once they exceed the auto-cache threshold. Any
+// failure falls back to the unmodified body.
+func applyGeminiContextCache(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, target geminiContextCacheTarget, from sdktranslator.Format, source, body []byte) ([]byte, string) {
+	if cfg == nil || cfg.ContextCache.Disable || target.endpoint == "" {
+		return body, ""
+	}
+	if gjson.GetBytes(body, "cachedContent").Exists() {
+		return body, ""
+	}
+	prefixes := planGeminiContextCache(cfg.ContextCache, auth, target.model, from, source, body)
+	if len(prefixes) == 0 {
+		return body, ""
+	}
+
+	// Reuse the longest live cache; create one for the longest prefix when nothing is cached
+	// yet or when it covers enough additional tokens to be worth a second cache.
+	var hit *geminiCachePrefix
+	var hitName string
+	var knownFailure bool
+	for i := len(prefixes) - 1; i >= 0; i-- {
+		entry, ok := getGeminiContextCache(prefixes[i].Key)
+		if !ok {
+			continue
+		}
+		if entry.Failed {
+			if i == len(prefixes)-1 {
+				knownFailure = true
+			}
+			continue
+		}
+		hit, hitName = &prefixes[i], entry.Name
+		break
+	}
+	longest := prefixes[len(prefixes)-1]
+	if !knownFailure && (hit == nil || (hit.Contents != longest.Contents && longest.Tokens-hit.Tokens >= cfg.ContextCache.MinTokens)) {
+		if name, errCreate := createGeminiContextCacheOnce(ctx, cfg, auth, target, body, longest); errCreate == nil {
+			hit, hitName = &longest, name
+		} else {
+			log.Debugf("gemini context cache: create failed, sending prompt inline: %v", errCreate)
+		}
+	}
+	if hit == nil {
+		return body, ""
+	}
+
+	out := body
+	for _, field := range geminiStaticFields {
+		out, _ = sjson.DeleteBytes(out, field)
+	}
+	contents := gjson.GetBytes(body, "contents").Array()
+	rest := make([]string, 0, len(contents)-hit.Contents)
+	for _, content := range contents[hit.Contents:] {
+		rest = append(rest, content.Raw)
+	}
+	out, _ = sjson.SetRawBytes(out, "contents", []byte("["+strings.Join(rest, ",")+"]"))
+	out, _ = sjson.SetBytes(out, "cachedContent", hitName)
+	return out, hitName
+}
+
+// planGeminiContextCache returns the cacheable prefixes of body in ascending length. Each
+// prefix leaves at least one content entry in the request and meets the minimum size.
+func planGeminiContextCache(cacheCfg config.ContextCacheConfig, auth *cliproxyauth.Auth, model string, from sdktranslator.Format, source, body []byte) []geminiCachePrefix {
+	contents := gjson.GetBytes(body, "contents").Array()
+	if len(contents) == 0 {
+		return nil
+	}
+
+	var static bytes.Buffer
+	for _, field := range geminiStaticFields {
+		if value := gjson.GetBytes(body, field); value.Exists() {
+			static.WriteString(field)
+			static.WriteString(value.Raw)
+		}
+	}
+	staticTokens := estimateGeminiCacheTokens(static.Len())
+
+	breakpoints := make(map[int]struct{})
+	if from == sdktranslator.FormatClaude {
+		root := gjson.ParseBytes(source)
+		if staticTokens > 0 && (claudeHasCacheControl(root.Get("system")) || claudeHasCacheControl(root.Get("tools"))) {
+			breakpoints[0] = struct{}{}
+		}
+		// Message breakpoints only map onto contents when the translation kept one entry per message.
+		if messages := root.Get("messages").Array(); len(messages) == len(contents) {
+			for i, message := range messages {
+				if claudeHasCacheControl(message.Get("content")) {
+					breakpoints[i+1] = struct{}{}
+				}
+			}
+		}
+	}
+	if staticTokens > 0 && cacheCfg.AutoTokens > 0 && staticTokens >= cacheCfg.AutoTokens {
+		breakpoints[0] = struct{}{}
+	}
+	if len(breakpoints) == 0 {
+		return nil
+	}
+
+	var authID string
+	if auth != nil {
+		authID = auth.ID
+	}
+	var prefixes []geminiCachePrefix
+	tokens := staticTokens
+	hasher := sha256.New()
+	hasher.Write([]byte(authID + "\x00" + model + "\x00"))
+	hasher.Write(static.Bytes())
+	for k := 0; k < len(contents); k++ {
+		if k > 0 {
+			tokens += estimateGeminiCacheTokens(len(contents[k-1].Raw))
+			hasher.Write([]byte{0})
+			hasher.Write([]byte(contents[k-1].Raw))
+		}
+		if _, ok := breakpoints[k]; !ok || tokens < cacheCfg.MinTokens {
+			continue
+		}
+		prefixes = append(prefixes, geminiCachePrefix{Contents: k, Tokens: tokens, Key: hex.EncodeToString(hasher.Sum(nil))})
+	}
+	return prefixes
+}
+
+// createGeminiContextCacheOnce creates the cache for prefix unless an identical creation is in
+// flight, in which case it waits for that one. The creation is detached from ctx so a client
+// leaving does not fail it for every request waiting on it.
+func createGeminiContextCacheOnce(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, target geminiContextCacheTarget, body []byte, prefix geminiCachePrefix) (string, error) {
+	result := geminiContextCacheCreates.DoChan(prefix.Key, func() (any, error) {
+		if entry, ok := getGeminiContextCache(prefix.Key); ok {
+			if entry.Failed {
+				return "", fmt.Errorf("gemini context cache: creation failed recently")
+			}
+			return entry.Name, nil
+		}
+		createCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), geminiContextCacheCreateTimeout)
+		defer cancel()
+		return createGeminiContextCache(createCtx, cfg, auth, target, body, prefix)
+	})
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// createGeminiContextCache creates a cachedContents resource for prefix. Failures are
+// remembered for the configured TTL so a rejected prefix is not retried on every request.
+func createGeminiContextCache(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, target geminiContextCacheTarget, body []byte, prefix geminiCachePrefix) (name string, err error) {
+	ttl := time.Duration(cfg.ContextCache.TTL) * time.Second
+	defer func() {
+		if err != nil {
+			setGeminiContextCache(prefix.Key, geminiContextCacheEntry{Failed: true, Expire: time.Now().Add(ttl)})
+		}
+	}()
+
+	payload := []byte(`{}`)
+	payload, _ = sjson.SetBytes(payload, "model", target.model)
+	contents := gjson.GetBytes(body, "contents").Array()
+	if prefix.Contents > 0 {
+		cached := make([]string, 0, prefix.Contents)
+		for _, content := range contents[:prefix.Contents] {
+			cached = append(cached, content.Raw)
+		}
+		payload, _ = sjson.SetRawBytes(payload, "contents", []byte("["+strings.Join(cached, ",")+"]"))
+	}
+	for _, field := range geminiStaticFields {
+		if value := gjson.GetBytes(body, field); value.Exists() {
+			payload, _ = sjson.SetRawBytes(payload, field, []byte(value.Raw))
+		}
+	}
+	payload, _ = sjson.SetBytes(payload, "ttl", fmt.Sprintf("%ds", cfg.ContextCache.TTL))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, target.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if target.prepare != nil {
+		if err = target.prepare(httpReq); err != nil {
+			return "", err
+		}
+	}
+	httpResp, err := newProxyAwareHTTPClient(ctx, cfg, auth, 0).Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("gemini context cache: close response body error: %v", errClose)
+		}
+	}()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return "", err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return "", statusErr{code: httpResp.StatusCode, msg: string(data)}
+	}
+	name = gjson.GetBytes(data, "name").String()
+	if name == "" {
+		return "", fmt.Errorf("gemini context cache: response without name")
+	}
+
+	expire := time.Now().Add(ttl)
+	if upstream, errParse := time.Parse(time.RFC3339Nano, gjson.GetBytes(data, "expireTime").String()); errParse == nil && upstream.Before(expire) {
+		expire = upstream
+	}
+	setGeminiContextCache(prefix.Key, geminiContextCacheEntry{Name: name, Expire: expire.Add(-geminiContextCacheExpiryMargin)})
+	return name, nil
+}
+
+// handleGeminiContextCacheError forgets cacheName when an upstream error shows that the
+// referenced cache is gone, so the next request recreates it.
+func handleGeminiContextCacheError(cacheName string, status int, body []byte) {
+	if cacheName == "" || status < 400 || status >= 500 {
+		return
+	}
+	if bytes.Contains(bytes.ToLower(body), []byte("cachedcontent")) {
+		forgetGeminiContextCache(cacheName)
+	}
+}
+
+// claudeHasCacheControl reports whether a Claude system, tools or content value carries a
+// cache_control marker on itself or on any of its blocks.
+func claudeHasCacheControl(value gjson.Result) bool {
+	if value.Get("cache_control").Exists() {
+		return true
+	}
+	if !value.IsArray() {
+		return false
+	}
+	found := false
+	value.ForEach(func(_, item gjson.Result) bool {
+		found = item.Get("cache_control").Exists()
+		return !found
+	})
+	return found
+}
+
+// estimateGeminiCacheTokens approximates a token count from a JSON byte length.
+func estimateGeminiCacheTokens(n int) int {
+	return n / 4
+}
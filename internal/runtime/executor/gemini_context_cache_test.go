@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func resetGeminiContextCache() {
+	geminiContextCacheMu.Lock()
+	geminiContextCacheMap = make(map[string]geminiContextCacheEntry)
+	geminiContextCacheMu.Unlock()
+}
+
+func newContextCacheServer(t *testing.T, status int, creates *int32, created *[]byte) geminiContextCacheTarget {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(creates, 1)
+		if created != nil {
+			*created, _ = io.ReadAll(r.Body)
+		}
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(`{"name":"cachedContents/abc","expireTime":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano) + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"error":{"code":400,"message":"too small"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	return geminiContextCacheTarget{endpoint: srv.URL + "/v1beta/cachedContents", model: "models/gemini-2.5-pro"}
+}
+
+func TestApplyGeminiContextCache_ReusesHandle(t *testing.T) {
+	resetGeminiContextCache()
+	var creates int32
+	var created []byte
+	target := newContextCacheServer(t, http.StatusOK, &creates, &created)
+	cfg := &config.Config{ContextCache: config.ContextCacheConfig{TTL: 300, MinTokens: 10, AutoTokens: -1}}
+	auth := &cliproxyauth.Auth{ID: "auth-1"}
+
+	system := strings.Repeat("You are a careful reviewer. ", 20)
+	source := []byte(`{"system":[{"type":"text","text":"` + system + `","cache_control":{"type":"ephemeral"}}],"messages":[{"role":"user","content":"hi"}]}`)
+	body := []byte(`{"systemInstruction":{"parts":[{"text":"` + system + `"}]},"contents":[{"role":"user","parts":[{"text":"hi"}]}],"generationConfig":{"temperature":1}}`)
+
+	out, name := applyGeminiContextCache(context.Background(), cfg, auth, target, sdktranslator.FormatClaude, source, body)
+	if name != "cachedContents/abc" || gjson.GetBytes(out, "cachedContent").String() != name {
+		t.Fatalf("expected cachedContent reference, got %s", out)
+	}
+	if gjson.GetBytes(out, "systemInstruction").Exists() || gjson.GetBytes(out, "contents.#").Int() != 1 || !gjson.GetBytes(out, "generationConfig").Exists() {
+		t.Fatalf("unexpected rewritten body: %s", out)
+	}
+	if gjson.GetBytes(created, "model").String() != "models/gemini-2.5-pro" || gjson.GetBytes(created, "ttl").String() != "300s" || !gjson.GetBytes(created, "systemInstruction").Exists() || gjson.GetBytes(created, "contents").Exists() {
+		t.Fatalf("unexpected cache creation payload: %s", created)
+	}
+
+	if _, name = applyGeminiContextCache(context.Background(), cfg, auth, target, sdktranslator.FormatClaude, source, body); name != "cachedContents/abc" {
+		t.Fatalf("second request did not reuse the cache")
+	}
+	if n := atomic.LoadInt32(&creates); n != 1 {
+		t.Fatalf("cache creations = %d, want 1", n)
+	}
+
+	// Handles are per credential.
+	if _, name = applyGeminiContextCache(context.Background(), cfg, &cliproxyauth.Auth{ID: "auth-2"}, target, sdktranslator.FormatClaude, source, body); name == "" || atomic.LoadInt32(&creates) != 2 {
+		t.Fatalf("expected a new cache for another credential")
+	}
+}
+
+func TestApplyGeminiContextCache_FailureFallsBack(t *testing.T) {
+	resetGeminiContextCache()
+	var creates int32
+	target := newContextCacheServer(t, http.StatusBadRequest, &creates, nil)
+	cfg := &config.Config{ContextCache: config.ContextCacheConfig{TTL: 300, MinTokens: 10, AutoTokens: 10}}
+	body := []byte(`{"tools":[{"functionDeclarations":[{"name":"lookup","description":"` + strings.Repeat("x", 200) + `"}]}],"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+
+	for i := 0; i < 2; i++ {
+		out, name := applyGeminiContextCache(context.Background(), cfg, nil, target, sdktranslator.FormatGemini, nil, body)
+		if name != "" || string(out) != string(body) {
+			t.Fatalf("expected the original body after a failed creation, got %s", out)
+		}
+	}
+	if n := atomic.LoadInt32(&creates); n != 1 {
+		t.Fatalf("cache creations = %d, want 1 (failures are remembered)", n)
+	}
+}
+
+func TestApplyGeminiContextCache_ConcurrentRequestsCreateOnce(t *testing.T) {
+	resetGeminiContextCache()
+	var creates int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&creates, 1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"name":"cachedContents/abc"}`))
+	}))
+	t.Cleanup(srv.Close)
+	target := geminiContextCacheTarget{endpoint: srv.URL + "/v1beta/cachedContents", model: "models/gemini-2.5-pro"}
+	cfg := &config.Config{ContextCache: config.ContextCacheConfig{TTL: 300, MinTokens: 10, AutoTokens: 10}}
+	body := []byte(`{"tools":[{"functionDeclarations":[{"name":"lookup","description":"` + strings.Repeat("x", 200) + `"}]}],"contents":[{"role":"user","parts":[{"text":"hi"}]}]}`)
+
+	var wg sync.WaitGroup
+	names := make([]string, 8)
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, names[i] = applyGeminiContextCache(context.Background(), cfg, nil, target, sdktranslator.FormatGemini, nil, body)
+		}()
+	}
+	wg.Wait()
+	for _, name := range names {
+		if name != "cachedContents/abc" {
+			t.Fatalf("expected every request to reference the shared cache, got %q", names)
+		}
+	}
+	if n := atomic.LoadInt32(&creates); n != 1 {
+		t.Fatalf("cache creations = %d, want 1", n)
+	}
+}
+
+func TestPlanGeminiContextCache(t *testing.T) {
+	long := strings.Repeat("a", 400)
+	body := []byte(`{"systemInstruction":{"parts":[{"text":"short"}]},"contents":[{"role":"user","parts":[{"text":"` + long + `"}]},{"role":"model","parts":[{"text":"ok"}]},{"role":"user","parts":[{"text":"next"}]}]}`)
+	source := []byte(`{"system":"short","messages":[{"role":"user","content":"` + long + `"},{"role":"assistant","content":[{"type":"text","text":"ok","cache_control":{"type":"ephemeral"}}]},{"role":"user","content":"next"}]}`)
+	cacheCfg := config.ContextCacheConfig{TTL: 300, MinTokens: 50, AutoTokens: -1}
+
+	prefixes := planGeminiContextCache(cacheCfg, nil, "m", sdktranslator.FormatClaude, source, body)
+	if len(prefixes) != 1 || prefixes[0].Contents != 2 {
+		t.Fatalf("expected one prefix covering two contents, got %+v", prefixes)
+	}
+
+	// A breakpoint on the last message would leave nothing to send.
+	lastOnly := []byte(`{"messages":[{"role":"user","content":"` + long + `"},{"role":"assistant","content":"ok"},{"role":"user","content":[{"type":"text","text":"next","cache_control":{"type":"ephemeral"}}]}]}`)
+	if prefixes = planGeminiContextCache(cacheCfg, nil, "m", sdktranslator.FormatClaude, lastOnly, body); len(prefixes) != 0 {
+		t.Fatalf("expected no prefix for a trailing breakpoint, got %+v", prefixes)
+	}
+
+	// Without breakpoints only the auto threshold applies.
+	if prefixes = planGeminiContextCache(cacheCfg, nil, "m", sdktranslator.FormatOpenAI, nil, body); len(prefixes) != 0 {
+		t.Fatalf("expected no prefix without breakpoints, got %+v", prefixes)
+	}
+	cacheCfg.MinTokens = 1
+	cacheCfg.AutoTokens = 5
+	if prefixes = planGeminiContextCache(cacheCfg, nil, "m", sdktranslator.FormatOpenAI, nil, body); len(prefixes) != 1 || prefixes[0].Contents != 0 {
+		t.Fatalf("expected an auto prefix for the system prompt, got %+v", prefixes)
+	}
+}
@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+// newRemoteImageServer serves a small PNG and returns the server with the encoded image.
+func newRemoteImageServer(t *testing.T) (*httptest.Server, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	data := buf.Bytes()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, data
+}
+
+func TestGeminiVertexExecutorInlinesRemoteImage(t *testing.T) {
+	imageServer, imageData := newRemoteImageServer(t)
+
+	var upstreamBody []byte
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer upstream.Close()
+
+	cfg := &config.Config{RemoteMedia: config.RemoteMediaConfig{AllowPrivateNetworks: true}}
+	auth := &cliproxyauth.Auth{
+		ID:         "vertex-test",
+		Provider:   "vertex",
+		Attributes: map[string]string{"api_key": "key", "base_url": upstream.URL},
+	}
+	payload := []byte(`{"model":"gemini-2.5-flash","messages":[{"role":"user","content":[{"type":"text","text":"describe"},{"type":"image_url","image_url":{"url":"` + imageServer.URL + `/cat.png"}}]}]}`)
+
+	_, err := NewGeminiVertexExecutor(cfg).Execute(context.Background(), auth, cliproxyexecutor.Request{
+		Model:   "gemini-2.5-flash",
+		Payload: payload,
+	}, cliproxyexecutor.Options{SourceFormat: sdktranslator.FromString("openai")})
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	inline := gjson.GetBytes(upstreamBody, `contents.0.parts.#(inlineData).inlineData`)
+	if got := inline.Get("mime_type").String(); got != "image/png" {
+		t.Fatalf("mime_type = %q, body = %s", got, upstreamBody)
+	}
+	if got := inline.Get("data").String(); got != base64.StdEncoding.EncodeToString(imageData) {
+		t.Fatalf("inline data = %.40q", got)
+	}
+}
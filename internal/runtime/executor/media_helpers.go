@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/media"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+// inlineRemoteMedia downloads remote image and document URLs in a translated-from payload so
+// that the request translators, which only understand inline data, keep them. Requests in the
+// provider's own format are left alone. A failed download is reported to the client as an
+// invalid request instead of silently dropping the content.
+func inlineRemoteMedia(ctx context.Context, cfg *config.Config, auth *cliproxyauth.Auth, from, to sdktranslator.Format, payload []byte) ([]byte, error) {
+	if from == to {
+		return payload, nil
+	}
+	var mediaCfg config.RemoteMediaConfig
+	if cfg != nil {
+		mediaCfg = cfg.RemoteMedia
+	}
+	client := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	out, errInline := media.InlineRemoteMedia(ctx, client, mediaCfg, from.String(), payload)
+	if errInline == nil {
+		return out, nil
+	}
+	if _, ok := errors.AsType[*media.Error](errInline); ok {
+		return nil, statusErr{code: http.StatusBadRequest, msg: errInline.Error()}
+	}
+	return nil, errInline
+}
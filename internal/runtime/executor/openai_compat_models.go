@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// FetchOpenAICompatModels lists the models served by an OpenAI-compatible upstream via
+// "GET {base_url}/models", authenticating with the auth's API key and custom headers.
+// Context length, completion limits, supported parameters and pricing are mapped when present.
+func FetchOpenAICompatModels(ctx context.Context, auth *cliproxyauth.Auth, cfg *config.Config, ownedBy string) ([]*registry.ModelInfo, error) {
+	exec := &OpenAICompatExecutor{cfg: cfg}
+	baseURL, apiKey := exec.resolveCredentials(auth)
+	if baseURL == "" {
+		return nil, fmt.Errorf("openai compat models: missing provider baseURL")
+	}
+	modelsURL := strings.TrimSuffix(baseURL, "/") + "/models"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	httpReq.Header.Set("User-Agent", "cli-proxy-openai-compat")
+	var attrs map[string]string
+	if auth != nil {
+		attrs = auth.Attributes
+	}
+	util.ApplyCustomHeadersFromAttrs(httpReq, attrs)
+
+	httpClient := newProxyAwareHTTPClient(ctx, cfg, auth, 0)
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if errClose := httpResp.Body.Close(); errClose != nil {
+			log.Errorf("openai compat models: close response body error: %v", errClose)
+		}
+	}()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, statusErr{code: httpResp.StatusCode, msg: string(body)}
+	}
+	return parseOpenAICompatModels(body, ownedBy), nil
+}
+
+// parseOpenAICompatModels converts an OpenAI-style model listing ({"data":[...]} or a bare
+// array) into registry models. OpenRouter-style metadata fields are honoured when present.
+func parseOpenAICompatModels(body []byte, ownedBy string) []*registry.ModelInfo {
+	root := gjson.ParseBytes(body)
+	list := root.Get("data")
+	if !list.IsArray() {
+		if root.IsArray() {
+			list = root
+		} else {
+			list = root.Get("models")
+		}
+	}
+	if !list.IsArray() {
+		return nil
+	}
+
+	out := make([]*registry.ModelInfo, 0, len(list.Array()))
+	seen := make(map[string]struct{})
+	list.ForEach(func(_, item gjson.Result) bool {
+		id := strings.TrimSpace(item.Get("id").String())
+		if id == "" {
+			return true
+		}
+		if _, exists := seen[id]; exists {
+			return true
+		}
+		seen[id] = struct{}{}
+
+		info := &registry.ModelInfo{
+			ID:          id,
+			Object:      "model",
+			Created:     item.Get("created").Int(),
+			OwnedBy:     ownedBy,
+			Type:        "openai-compatibility",
+			DisplayName: id,
+			Description: item.Get("description").String(),
+			UserDefined: true,
+		}
+		if name := strings.TrimSpace(item.Get("name").String()); name != "" {
+			info.DisplayName = name
+		}
+		for _, path := range []string{"context_length", "context_window", "max_context_length", "top_provider.context_length"} {
+			if v := item.Get(path).Int(); v > 0 {
+				info.ContextLength = int(v)
+				break
+			}
+		}
+		for _, path := range []string{"top_provider.max_completion_tokens", "max_completion_tokens", "max_output_tokens"} {
+			if v := item.Get(path).Int(); v > 0 {
+				info.MaxCompletionTokens = int(v)
+				break
+			}
+		}
+		if params := item.Get("supported_parameters"); params.IsArray() {
+			for _, p := range params.Array() {
+				if v := strings.TrimSpace(p.String()); v != "" {
+					info.SupportedParameters = append(info.SupportedParameters, v)
+				}
+			}
+		}
+		if pricing := item.Get("pricing"); pricing.IsObject() {
+			price := &registry.ModelPricing{
+				Prompt:          pricing.Get("prompt").String(),
+				Completion:      pricing.Get("completion").String(),
+				Request:         pricing.Get("request").String(),
+				Image:           pricing.Get("image").String(),
+				InputCacheRead:  pricing.Get("input_cache_read").String(),
+				InputCacheWrite: pricing.Get("input_cache_write").String(),
+			}
+			if *price != (registry.ModelPricing{}) {
+				info.Pricing = price
+			}
+		}
+		out = append(out, info)
+		return true
+	})
+	return out
+}
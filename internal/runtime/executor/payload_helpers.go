@@ -2,10 +2,14 @@ package executor
 
 import (
 	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
@@ -15,13 +19,14 @@ import (
 // paths as relative to the provided root path (for example, "request" for Gemini CLI)
 // and restricts matches to the given protocol when supplied. Defaults are checked
 // against the original payload when provided. requestedModel carries the client-visible
-// model name before alias resolution so payload rules can target aliases precisely.
-func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string, payload, original []byte, requestedModel string) []byte {
+// model name before alias resolution so payload rules can target aliases precisely, and
+// scope carries the request attributes matched by each rule's match conditions.
+func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string, payload, original []byte, requestedModel string, scope payloadScope) []byte {
 	if cfg == nil || len(payload) == 0 {
 		return payload
 	}
 	rules := cfg.Payload
-	if len(rules.Default) == 0 && len(rules.DefaultRaw) == 0 && len(rules.Override) == 0 && len(rules.OverrideRaw) == 0 && len(rules.Filter) == 0 {
+	if len(rules.Default) == 0 && len(rules.DefaultRaw) == 0 && len(rules.Override) == 0 && len(rules.OverrideRaw) == 0 && len(rules.Filter) == 0 && len(rules.Operations) == 0 {
 		return payload
 	}
 	model = strings.TrimSpace(model)
@@ -39,7 +44,7 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	// Apply default rules: first write wins per field across all matching rules.
 	for i := range rules.Default {
 		rule := &rules.Default[i]
-		if !payloadModelRulesMatch(rule.Models, protocol, candidates) {
+		if !payloadModelRulesMatch(rule.Models, protocol, candidates) || !payloadMatchApplies(rule.Match, scope, root, out) {
 			continue
 		}
 		for path, value := range rule.Params {
@@ -64,7 +69,7 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	// Apply default raw rules: first write wins per field across all matching rules.
 	for i := range rules.DefaultRaw {
 		rule := &rules.DefaultRaw[i]
-		if !payloadModelRulesMatch(rule.Models, protocol, candidates) {
+		if !payloadModelRulesMatch(rule.Models, protocol, candidates) || !payloadMatchApplies(rule.Match, scope, root, out) {
 			continue
 		}
 		for path, value := range rule.Params {
@@ -93,7 +98,7 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	// Apply override rules: last write wins per field across all matching rules.
 	for i := range rules.Override {
 		rule := &rules.Override[i]
-		if !payloadModelRulesMatch(rule.Models, protocol, candidates) {
+		if !payloadModelRulesMatch(rule.Models, protocol, candidates) || !payloadMatchApplies(rule.Match, scope, root, out) {
 			continue
 		}
 		for path, value := range rule.Params {
@@ -111,7 +116,7 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	// Apply override raw rules: last write wins per field across all matching rules.
 	for i := range rules.OverrideRaw {
 		rule := &rules.OverrideRaw[i]
-		if !payloadModelRulesMatch(rule.Models, protocol, candidates) {
+		if !payloadModelRulesMatch(rule.Models, protocol, candidates) || !payloadMatchApplies(rule.Match, scope, root, out) {
 			continue
 		}
 		for path, value := range rule.Params {
@@ -133,7 +138,7 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 	// Apply filter rules: remove matching paths from payload.
 	for i := range rules.Filter {
 		rule := &rules.Filter[i]
-		if !payloadModelRulesMatch(rule.Models, protocol, candidates) {
+		if !payloadModelRulesMatch(rule.Models, protocol, candidates) || !payloadMatchApplies(rule.Match, scope, root, out) {
 			continue
 		}
 		for _, path := range rule.Params {
@@ -148,6 +153,232 @@ func applyPayloadConfigWithRoot(cfg *config.Config, model, protocol, root string
 			out = updated
 		}
 	}
+	// Apply operation rules: operations run in order, each on the result of the previous one.
+	for i := range rules.Operations {
+		rule := &rules.Operations[i]
+		if !payloadModelRulesMatch(rule.Models, protocol, candidates) || !payloadMatchApplies(rule.Match, scope, root, out) {
+			continue
+		}
+		for _, op := range rule.Ops {
+			out = applyPayloadOperation(out, protocol, root, op)
+		}
+	}
+	return out
+}
+
+// payloadScope carries the request attributes payload rules can match on besides the model.
+type payloadScope struct {
+	apiKey       string
+	headers      http.Header
+	sourceFormat string
+	prefix       string
+}
+
+// newPayloadScope collects the client API key and headers forwarded by the request handlers,
+// the client's request format and the prefix of the credential serving the request.
+func newPayloadScope(auth *cliproxyauth.Auth, opts cliproxyexecutor.Options) payloadScope {
+	scope := payloadScope{sourceFormat: opts.SourceFormat.String()}
+	if auth != nil {
+		scope.prefix = strings.TrimSpace(auth.Prefix)
+	}
+	if apiKey, ok := opts.Metadata[cliproxyexecutor.ClientAPIKeyMetadataKey].(string); ok {
+		scope.apiKey = apiKey
+	}
+	if headers, ok := opts.Metadata[cliproxyexecutor.ClientHeadersMetadataKey].(http.Header); ok {
+		scope.headers = headers
+	}
+	return scope
+}
+
+// payloadMatchApplies reports whether every condition of match holds for the request.
+func payloadMatchApplies(match config.PayloadMatch, scope payloadScope, root string, payload []byte) bool {
+	if len(match.APIKeys) > 0 && (scope.apiKey == "" || !slices.Contains(match.APIKeys, scope.apiKey)) {
+		return false
+	}
+	if len(match.SourceFormats) > 0 && !slices.ContainsFunc(match.SourceFormats, func(format string) bool {
+		return strings.EqualFold(strings.TrimSpace(format), scope.sourceFormat)
+	}) {
+		return false
+	}
+	if len(match.Prefixes) > 0 && !slices.ContainsFunc(match.Prefixes, func(prefix string) bool {
+		return strings.EqualFold(strings.Trim(strings.TrimSpace(prefix), "/"), strings.Trim(scope.prefix, "/"))
+	}) {
+		return false
+	}
+	for name, pattern := range match.Headers {
+		values := scope.headers.Values(name)
+		if len(values) == 0 {
+			return false
+		}
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		if !slices.ContainsFunc(values, func(value string) bool { return matchModelPattern(pattern, value) }) {
+			return false
+		}
+	}
+	for path, want := range match.When {
+		fullPath := buildPayloadPath(root, path)
+		if fullPath == "" || !payloadValueMatches(gjson.GetBytes(payload, fullPath), want) {
+			return false
+		}
+	}
+	return true
+}
+
+// payloadValueMatches compares a payload value with a "when" condition value.
+func payloadValueMatches(got gjson.Result, want any) bool {
+	if want == nil {
+		return !got.Exists() || got.Type == gjson.Null
+	}
+	if !got.Exists() {
+		return false
+	}
+	if pattern, ok := want.(string); ok {
+		return pattern == "*" || matchModelPattern(pattern, got.String())
+	}
+	wantRaw, errMarshal := json.Marshal(want)
+	if errMarshal != nil {
+		return false
+	}
+	var wantValue, gotValue any
+	if json.Unmarshal(wantRaw, &wantValue) != nil || json.Unmarshal([]byte(got.Raw), &gotValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(wantValue, gotValue)
+}
+
+// applyPayloadOperation applies one operation, returning the payload unchanged when the
+// operation does not fit it.
+func applyPayloadOperation(payload []byte, protocol, root string, op config.PayloadOperation) []byte {
+	if op.Op == config.PayloadOpPrependSystem {
+		text, _ := op.Value.(string)
+		return prependSystemPrompt(payload, protocol, root, text)
+	}
+	path := buildPayloadPath(root, op.Path)
+	if path == "" {
+		return payload
+	}
+	var (
+		out []byte
+		err error
+	)
+	switch op.Op {
+	case config.PayloadOpSet:
+		out, err = sjson.SetBytes(payload, path, op.Value)
+	case config.PayloadOpSetRaw:
+		rawValue, ok := payloadRawValue(op.Value)
+		if !ok {
+			return payload
+		}
+		out, err = sjson.SetRawBytes(payload, path, rawValue)
+	case config.PayloadOpDelete:
+		out, err = sjson.DeleteBytes(payload, path)
+	case config.PayloadOpAppend, config.PayloadOpPrepend:
+		existing := gjson.GetBytes(payload, path)
+		if existing.Exists() && !existing.IsArray() {
+			return payload
+		}
+		item, errMarshal := json.Marshal(op.Value)
+		if errMarshal != nil {
+			return payload
+		}
+		out, err = sjson.SetRawBytes(payload, path, insertArrayItem(existing, item, op.Op == config.PayloadOpPrepend))
+	case config.PayloadOpRename:
+		value := gjson.GetBytes(payload, path)
+		to := buildPayloadPath(root, op.To)
+		if !value.Exists() || to == "" {
+			return payload
+		}
+		if out, err = sjson.DeleteBytes(payload, path); err == nil {
+			out, err = sjson.SetRawBytes(out, to, []byte(value.Raw))
+		}
+	case config.PayloadOpRemoveItems:
+		existing := gjson.GetBytes(payload, path)
+		conditions, _ := op.Value.(map[string]any)
+		if !existing.IsArray() || len(conditions) == 0 {
+			return payload
+		}
+		kept := make([]string, 0, len(existing.Array()))
+		existing.ForEach(func(_, item gjson.Result) bool {
+			for field, want := range conditions {
+				if !payloadValueMatches(item.Get(field), want) {
+					kept = append(kept, item.Raw)
+					return true
+				}
+			}
+			return true
+		})
+		if len(kept) == len(existing.Array()) {
+			return payload
+		}
+		out, err = sjson.SetRawBytes(payload, path, []byte("["+strings.Join(kept, ",")+"]"))
+	default:
+		return payload
+	}
+	if err != nil {
+		return payload
+	}
+	return out
+}
+
+// insertArrayItem returns the raw JSON of array with item added at its start or end.
+func insertArrayItem(array gjson.Result, item []byte, atStart bool) []byte {
+	items := make([]string, 0, 1+len(array.Array()))
+	array.ForEach(func(_, value gjson.Result) bool {
+		items = append(items, value.Raw)
+		return true
+	})
+	if atStart {
+		items = append([]string{string(item)}, items...)
+	} else {
+		items = append(items, string(item))
+	}
+	return []byte("[" + strings.Join(items, ",") + "]")
+}
+
+// prependSystemPrompt places text before the system prompt of a payload in the given
+// protocol, creating the system prompt when the payload has none.
+func prependSystemPrompt(payload []byte, protocol, root, text string) []byte {
+	if text == "" {
+		return payload
+	}
+	at := func(path string) string { return buildPayloadPath(root, path) }
+	var (
+		out []byte
+		err error
+	)
+	switch strings.ToLower(protocol) {
+	case "claude":
+		system := gjson.GetBytes(payload, at("system"))
+		switch {
+		case system.IsArray():
+			block, _ := sjson.Set(`{"type":"text","text":""}`, "text", text)
+			out, err = sjson.SetRawBytes(payload, at("system"), insertArrayItem(system, []byte(block), true))
+		case system.String() != "":
+			out, err = sjson.SetBytes(payload, at("system"), text+"\n\n"+system.String())
+		default:
+			out, err = sjson.SetBytes(payload, at("system"), text)
+		}
+	case "openai-response", "codex":
+		if instructions := gjson.GetBytes(payload, at("instructions")).String(); instructions != "" {
+			text += "\n\n" + instructions
+		}
+		out, err = sjson.SetBytes(payload, at("instructions"), text)
+	case "gemini", "gemini-cli", "antigravity":
+		field := "systemInstruction"
+		if !gjson.GetBytes(payload, at(field)).Exists() && gjson.GetBytes(payload, at("system_instruction")).Exists() {
+			field = "system_instruction"
+		}
+		part, _ := sjson.Set(`{"text":""}`, "text", text)
+		out, err = sjson.SetRawBytes(payload, at(field+".parts"), insertArrayItem(gjson.GetBytes(payload, at(field+".parts")), []byte(part), true))
+	default:
+		message, _ := sjson.Set(`{"role":"system","content":""}`, "content", text)
+		out, err = sjson.SetRawBytes(payload, at("messages"), insertArrayItem(gjson.GetBytes(payload, at("messages")), []byte(message), true))
+	}
+	if err != nil {
+		return payload
+	}
 	return out
 }
 
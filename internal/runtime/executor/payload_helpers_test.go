@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyPayloadConfigMatchConditions(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Payload.Override = []config.PayloadRule{{
+		Models: []config.PayloadModelRule{{Name: "claude-*"}},
+		Match: config.PayloadMatch{
+			APIKeys:       []string{"key-a"},
+			Headers:       map[string]string{"X-Team": "research-*"},
+			SourceFormats: []string{"openai"},
+			Prefixes:      []string{"teamA"},
+			When:          map[string]any{"thinking.type": "enabled", "metadata": nil},
+		},
+		Params: map[string]any{"max_tokens": 4096},
+	}}
+
+	headers := http.Header{}
+	headers.Set("X-Team", "research-infra")
+	opts := cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FormatOpenAI,
+		Metadata: map[string]any{
+			cliproxyexecutor.ClientAPIKeyMetadataKey:  "key-a",
+			cliproxyexecutor.ClientHeadersMetadataKey: headers,
+		},
+	}
+	auth := &cliproxyauth.Auth{Prefix: "teamA"}
+	payload := []byte(`{"model":"claude-sonnet-4-5","thinking":{"type":"enabled"},"max_tokens":1024}`)
+
+	out := applyPayloadConfigWithRoot(cfg, "claude-sonnet-4-5", "claude", "", payload, nil, "", newPayloadScope(auth, opts))
+	if got := gjson.GetBytes(out, "max_tokens").Int(); got != 4096 {
+		t.Fatalf("expected matching rule to apply, max_tokens = %d", got)
+	}
+
+	mismatches := map[string]func() ([]byte, payloadScope){
+		"api key": func() ([]byte, payloadScope) {
+			scope := newPayloadScope(auth, opts)
+			scope.apiKey = "key-b"
+			return payload, scope
+		},
+		"header": func() ([]byte, payloadScope) {
+			scope := newPayloadScope(auth, opts)
+			scope.headers = http.Header{"X-Team": []string{"sales"}}
+			return payload, scope
+		},
+		"source format": func() ([]byte, payloadScope) {
+			scope := newPayloadScope(auth, opts)
+			scope.sourceFormat = "claude"
+			return payload, scope
+		},
+		"credential prefix": func() ([]byte, payloadScope) {
+			return payload, newPayloadScope(&cliproxyauth.Auth{}, opts)
+		},
+		"when value": func() ([]byte, payloadScope) {
+			return []byte(`{"thinking":{"type":"disabled"},"max_tokens":1024}`), newPayloadScope(auth, opts)
+		},
+		"when absent": func() ([]byte, payloadScope) {
+			return []byte(`{"thinking":{"type":"enabled"},"metadata":{},"max_tokens":1024}`), newPayloadScope(auth, opts)
+		},
+	}
+	for name, build := range mismatches {
+		body, scope := build()
+		out = applyPayloadConfigWithRoot(cfg, "claude-sonnet-4-5", "claude", "", body, nil, "", scope)
+		if got := gjson.GetBytes(out, "max_tokens").Int(); got != 1024 {
+			t.Errorf("%s mismatch: rule applied, max_tokens = %d", name, got)
+		}
+	}
+}
+
+func TestApplyPayloadConfigOperations(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Payload.Operations = []config.PayloadOperationRule{{
+		Models: []config.PayloadModelRule{{Name: "*"}},
+		Ops: []config.PayloadOperation{
+			{Op: config.PayloadOpPrependSystem, Value: "Be terse."},
+			{Op: config.PayloadOpAppend, Path: "stop", Value: "END"},
+			{Op: config.PayloadOpPrepend, Path: "tags", Value: map[string]any{"name": "first"}},
+			{Op: config.PayloadOpRename, Path: "user", To: "metadata.user_id"},
+		},
+	}}
+
+	chat := []byte(`{"messages":[{"role":"user","content":"hi"}],"stop":["\n"],"tags":[{"name":"second"}],"user":"u1"}`)
+	out := applyPayloadConfigWithRoot(cfg, "gpt-4o", "openai", "", chat, nil, "", payloadScope{})
+	if gjson.GetBytes(out, "messages.0.role").String() != "system" || gjson.GetBytes(out, "messages.0.content").String() != "Be terse." {
+		t.Fatalf("system message not prepended: %s", out)
+	}
+	if gjson.GetBytes(out, "stop.#").Int() != 2 || gjson.GetBytes(out, "stop.1").String() != "END" {
+		t.Fatalf("stop not appended: %s", out)
+	}
+	if gjson.GetBytes(out, "tags.0.name").String() != "first" || gjson.GetBytes(out, "tags.1.name").String() != "second" {
+		t.Fatalf("tag not prepended: %s", out)
+	}
+	if gjson.GetBytes(out, "user").Exists() || gjson.GetBytes(out, "metadata.user_id").String() != "u1" {
+		t.Fatalf("user not renamed: %s", out)
+	}
+
+	claude := []byte(`{"system":[{"type":"text","text":"You are helpful."}],"messages":[]}`)
+	out = applyPayloadConfigWithRoot(cfg, "claude-sonnet-4-5", "claude", "", claude, nil, "", payloadScope{})
+	if gjson.GetBytes(out, "system.0.text").String() != "Be terse." || gjson.GetBytes(out, "system.1.text").String() != "You are helpful." {
+		t.Fatalf("claude system not prepended: %s", out)
+	}
+	if gjson.GetBytes(out, "stop.0").String() != "END" {
+		t.Fatalf("missing array not created: %s", out)
+	}
+
+	cli := []byte(`{"model":"gemini-2.5-pro","request":{"systemInstruction":{"parts":[{"text":"Existing."}]}}}`)
+	out = applyPayloadConfigWithRoot(cfg, "gemini-2.5-pro", "gemini", "request", cli, nil, "", payloadScope{})
+	if gjson.GetBytes(out, "request.systemInstruction.parts.0.text").String() != "Be terse." ||
+		gjson.GetBytes(out, "request.systemInstruction.parts.1.text").String() != "Existing." {
+		t.Fatalf("gemini system not prepended under root: %s", out)
+	}
+
+	responses := []byte(`{"instructions":"Follow policy.","input":[]}`)
+	out = applyPayloadConfigWithRoot(cfg, "gpt-5", "codex", "", responses, nil, "", payloadScope{})
+	if got := gjson.GetBytes(out, "instructions").String(); got != "Be terse.\n\nFollow policy." {
+		t.Fatalf("instructions = %q", got)
+	}
+}
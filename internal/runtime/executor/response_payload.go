@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ResponsePayloadRewriterProvider applies the response-payload rules of the runtime config to
+// translated executor output. The output is already in the client's format, so rule protocols
+// match the client request format (for example "openai", "claude" or "gemini").
+type ResponsePayloadRewriterProvider struct{}
+
+// NewResponsePayloadRewriterProvider returns the provider registered with the auth manager.
+func NewResponsePayloadRewriterProvider() ResponsePayloadRewriterProvider {
+	return ResponsePayloadRewriterProvider{}
+}
+
+// ResponseRewriterFor selects the rules whose models, client and credential conditions fit the
+// attempt. Payload ("when") conditions are checked per body or event.
+func (ResponsePayloadRewriterProvider) ResponseRewriterFor(cfg *config.Config, auth *cliproxyauth.Auth, provider, model string, opts cliproxyexecutor.Options) cliproxyauth.ResponseRewriter {
+	if cfg == nil || len(cfg.ResponsePayload) == 0 {
+		return nil
+	}
+	candidates := payloadModelCandidates(strings.TrimSpace(model), strings.TrimSpace(payloadRequestedModel(opts, model)))
+	if len(candidates) == 0 {
+		return nil
+	}
+	scope := newPayloadScope(auth, opts)
+	protocol := opts.SourceFormat.String()
+	var rules []config.PayloadOperationRule
+	for _, rule := range cfg.ResponsePayload {
+		match := rule.Match
+		match.When = nil
+		if payloadModelRulesMatch(rule.Models, protocol, candidates) && payloadMatchApplies(match, scope, "", nil) {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &responsePayloadRewriter{rules: rules, scope: scope, protocol: protocol}
+}
+
+type responsePayloadRewriter struct {
+	rules    []config.PayloadOperationRule
+	scope    payloadScope
+	protocol string
+}
+
+// RewriteResponse applies the rules to a non-streaming JSON body. drop-event has no effect here.
+func (r *responsePayloadRewriter) RewriteResponse(payload []byte) []byte {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return payload
+	}
+	out, _ := r.apply(trimmed)
+	return out
+}
+
+// RewriteStreamChunk applies the rules to each event of a streamed chunk. Chunks are either a
+// bare JSON object or SSE text; for SSE every "data:" line is edited and a dropped event loses
+// its "event:" line and trailing separator as well.
+func (r *responsePayloadRewriter) RewriteStreamChunk(chunk []byte) []byte {
+	trimmed := bytes.TrimSpace(chunk)
+	if len(trimmed) == 0 {
+		return chunk
+	}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		out, drop := r.apply(trimmed)
+		if drop {
+			return nil
+		}
+		return out
+	}
+	lines := bytes.Split(chunk, []byte("\n"))
+	out := make([][]byte, 0, len(lines))
+	event := make([][]byte, 0, 2)
+	dropped := false
+	for i, line := range lines {
+		blank := len(bytes.TrimSpace(line)) == 0
+		if !blank {
+			if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+				data = bytes.TrimSpace(data)
+				if len(data) > 0 && (data[0] == '{' || data[0] == '[') {
+					edited, drop := r.apply(data)
+					dropped = dropped || drop
+					line = append([]byte("data: "), edited...)
+				}
+			}
+			event = append(event, line)
+			if i < len(lines)-1 {
+				continue
+			}
+		}
+		if !dropped {
+			out = append(out, event...)
+			if blank {
+				out = append(out, line)
+			}
+		}
+		event = event[:0]
+		dropped = false
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+// apply runs every rule whose "when" conditions hold for data, reporting whether an applied
+// rule drops the event.
+func (r *responsePayloadRewriter) apply(data []byte) ([]byte, bool) {
+	out := data
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if len(rule.Match.When) > 0 && !payloadMatchApplies(config.PayloadMatch{When: rule.Match.When}, r.scope, "", out) {
+			continue
+		}
+		for _, op := range rule.Ops {
+			if op.Op == config.PayloadOpDropEvent {
+				return out, true
+			}
+			out = applyPayloadOperation(out, r.protocol, "", op)
+		}
+	}
+	return out, false
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+func TestResponsePayloadRewriter(t *testing.T) {
+	cfg := &config.Config{ResponsePayload: []config.PayloadOperationRule{
+		{
+			Models: []config.PayloadModelRule{{Name: "claude-*", Protocol: "claude"}},
+			Match:  config.PayloadMatch{APIKeys: []string{"amp"}},
+			Ops: []config.PayloadOperation{
+				{Op: config.PayloadOpRemoveItems, Path: "content", Value: map[string]any{"type": "thinking"}},
+				{Op: config.PayloadOpSet, Path: "model", Value: "claude-alias"},
+			},
+		},
+		{
+			Models: []config.PayloadModelRule{{Name: "*"}},
+			Match:  config.PayloadMatch{When: map[string]any{"delta.type": "thinking_delta"}},
+			Ops:    []config.PayloadOperation{{Op: config.PayloadOpDropEvent}},
+		},
+	}}
+	cfg.SanitizePayloadRules()
+	if len(cfg.ResponsePayload) != 2 {
+		t.Fatalf("sanitize dropped rules: %+v", cfg.ResponsePayload)
+	}
+	opts := cliproxyexecutor.Options{
+		SourceFormat: sdktranslator.FormatClaude,
+		Metadata:     map[string]any{cliproxyexecutor.ClientAPIKeyMetadataKey: "amp"},
+	}
+	provider := NewResponsePayloadRewriterProvider()
+	auth := &cliproxyauth.Auth{}
+
+	geminiOpts := cliproxyexecutor.Options{SourceFormat: sdktranslator.FormatGemini, Metadata: opts.Metadata}
+	if rw := provider.ResponseRewriterFor(cfg, auth, "claude", "claude-sonnet-4-5", geminiOpts); rw == nil {
+		t.Fatal("catch-all rule should select a rewriter")
+	} else if got := rw.RewriteResponse([]byte(`{"content":[{"type":"thinking"}]}`)); gjson.GetBytes(got, "content.#").Int() != 1 {
+		t.Fatalf("claude rule applied to a gemini client: %s", got)
+	}
+
+	// A Claude client served by another provider still gets the claude rules.
+	rw := provider.ResponseRewriterFor(cfg, auth, "antigravity", "claude-sonnet-4-5", opts)
+	body := rw.RewriteResponse([]byte(`{"model":"claude-sonnet-4-5","content":[{"type":"thinking","thinking":"..."},{"type":"text","text":"hi"}]}`))
+	if gjson.GetBytes(body, "content.#").Int() != 1 || gjson.GetBytes(body, "content.0.type").String() != "text" {
+		t.Fatalf("thinking block not removed: %s", body)
+	}
+	if gjson.GetBytes(body, "model").String() != "claude-alias" {
+		t.Fatalf("model not rewritten: %s", body)
+	}
+
+	dropped := "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"thinking_delta\"}}\n\n"
+	if got := rw.RewriteStreamChunk([]byte(dropped)); len(got) != 0 {
+		t.Fatalf("thinking delta not dropped: %q", got)
+	}
+	kept := "event: message_start\ndata: {\"type\":\"message_start\",\"model\":\"claude-sonnet-4-5\"}\n\n"
+	got := string(rw.RewriteStreamChunk([]byte(kept)))
+	if !strings.HasPrefix(got, "event: message_start\ndata: ") || !strings.HasSuffix(got, "\n\n") || !strings.Contains(got, `"model":"claude-alias"`) {
+		t.Fatalf("event not rewritten in place: %q", got)
+	}
+	if got := rw.RewriteStreamChunk([]byte(`{"delta":{"type":"thinking_delta"}}`)); len(got) != 0 {
+		t.Fatalf("bare JSON chunk not dropped: %q", got)
+	}
+}
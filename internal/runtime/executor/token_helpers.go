@@ -1,9 +1,17 @@
 package executor
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"regexp"
 	"strings"
 
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	"github.com/tidwall/gjson"
 	"github.com/tiktoken-go/tokenizer"
 )
@@ -69,6 +77,27 @@ func countOpenAIChatTokens(enc tokenizer.Codec, payload []byte) (int64, error) {
 	return int64(count), nil
 }
 
+// CountRequestTokens approximates the prompt tokens of a request in any client format without
+// contacting an upstream. Claude and Gemini payloads are counted natively; other formats are
+// translated to OpenAI chat completions first. The tokenizer is chosen by model.
+func CountRequestTokens(from sdktranslator.Format, model string, payload []byte) (int64, error) {
+	enc, err := tokenizerForModel(model)
+	if err != nil {
+		return 0, fmt.Errorf("tokenizer init failed: %w", err)
+	}
+	switch from {
+	case sdktranslator.FormatClaude:
+		return countClaudeTokens(enc, payload)
+	case sdktranslator.FormatGemini:
+		return countGeminiTokens(enc, payload)
+	case sdktranslator.FormatGeminiCLI:
+		return countGeminiTokens(enc, []byte(gjson.GetBytes(payload, "request").Raw))
+	case sdktranslator.FormatOpenAI:
+		return countOpenAIChatTokens(enc, payload)
+	}
+	return countOpenAIChatTokens(enc, sdktranslator.TranslateRequest(from, sdktranslator.FormatOpenAI, model, payload, false))
+}
+
 // buildOpenAIUsageJSON returns a minimal usage structure understood by downstream translators.
 func buildOpenAIUsageJSON(count int64) []byte {
 	return []byte(fmt.Sprintf(`{"usage":{"prompt_tokens":%d,"completion_tokens":0,"total_tokens":%d}}`, count, count))
@@ -103,7 +132,10 @@ func collectOpenAIContent(content gjson.Result, segments *[]string) {
 			case "text", "input_text", "output_text":
 				addIfNotEmpty(segments, part.Get("text").String())
 			case "image_url":
-				addIfNotEmpty(segments, part.Get("image_url.url").String())
+				// Inline images are not text; counting their base64 data would inflate the total.
+				if url := part.Get("image_url.url").String(); !strings.HasPrefix(url, "data:") {
+					addIfNotEmpty(segments, url)
+				}
 			case "input_audio", "output_audio", "audio":
 				addIfNotEmpty(segments, part.Get("id").String())
 			case "tool_result":
@@ -234,3 +266,250 @@ func addIfNotEmpty(segments *[]string, value string) {
 		*segments = append(*segments, trimmed)
 	}
 }
+
+// Image and document costs used when the content itself cannot be measured. Claude bills images
+// by pixel area (about width*height/750 after scaling to at most 1568px on the long edge);
+// Gemini bills 258 tokens per 768px tile and per PDF page.
+const (
+	claudeDefaultImageTokens = 1600
+	claudeMaxImageEdge       = 1568
+	claudeTokensPerPDFPage   = 2000
+	geminiTokensPerTile      = 258
+	geminiImageTileEdge      = 768
+	geminiSmallImageEdge     = 384
+	defaultPDFPages          = 1
+	// imageHeaderBase64Limit bounds how much base64 data is decoded to read image dimensions.
+	imageHeaderBase64Limit = 256 << 10
+)
+
+// countClaudeTokens approximates prompt tokens for Claude Messages payloads, including the
+// system prompt, tool definitions, tool calls and results, thinking blocks, images and documents.
+func countClaudeTokens(enc tokenizer.Codec, payload []byte) (int64, error) {
+	if enc == nil {
+		return 0, fmt.Errorf("encoder is nil")
+	}
+	root := gjson.ParseBytes(payload)
+	segments := make([]string, 0, 32)
+	var media int64
+
+	system := root.Get("system")
+	if system.IsArray() {
+		media += collectClaudeBlocks(system, &segments)
+	} else {
+		addIfNotEmpty(&segments, system.String())
+	}
+	root.Get("messages").ForEach(func(_, message gjson.Result) bool {
+		addIfNotEmpty(&segments, message.Get("role").String())
+		content := message.Get("content")
+		if content.IsArray() {
+			media += collectClaudeBlocks(content, &segments)
+		} else {
+			addIfNotEmpty(&segments, content.String())
+		}
+		return true
+	})
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		addIfNotEmpty(&segments, tool.Get("name").String())
+		addIfNotEmpty(&segments, tool.Get("description").String())
+		if schema := tool.Get("input_schema"); schema.Exists() {
+			addIfNotEmpty(&segments, schema.Raw)
+		}
+		return true
+	})
+	if choice := root.Get("tool_choice"); choice.Exists() {
+		addIfNotEmpty(&segments, choice.Raw)
+	}
+	return countSegments(enc, segments, media)
+}
+
+// collectClaudeBlocks appends the text of Claude content blocks to segments and returns the
+// estimated tokens of images and documents, which are not tokenized as text.
+func collectClaudeBlocks(blocks gjson.Result, segments *[]string) int64 {
+	var media int64
+	blocks.ForEach(func(_, block gjson.Result) bool {
+		switch block.Get("type").String() {
+		case "text":
+			addIfNotEmpty(segments, block.Get("text").String())
+		case "thinking":
+			addIfNotEmpty(segments, block.Get("thinking").String())
+		case "image":
+			media += claudeImageTokens(block.Get("source.data").String())
+		case "document":
+			switch block.Get("source.type").String() {
+			case "text":
+				addIfNotEmpty(segments, block.Get("source.data").String())
+			case "content":
+				media += collectClaudeBlocks(block.Get("source.content"), segments)
+			default:
+				media += int64(pdfPageCount(block.Get("source.data").String())) * claudeTokensPerPDFPage
+			}
+		case "tool_use", "server_tool_use":
+			addIfNotEmpty(segments, block.Get("name").String())
+			addIfNotEmpty(segments, block.Get("input").Raw)
+		case "tool_result":
+			content := block.Get("content")
+			if content.IsArray() {
+				media += collectClaudeBlocks(content, segments)
+			} else {
+				addIfNotEmpty(segments, content.String())
+			}
+		}
+		return true
+	})
+	return media
+}
+
+// countGeminiTokens approximates prompt tokens for Gemini generateContent and countTokens
+// payloads, including thought parts, function calls and responses, tools and inline media.
+func countGeminiTokens(enc tokenizer.Codec, payload []byte) (int64, error) {
+	if enc == nil {
+		return 0, fmt.Errorf("encoder is nil")
+	}
+	root := gjson.ParseBytes(payload)
+	if wrapped := root.Get("generateContentRequest"); wrapped.IsObject() {
+		root = wrapped
+	}
+	segments := make([]string, 0, 32)
+	var media int64
+
+	for _, path := range []string{"systemInstruction.parts", "system_instruction.parts"} {
+		media += collectGeminiParts(root.Get(path), &segments)
+	}
+	root.Get("contents").ForEach(func(_, content gjson.Result) bool {
+		addIfNotEmpty(&segments, content.Get("role").String())
+		media += collectGeminiParts(content.Get("parts"), &segments)
+		return true
+	})
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		for _, path := range []string{"functionDeclarations", "function_declarations"} {
+			tool.Get(path).ForEach(func(_, decl gjson.Result) bool {
+				addIfNotEmpty(&segments, decl.Get("name").String())
+				addIfNotEmpty(&segments, decl.Get("description").String())
+				for _, schemaPath := range []string{"parameters", "parametersJsonSchema"} {
+					if schema := decl.Get(schemaPath); schema.Exists() {
+						addIfNotEmpty(&segments, schema.Raw)
+					}
+				}
+				return true
+			})
+		}
+		return true
+	})
+	if toolConfig := root.Get("toolConfig"); toolConfig.Exists() {
+		addIfNotEmpty(&segments, toolConfig.Raw)
+	}
+	return countSegments(enc, segments, media)
+}
+
+func collectGeminiParts(parts gjson.Result, segments *[]string) int64 {
+	var media int64
+	parts.ForEach(func(_, part gjson.Result) bool {
+		addIfNotEmpty(segments, part.Get("text").String())
+		for _, path := range []string{"functionCall", "function_call"} {
+			if call := part.Get(path); call.Exists() {
+				addIfNotEmpty(segments, call.Get("name").String())
+				addIfNotEmpty(segments, call.Get("args").Raw)
+			}
+		}
+		for _, path := range []string{"functionResponse", "function_response"} {
+			if response := part.Get(path); response.Exists() {
+				addIfNotEmpty(segments, response.Get("name").String())
+				addIfNotEmpty(segments, response.Get("response").Raw)
+			}
+		}
+		inline := part.Get("inlineData")
+		if !inline.Exists() {
+			inline = part.Get("inline_data")
+		}
+		if inline.Exists() {
+			mimeType := inline.Get("mimeType").String()
+			if mimeType == "" {
+				mimeType = inline.Get("mime_type").String()
+			}
+			switch {
+			case strings.HasPrefix(mimeType, "image/"):
+				media += geminiImageTokens(inline.Get("data").String())
+			case mimeType == "application/pdf":
+				media += int64(pdfPageCount(inline.Get("data").String())) * geminiTokensPerTile
+			default:
+				media += geminiTokensPerTile
+			}
+		}
+		if part.Get("fileData").Exists() || part.Get("file_data").Exists() {
+			media += geminiTokensPerTile
+		}
+		return true
+	})
+	return media
+}
+
+func countSegments(enc tokenizer.Codec, segments []string, extra int64) (int64, error) {
+	joined := strings.TrimSpace(strings.Join(segments, "\n"))
+	if joined == "" {
+		return extra, nil
+	}
+	count, err := enc.Count(joined)
+	if err != nil {
+		return 0, err
+	}
+	return int64(count) + extra, nil
+}
+
+// claudeImageTokens estimates the cost of a base64 image after Claude's downscaling.
+func claudeImageTokens(data string) int64 {
+	width, height := imageDimensions(data)
+	if width <= 0 || height <= 0 {
+		return claudeDefaultImageTokens
+	}
+	if longEdge := max(width, height); longEdge > claudeMaxImageEdge {
+		scale := float64(claudeMaxImageEdge) / float64(longEdge)
+		width = int(float64(width) * scale)
+		height = int(float64(height) * scale)
+	}
+	return min(int64(width*height)/750+1, claudeDefaultImageTokens)
+}
+
+// geminiImageTokens estimates the cost of a base64 image as a number of 768px tiles.
+func geminiImageTokens(data string) int64 {
+	width, height := imageDimensions(data)
+	if width <= geminiSmallImageEdge && height <= geminiSmallImageEdge {
+		return geminiTokensPerTile
+	}
+	tilesX := (width + geminiImageTileEdge - 1) / geminiImageTileEdge
+	tilesY := (height + geminiImageTileEdge - 1) / geminiImageTileEdge
+	return int64(tilesX*tilesY) * geminiTokensPerTile
+}
+
+// imageDimensions reads the size of a base64 PNG, JPEG or GIF image from its header.
+// Unknown or malformed images report zero.
+func imageDimensions(data string) (int, int) {
+	if idx := strings.Index(data, ","); idx >= 0 && strings.HasPrefix(data, "data:") {
+		data = data[idx+1:]
+	}
+	if len(data) > imageHeaderBase64Limit {
+		data = data[:imageHeaderBase64Limit]
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data[:len(data)/4*4])
+	if err != nil {
+		return 0, 0
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(decoded))
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
+
+var pdfPagePattern = regexp.MustCompile(`/Type\s*/Page[^s]`)
+
+// pdfPageCount counts the page objects of a base64 PDF.
+func pdfPageCount(data string) int {
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return defaultPDFPages
+	}
+	if pages := len(pdfPagePattern.FindAllIndex(decoded, -1)); pages > 0 {
+		return pages
+	}
+	return defaultPDFPages
+}
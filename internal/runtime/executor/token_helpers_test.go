@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+)
+
+func testPNGBase64(t *testing.T, width, height int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestCountClaudeTokens(t *testing.T) {
+	text := strings.Repeat("hello ", 50)
+	base := []byte(`{"messages":[{"role":"user","content":"` + text + `"}]}`)
+	baseCount, err := CountRequestTokens(sdktranslator.FormatClaude, "claude-sonnet-4-5", base)
+	if err != nil || baseCount < 50 {
+		t.Fatalf("base count = %d, %v", baseCount, err)
+	}
+
+	full := []byte(`{"system":[{"type":"text","text":"be brief"}],"tools":[{"name":"lookup","description":"find things","input_schema":{"type":"object","properties":{"q":{"type":"string"}}}}],"messages":[` +
+		`{"role":"user","content":[{"type":"text","text":"` + text + `"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + testPNGBase64(t, 1000, 750) + `"}}]},` +
+		`{"role":"assistant","content":[{"type":"thinking","thinking":"` + text + `","signature":"sig"},{"type":"tool_use","id":"t1","name":"lookup","input":{"q":"x"}}]},` +
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"found"}]}]}`)
+	fullCount, err := CountRequestTokens(sdktranslator.FormatClaude, "claude-sonnet-4-5", full)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	// 1000x750 pixels cost 1000 tokens; thinking doubles the text.
+	if fullCount < baseCount*2+1000 || fullCount > baseCount*2+1200 {
+		t.Fatalf("full count = %d, base %d", fullCount, baseCount)
+	}
+}
+
+func TestCountGeminiTokens(t *testing.T) {
+	picture := testPNGBase64(t, 1536, 800)
+	payload := []byte(`{"contents":[{"role":"user","parts":[{"text":"describe"},{"inlineData":{"mimeType":"image/png","data":"` + picture + `"}}]},` +
+		`{"role":"model","parts":[{"text":"hmm","thought":true},{"functionCall":{"name":"zoom","args":{"level":2}}}]}],` +
+		`"tools":[{"functionDeclarations":[{"name":"zoom","parameters":{"type":"object"}}]}]}`)
+	count, err := CountRequestTokens(sdktranslator.FormatGemini, "gemini-2.5-pro", payload)
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	// Two by two tiles of 258 tokens plus a little text.
+	if count < 4*258 || count > 4*258+60 {
+		t.Fatalf("count = %d", count)
+	}
+
+	wrapped := []byte(`{"generateContentRequest":` + string(payload) + `}`)
+	if wrappedCount, _ := CountRequestTokens(sdktranslator.FormatGemini, "gemini-2.5-pro", wrapped); wrappedCount != count {
+		t.Fatalf("generateContentRequest count = %d, want %d", wrappedCount, count)
+	}
+	cli := []byte(`{"model":"gemini-2.5-pro","request":` + string(payload) + `}`)
+	if cliCount, _ := CountRequestTokens(sdktranslator.FormatGeminiCLI, "gemini-2.5-pro", cli); cliCount != count {
+		t.Fatalf("gemini-cli count = %d, want %d", cliCount, count)
+	}
+}
+
+func TestCountOpenAIChatTokensSkipsInlineImages(t *testing.T) {
+	payload := []byte(`{"messages":[{"role":"user","content":[{"type":"text","text":"hi"},{"type":"image_url","image_url":{"url":"data:image/png;base64,` + strings.Repeat("QUFB", 5000) + `"}}]}]}`)
+	count, err := CountRequestTokens(sdktranslator.FormatOpenAI, "gpt-4o", payload)
+	if err != nil || count > 10 {
+		t.Fatalf("count = %d, %v", count, err)
+	}
+}
+
+func TestPDFPageCount(t *testing.T) {
+	pdf := "%PDF-1.4\n1 0 obj <</Type /Pages /Count 2>>\n2 0 obj <</Type /Page>>\n3 0 obj <</Type/Page /Parent 1 0 R>>\n"
+	if pages := pdfPageCount(base64.StdEncoding.EncodeToString([]byte(pdf))); pages != 2 {
+		t.Fatalf("pages = %d, want 2", pages)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	cliproxyauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -24,6 +25,8 @@ type usageReporter struct {
 	source      string
 	requestedAt time.Time
 	once        sync.Once
+	// estimate counts prompt tokens locally when the upstream reports no usage.
+	estimate func() int64
 }
 
 func newUsageReporter(ctx context.Context, provider, model string, auth *cliproxyauth.Auth) *usageReporter {
@@ -42,6 +45,22 @@ func newUsageReporter(ctx context.Context, provider, model string, auth *cliprox
 	return reporter
 }
 
+// estimateInputFrom lets ensurePublished report an offline prompt token count, taken from the
+// client request, for upstreams that omit usage. Counting is deferred until it is needed.
+func (r *usageReporter) estimateInputFrom(format sdktranslator.Format, payload []byte) {
+	if r == nil || len(payload) == 0 {
+		return
+	}
+	model := r.model
+	r.estimate = func() int64 {
+		count, err := CountRequestTokens(format, model, payload)
+		if err != nil {
+			return 0
+		}
+		return count
+	}
+}
+
 func (r *usageReporter) publish(ctx context.Context, detail usage.Detail) {
 	r.publishWithOutcome(ctx, detail, false)
 }
@@ -90,12 +109,18 @@ func (r *usageReporter) publishWithOutcome(ctx context.Context, detail usage.Det
 // ensurePublished guarantees that a usage record is emitted exactly once.
 // It is safe to call multiple times; only the first call wins due to once.Do.
 // This is used to ensure request counting even when upstream responses do not
-// include any usage fields (tokens), especially for streaming paths.
+// include any usage fields (tokens), especially for streaming paths. Such records carry the
+// prompt estimate registered with estimateInputFrom, if any.
 func (r *usageReporter) ensurePublished(ctx context.Context) {
 	if r == nil {
 		return
 	}
 	r.once.Do(func() {
+		var detail usage.Detail
+		if r.estimate != nil {
+			detail.InputTokens = r.estimate()
+			detail.TotalTokens = detail.InputTokens
+		}
 		usage.PublishRecord(ctx, usage.Record{
 			Provider:    r.provider,
 			Model:       r.model,
@@ -105,7 +130,7 @@ func (r *usageReporter) ensurePublished(ctx context.Context) {
 			AuthIndex:   r.authIndex,
 			RequestedAt: r.requestedAt,
 			Failed:      false,
-			Detail:      usage.Detail{},
+			Detail:      detail,
 		})
 	})
 }
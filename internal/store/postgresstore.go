@@ -20,18 +20,20 @@ import (
 )
 
 const (
-	defaultConfigTable = "config_store"
-	defaultAuthTable   = "auth_store"
-	defaultConfigKey   = "config"
+	defaultConfigTable    = "config_store"
+	defaultAuthTable      = "auth_store"
+	defaultSignatureTable = "signature_store"
+	defaultConfigKey      = "config"
 )
 
 // PostgresStoreConfig captures configuration required to initialize a Postgres-backed store.
 type PostgresStoreConfig struct {
-	DSN         string
-	Schema      string
-	ConfigTable string
-	AuthTable   string
-	SpoolDir    string
+	DSN            string
+	Schema         string
+	ConfigTable    string
+	AuthTable      string
+	SignatureTable string
+	SpoolDir       string
 }
 
 // PostgresStore persists configuration and authentication metadata using PostgreSQL as backend
@@ -58,6 +60,9 @@ func NewPostgresStore(ctx context.Context, cfg PostgresStoreConfig) (*PostgresSt
 	if cfg.AuthTable == "" {
 		cfg.AuthTable = defaultAuthTable
 	}
+	if cfg.SignatureTable == "" {
+		cfg.SignatureTable = defaultSignatureTable
+	}
 
 	spoolRoot := strings.TrimSpace(cfg.SpoolDir)
 	if spoolRoot == "" {
@@ -140,6 +145,16 @@ func (s *PostgresStore) EnsureSchema(ctx context.Context) error {
 	`, authTable)); err != nil {
 		return fmt.Errorf("postgres store: create auth table: %w", err)
 	}
+	signatureTable := s.fullTableName(s.cfg.SignatureTable)
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			signature TEXT NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`, signatureTable)); err != nil {
+		return fmt.Errorf("postgres store: create signature table: %w", err)
+	}
 	return nil
 }
 
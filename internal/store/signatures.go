@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/cache"
+)
+
+const objectStoreSignaturePrefix = "signatures"
+
+var (
+	_ cache.SignatureBackend = (*PostgresStore)(nil)
+	_ cache.SignaturePurger  = (*PostgresStore)(nil)
+	_ cache.SignatureBackend = (*ObjectTokenStore)(nil)
+	_ cache.SignaturePurger  = (*ObjectTokenStore)(nil)
+)
+
+func signatureID(group, hash string) string {
+	return url.PathEscape(group) + "/" + hash
+}
+
+// LoadSignature implements cache.SignatureBackend.
+func (s *PostgresStore) LoadSignature(ctx context.Context, group, hash string) (cache.SignatureEntry, bool, error) {
+	var entry cache.SignatureEntry
+	query := fmt.Sprintf("SELECT signature, updated_at FROM %s WHERE id = $1", s.fullTableName(s.cfg.SignatureTable))
+	err := s.db.QueryRowContext(ctx, query, signatureID(group, hash)).Scan(&entry.Signature, &entry.Timestamp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, fmt.Errorf("postgres store: load signature: %w", err)
+	}
+	return entry, true, nil
+}
+
+// SaveSignature implements cache.SignatureBackend.
+func (s *PostgresStore) SaveSignature(ctx context.Context, group, hash string, entry cache.SignatureEntry) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, signature, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id)
+		DO UPDATE SET signature = EXCLUDED.signature, updated_at = EXCLUDED.updated_at
+	`, s.fullTableName(s.cfg.SignatureTable))
+	if _, err := s.db.ExecContext(ctx, query, signatureID(group, hash), entry.Signature, entry.Timestamp); err != nil {
+		return fmt.Errorf("postgres store: upsert signature: %w", err)
+	}
+	return nil
+}
+
+// DeleteSignatures implements cache.SignatureBackend.
+func (s *PostgresStore) DeleteSignatures(ctx context.Context, group string) error {
+	table := s.fullTableName(s.cfg.SignatureTable)
+	var err error
+	if group == "" {
+		_, err = s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", table))
+	} else {
+		_, err = s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE starts_with(id, $1)", table), url.PathEscape(group)+"/")
+	}
+	if err != nil {
+		return fmt.Errorf("postgres store: delete signatures: %w", err)
+	}
+	return nil
+}
+
+// PurgeSignatures implements cache.SignaturePurger.
+func (s *PostgresStore) PurgeSignatures(ctx context.Context, before time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE updated_at < $1", s.fullTableName(s.cfg.SignatureTable))
+	if _, err := s.db.ExecContext(ctx, query, before); err != nil {
+		return fmt.Errorf("postgres store: purge signatures: %w", err)
+	}
+	return nil
+}
+
+// LoadSignature implements cache.SignatureBackend.
+func (s *ObjectTokenStore) LoadSignature(ctx context.Context, group, hash string) (cache.SignatureEntry, bool, error) {
+	var entry cache.SignatureEntry
+	key := s.prefixedKey(objectStoreSignaturePrefix + "/" + signatureID(group, hash) + ".json")
+	object, err := s.client.GetObject(ctx, s.cfg.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		if isObjectNotFound(err) {
+			return entry, false, nil
+		}
+		return entry, false, fmt.Errorf("object store: fetch signature: %w", err)
+	}
+	defer object.Close()
+	data, err := io.ReadAll(object)
+	if err != nil {
+		if isObjectNotFound(err) {
+			return entry, false, nil
+		}
+		return entry, false, fmt.Errorf("object store: read signature: %w", err)
+	}
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return entry, false, fmt.Errorf("object store: decode signature: %w", err)
+	}
+	return entry, entry.Signature != "", nil
+}
+
+// SaveSignature implements cache.SignatureBackend.
+func (s *ObjectTokenStore) SaveSignature(ctx context.Context, group, hash string, entry cache.SignatureEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("object store: encode signature: %w", err)
+	}
+	return s.putObject(ctx, objectStoreSignaturePrefix+"/"+signatureID(group, hash)+".json", data, "application/json")
+}
+
+// DeleteSignatures implements cache.SignatureBackend.
+func (s *ObjectTokenStore) DeleteSignatures(ctx context.Context, group string) error {
+	prefix := objectStoreSignaturePrefix + "/"
+	if group != "" {
+		prefix += url.PathEscape(group) + "/"
+	}
+	return s.removeSignatures(ctx, prefix, time.Time{})
+}
+
+// PurgeSignatures implements cache.SignaturePurger.
+func (s *ObjectTokenStore) PurgeSignatures(ctx context.Context, before time.Time) error {
+	return s.removeSignatures(ctx, objectStoreSignaturePrefix+"/", before)
+}
+
+// removeSignatures deletes the signature objects under prefix, limited to objects last
+// modified before the cutoff when it is set.
+func (s *ObjectTokenStore) removeSignatures(ctx context.Context, prefix string, before time.Time) error {
+	objectCh := s.client.ListObjects(ctx, s.cfg.Bucket, minio.ListObjectsOptions{
+		Prefix:    s.prefixedKey(prefix),
+		Recursive: true,
+	})
+	for object := range objectCh {
+		if object.Err != nil {
+			return fmt.Errorf("object store: list signatures: %w", object.Err)
+		}
+		if !before.IsZero() && !object.LastModified.Before(before) {
+			continue
+		}
+		if err := s.client.RemoveObject(ctx, s.cfg.Bucket, object.Key, minio.RemoveObjectOptions{}); err != nil && !isObjectNotFound(err) {
+			return fmt.Errorf("object store: delete signature %s: %w", object.Key, err)
+		}
+	}
+	return nil
+}
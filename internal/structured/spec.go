@@ -0,0 +1,206 @@
+// Package structured extracts structured-output (JSON schema) requests from the client formats
+// the proxy accepts, renders them for other formats and validates model output against them.
+package structured
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// DefaultName is used when a request does not name its schema.
+const DefaultName = "response"
+
+// Spec is a structured-output request independent of the client format.
+type Spec struct {
+	// Name identifies the schema; formats that require one get DefaultName.
+	Name string
+	// Schema is the raw JSON schema. It is empty for plain JSON mode (any JSON object).
+	Schema string
+	// Strict mirrors the OpenAI strict flag.
+	Strict bool
+}
+
+// FromOpenAIChat reads response_format from an OpenAI Chat Completions request.
+func FromOpenAIChat(root gjson.Result) (Spec, bool) {
+	format := root.Get("response_format")
+	switch format.Get("type").String() {
+	case "json_schema":
+		return newSpec(format.Get("json_schema.name").String(), format.Get("json_schema.schema"), format.Get("json_schema.strict").Bool())
+	case "json_object":
+		return Spec{Name: DefaultName}, true
+	}
+	return Spec{}, false
+}
+
+// FromResponses reads text.format from an OpenAI Responses request.
+func FromResponses(root gjson.Result) (Spec, bool) {
+	format := root.Get("text.format")
+	switch format.Get("type").String() {
+	case "json_schema":
+		return newSpec(format.Get("name").String(), format.Get("schema"), format.Get("strict").Bool())
+	case "json_object":
+		return Spec{Name: DefaultName}, true
+	}
+	return Spec{}, false
+}
+
+// FromClaude reads output_format, or its newer output_config.format spelling, from a Claude
+// Messages request.
+func FromClaude(root gjson.Result) (Spec, bool) {
+	for _, path := range []string{"output_config.format", "output_format"} {
+		if format := root.Get(path); format.Get("type").String() == "json_schema" {
+			return newSpec("", format.Get("schema"), false)
+		}
+	}
+	return Spec{}, false
+}
+
+// FromGemini reads the JSON response settings from a Gemini request's generationConfig.
+// Schemas given as responseSchema (the OpenAPI subset with upper-case types) are normalized
+// to JSON Schema.
+func FromGemini(root gjson.Result) (Spec, bool) {
+	config := root.Get("generationConfig")
+	if !config.Exists() {
+		config = root.Get("generation_config")
+	}
+	for _, path := range []string{"responseJsonSchema", "response_json_schema", "responseSchema", "response_schema"} {
+		if schema := config.Get(path); schema.IsObject() {
+			return newSpec("", gjson.Parse(normalizeOpenAPISchema(schema.Raw)), false)
+		}
+	}
+	mimeType := config.Get("responseMimeType")
+	if !mimeType.Exists() {
+		mimeType = config.Get("response_mime_type")
+	}
+	if strings.EqualFold(mimeType.String(), "application/json") {
+		return Spec{Name: DefaultName}, true
+	}
+	return Spec{}, false
+}
+
+// Parse reads the structured-output request of rawJSON in the given client format
+// ("openai", "openai-response", "claude", "gemini" or "gemini-cli").
+func Parse(format string, rawJSON []byte) (Spec, bool) {
+	root := gjson.ParseBytes(rawJSON)
+	switch format {
+	case "openai":
+		return FromOpenAIChat(root)
+	case "openai-response":
+		return FromResponses(root)
+	case "claude":
+		return FromClaude(root)
+	case "gemini":
+		return FromGemini(root)
+	case "gemini-cli":
+		return FromGemini(root.Get("request"))
+	}
+	return Spec{}, false
+}
+
+func newSpec(name string, schema gjson.Result, strict bool) (Spec, bool) {
+	if !schema.IsObject() {
+		return Spec{}, false
+	}
+	if strings.TrimSpace(name) == "" {
+		name = DefaultName
+	}
+	return Spec{Name: name, Schema: schema.Raw, Strict: strict}, true
+}
+
+// JSONOnly reports whether the request only asks for a JSON object without a schema.
+func (s Spec) JSONOnly() bool {
+	return s.Schema == ""
+}
+
+// OpenAIResponseFormat renders the spec as an OpenAI Chat Completions response_format value.
+func (s Spec) OpenAIResponseFormat() string {
+	if s.JSONOnly() {
+		return `{"type":"json_object"}`
+	}
+	out := `{"type":"json_schema","json_schema":{"name":""}}`
+	out, _ = sjson.Set(out, "json_schema.name", s.Name)
+	if s.Strict {
+		out, _ = sjson.Set(out, "json_schema.strict", true)
+	}
+	out, _ = sjson.SetRaw(out, "json_schema.schema", s.Schema)
+	return out
+}
+
+// ResponsesTextFormat renders the spec as an OpenAI Responses text.format value.
+func (s Spec) ResponsesTextFormat() string {
+	if s.JSONOnly() {
+		return `{"type":"json_object"}`
+	}
+	out := `{"type":"json_schema","name":""}`
+	out, _ = sjson.Set(out, "name", s.Name)
+	if s.Strict {
+		out, _ = sjson.Set(out, "strict", true)
+	}
+	out, _ = sjson.SetRaw(out, "schema", s.Schema)
+	return out
+}
+
+// ApplyGemini sets responseMimeType and responseJsonSchema on the generationConfig found at
+// configPath of a Gemini request.
+func (s Spec) ApplyGemini(out []byte, configPath string) []byte {
+	out, _ = sjson.SetBytes(out, configPath+".responseMimeType", "application/json")
+	if !s.JSONOnly() {
+		out, _ = sjson.SetRawBytes(out, configPath+".responseJsonSchema", []byte(s.Schema))
+	}
+	return out
+}
+
+// Instruction is the system prompt text used to ask a model without native structured output
+// for JSON matching the spec.
+func (s Spec) Instruction() string {
+	if s.JSONOnly() {
+		return "Respond only with a single valid JSON object. Do not wrap it in Markdown code fences or add any other text."
+	}
+	return fmt.Sprintf("Respond only with a single JSON value that conforms to the following JSON Schema. Do not wrap it in Markdown code fences or add any other text.\n\nJSON Schema:\n%s", s.Schema)
+}
+
+// normalizeOpenAPISchema lower-cases the "type" keywords of an OpenAPI-style Gemini schema and
+// turns nullable into a null type, which is what JSON Schema expects.
+func normalizeOpenAPISchema(raw string) string {
+	var walk func(node gjson.Result, path string)
+	out := raw
+	walk = func(node gjson.Result, path string) {
+		prefix := path
+		if prefix != "" {
+			prefix += "."
+		}
+		switch {
+		case node.IsObject():
+			if typ := node.Get("type"); typ.Type == gjson.String {
+				lower := strings.ToLower(typ.String())
+				if node.Get("nullable").Bool() {
+					out, _ = sjson.Set(out, prefix+"type", []string{lower, "null"})
+					out, _ = sjson.Delete(out, prefix+"nullable")
+				} else if lower != typ.String() {
+					out, _ = sjson.Set(out, prefix+"type", lower)
+				}
+			}
+			node.ForEach(func(key, value gjson.Result) bool {
+				if key.String() != "type" && (value.IsObject() || value.IsArray()) {
+					walk(value, prefix+escapePathKey(key.String()))
+				}
+				return true
+			})
+		case node.IsArray():
+			for i, item := range node.Array() {
+				walk(item, fmt.Sprintf("%s%d", prefix, i))
+			}
+		}
+	}
+	walk(gjson.Parse(raw), "")
+	return out
+}
+
+var pathKeyReplacer = strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`, "|", `\|`, "#", `\#`, "@", `\@`)
+
+func escapePathKey(key string) string {
+	return pathKeyReplacer.Replace(key)
+}
@@ -0,0 +1,90 @@
+package structured
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+const personSchema = `{"type":"object","properties":{"name":{"type":"string","minLength":1},"age":{"type":"integer","minimum":0},"tags":{"type":"array","items":{"$ref":"#/$defs/tag"},"maxItems":2}},"required":["name","age"],"additionalProperties":false,"$defs":{"tag":{"type":"string","enum":["a","b"]}}}`
+
+func TestSpecValidate(t *testing.T) {
+	spec := Spec{Name: "person", Schema: personSchema}
+	tests := []struct {
+		text   string
+		reason string
+	}{
+		{text: `{"name":"Ada","age":36,"tags":["a"]}`},
+		{text: `{"name":"Ada","age":36.5}`, reason: "expected integer"},
+		{text: `{"name":"Ada"}`, reason: `missing required property "age"`},
+		{text: `{"name":"","age":1}`, reason: "shorter than 1"},
+		{text: `{"name":"Ada","age":1,"extra":true}`, reason: `unexpected property "extra"`},
+		{text: `{"name":"Ada","age":1,"tags":["c"]}`, reason: "/tags/0"},
+		{text: `{"name":"Ada","age":1,"tags":["a","b","a"]}`, reason: "more than 2"},
+		{text: `{"name":"Ada","age":-1}`, reason: "minimum"},
+		{text: `Sure! {"name":"Ada"}`, reason: "not valid JSON"},
+	}
+	for _, tt := range tests {
+		err := spec.Validate(tt.text)
+		if tt.reason == "" {
+			if err != nil {
+				t.Errorf("Validate(%s) = %v, want nil", tt.text, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("Validate(%s) = %v, want error containing %q", tt.text, err, tt.reason)
+		}
+	}
+
+	if err := (Spec{}).Validate(`[1,2]`); err == nil {
+		t.Errorf("plain JSON mode must require an object")
+	}
+	anyOf := Spec{Schema: `{"anyOf":[{"type":"string"},{"type":"null"}]}`}
+	if err := anyOf.Validate(`null`); err != nil {
+		t.Errorf("anyOf null: %v", err)
+	}
+	if err := anyOf.Validate(`1`); err == nil {
+		t.Errorf("anyOf must reject a number")
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	spec, ok := Parse("openai", []byte(`{"response_format":{"type":"json_schema","json_schema":{"name":"person","strict":true,"schema":`+personSchema+`}}}`))
+	if !ok || spec.Name != "person" || !spec.Strict || spec.Schema != personSchema {
+		t.Fatalf("unexpected openai spec: %+v", spec)
+	}
+	if got := gjson.Parse(spec.ResponsesTextFormat()); got.Get("name").String() != "person" || got.Get("schema.required.0").String() != "name" {
+		t.Fatalf("unexpected text.format: %s", got.Raw)
+	}
+
+	spec, ok = Parse("claude", []byte(`{"output_format":{"type":"json_schema","schema":{"type":"object"}}}`))
+	if !ok || spec.Name != DefaultName || spec.Schema != `{"type":"object"}` {
+		t.Fatalf("unexpected claude spec: %+v", spec)
+	}
+
+	spec, ok = Parse("gemini-cli", []byte(`{"request":{"generationConfig":{"responseMimeType":"application/json","responseSchema":{"type":"OBJECT","properties":{"n":{"type":"INTEGER","nullable":true}}}}}}`))
+	if !ok || gjson.Get(spec.Schema, "type").String() != "object" || gjson.Get(spec.Schema, "properties.n.type").Raw != `["integer","null"]` {
+		t.Fatalf("unexpected gemini spec: %+v", spec)
+	}
+
+	if spec, ok = Parse("openai-response", []byte(`{"text":{"format":{"type":"json_object"}}}`)); !ok || !spec.JSONOnly() {
+		t.Fatalf("unexpected json_object spec: %+v", spec)
+	}
+	if _, ok = Parse("openai", []byte(`{"response_format":{"type":"text"}}`)); ok {
+		t.Fatalf("text response_format is not structured output")
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	for in, want := range map[string]string{
+		"```json\n{\"a\":1}\n```": `{"a":1}`,
+		"```\n{\"a\":1}```":       `{"a":1}`,
+		`{"a":1}`:                 `{"a":1}`,
+	} {
+		if got := StripCodeFence(in); got != want {
+			t.Errorf("StripCodeFence(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
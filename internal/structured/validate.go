@@ -0,0 +1,397 @@
+package structured
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// ValidationError describes why a model response does not satisfy a Spec.
+type ValidationError struct {
+	// Path is a JSON pointer to the offending value; empty for the document itself.
+	Path   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// Validate checks that text is a JSON document satisfying the spec. Plain JSON mode only
+// requires a JSON object. Schemas are checked against the JSON Schema keywords used for
+// structured output: type, enum, const, properties, required, additionalProperties, items,
+// prefixItems, anyOf, oneOf, allOf, $ref into $defs or definitions, and the numeric, string
+// and array bounds. Unknown keywords are ignored.
+func (s Spec) Validate(text string) error {
+	text = strings.TrimSpace(text)
+	if !gjson.Valid(text) {
+		return &ValidationError{Reason: "response is not valid JSON"}
+	}
+	value := gjson.Parse(text)
+	if s.JSONOnly() {
+		if !value.IsObject() {
+			return &ValidationError{Reason: "response is not a JSON object"}
+		}
+		return nil
+	}
+	root := gjson.Parse(s.Schema)
+	return (&validator{root: root}).validate(root, value, "", 0)
+}
+
+// StripCodeFence removes a Markdown code fence wrapped around a JSON answer, which models
+// asked for JSON through the prompt often add.
+func StripCodeFence(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") || len(trimmed) < 6 {
+		return text
+	}
+	inner := trimmed[3 : len(trimmed)-3]
+	if idx := strings.IndexByte(inner, '\n'); idx >= 0 && !strings.ContainsAny(inner[:idx], "{[\"") {
+		inner = inner[idx+1:]
+	}
+	return strings.TrimSpace(inner)
+}
+
+// maxRefDepth bounds $ref resolution so recursive schemas cannot loop forever.
+const maxRefDepth = 64
+
+type validator struct {
+	root gjson.Result
+}
+
+func (v *validator) validate(schema, value gjson.Result, path string, depth int) error {
+	if depth > maxRefDepth {
+		return &ValidationError{Path: path, Reason: "schema nesting too deep"}
+	}
+	if schema.Type == gjson.True || !schema.Exists() {
+		return nil
+	}
+	if schema.Type == gjson.False {
+		return &ValidationError{Path: path, Reason: "no value is allowed here"}
+	}
+	if !schema.IsObject() {
+		return nil
+	}
+
+	if ref := schema.Get("$ref"); ref.Exists() {
+		resolved, ok := v.resolve(ref.String())
+		if !ok {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("unresolvable $ref %q", ref.String())}
+		}
+		if err := v.validate(resolved, value, path, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if typ := schema.Get("type"); typ.Exists() {
+		allowed := typ.Array()
+		if !typ.IsArray() {
+			allowed = []gjson.Result{typ}
+		}
+		if schema.Get("nullable").Bool() {
+			allowed = append(allowed, gjson.Parse(`"null"`))
+		}
+		matched := false
+		names := make([]string, 0, len(allowed))
+		for _, t := range allowed {
+			name := strings.ToLower(t.String())
+			names = append(names, name)
+			if matchesType(name, value) {
+				matched = true
+			}
+		}
+		if !matched {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("expected %s, got %s", strings.Join(names, " or "), typeName(value))}
+		}
+	}
+
+	if enum := schema.Get("enum"); enum.IsArray() {
+		found := false
+		for _, candidate := range enum.Array() {
+			if jsonEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("value %s is not one of %s", value.Raw, enum.Raw)}
+		}
+	}
+	if constant := schema.Get("const"); constant.Exists() && !jsonEqual(constant, value) {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("value %s does not equal %s", value.Raw, constant.Raw)}
+	}
+
+	if err := v.validateCombinators(schema, value, path, depth); err != nil {
+		return err
+	}
+
+	switch {
+	case value.IsObject():
+		return v.validateObject(schema, value, path, depth)
+	case value.IsArray():
+		return v.validateArray(schema, value, path, depth)
+	case value.Type == gjson.String:
+		return validateString(schema, value, path)
+	case value.Type == gjson.Number:
+		return validateNumber(schema, value, path)
+	}
+	return nil
+}
+
+func (v *validator) validateCombinators(schema, value gjson.Result, path string, depth int) error {
+	for _, sub := range schema.Get("allOf").Array() {
+		if err := v.validate(sub, value, path, depth+1); err != nil {
+			return err
+		}
+	}
+	if anyOf := schema.Get("anyOf"); anyOf.IsArray() {
+		var firstErr error
+		matched := false
+		for _, sub := range anyOf.Array() {
+			err := v.validate(sub, value, path, depth+1)
+			if err == nil {
+				matched = true
+				break
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if !matched {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("value matches none of anyOf (%v)", firstErr)}
+		}
+	}
+	if oneOf := schema.Get("oneOf"); oneOf.IsArray() {
+		matches := 0
+		for _, sub := range oneOf.Array() {
+			if v.validate(sub, value, path, depth+1) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("value matches %d of oneOf, want exactly 1", matches)}
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateObject(schema, value gjson.Result, path string, depth int) error {
+	properties := schema.Get("properties")
+	for _, name := range schema.Get("required").Array() {
+		if !value.Get(escapePathKey(name.String())).Exists() {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("missing required property %q", name.String())}
+		}
+	}
+	count := 0
+	var err error
+	value.ForEach(func(key, item gjson.Result) bool {
+		count++
+		childPath := path + "/" + escapePointer(key.String())
+		if propSchema := properties.Get(escapePathKey(key.String())); propSchema.Exists() {
+			err = v.validate(propSchema, item, childPath, depth+1)
+			return err == nil
+		}
+		additional := schema.Get("additionalProperties")
+		if additional.Type == gjson.False {
+			err = &ValidationError{Path: path, Reason: fmt.Sprintf("unexpected property %q", key.String())}
+			return false
+		}
+		if additional.IsObject() {
+			err = v.validate(additional, item, childPath, depth+1)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if minProps := schema.Get("minProperties"); minProps.Exists() && int64(count) < minProps.Int() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("object has %d properties, fewer than %d", count, minProps.Int())}
+	}
+	if maxProps := schema.Get("maxProperties"); maxProps.Exists() && int64(count) > maxProps.Int() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("object has %d properties, more than %d", count, maxProps.Int())}
+	}
+	return nil
+}
+
+func (v *validator) validateArray(schema, value gjson.Result, path string, depth int) error {
+	items := value.Array()
+	if minItems := schema.Get("minItems"); minItems.Exists() && int64(len(items)) < minItems.Int() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("array has %d items, fewer than %d", len(items), minItems.Int())}
+	}
+	if maxItems := schema.Get("maxItems"); maxItems.Exists() && int64(len(items)) > maxItems.Int() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("array has %d items, more than %d", len(items), maxItems.Int())}
+	}
+	prefix := schema.Get("prefixItems").Array()
+	itemSchema := schema.Get("items")
+	for i, item := range items {
+		childPath := fmt.Sprintf("%s/%d", path, i)
+		var err error
+		switch {
+		case i < len(prefix):
+			err = v.validate(prefix[i], item, childPath, depth+1)
+		case itemSchema.IsArray():
+			// Draft-07 tuple form.
+			if tuple := itemSchema.Array(); i < len(tuple) {
+				err = v.validate(tuple[i], item, childPath, depth+1)
+			}
+		default:
+			err = v.validate(itemSchema, item, childPath, depth+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if schema.Get("uniqueItems").Bool() {
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if jsonEqual(items[i], items[j]) {
+					return &ValidationError{Path: path, Reason: "array items are not unique"}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateString(schema, value gjson.Result, path string) error {
+	length := int64(utf8.RuneCountInString(value.String()))
+	if minLength := schema.Get("minLength"); minLength.Exists() && length < minLength.Int() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("string is shorter than %d characters", minLength.Int())}
+	}
+	if maxLength := schema.Get("maxLength"); maxLength.Exists() && length > maxLength.Int() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("string is longer than %d characters", maxLength.Int())}
+	}
+	if pattern := schema.Get("pattern"); pattern.Type == gjson.String {
+		// Patterns RE2 cannot compile (e.g. lookaheads) are not enforced.
+		if re, errCompile := regexp.Compile(pattern.String()); errCompile == nil && !re.MatchString(value.String()) {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("string does not match pattern %q", pattern.String())}
+		}
+	}
+	return nil
+}
+
+func validateNumber(schema, value gjson.Result, path string) error {
+	n := value.Float()
+	if minimum := schema.Get("minimum"); minimum.Exists() && n < minimum.Float() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("%s is less than the minimum %s", value.Raw, minimum.Raw)}
+	}
+	if maximum := schema.Get("maximum"); maximum.Exists() && n > maximum.Float() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("%s is greater than the maximum %s", value.Raw, maximum.Raw)}
+	}
+	if exclusive := schema.Get("exclusiveMinimum"); exclusive.Type == gjson.Number && n <= exclusive.Float() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("%s is not greater than %s", value.Raw, exclusive.Raw)}
+	}
+	if exclusive := schema.Get("exclusiveMaximum"); exclusive.Type == gjson.Number && n >= exclusive.Float() {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("%s is not less than %s", value.Raw, exclusive.Raw)}
+	}
+	if multiple := schema.Get("multipleOf"); multiple.Type == gjson.Number && multiple.Float() > 0 {
+		if q := n / multiple.Float(); math.Abs(q-math.Round(q)) > 1e-9 {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("%s is not a multiple of %s", value.Raw, multiple.Raw)}
+		}
+	}
+	return nil
+}
+
+// resolve follows a local JSON pointer reference such as "#/$defs/item".
+func (v *validator) resolve(ref string) (gjson.Result, bool) {
+	if ref == "#" {
+		return v.root, true
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return gjson.Result{}, false
+	}
+	node := v.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		node = node.Get(escapePathKey(token))
+		if !node.Exists() {
+			return gjson.Result{}, false
+		}
+	}
+	return node, true
+}
+
+func matchesType(name string, value gjson.Result) bool {
+	switch name {
+	case "object":
+		return value.IsObject()
+	case "array":
+		return value.IsArray()
+	case "string":
+		return value.Type == gjson.String
+	case "number":
+		return value.Type == gjson.Number
+	case "integer":
+		return value.Type == gjson.Number && value.Float() == math.Trunc(value.Float())
+	case "boolean":
+		return value.Type == gjson.True || value.Type == gjson.False
+	case "null":
+		return value.Type == gjson.Null
+	}
+	return true
+}
+
+func typeName(value gjson.Result) string {
+	switch {
+	case value.IsObject():
+		return "object"
+	case value.IsArray():
+		return "array"
+	}
+	switch value.Type {
+	case gjson.String:
+		return "string"
+	case gjson.Number:
+		return "number"
+	case gjson.True, gjson.False:
+		return "boolean"
+	}
+	return "null"
+}
+
+// jsonEqual compares two JSON values structurally.
+func jsonEqual(a, b gjson.Result) bool {
+	switch {
+	case a.IsObject() && b.IsObject():
+		am, bm := a.Map(), b.Map()
+		if len(am) != len(bm) {
+			return false
+		}
+		for key, av := range am {
+			bv, ok := bm[key]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	case a.IsArray() && b.IsArray():
+		aa, ba := a.Array(), b.Array()
+		if len(aa) != len(ba) {
+			return false
+		}
+		for i := range aa {
+			if !jsonEqual(aa[i], ba[i]) {
+				return false
+			}
+		}
+		return true
+	case a.Type != b.Type:
+		return false
+	case a.Type == gjson.Number:
+		return a.Float() == b.Float()
+	case a.Type == gjson.String:
+		return a.String() == b.String()
+	}
+	return a.IsObject() == b.IsObject() && a.IsArray() == b.IsArray()
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
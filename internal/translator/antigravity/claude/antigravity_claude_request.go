@@ -254,6 +254,10 @@ func ConvertClaudeRequestToAntigravity(modelName string, inputRawJSON []byte, _
 							partJSON, _ = sjson.SetRaw(partJSON, "inlineData", inlineDataJSON)
 							clientContentJSON, _ = sjson.SetRaw(clientContentJSON, "parts.-1", partJSON)
 						}
+					} else if contentTypeResult.Type == gjson.String && contentTypeResult.String() == "document" {
+						if doc, ok := util.DocumentFromClaudeBlock(contentResult); ok {
+							clientContentJSON, _ = sjson.SetRaw(clientContentJSON, "parts.-1", doc.GeminiPart())
+						}
 					}
 				}
 
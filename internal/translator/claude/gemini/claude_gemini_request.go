@@ -262,6 +262,12 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 						return true
 					}
 
+					// Document content (PDF and other non-media inline or http(s) file data)
+					if doc, ok := util.DocumentFromGeminiPart(part); ok && (doc.URI == "" || doc.HasHTTPURI()) {
+						msg, _ = sjson.SetRaw(msg, "content.-1", doc.ClaudeBlock())
+						return true
+					}
+
 					// Image content (inline_data) conversion to Claude Code format
 					if inlineData := part.Get("inline_data"); inlineData.Exists() {
 						imageContent := `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
@@ -343,15 +349,31 @@ func ConvertGeminiRequestToClaude(modelName string, inputRawJSON []byte, stream
 	}
 
 	// Tool config mapping from Gemini format to Claude Code format
-	if toolConfig := root.Get("tool_config"); toolConfig.Exists() {
-		if funcCalling := toolConfig.Get("function_calling_config"); funcCalling.Exists() {
-			if mode := funcCalling.Get("mode"); mode.Exists() {
-				switch mode.String() {
-				case "AUTO":
-					out, _ = sjson.SetRaw(out, "tool_choice", `{"type":"auto"}`)
-				case "NONE":
-					out, _ = sjson.SetRaw(out, "tool_choice", `{"type":"none"}`)
-				case "ANY":
+	toolConfig := root.Get("tool_config")
+	if !toolConfig.Exists() {
+		toolConfig = root.Get("toolConfig")
+	}
+	if toolConfig.Exists() {
+		funcCalling := toolConfig.Get("function_calling_config")
+		if !funcCalling.Exists() {
+			funcCalling = toolConfig.Get("functionCallingConfig")
+		}
+		if mode := funcCalling.Get("mode"); mode.Exists() {
+			switch mode.String() {
+			case "AUTO":
+				out, _ = sjson.SetRaw(out, "tool_choice", `{"type":"auto"}`)
+			case "NONE":
+				out, _ = sjson.SetRaw(out, "tool_choice", `{"type":"none"}`)
+			case "ANY":
+				allowed := funcCalling.Get("allowedFunctionNames").Array()
+				if len(allowed) == 0 {
+					allowed = funcCalling.Get("allowed_function_names").Array()
+				}
+				if len(allowed) == 1 {
+					choice := `{"type":"tool","name":""}`
+					choice, _ = sjson.Set(choice, "name", allowed[0].String())
+					out, _ = sjson.SetRaw(out, "tool_choice", choice)
+				} else {
 					out, _ = sjson.SetRaw(out, "tool_choice", `{"type":"any"}`)
 				}
 			}
@@ -10,7 +10,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -103,6 +105,12 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 				hasContent = true
 			}
 
+			appendRawContent := func(part string) {
+				message, _ = sjson.SetRaw(message, fmt.Sprintf("content.%d", contentIndex), part)
+				contentIndex++
+				hasContent = true
+			}
+
 			messageContentsResult := messageResult.Get("content")
 			if messageContentsResult.IsArray() {
 				messageContentResults := messageContentsResult.Array()
@@ -132,6 +140,10 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 								appendImageContent(dataURL)
 							}
 						}
+					case "document":
+						if doc, ok := util.DocumentFromClaudeBlock(messageContentResult); ok && messageRole == "user" {
+							appendRawContent(doc.ResponsesInputFile())
+						}
 					case "tool_use":
 						flushMessage()
 						functionCallMessage := `{"type":"function_call"}`
@@ -238,6 +250,11 @@ func ConvertClaudeRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 	template, _ = sjson.Set(template, "store", false)
 	template, _ = sjson.Set(template, "include", []string{"reasoning.encrypted_content"})
 
+	// Map output_format -> text.format
+	if spec, ok := structured.FromClaude(rootResult); ok {
+		template, _ = sjson.SetRaw(template, "text.format", spec.ResponsesTextFormat())
+	}
+
 	return []byte(template)
 }
 
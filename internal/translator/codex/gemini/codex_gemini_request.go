@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
@@ -137,6 +138,14 @@ func ConvertGeminiRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 					continue
 				}
 
+				// document part (PDF and other non-media inline or http(s) file data)
+				if doc, ok := util.DocumentFromGeminiPart(p); ok && role == "user" && (doc.URI == "" || doc.HasHTTPURI()) {
+					msg := `{"type":"message","role":"user","content":[]}`
+					msg, _ = sjson.SetRaw(msg, "content.-1", doc.ResponsesInputFile())
+					out, _ = sjson.SetRaw(out, "input.-1", msg)
+					continue
+				}
+
 				// function call from model
 				if fc := p.Get("functionCall"); fc.Exists() {
 					fn := `{"type":"function_call"}`
@@ -279,6 +288,11 @@ func ConvertGeminiRequestToCodex(modelName string, inputRawJSON []byte, _ bool)
 	out, _ = sjson.Set(out, "store", false)
 	out, _ = sjson.Set(out, "include", []string{"reasoning.encrypted_content"})
 
+	// Map responseMimeType/responseJsonSchema -> text.format
+	if spec, ok := structured.FromGemini(root); ok {
+		out, _ = sjson.SetRaw(out, "text.format", spec.ResponsesTextFormat())
+	}
+
 	var pathsToLower []string
 	toolsResult := gjson.Get(out, "tools")
 	util.Walk(toolsResult, "", "type", &pathsToLower)
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -180,7 +181,12 @@ func ConvertOpenAIRequestToCodex(modelName string, inputRawJSON []byte, stream b
 								msg, _ = sjson.SetRaw(msg, "content.-1", part)
 							}
 						case "file":
-							// Files are not specified in examples; skip for now
+							// Map inline files to input_file for Responses API
+							if role == "user" {
+								if doc, ok := util.DocumentFromOpenAIFilePart(it); ok {
+									msg, _ = sjson.SetRaw(msg, "content.-1", doc.ResponsesInputFile())
+								}
+							}
 						}
 					}
 				}
@@ -9,7 +9,9 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -129,6 +131,11 @@ func ConvertClaudeRequestToCLI(modelName string, inputRawJSON []byte, _ bool) []
 								contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", part)
 							}
 						}
+
+					case "document":
+						if doc, ok := util.DocumentFromClaudeBlock(contentResult); ok {
+							contentJSON, _ = sjson.SetRaw(contentJSON, "parts.-1", doc.GeminiPart())
+						}
 					}
 					return true
 				})
@@ -196,6 +203,9 @@ func ConvertClaudeRequestToCLI(modelName string, inputRawJSON []byte, _ bool) []
 	if v := gjson.GetBytes(rawJSON, "top_k"); v.Exists() && v.Type == gjson.Number {
 		out, _ = sjson.Set(out, "request.generationConfig.topK", v.Num)
 	}
+	if spec, ok := structured.FromClaude(gjson.ParseBytes(rawJSON)); ok {
+		out = string(spec.ApplyGemini([]byte(out), "request.generationConfig"))
+	}
 
 	outBytes := []byte(out)
 	outBytes = common.AttachDefaultSafetySettings(outBytes, "request.safetySettings")
@@ -251,7 +251,11 @@ func ConvertGeminiCLIResponseToClaude(_ context.Context, _ string, originalReque
 				// Include thinking tokens in output token count if present
 				thoughtsTokenCount := usageResult.Get("thoughtsTokenCount").Int()
 				template, _ = sjson.Set(template, "usage.output_tokens", candidatesTokenCountResult.Int()+thoughtsTokenCount)
-				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int())
+				cachedTokenCount := usageResult.Get("cachedContentTokenCount").Int()
+				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int()-cachedTokenCount)
+				if cachedTokenCount > 0 {
+					template, _ = sjson.Set(template, "usage.cache_read_input_tokens", cachedTokenCount)
+				}
 
 				output = output + template + "\n\n\n"
 			}
@@ -281,10 +285,14 @@ func ConvertGeminiCLIResponseToClaudeNonStream(_ context.Context, _ string, orig
 	out, _ = sjson.Set(out, "id", root.Get("response.responseId").String())
 	out, _ = sjson.Set(out, "model", root.Get("response.modelVersion").String())
 
-	inputTokens := root.Get("response.usageMetadata.promptTokenCount").Int()
+	cachedTokens := root.Get("response.usageMetadata.cachedContentTokenCount").Int()
+	inputTokens := root.Get("response.usageMetadata.promptTokenCount").Int() - cachedTokens
 	outputTokens := root.Get("response.usageMetadata.candidatesTokenCount").Int() + root.Get("response.usageMetadata.thoughtsTokenCount").Int()
 	out, _ = sjson.Set(out, "usage.input_tokens", inputTokens)
 	out, _ = sjson.Set(out, "usage.output_tokens", outputTokens)
+	if cachedTokens > 0 {
+		out, _ = sjson.Set(out, "usage.cache_read_input_tokens", cachedTokens)
+	}
 
 	parts := root.Get("response.candidates.0.content.parts")
 	textBuilder := strings.Builder{}
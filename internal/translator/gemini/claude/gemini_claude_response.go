@@ -257,7 +257,11 @@ func ConvertGeminiResponseToClaude(_ context.Context, _ string, originalRequestR
 
 				thoughtsTokenCount := usageResult.Get("thoughtsTokenCount").Int()
 				template, _ = sjson.Set(template, "usage.output_tokens", candidatesTokenCountResult.Int()+thoughtsTokenCount)
-				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int())
+				cachedTokenCount := usageResult.Get("cachedContentTokenCount").Int()
+				template, _ = sjson.Set(template, "usage.input_tokens", usageResult.Get("promptTokenCount").Int()-cachedTokenCount)
+				if cachedTokenCount > 0 {
+					template, _ = sjson.Set(template, "usage.cache_read_input_tokens", cachedTokenCount)
+				}
 
 				output = output + template + "\n\n\n"
 			}
@@ -287,10 +291,14 @@ func ConvertGeminiResponseToClaudeNonStream(_ context.Context, _ string, origina
 	out, _ = sjson.Set(out, "id", root.Get("responseId").String())
 	out, _ = sjson.Set(out, "model", root.Get("modelVersion").String())
 
-	inputTokens := root.Get("usageMetadata.promptTokenCount").Int()
+	cachedTokens := root.Get("usageMetadata.cachedContentTokenCount").Int()
+	inputTokens := root.Get("usageMetadata.promptTokenCount").Int() - cachedTokens
 	outputTokens := root.Get("usageMetadata.candidatesTokenCount").Int() + root.Get("usageMetadata.thoughtsTokenCount").Int()
 	out, _ = sjson.Set(out, "usage.input_tokens", inputTokens)
 	out, _ = sjson.Set(out, "usage.output_tokens", outputTokens)
+	if cachedTokens > 0 {
+		out, _ = sjson.Set(out, "usage.cache_read_input_tokens", cachedTokens)
+	}
 
 	parts := root.Get("candidates.0.content.parts")
 	textBuilder := strings.Builder{}
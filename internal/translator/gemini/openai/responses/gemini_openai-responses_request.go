@@ -3,7 +3,9 @@ package responses
 import (
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/translator/gemini/common"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -237,6 +239,10 @@ func ConvertOpenAIResponsesRequestToGemini(modelName string, inputRawJSON []byte
 									partJSON, _ = sjson.Set(partJSON, "inline_data.data", data)
 								}
 							}
+						case "input_file":
+							if doc, ok := util.DocumentFromResponsesInputFile(contentItem); ok {
+								partJSON = doc.GeminiPart()
+							}
 						}
 
 						if partJSON != "" {
@@ -419,6 +425,11 @@ func ConvertOpenAIResponsesRequestToGemini(modelName string, inputRawJSON []byte
 		out, _ = sjson.Set(out, "generationConfig.stopSequences", sequences)
 	}
 
+	// Map text.format -> generationConfig.responseMimeType/responseJsonSchema
+	if spec, ok := structured.FromResponses(root); ok {
+		out = string(spec.ApplyGemini([]byte(out), "generationConfig"))
+	}
+
 	// Apply thinking configuration: convert OpenAI Responses API reasoning.effort to Gemini thinkingConfig.
 	// Inline translation-only mapping; capability checks happen later in ApplyThinking.
 	re := root.Get("reasoning.effort")
@@ -8,7 +8,9 @@ package claude
 import (
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -38,6 +40,11 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 		out, _ = sjson.Set(out, "top_p", topP.Float())
 	}
 
+	// Output format -> response_format
+	if spec, ok := structured.FromClaude(root); ok {
+		out, _ = sjson.SetRaw(out, "response_format", spec.OpenAIResponseFormat())
+	}
+
 	// Stop sequences -> stop
 	if stopSequences := root.Get("stop_sequences"); stopSequences.Exists() {
 		if stopSequences.IsArray() {
@@ -149,7 +156,7 @@ func ConvertClaudeRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 					case "redacted_thinking":
 						// Explicitly ignore redacted_thinking - never map to reasoning_content (AC2)
 
-					case "text", "image":
+					case "text", "image", "document":
 						if contentItem, ok := convertClaudeContentPart(part); ok {
 							contentItems = append(contentItems, contentItem)
 						}
@@ -361,6 +368,13 @@ func convertClaudeContentPart(part gjson.Result) (string, bool) {
 
 		return imageContent, true
 
+	case "document":
+		doc, ok := util.DocumentFromClaudeBlock(part)
+		if !ok {
+			return "", false
+		}
+		return doc.OpenAIFilePart(), true
+
 	default:
 		return "", false
 	}
@@ -11,7 +11,9 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -41,6 +43,11 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 	// Model mapping
 	out, _ = sjson.Set(out, "model", modelName)
 
+	// JSON response settings -> response_format
+	if spec, ok := structured.FromGemini(root); ok {
+		out, _ = sjson.SetRaw(out, "response_format", spec.OpenAIResponseFormat())
+	}
+
 	// Generation config mapping
 	if genConfig := root.Get("generationConfig"); genConfig.Exists() {
 		// Temperature
@@ -190,6 +197,14 @@ func ConvertGeminiRequestToOpenAI(modelName string, inputRawJSON []byte, stream
 						contentPartsCount++
 					}
 
+					// Handle documents (e.g., PDFs) carried as inline or file data
+					if doc, ok := util.DocumentFromGeminiPart(part); ok {
+						onlyTextContent = false
+						contentWrapper, _ = sjson.SetRaw(contentWrapper, "arr.-1", doc.OpenAIFilePart())
+						contentPartsCount++
+						return true
+					}
+
 					// Handle inline data (e.g., images)
 					if inlineData := part.Get("inlineData"); inlineData.Exists() {
 						onlyTextContent = false
@@ -3,6 +3,8 @@ package responses
 import (
 	"strings"
 
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 )
@@ -44,6 +46,11 @@ func ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName string, inpu
 		out, _ = sjson.Set(out, "max_tokens", maxTokens.Int())
 	}
 
+	// Map text.format to response_format
+	if spec, ok := structured.FromResponses(root); ok {
+		out, _ = sjson.SetRaw(out, "response_format", spec.OpenAIResponseFormat())
+	}
+
 	if parallelToolCalls := root.Get("parallel_tool_calls"); parallelToolCalls.Exists() {
 		out, _ = sjson.Set(out, "parallel_tool_calls", parallelToolCalls.Bool())
 	}
@@ -94,6 +101,10 @@ func ConvertOpenAIResponsesRequestToOpenAIChatCompletions(modelName string, inpu
 							contentPart := `{"type":"image_url","image_url":{"url":""}}`
 							contentPart, _ = sjson.Set(contentPart, "image_url.url", imageURL)
 							message, _ = sjson.SetRaw(message, "content.-1", contentPart)
+						case "input_file":
+							if doc, ok := util.DocumentFromResponsesInputFile(contentItem); ok {
+								message, _ = sjson.SetRaw(message, "content.-1", doc.OpenAIFilePart())
+							}
 						}
 						return true
 					})
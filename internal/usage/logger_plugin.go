@@ -64,6 +64,8 @@ type RequestStatistics struct {
 	successCount  int64
 	failureCount  int64
 	totalTokens   int64
+	// coalescedCount counts requests served by an identical in-flight request.
+	coalescedCount int64
 
 	apis map[string]*apiStats
 
@@ -94,6 +96,7 @@ type RequestDetail struct {
 	AuthIndex string     `json:"auth_index"`
 	Tokens    TokenStats `json:"tokens"`
 	Failed    bool       `json:"failed"`
+	Coalesced bool       `json:"coalesced,omitempty"`
 }
 
 // TokenStats captures the token usage breakdown for a request.
@@ -111,6 +114,8 @@ type StatisticsSnapshot struct {
 	SuccessCount  int64 `json:"success_count"`
 	FailureCount  int64 `json:"failure_count"`
 	TotalTokens   int64 `json:"total_tokens"`
+	// CoalescedCount is how many requests were answered by an identical in-flight request.
+	CoalescedCount int64 `json:"coalesced_count"`
 
 	APIs map[string]APISnapshot `json:"apis"`
 
@@ -189,6 +194,9 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 	} else {
 		s.failureCount++
 	}
+	if record.Coalesced {
+		s.coalescedCount++
+	}
 	s.totalTokens += totalTokens
 
 	stats, ok := s.apis[statsKey]
@@ -202,6 +210,7 @@ func (s *RequestStatistics) Record(ctx context.Context, record coreusage.Record)
 		AuthIndex: record.AuthIndex,
 		Tokens:    detail,
 		Failed:    failed,
+		Coalesced: record.Coalesced,
 	})
 
 	s.requestsByDay[dayKey]++
@@ -237,6 +246,7 @@ func (s *RequestStatistics) Snapshot() StatisticsSnapshot {
 	result.SuccessCount = s.successCount
 	result.FailureCount = s.failureCount
 	result.TotalTokens = s.totalTokens
+	result.CoalescedCount = s.coalescedCount
 
 	result.APIs = make(map[string]APISnapshot, len(s.apis))
 	for apiName, stats := range s.apis {
@@ -362,6 +372,9 @@ func (s *RequestStatistics) recordImported(apiName, modelName string, stats *api
 	} else {
 		s.successCount++
 	}
+	if detail.Coalesced {
+		s.coalescedCount++
+	}
 	s.totalTokens += totalTokens
 
 	s.updateAPIStats(stats, modelName, detail)
@@ -0,0 +1,284 @@
+package util
+
+import (
+	"path"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/misc"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Document is a file attachment (typically a PDF) normalized across the OpenAI, Claude and
+// Gemini request formats so that request translators can convert it between them.
+// Exactly one of Data, URI or Text carries the content.
+type Document struct {
+	// MimeType is the media type of the document, e.g. "application/pdf".
+	MimeType string
+	// Data is the base64-encoded document content.
+	Data string
+	// URI references the document remotely (an http(s) URL or a provider file URI).
+	URI string
+	// Text is the document content for plain-text documents.
+	Text string
+	// Filename is the original file name, when known.
+	Filename string
+}
+
+// ParseDataURL splits a base64 data: URL into its media type and payload.
+func ParseDataURL(value string) (mimeType, data string, ok bool) {
+	if !strings.HasPrefix(value, "data:") {
+		return "", "", false
+	}
+	header, payload, found := strings.Cut(strings.TrimPrefix(value, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return "", "", false
+	}
+	mimeType = strings.TrimSuffix(header, ";base64")
+	if idx := strings.Index(mimeType, ";"); idx >= 0 {
+		mimeType = mimeType[:idx]
+	}
+	return mimeType, payload, payload != ""
+}
+
+// MimeTypeFromFilename guesses a media type from a file name or URL extension.
+func MimeTypeFromFilename(name string) string {
+	if idx := strings.IndexAny(name, "?#"); idx >= 0 {
+		name = name[:idx]
+	}
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	if ext == "" {
+		return ""
+	}
+	return misc.MimeTypes[ext]
+}
+
+// IsDocumentMimeType reports whether a media type is carried as a document rather than as
+// an image, audio or video part.
+func IsDocumentMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if mimeType == "" {
+		return false
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/"} {
+		if strings.HasPrefix(mimeType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// newDocument fills in the media type of a document from its file name or URI.
+func newDocument(doc Document) (Document, bool) {
+	if doc.Data == "" && doc.URI == "" && doc.Text == "" {
+		return Document{}, false
+	}
+	if doc.MimeType == "" || doc.MimeType == "application/octet-stream" {
+		if guessed := MimeTypeFromFilename(doc.Filename); guessed != "" {
+			doc.MimeType = guessed
+		} else if guessed = MimeTypeFromFilename(doc.URI); guessed != "" {
+			doc.MimeType = guessed
+		}
+	}
+	if doc.MimeType == "" {
+		if doc.Text != "" {
+			doc.MimeType = "text/plain"
+		} else {
+			doc.MimeType = "application/pdf"
+		}
+	}
+	return doc, true
+}
+
+// fileDataDocument builds a document from an OpenAI file_data value, which is a data URL
+// or, for older clients, bare base64 content.
+func fileDataDocument(fileData, fileURL, filename string) (Document, bool) {
+	doc := Document{Filename: filename, URI: fileURL}
+	if fileData != "" {
+		if mimeType, data, ok := ParseDataURL(fileData); ok {
+			doc.MimeType, doc.Data = mimeType, data
+		} else if !strings.HasPrefix(fileData, "data:") {
+			doc.Data = fileData
+		}
+		doc.URI = ""
+	}
+	return newDocument(doc)
+}
+
+// DocumentFromOpenAIFilePart reads an OpenAI Chat Completions "file" content part.
+// Parts that only reference an uploaded file_id cannot be converted and are not returned.
+func DocumentFromOpenAIFilePart(part gjson.Result) (Document, bool) {
+	file := part.Get("file")
+	return fileDataDocument(file.Get("file_data").String(), "", file.Get("filename").String())
+}
+
+// DocumentFromResponsesInputFile reads an OpenAI Responses "input_file" content part.
+func DocumentFromResponsesInputFile(part gjson.Result) (Document, bool) {
+	return fileDataDocument(part.Get("file_data").String(), part.Get("file_url").String(), part.Get("filename").String())
+}
+
+// DocumentFromClaudeBlock reads a Claude "document" content block with a base64, url or
+// text source.
+func DocumentFromClaudeBlock(block gjson.Result) (Document, bool) {
+	source := block.Get("source")
+	doc := Document{Filename: block.Get("title").String(), MimeType: source.Get("media_type").String()}
+	switch source.Get("type").String() {
+	case "base64":
+		doc.Data = source.Get("data").String()
+	case "url":
+		doc.URI = source.Get("url").String()
+	case "text":
+		doc.Text = source.Get("data").String()
+	default:
+		return Document{}, false
+	}
+	return newDocument(doc)
+}
+
+// DocumentFromGeminiPart reads a Gemini inlineData or fileData part whose media type is a
+// document. Image, audio and video parts are not returned.
+func DocumentFromGeminiPart(part gjson.Result) (Document, bool) {
+	if inline := firstExisting(part, "inlineData", "inline_data"); inline.Exists() {
+		mimeType := firstExisting(inline, "mimeType", "mime_type").String()
+		if !IsDocumentMimeType(mimeType) {
+			return Document{}, false
+		}
+		return newDocument(Document{MimeType: mimeType, Data: inline.Get("data").String()})
+	}
+	if file := firstExisting(part, "fileData", "file_data"); file.Exists() {
+		mimeType := firstExisting(file, "mimeType", "mime_type").String()
+		if !IsDocumentMimeType(mimeType) {
+			return Document{}, false
+		}
+		return newDocument(Document{MimeType: mimeType, URI: firstExisting(file, "fileUri", "file_uri").String()})
+	}
+	return Document{}, false
+}
+
+func firstExisting(value gjson.Result, paths ...string) gjson.Result {
+	for _, p := range paths {
+		if result := value.Get(p); result.Exists() {
+			return result
+		}
+	}
+	return gjson.Result{}
+}
+
+// HasHTTPURI reports whether the document references an http(s) URL, which unlike
+// provider file URIs can be fetched by any upstream.
+func (d Document) HasHTTPURI() bool {
+	uri := strings.ToLower(d.URI)
+	return strings.HasPrefix(uri, "https://") || strings.HasPrefix(uri, "http://")
+}
+
+// DataURL returns the document as a base64 data: URL, or "" when it has no inline data.
+func (d Document) DataURL() string {
+	if d.Data == "" {
+		return ""
+	}
+	return "data:" + d.MimeType + ";base64," + d.Data
+}
+
+// ClaudeBlock renders the document as a Claude "document" content block, or as an "image"
+// block when an attached file turns out to be an image.
+func (d Document) ClaudeBlock() string {
+	block := `{"type":"document","source":{}}`
+	switch {
+	case d.Data != "" && strings.HasPrefix(d.MimeType, "image/"):
+		block = `{"type":"image","source":{"type":"base64","media_type":"","data":""}}`
+		block, _ = sjson.Set(block, "source.media_type", d.MimeType)
+		block, _ = sjson.Set(block, "source.data", d.Data)
+		return block
+	case d.Data != "":
+		block, _ = sjson.Set(block, "source.type", "base64")
+		block, _ = sjson.Set(block, "source.media_type", d.MimeType)
+		block, _ = sjson.Set(block, "source.data", d.Data)
+	case d.Text != "":
+		block, _ = sjson.Set(block, "source.type", "text")
+		block, _ = sjson.Set(block, "source.media_type", "text/plain")
+		block, _ = sjson.Set(block, "source.data", d.Text)
+	default:
+		block, _ = sjson.Set(block, "source.type", "url")
+		block, _ = sjson.Set(block, "source.url", d.URI)
+	}
+	if d.Filename != "" {
+		block, _ = sjson.Set(block, "title", d.Filename)
+	}
+	return block
+}
+
+// GeminiPart renders the document as a Gemini part: inlineData for inline content,
+// fileData for URIs and a text part for plain-text documents.
+func (d Document) GeminiPart() string {
+	switch {
+	case d.Data != "":
+		part := `{"inlineData":{"mime_type":"","data":""}}`
+		part, _ = sjson.Set(part, "inlineData.mime_type", d.MimeType)
+		part, _ = sjson.Set(part, "inlineData.data", d.Data)
+		return part
+	case d.Text != "":
+		part := `{"text":""}`
+		part, _ = sjson.Set(part, "text", d.Text)
+		return part
+	default:
+		part := `{"fileData":{"mimeType":"","fileUri":""}}`
+		part, _ = sjson.Set(part, "fileData.mimeType", d.MimeType)
+		part, _ = sjson.Set(part, "fileData.fileUri", d.URI)
+		return part
+	}
+}
+
+// OpenAIFilePart renders the document as an OpenAI Chat Completions content part. Chat
+// Completions only accepts inline files, so URI documents become a text reference.
+func (d Document) OpenAIFilePart() string {
+	switch {
+	case d.Data != "":
+		part := `{"type":"file","file":{"filename":"","file_data":""}}`
+		part, _ = sjson.Set(part, "file.filename", d.filenameOrDefault())
+		part, _ = sjson.Set(part, "file.file_data", d.DataURL())
+		return part
+	case d.Text != "":
+		part := `{"type":"text","text":""}`
+		part, _ = sjson.Set(part, "text", d.Text)
+		return part
+	default:
+		part := `{"type":"text","text":""}`
+		part, _ = sjson.Set(part, "text", "[document: "+d.URI+"]")
+		return part
+	}
+}
+
+// ResponsesInputFile renders the document as an OpenAI Responses content part.
+func (d Document) ResponsesInputFile() string {
+	switch {
+	case d.Data != "":
+		part := `{"type":"input_file","filename":"","file_data":""}`
+		part, _ = sjson.Set(part, "filename", d.filenameOrDefault())
+		part, _ = sjson.Set(part, "file_data", d.DataURL())
+		return part
+	case d.Text != "":
+		part := `{"type":"input_text","text":""}`
+		part, _ = sjson.Set(part, "text", d.Text)
+		return part
+	default:
+		part := `{"type":"input_file","file_url":""}`
+		part, _ = sjson.Set(part, "file_url", d.URI)
+		return part
+	}
+}
+
+// filenameOrDefault returns the file name, deriving one from the media type when missing;
+// OpenAI requires a file name for inline files.
+func (d Document) filenameOrDefault() string {
+	if d.Filename != "" {
+		return d.Filename
+	}
+	switch d.MimeType {
+	case "application/pdf":
+		return "document.pdf"
+	case "text/plain":
+		return "document.txt"
+	default:
+		return "document"
+	}
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestParseDataURL(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantMime string
+		wantData string
+		wantOK   bool
+	}{
+		{in: "data:application/pdf;base64,JVBE", wantMime: "application/pdf", wantData: "JVBE", wantOK: true},
+		{in: "data:text/plain;charset=utf-8;base64,aGk=", wantMime: "text/plain", wantData: "aGk=", wantOK: true},
+		{in: "data:text/plain,hello", wantOK: false},
+		{in: "https://example.com/a.pdf", wantOK: false},
+	}
+	for _, tt := range tests {
+		mimeType, data, ok := ParseDataURL(tt.in)
+		if ok != tt.wantOK || mimeType != tt.wantMime || data != tt.wantData {
+			t.Errorf("ParseDataURL(%q) = (%q, %q, %v), want (%q, %q, %v)", tt.in, mimeType, data, ok, tt.wantMime, tt.wantData, tt.wantOK)
+		}
+	}
+}
+
+func TestDocumentFromParts(t *testing.T) {
+	if _, ok := DocumentFromGeminiPart(gjson.Parse(`{"inlineData":{"mimeType":"image/png","data":"AAAA"}}`)); ok {
+		t.Fatalf("image parts must not be treated as documents")
+	}
+
+	doc, ok := DocumentFromGeminiPart(gjson.Parse(`{"file_data":{"mime_type":"application/pdf","file_uri":"https://example.com/a.pdf"}}`))
+	if !ok || doc.URI != "https://example.com/a.pdf" || !doc.HasHTTPURI() {
+		t.Fatalf("unexpected document from file_data: %+v", doc)
+	}
+	if got := gjson.Parse(doc.ClaudeBlock()); got.Get("source.type").String() != "url" || got.Get("source.url").String() != doc.URI {
+		t.Fatalf("unexpected claude block: %s", got.Raw)
+	}
+
+	// Bare base64 without a media type is identified by its file name.
+	doc, ok = DocumentFromOpenAIFilePart(gjson.Parse(`{"type":"file","file":{"filename":"notes.txt","file_data":"aGk="}}`))
+	if !ok || doc.MimeType != "text/plain" || doc.Data != "aGk=" {
+		t.Fatalf("unexpected document from file part: %+v", doc)
+	}
+
+	if _, ok = DocumentFromOpenAIFilePart(gjson.Parse(`{"type":"file","file":{"file_id":"file-123"}}`)); ok {
+		t.Fatalf("file_id references cannot be converted")
+	}
+
+	doc, ok = DocumentFromResponsesInputFile(gjson.Parse(`{"type":"input_file","file_data":"data:application/pdf;base64,JVBE"}`))
+	if !ok {
+		t.Fatalf("expected a document from input_file")
+	}
+	if got := gjson.Parse(doc.OpenAIFilePart()); got.Get("file.filename").String() != "document.pdf" || got.Get("file.file_data").String() != "data:application/pdf;base64,JVBE" {
+		t.Fatalf("unexpected openai file part: %s", got.Raw)
+	}
+}
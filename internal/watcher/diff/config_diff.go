@@ -57,6 +57,24 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.MaxRetryInterval != newCfg.MaxRetryInterval {
 		changes = append(changes, fmt.Sprintf("max-retry-interval: %d -> %d", oldCfg.MaxRetryInterval, newCfg.MaxRetryInterval))
 	}
+	if !reflect.DeepEqual(oldCfg.RetryPolicies, newCfg.RetryPolicies) {
+		changes = append(changes, fmt.Sprintf("retry-policy: updated (%d -> %d policies)", len(oldCfg.RetryPolicies), len(newCfg.RetryPolicies)))
+	}
+	if oldCfg.CircuitBreaker != newCfg.CircuitBreaker {
+		changes = append(changes, "circuit-breaker: updated")
+	}
+	if !reflect.DeepEqual(oldCfg.Concurrency, newCfg.Concurrency) {
+		changes = append(changes, "concurrency: updated")
+	}
+	if !reflect.DeepEqual(oldCfg.Pacing, newCfg.Pacing) {
+		changes = append(changes, fmt.Sprintf("pacing: updated (%d -> %d rules)", len(oldCfg.Pacing.Rules), len(newCfg.Pacing.Rules)))
+	}
+	if !reflect.DeepEqual(oldCfg.Priority, newCfg.Priority) {
+		changes = append(changes, fmt.Sprintf("priority-classes: updated (%d -> %d classes)", len(oldCfg.Priority.Classes), len(newCfg.Priority.Classes)))
+	}
+	if !reflect.DeepEqual(oldCfg.Hedging, newCfg.Hedging) {
+		changes = append(changes, fmt.Sprintf("hedging: updated (%d -> %d rules)", len(oldCfg.Hedging), len(newCfg.Hedging)))
+	}
 	if oldCfg.ProxyURL != newCfg.ProxyURL {
 		changes = append(changes, fmt.Sprintf("proxy-url: %s -> %s", formatProxyURL(oldCfg.ProxyURL), formatProxyURL(newCfg.ProxyURL)))
 	}
@@ -69,6 +87,33 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 	if oldCfg.NonStreamKeepAliveInterval != newCfg.NonStreamKeepAliveInterval {
 		changes = append(changes, fmt.Sprintf("nonstream-keepalive-interval: %d -> %d", oldCfg.NonStreamKeepAliveInterval, newCfg.NonStreamKeepAliveInterval))
 	}
+	if oldCfg.StructuredOutput.Disable != newCfg.StructuredOutput.Disable {
+		changes = append(changes, fmt.Sprintf("structured-output.disable: %t -> %t", oldCfg.StructuredOutput.Disable, newCfg.StructuredOutput.Disable))
+	}
+	if oldCfg.StructuredOutput.Strategy != newCfg.StructuredOutput.Strategy {
+		changes = append(changes, fmt.Sprintf("structured-output.strategy: %s -> %s", oldCfg.StructuredOutput.Strategy, newCfg.StructuredOutput.Strategy))
+	}
+	if oldCfg.StructuredOutput.RepairRetries != newCfg.StructuredOutput.RepairRetries {
+		changes = append(changes, fmt.Sprintf("structured-output.repair-retries: %d -> %d", oldCfg.StructuredOutput.RepairRetries, newCfg.StructuredOutput.RepairRetries))
+	}
+	if !reflect.DeepEqual(oldCfg.ContextPolicies, newCfg.ContextPolicies) {
+		changes = append(changes, fmt.Sprintf("context-policies: updated (%d -> %d entries)", len(oldCfg.ContextPolicies), len(newCfg.ContextPolicies)))
+	}
+	if oldCfg.TokenCounting.Mode != newCfg.TokenCounting.Mode {
+		changes = append(changes, fmt.Sprintf("token-counting.mode: %s -> %s", oldCfg.TokenCounting.Mode, newCfg.TokenCounting.Mode))
+	}
+	if oldCfg.ReasoningOutput.Mode != newCfg.ReasoningOutput.Mode {
+		changes = append(changes, fmt.Sprintf("reasoning-output.mode: %s -> %s", oldCfg.ReasoningOutput.Mode, newCfg.ReasoningOutput.Mode))
+	}
+	if !equalStringMap(oldCfg.ReasoningOutput.APIKeys, newCfg.ReasoningOutput.APIKeys) {
+		changes = append(changes, fmt.Sprintf("reasoning-output.api-keys: updated (%d -> %d entries)", len(oldCfg.ReasoningOutput.APIKeys), len(newCfg.ReasoningOutput.APIKeys)))
+	}
+	if oldCfg.Coalescing.Enabled != newCfg.Coalescing.Enabled {
+		changes = append(changes, fmt.Sprintf("request-coalescing.enabled: %t -> %t", oldCfg.Coalescing.Enabled, newCfg.Coalescing.Enabled))
+	}
+	if !reflect.DeepEqual(oldCfg.Coalescing.Models, newCfg.Coalescing.Models) {
+		changes = append(changes, "request-coalescing.models: updated")
+	}
 
 	// Quota-exceeded behavior
 	if oldCfg.QuotaExceeded.SwitchProject != newCfg.QuotaExceeded.SwitchProject {
@@ -168,6 +213,96 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		}
 	}
 
+	// Bedrock credentials (do not print key material)
+	if len(oldCfg.BedrockKey) != len(newCfg.BedrockKey) {
+		changes = append(changes, fmt.Sprintf("bedrock count: %d -> %d", len(oldCfg.BedrockKey), len(newCfg.BedrockKey)))
+	} else {
+		for i := range oldCfg.BedrockKey {
+			o := oldCfg.BedrockKey[i]
+			n := newCfg.BedrockKey[i]
+			if strings.TrimSpace(o.Region) != strings.TrimSpace(n.Region) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].region: %s -> %s", i, strings.TrimSpace(o.Region), strings.TrimSpace(n.Region)))
+			}
+			if strings.TrimSpace(o.BaseURL) != strings.TrimSpace(n.BaseURL) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].base-url: %s -> %s", i, strings.TrimSpace(o.BaseURL), strings.TrimSpace(n.BaseURL)))
+			}
+			if strings.TrimSpace(o.ProxyURL) != strings.TrimSpace(n.ProxyURL) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].proxy-url: %s -> %s", i, formatProxyURL(o.ProxyURL), formatProxyURL(n.ProxyURL)))
+			}
+			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
+			}
+			if strings.TrimSpace(o.AccessKeyID) != strings.TrimSpace(n.AccessKeyID) ||
+				strings.TrimSpace(o.SecretAccessKey) != strings.TrimSpace(n.SecretAccessKey) ||
+				strings.TrimSpace(o.SessionToken) != strings.TrimSpace(n.SessionToken) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].credentials: updated", i))
+			}
+			if strings.TrimSpace(o.Profile) != strings.TrimSpace(n.Profile) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].profile: %s -> %s", i, strings.TrimSpace(o.Profile), strings.TrimSpace(n.Profile)))
+			}
+			if strings.TrimSpace(o.CredentialsFile) != strings.TrimSpace(n.CredentialsFile) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].credentials-file: %s -> %s", i, strings.TrimSpace(o.CredentialsFile), strings.TrimSpace(n.CredentialsFile)))
+			}
+			if o.Priority != n.Priority {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].priority: %d -> %d", i, o.Priority, n.Priority))
+			}
+			if !equalStringMap(o.Headers, n.Headers) {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].headers: updated", i))
+			}
+			oldModels := SummarizeClaudeModels(o.Models)
+			newModels := SummarizeClaudeModels(n.Models)
+			if oldModels.hash != newModels.hash {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].models: updated (%d -> %d entries)", i, oldModels.count, newModels.count))
+			}
+			oldExcluded := SummarizeExcludedModels(o.ExcludedModels)
+			newExcluded := SummarizeExcludedModels(n.ExcludedModels)
+			if oldExcluded.hash != newExcluded.hash {
+				changes = append(changes, fmt.Sprintf("bedrock[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
+			}
+		}
+	}
+
+	// Azure OpenAI keys (do not print key material)
+	if len(oldCfg.AzureOpenAIKey) != len(newCfg.AzureOpenAIKey) {
+		changes = append(changes, fmt.Sprintf("azure-openai count: %d -> %d", len(oldCfg.AzureOpenAIKey), len(newCfg.AzureOpenAIKey)))
+	} else {
+		for i := range oldCfg.AzureOpenAIKey {
+			o := oldCfg.AzureOpenAIKey[i]
+			n := newCfg.AzureOpenAIKey[i]
+			if strings.TrimSpace(o.BaseURL) != strings.TrimSpace(n.BaseURL) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].base-url: %s -> %s", i, strings.TrimSpace(o.BaseURL), strings.TrimSpace(n.BaseURL)))
+			}
+			if strings.TrimSpace(o.APIVersion) != strings.TrimSpace(n.APIVersion) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].api-version: %s -> %s", i, strings.TrimSpace(o.APIVersion), strings.TrimSpace(n.APIVersion)))
+			}
+			if strings.TrimSpace(o.ProxyURL) != strings.TrimSpace(n.ProxyURL) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].proxy-url: %s -> %s", i, formatProxyURL(o.ProxyURL), formatProxyURL(n.ProxyURL)))
+			}
+			if strings.TrimSpace(o.Prefix) != strings.TrimSpace(n.Prefix) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].prefix: %s -> %s", i, strings.TrimSpace(o.Prefix), strings.TrimSpace(n.Prefix)))
+			}
+			if strings.TrimSpace(o.APIKey) != strings.TrimSpace(n.APIKey) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].api-key: updated", i))
+			}
+			if o.Priority != n.Priority {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].priority: %d -> %d", i, o.Priority, n.Priority))
+			}
+			if !equalStringMap(o.Headers, n.Headers) {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].headers: updated", i))
+			}
+			oldModels := SummarizeAzureOpenAIModels(o.Models)
+			newModels := SummarizeAzureOpenAIModels(n.Models)
+			if oldModels.hash != newModels.hash {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].models: updated (%d -> %d entries)", i, oldModels.count, newModels.count))
+			}
+			oldExcluded := SummarizeExcludedModels(o.ExcludedModels)
+			newExcluded := SummarizeExcludedModels(n.ExcludedModels)
+			if oldExcluded.hash != newExcluded.hash {
+				changes = append(changes, fmt.Sprintf("azure-openai[%d].excluded-models: updated (%d -> %d entries)", i, oldExcluded.count, newExcluded.count))
+			}
+		}
+	}
+
 	// Codex keys (do not print key material)
 	if len(oldCfg.CodexKey) != len(newCfg.CodexKey) {
 		changes = append(changes, fmt.Sprintf("codex-api-key count: %d -> %d", len(oldCfg.CodexKey), len(newCfg.CodexKey)))
@@ -246,6 +381,73 @@ func BuildConfigChangeDetails(oldCfg, newCfg *config.Config) []string {
 		changes = append(changes, entries...)
 	}
 
+	// External model definitions
+	if oldCfg.ModelDefinitions.Path != newCfg.ModelDefinitions.Path {
+		changes = append(changes, fmt.Sprintf("model-definitions.path: %s -> %s", oldCfg.ModelDefinitions.Path, newCfg.ModelDefinitions.Path))
+	}
+	if oldCfg.ModelDefinitions.URL != newCfg.ModelDefinitions.URL {
+		changes = append(changes, "model-definitions.url: updated")
+	}
+	if oldCfg.ModelDefinitions.RefreshInterval != newCfg.ModelDefinitions.RefreshInterval {
+		changes = append(changes, fmt.Sprintf("model-definitions.refresh-interval: %d -> %d", oldCfg.ModelDefinitions.RefreshInterval, newCfg.ModelDefinitions.RefreshInterval))
+	}
+
+	// Remote media downloads
+	if oldCfg.RemoteMedia.Disable != newCfg.RemoteMedia.Disable {
+		changes = append(changes, fmt.Sprintf("remote-media.disable: %t -> %t", oldCfg.RemoteMedia.Disable, newCfg.RemoteMedia.Disable))
+	}
+	if oldCfg.RemoteMedia.MaxBytes != newCfg.RemoteMedia.MaxBytes {
+		changes = append(changes, fmt.Sprintf("remote-media.max-bytes: %d -> %d", oldCfg.RemoteMedia.MaxBytes, newCfg.RemoteMedia.MaxBytes))
+	}
+	if oldCfg.RemoteMedia.Timeout != newCfg.RemoteMedia.Timeout {
+		changes = append(changes, fmt.Sprintf("remote-media.timeout: %d -> %d", oldCfg.RemoteMedia.Timeout, newCfg.RemoteMedia.Timeout))
+	}
+	if oldCfg.RemoteMedia.CacheTTL != newCfg.RemoteMedia.CacheTTL {
+		changes = append(changes, fmt.Sprintf("remote-media.cache-ttl: %d -> %d", oldCfg.RemoteMedia.CacheTTL, newCfg.RemoteMedia.CacheTTL))
+	}
+	if !equalStringSet(oldCfg.RemoteMedia.AllowedHosts, newCfg.RemoteMedia.AllowedHosts) {
+		changes = append(changes, fmt.Sprintf("remote-media.allowed-hosts: %v -> %v", oldCfg.RemoteMedia.AllowedHosts, newCfg.RemoteMedia.AllowedHosts))
+	}
+	if !equalStringSet(oldCfg.RemoteMedia.BlockedHosts, newCfg.RemoteMedia.BlockedHosts) {
+		changes = append(changes, fmt.Sprintf("remote-media.blocked-hosts: %v -> %v", oldCfg.RemoteMedia.BlockedHosts, newCfg.RemoteMedia.BlockedHosts))
+	}
+	if oldCfg.RemoteMedia.AllowPrivateNetworks != newCfg.RemoteMedia.AllowPrivateNetworks {
+		changes = append(changes, fmt.Sprintf("remote-media.allow-private-networks: %t -> %t", oldCfg.RemoteMedia.AllowPrivateNetworks, newCfg.RemoteMedia.AllowPrivateNetworks))
+	}
+
+	// Gemini context cache
+	if oldCfg.ContextCache.Disable != newCfg.ContextCache.Disable {
+		changes = append(changes, fmt.Sprintf("context-cache.disable: %t -> %t", oldCfg.ContextCache.Disable, newCfg.ContextCache.Disable))
+	}
+	if oldCfg.ContextCache.TTL != newCfg.ContextCache.TTL {
+		changes = append(changes, fmt.Sprintf("context-cache.ttl: %d -> %d", oldCfg.ContextCache.TTL, newCfg.ContextCache.TTL))
+	}
+	if oldCfg.ContextCache.MinTokens != newCfg.ContextCache.MinTokens {
+		changes = append(changes, fmt.Sprintf("context-cache.min-tokens: %d -> %d", oldCfg.ContextCache.MinTokens, newCfg.ContextCache.MinTokens))
+	}
+	if oldCfg.ContextCache.AutoTokens != newCfg.ContextCache.AutoTokens {
+		changes = append(changes, fmt.Sprintf("context-cache.auto-tokens: %d -> %d", oldCfg.ContextCache.AutoTokens, newCfg.ContextCache.AutoTokens))
+	}
+	if !reflect.DeepEqual(oldCfg.Payload, newCfg.Payload) {
+		oldPayload, newPayload := oldCfg.Payload, newCfg.Payload
+		changes = append(changes, fmt.Sprintf("payload: updated (default %d -> %d, default-raw %d -> %d, override %d -> %d, override-raw %d -> %d, filter %d -> %d, operations %d -> %d)",
+			len(oldPayload.Default), len(newPayload.Default), len(oldPayload.DefaultRaw), len(newPayload.DefaultRaw),
+			len(oldPayload.Override), len(newPayload.Override), len(oldPayload.OverrideRaw), len(newPayload.OverrideRaw),
+			len(oldPayload.Filter), len(newPayload.Filter), len(oldPayload.Operations), len(newPayload.Operations)))
+	}
+	if !reflect.DeepEqual(oldCfg.ResponsePayload, newCfg.ResponsePayload) {
+		changes = append(changes, fmt.Sprintf("response-payload: updated (%d -> %d rules)", len(oldCfg.ResponsePayload), len(newCfg.ResponsePayload)))
+	}
+	if oldCfg.SignatureCache.Backend != newCfg.SignatureCache.Backend {
+		changes = append(changes, fmt.Sprintf("signature-cache.backend: %s -> %s", oldCfg.SignatureCache.Backend, newCfg.SignatureCache.Backend))
+	}
+	if oldCfg.SignatureCache.Path != newCfg.SignatureCache.Path {
+		changes = append(changes, fmt.Sprintf("signature-cache.path: %s -> %s", oldCfg.SignatureCache.Path, newCfg.SignatureCache.Path))
+	}
+	if oldCfg.SignatureCache.MaxEntries != newCfg.SignatureCache.MaxEntries {
+		changes = append(changes, fmt.Sprintf("signature-cache.max-entries: %d -> %d", oldCfg.SignatureCache.MaxEntries, newCfg.SignatureCache.MaxEntries))
+	}
+
 	// Remote management (never print the key)
 	if oldCfg.RemoteManagement.AllowRemote != newCfg.RemoteManagement.AllowRemote {
 		changes = append(changes, fmt.Sprintf("remote-management.allow-remote: %t -> %t", oldCfg.RemoteManagement.AllowRemote, newCfg.RemoteManagement.AllowRemote))
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
@@ -20,7 +21,7 @@ func ComputeOpenAICompatModelsHash(models []config.OpenAICompatibilityModel) str
 			if name == "" && alias == "" {
 				continue
 			}
-			out(strings.ToLower(name) + "|" + strings.ToLower(alias))
+			out(strings.ToLower(name) + "|" + strings.ToLower(alias) + capabilitiesKey(model.Capabilities))
 		}
 	})
 	return hashJoined(keys)
@@ -35,7 +36,7 @@ func ComputeVertexCompatModelsHash(models []config.VertexCompatModel) string {
 			if name == "" && alias == "" {
 				continue
 			}
-			out(strings.ToLower(name) + "|" + strings.ToLower(alias))
+			out(strings.ToLower(name) + "|" + strings.ToLower(alias) + capabilitiesKey(model.Capabilities))
 		}
 	})
 	return hashJoined(keys)
@@ -50,7 +51,7 @@ func ComputeClaudeModelsHash(models []config.ClaudeModel) string {
 			if name == "" && alias == "" {
 				continue
 			}
-			out(strings.ToLower(name) + "|" + strings.ToLower(alias))
+			out(strings.ToLower(name) + "|" + strings.ToLower(alias) + capabilitiesKey(model.Capabilities))
 		}
 	})
 	return hashJoined(keys)
@@ -65,7 +66,22 @@ func ComputeCodexModelsHash(models []config.CodexModel) string {
 			if name == "" && alias == "" {
 				continue
 			}
-			out(strings.ToLower(name) + "|" + strings.ToLower(alias))
+			out(strings.ToLower(name) + "|" + strings.ToLower(alias) + capabilitiesKey(model.Capabilities))
+		}
+	})
+	return hashJoined(keys)
+}
+
+// ComputeAzureOpenAIModelsHash returns a stable hash for Azure OpenAI deployment mappings.
+func ComputeAzureOpenAIModelsHash(models []config.AzureOpenAIModel) string {
+	keys := normalizeModelPairs(func(out func(key string)) {
+		for _, model := range models {
+			name := strings.TrimSpace(model.Name)
+			alias := strings.TrimSpace(model.Alias)
+			if name == "" && alias == "" {
+				continue
+			}
+			out(strings.ToLower(name) + "|" + strings.ToLower(alias) + capabilitiesKey(model.Capabilities))
 		}
 	})
 	return hashJoined(keys)
@@ -80,12 +96,27 @@ func ComputeGeminiModelsHash(models []config.GeminiModel) string {
 			if name == "" && alias == "" {
 				continue
 			}
-			out(strings.ToLower(name) + "|" + strings.ToLower(alias))
+			out(strings.ToLower(name) + "|" + strings.ToLower(alias) + capabilitiesKey(model.Capabilities))
 		}
 	})
 	return hashJoined(keys)
 }
 
+// ComputeModelDiscoveryHash returns a stable hash for an openai-compatibility provider's
+// model discovery settings, or "" when discovery is disabled.
+func ComputeModelDiscoveryHash(compat *config.OpenAICompatibility) string {
+	if compat == nil || !compat.DiscoverModels {
+		return ""
+	}
+	payload := strings.Join([]string{
+		strconv.Itoa(compat.DiscoverRefreshInterval),
+		ComputeExcludedModelsHash(compat.DiscoverInclude),
+		ComputeExcludedModelsHash(compat.DiscoverExclude),
+	}, "|")
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
 // ComputeExcludedModelsHash returns a normalized hash for excluded model lists.
 func ComputeExcludedModelsHash(excluded []string) string {
 	if len(excluded) == 0 {
@@ -130,3 +161,19 @@ func hashJoined(keys []string) string {
 	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
 	return hex.EncodeToString(sum[:])
 }
+
+// capabilitiesKey renders declared model capabilities for hashing; it is empty when none
+// are declared so hashes of configs without capabilities stay unchanged.
+func capabilitiesKey(capabilities []string) string {
+	if len(capabilities) == 0 {
+		return ""
+	}
+	normalized := make([]string, 0, len(capabilities))
+	for _, c := range capabilities {
+		if trimmed := strings.ToLower(strings.TrimSpace(c)); trimmed != "" {
+			normalized = append(normalized, trimmed)
+		}
+	}
+	sort.Strings(normalized)
+	return "|caps=" + strings.Join(normalized, ",")
+}
@@ -75,6 +75,11 @@ func describeOpenAICompatibilityUpdate(oldEntry, newEntry config.OpenAICompatibi
 	if !equalStringMap(oldEntry.Headers, newEntry.Headers) {
 		details = append(details, "headers updated")
 	}
+	if oldEntry.DiscoverModels != newEntry.DiscoverModels {
+		details = append(details, fmt.Sprintf("discover-models %t -> %t", oldEntry.DiscoverModels, newEntry.DiscoverModels))
+	} else if ComputeModelDiscoveryHash(&oldEntry) != ComputeModelDiscoveryHash(&newEntry) {
+		details = append(details, "model discovery updated")
+	}
 	if len(details) == 0 {
 		return ""
 	}
@@ -41,6 +41,11 @@ func (w *Watcher) start(ctx context.Context) error {
 
 	go w.processEvents(ctx)
 
+	w.clientsMutex.RLock()
+	cfg := w.config
+	w.clientsMutex.RUnlock()
+	w.syncModelDefinitions(cfg)
+
 	w.reloadClients(true, nil, false)
 	return nil
 }
@@ -73,6 +78,12 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	isConfigEvent := normalizedName == normalizedConfigPath && event.Op&configOps != 0
 	authOps := fsnotify.Create | fsnotify.Write | fsnotify.Remove | fsnotify.Rename
 	isAuthJSON := strings.HasPrefix(normalizedName, normalizedAuthDir) && strings.HasSuffix(normalizedName, ".json") && event.Op&authOps != 0
+	isModelDefsEvent := w.isModelDefinitionsPath(normalizedName) && event.Op&authOps != 0
+	if isModelDefsEvent {
+		log.Debugf("model definitions file event detected: %s %s", event.Op.String(), event.Name)
+		w.reloadModelDefinitionsFile(event.Op)
+		return
+	}
 	if !isConfigEvent && !isAuthJSON {
 		// Ignore unrelated files (e.g., cookie snapshots *.cookie) and other noise.
 		return
@@ -0,0 +1,231 @@
+// model_definitions.go loads external model definitions and hot-reloads them.
+// The local file is watched through fsnotify; a remote URL is polled periodically.
+package watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// modelDefinitionsFetchTimeout bounds a single fetch of the remote definitions URL.
+const modelDefinitionsFetchTimeout = 30 * time.Second
+
+// resolveModelDefinitionsPath resolves a configured definitions path relative to the
+// directory of the config file.
+func resolveModelDefinitionsPath(configPath, path string) string {
+	if path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(configPath), path)
+	}
+	return filepath.Clean(path)
+}
+
+// syncModelDefinitions aligns the watched definitions file and the URL poller with cfg and
+// rebuilds the model catalog. It reports whether the effective definitions changed.
+func (w *Watcher) syncModelDefinitions(cfg *config.Config) bool {
+	var defs config.ModelDefinitionsConfig
+	if cfg != nil {
+		defs = cfg.ModelDefinitions
+	}
+	path := resolveModelDefinitionsPath(w.configPath, defs.Path)
+
+	w.modelDefsMu.Lock()
+	if path != w.modelDefsPath {
+		if w.modelDefsPath != "" {
+			_ = w.watcher.Remove(w.modelDefsPath)
+		}
+		w.modelDefsPath = path
+		if path != "" {
+			if errAdd := w.watcher.Add(path); errAdd != nil {
+				log.Warnf("failed to watch model definitions file %s: %v", path, errAdd)
+			} else {
+				log.Debugf("watching model definitions file: %s", path)
+			}
+		}
+	}
+	w.modelDefsFileData = readModelDefinitionsFile(path)
+
+	pollerKey := ""
+	if defs.URL != "" {
+		pollerKey = fmt.Sprintf("%s|%d", defs.URL, defs.RefreshInterval)
+	}
+	if pollerKey != w.modelDefsPollerKey {
+		if w.modelDefsPollerCancel != nil {
+			w.modelDefsPollerCancel()
+			w.modelDefsPollerCancel = nil
+		}
+		w.modelDefsPollerKey = pollerKey
+		if defs.URL != w.modelDefsURL {
+			w.modelDefsURL = defs.URL
+			w.modelDefsURLData = nil
+		}
+		if pollerKey != "" {
+			ctx, cancel := context.WithCancel(context.Background())
+			w.modelDefsPollerCancel = cancel
+			interval := time.Duration(defs.RefreshInterval) * time.Second
+			go w.pollModelDefinitionsURL(ctx, cfg, defs.URL, interval)
+		}
+	}
+	w.modelDefsMu.Unlock()
+
+	return w.applyModelDefinitions()
+}
+
+// applyModelDefinitions rebuilds the registry catalog from the current sources when they
+// differ from the last applied ones. Invalid sources are logged and leave the catalog as is.
+func (w *Watcher) applyModelDefinitions() bool {
+	w.modelDefsMu.Lock()
+	defer w.modelDefsMu.Unlock()
+
+	sources := make([]registry.ModelDefinitionSource, 0, 2)
+	hasher := sha256.New()
+	if len(w.modelDefsFileData) > 0 {
+		sources = append(sources, registry.ModelDefinitionSource{Name: w.modelDefsPath, Data: w.modelDefsFileData})
+		hasher.Write([]byte(w.modelDefsPath))
+		hasher.Write(w.modelDefsFileData)
+	}
+	if len(w.modelDefsURLData) > 0 {
+		sources = append(sources, registry.ModelDefinitionSource{Name: w.modelDefsURL, Data: w.modelDefsURLData})
+		hasher.Write([]byte(w.modelDefsURL))
+		hasher.Write(w.modelDefsURLData)
+	}
+	hash := ""
+	if len(sources) > 0 {
+		hash = hex.EncodeToString(hasher.Sum(nil))
+	}
+	if hash == w.modelDefsHash {
+		return false
+	}
+	if errApply := registry.ApplyModelDefinitionSources(sources...); errApply != nil {
+		log.Errorf("failed to apply model definitions: %v", errApply)
+		return false
+	}
+	w.modelDefsHash = hash
+	if len(sources) == 0 {
+		log.Info("model definitions reset to embedded defaults")
+	} else {
+		log.Infof("model definitions reloaded from %d external source(s)", len(sources))
+	}
+	return true
+}
+
+// reloadModelDefinitionsFile rereads the watched definitions file after a change and
+// refreshes model registrations when the effective definitions changed.
+func (w *Watcher) reloadModelDefinitionsFile(op fsnotify.Op) {
+	w.modelDefsMu.Lock()
+	path := w.modelDefsPath
+	if path != "" && op&(fsnotify.Rename|fsnotify.Remove) != 0 {
+		// Atomic replace drops the watch on the old inode; watch the new file again.
+		time.Sleep(replaceCheckDelay)
+		_ = w.watcher.Add(path)
+	}
+	w.modelDefsFileData = readModelDefinitionsFile(path)
+	w.modelDefsMu.Unlock()
+
+	if w.applyModelDefinitions() {
+		w.refreshAuthState(true)
+	}
+}
+
+// pollModelDefinitionsURL fetches the remote definitions immediately and then once per
+// interval, refreshing model registrations whenever the document changes.
+func (w *Watcher) pollModelDefinitionsURL(ctx context.Context, cfg *config.Config, url string, interval time.Duration) {
+	for {
+		data, errFetch := fetchModelDefinitionsURL(ctx, cfg, url)
+		if errFetch != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warnf("failed to fetch model definitions from %s: %v", url, errFetch)
+		} else {
+			w.modelDefsMu.Lock()
+			current := w.modelDefsURL == url
+			if current {
+				w.modelDefsURLData = data
+			}
+			w.modelDefsMu.Unlock()
+			if current && w.applyModelDefinitions() {
+				w.refreshAuthState(true)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *Watcher) stopModelDefinitions() {
+	w.modelDefsMu.Lock()
+	if w.modelDefsPollerCancel != nil {
+		w.modelDefsPollerCancel()
+		w.modelDefsPollerCancel = nil
+	}
+	w.modelDefsPollerKey = ""
+	w.modelDefsMu.Unlock()
+}
+
+func readModelDefinitionsFile(path string) []byte {
+	if path == "" {
+		return nil
+	}
+	data, errRead := os.ReadFile(path)
+	if errRead != nil {
+		log.Warnf("failed to read model definitions file %s: %v", path, errRead)
+		return nil
+	}
+	return data
+}
+
+func fetchModelDefinitionsURL(ctx context.Context, cfg *config.Config, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, modelDefinitionsFetchTimeout)
+	defer cancel()
+	req, errReq := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if errReq != nil {
+		return nil, errReq
+	}
+	client := &http.Client{}
+	if cfg != nil {
+		util.SetProxy(&cfg.SDKConfig, client)
+	}
+	resp, errDo := client.Do(req)
+	if errDo != nil {
+		return nil, errDo
+	}
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			log.Debugf("model definitions: close response body error: %v", errClose)
+		}
+	}()
+	body, errRead := io.ReadAll(resp.Body)
+	if errRead != nil {
+		return nil, errRead
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+func (w *Watcher) isModelDefinitionsPath(normalizedPath string) bool {
+	w.modelDefsMu.Lock()
+	path := w.modelDefsPath
+	w.modelDefsMu.Unlock()
+	return path != "" && w.normalizeAuthPath(path) == normalizedPath
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // ConfigSynthesizer generates Auth entries from configuration API keys.
-// It handles Gemini, Claude, Codex, OpenAI-compat, and Vertex-compat providers.
+// It handles Gemini, Claude, Bedrock, Codex, Azure OpenAI, OpenAI-compat, and Vertex-compat providers.
 type ConfigSynthesizer struct{}
 
 // NewConfigSynthesizer creates a new ConfigSynthesizer instance.
@@ -29,8 +29,12 @@ func (s *ConfigSynthesizer) Synthesize(ctx *SynthesisContext) ([]*coreauth.Auth,
 	out = append(out, s.synthesizeGeminiKeys(ctx)...)
 	// Claude API Keys
 	out = append(out, s.synthesizeClaudeKeys(ctx)...)
+	// AWS Bedrock credentials
+	out = append(out, s.synthesizeBedrockKeys(ctx)...)
 	// Codex API Keys
 	out = append(out, s.synthesizeCodexKeys(ctx)...)
+	// Azure OpenAI
+	out = append(out, s.synthesizeAzureOpenAIKeys(ctx)...)
 	// OpenAI-compat
 	out = append(out, s.synthesizeOpenAICompat(ctx)...)
 	// Vertex-compat
@@ -135,6 +139,71 @@ func (s *ConfigSynthesizer) synthesizeClaudeKeys(ctx *SynthesisContext) []*corea
 	return out
 }
 
+// synthesizeBedrockKeys creates Auth entries for AWS Bedrock credentials.
+func (s *ConfigSynthesizer) synthesizeBedrockKeys(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0, len(cfg.BedrockKey))
+	for i := range cfg.BedrockKey {
+		bk := cfg.BedrockKey[i]
+		region := strings.TrimSpace(bk.Region)
+		accessKeyID := strings.TrimSpace(bk.AccessKeyID)
+		profile := strings.TrimSpace(bk.Profile)
+		credentialsFile := strings.TrimSpace(bk.CredentialsFile)
+		if region == "" || (accessKeyID == "" && profile == "" && credentialsFile == "") {
+			continue
+		}
+		prefix := strings.TrimSpace(bk.Prefix)
+		base := strings.TrimSpace(bk.BaseURL)
+		id, token := idGen.Next("bedrock:aws", accessKeyID, profile, credentialsFile, region, base)
+		attrs := map[string]string{
+			"source": fmt.Sprintf("config:bedrock[%s]", token),
+			"region": region,
+		}
+		if accessKeyID != "" {
+			attrs["access_key_id"] = accessKeyID
+			attrs["secret_access_key"] = strings.TrimSpace(bk.SecretAccessKey)
+			if sessionToken := strings.TrimSpace(bk.SessionToken); sessionToken != "" {
+				attrs["session_token"] = sessionToken
+			}
+		} else {
+			if profile != "" {
+				attrs["profile"] = profile
+			}
+			if credentialsFile != "" {
+				attrs["credentials_file"] = credentialsFile
+			}
+		}
+		if bk.Priority != 0 {
+			attrs["priority"] = strconv.Itoa(bk.Priority)
+		}
+		if base != "" {
+			attrs["base_url"] = base
+		}
+		if hash := diff.ComputeClaudeModelsHash(bk.Models); hash != "" {
+			attrs["models_hash"] = hash
+		}
+		addConfigHeadersToAttrs(bk.Headers, attrs)
+		proxyURL := strings.TrimSpace(bk.ProxyURL)
+		a := &coreauth.Auth{
+			ID:         id,
+			Provider:   "bedrock",
+			Label:      "bedrock",
+			Prefix:     prefix,
+			Status:     coreauth.StatusActive,
+			ProxyURL:   proxyURL,
+			Attributes: attrs,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		ApplyAuthExcludedModelsMeta(a, cfg, bk.ExcludedModels, "apikey")
+		out = append(out, a)
+	}
+	return out
+}
+
 // synthesizeCodexKeys creates Auth entries for Codex API keys.
 func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreauth.Auth {
 	cfg := ctx.Config
@@ -185,6 +254,55 @@ func (s *ConfigSynthesizer) synthesizeCodexKeys(ctx *SynthesisContext) []*coreau
 	return out
 }
 
+// synthesizeAzureOpenAIKeys creates Auth entries for Azure OpenAI resources.
+func (s *ConfigSynthesizer) synthesizeAzureOpenAIKeys(ctx *SynthesisContext) []*coreauth.Auth {
+	cfg := ctx.Config
+	now := ctx.Now
+	idGen := ctx.IDGenerator
+
+	out := make([]*coreauth.Auth, 0, len(cfg.AzureOpenAIKey))
+	for i := range cfg.AzureOpenAIKey {
+		ak := cfg.AzureOpenAIKey[i]
+		key := strings.TrimSpace(ak.APIKey)
+		base := strings.TrimSpace(ak.BaseURL)
+		if key == "" || base == "" {
+			continue
+		}
+		prefix := strings.TrimSpace(ak.Prefix)
+		id, token := idGen.Next("azure-openai:apikey", key, base)
+		attrs := map[string]string{
+			"source":   fmt.Sprintf("config:azure-openai[%s]", token),
+			"api_key":  key,
+			"base_url": base,
+		}
+		if apiVersion := strings.TrimSpace(ak.APIVersion); apiVersion != "" {
+			attrs["api_version"] = apiVersion
+		}
+		if ak.Priority != 0 {
+			attrs["priority"] = strconv.Itoa(ak.Priority)
+		}
+		if hash := diff.ComputeAzureOpenAIModelsHash(ak.Models); hash != "" {
+			attrs["models_hash"] = hash
+		}
+		addConfigHeadersToAttrs(ak.Headers, attrs)
+		proxyURL := strings.TrimSpace(ak.ProxyURL)
+		a := &coreauth.Auth{
+			ID:         id,
+			Provider:   "azure-openai",
+			Label:      "azure-openai-apikey",
+			Prefix:     prefix,
+			Status:     coreauth.StatusActive,
+			ProxyURL:   proxyURL,
+			Attributes: attrs,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		ApplyAuthExcludedModelsMeta(a, cfg, ak.ExcludedModels, "apikey")
+		out = append(out, a)
+	}
+	return out
+}
+
 // synthesizeOpenAICompat creates Auth entries for OpenAI-compatible providers.
 func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*coreauth.Auth {
 	cfg := ctx.Config
@@ -224,6 +342,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
+			if hash := diff.ComputeModelDiscoveryHash(compat); hash != "" {
+				attrs["models_discovery"] = hash
+			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
@@ -255,6 +376,9 @@ func (s *ConfigSynthesizer) synthesizeOpenAICompat(ctx *SynthesisContext) []*cor
 			if hash := diff.ComputeOpenAICompatModelsHash(compat.Models); hash != "" {
 				attrs["models_hash"] = hash
 			}
+			if hash := diff.ComputeModelDiscoveryHash(compat); hash != "" {
+				attrs["models_discovery"] = hash
+			}
 			addConfigHeadersToAttrs(compat.Headers, attrs)
 			a := &coreauth.Auth{
 				ID:         id,
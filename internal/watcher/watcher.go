@@ -52,6 +52,15 @@ type Watcher struct {
 	storePersister    storePersister
 	mirroredAuthDir   string
 	oldConfigYaml     []byte
+
+	modelDefsMu           sync.Mutex
+	modelDefsPath         string
+	modelDefsFileData     []byte
+	modelDefsURL          string
+	modelDefsURLData      []byte
+	modelDefsHash         string
+	modelDefsPollerKey    string
+	modelDefsPollerCancel context.CancelFunc
 }
 
 // AuthUpdateAction represents the type of change detected in auth sources.
@@ -116,6 +125,7 @@ func (w *Watcher) Start(ctx context.Context) error {
 func (w *Watcher) Stop() error {
 	w.stopDispatch()
 	w.stopConfigReloadTimer()
+	w.stopModelDefinitions()
 	return w.watcher.Close()
 }
 
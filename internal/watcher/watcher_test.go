@@ -15,6 +15,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/diff"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/watcher/synthesizer"
 	sdkAuth "github.com/router-for-me/CLIProxyAPI/v6/sdk/auth"
@@ -299,6 +300,50 @@ func TestStartAndStopSuccess(t *testing.T) {
 	}
 }
 
+func TestSyncModelDefinitionsLoadsAndReloadsFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	defsPath := filepath.Join(tmpDir, "models.yaml")
+	if err := os.WriteFile(defsPath, []byte("claude:\n  - id: claude-watch-test\n    max_completion_tokens: 1000\n"), 0o644); err != nil {
+		t.Fatalf("failed to write definitions: %v", err)
+	}
+
+	w, err := NewWatcher(configPath, tmpDir, nil)
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = w.Stop()
+		_ = registry.ApplyModelDefinitionSources()
+	})
+
+	cfg := &config.Config{ModelDefinitions: config.ModelDefinitionsConfig{Path: "models.yaml"}}
+	if !w.syncModelDefinitions(cfg) {
+		t.Fatal("expected definitions to change on first sync")
+	}
+	if got := registry.LookupStaticModelInfo("claude-watch-test"); got == nil || got.MaxCompletionTokens != 1000 {
+		t.Fatalf("expected model from definitions file, got %+v", got)
+	}
+	if w.syncModelDefinitions(cfg) {
+		t.Fatal("expected unchanged definitions to be skipped")
+	}
+
+	if err = os.WriteFile(defsPath, []byte("claude:\n  - id: claude-watch-test\n    max_completion_tokens: 2000\n"), 0o644); err != nil {
+		t.Fatalf("failed to update definitions: %v", err)
+	}
+	w.reloadModelDefinitionsFile(fsnotify.Write)
+	if got := registry.LookupStaticModelInfo("claude-watch-test"); got == nil || got.MaxCompletionTokens != 2000 {
+		t.Fatalf("expected reloaded model definition, got %+v", got)
+	}
+
+	if !w.syncModelDefinitions(&config.Config{}) {
+		t.Fatal("expected definitions to reset when the path is removed")
+	}
+	if got := registry.LookupStaticModelInfo("claude-watch-test"); got != nil {
+		t.Fatalf("expected embedded catalog after reset, got %+v", got)
+	}
+}
+
 func TestStartFailsWhenConfigMissing(t *testing.T) {
 	tmpDir := t.TempDir()
 	authDir := filepath.Join(tmpDir, "auth")
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/tidwall/gjson"
+)
+
+// requiredCapabilities inspects a request in the handler's native format and returns the
+// model capabilities it needs, in a stable order.
+func requiredCapabilities(handlerType string, rawJSON []byte) []string {
+	if len(rawJSON) == 0 || !gjson.ValidBytes(rawJSON) {
+		return nil
+	}
+	root := gjson.ParseBytes(rawJSON)
+	needs := make(map[string]bool)
+	switch handlerType {
+	case constant.OpenAI:
+		detectOpenAIChatCapabilities(root, needs)
+	case constant.OpenaiResponse:
+		detectOpenAIResponsesCapabilities(root, needs)
+	case constant.Claude:
+		detectClaudeCapabilities(root, needs)
+	case constant.Gemini:
+		detectGeminiCapabilities(root, needs)
+	case constant.GeminiCLI:
+		detectGeminiCapabilities(root.Get("request"), needs)
+	default:
+		return nil
+	}
+
+	ordered := []string{
+		registry.CapabilitySystemPrompt,
+		registry.CapabilityImageInput,
+		registry.CapabilityPDFInput,
+		registry.CapabilityTools,
+		registry.CapabilityJSONSchema,
+	}
+	out := make([]string, 0, len(needs))
+	for _, capability := range ordered {
+		if needs[capability] {
+			out = append(out, capability)
+		}
+	}
+	return out
+}
+
+func detectOpenAIChatCapabilities(root gjson.Result, needs map[string]bool) {
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		switch msg.Get("role").String() {
+		case "system", "developer":
+			needs[registry.CapabilitySystemPrompt] = true
+		}
+		msg.Get("content").ForEach(func(_, part gjson.Result) bool {
+			switch part.Get("type").String() {
+			case "image_url":
+				needs[registry.CapabilityImageInput] = true
+			case "file":
+				if isPDFReference(part.Get("file.filename").String(), part.Get("file.file_data").String()) {
+					needs[registry.CapabilityPDFInput] = true
+				}
+			}
+			return true
+		})
+		return true
+	})
+	if len(root.Get("tools").Array()) > 0 || len(root.Get("functions").Array()) > 0 {
+		needs[registry.CapabilityTools] = true
+	}
+	if root.Get("response_format.type").String() == "json_schema" {
+		needs[registry.CapabilityJSONSchema] = true
+	}
+}
+
+func detectOpenAIResponsesCapabilities(root gjson.Result, needs map[string]bool) {
+	if strings.TrimSpace(root.Get("instructions").String()) != "" {
+		needs[registry.CapabilitySystemPrompt] = true
+	}
+	root.Get("input").ForEach(func(_, item gjson.Result) bool {
+		switch item.Get("role").String() {
+		case "system", "developer":
+			needs[registry.CapabilitySystemPrompt] = true
+		}
+		item.Get("content").ForEach(func(_, part gjson.Result) bool {
+			switch part.Get("type").String() {
+			case "input_image":
+				needs[registry.CapabilityImageInput] = true
+			case "input_file":
+				if isPDFReference(part.Get("filename").String(), part.Get("file_data").String()) || isPDFReference(part.Get("file_url").String(), "") {
+					needs[registry.CapabilityPDFInput] = true
+				}
+			}
+			return true
+		})
+		return true
+	})
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		switch tool.Get("type").String() {
+		case "function", "custom":
+			needs[registry.CapabilityTools] = true
+			return false
+		}
+		return true
+	})
+	if root.Get("text.format.type").String() == "json_schema" {
+		needs[registry.CapabilityJSONSchema] = true
+	}
+}
+
+func detectClaudeCapabilities(root gjson.Result, needs map[string]bool) {
+	system := root.Get("system")
+	if (system.Type == gjson.String && strings.TrimSpace(system.String()) != "") || (system.IsArray() && len(system.Array()) > 0) {
+		needs[registry.CapabilitySystemPrompt] = true
+	}
+	var scanBlocks func(blocks gjson.Result)
+	scanBlocks = func(blocks gjson.Result) {
+		blocks.ForEach(func(_, block gjson.Result) bool {
+			switch block.Get("type").String() {
+			case "image":
+				needs[registry.CapabilityImageInput] = true
+			case "document":
+				switch block.Get("source.type").String() {
+				case "text", "content":
+				default:
+					needs[registry.CapabilityPDFInput] = true
+				}
+			case "tool_result":
+				if content := block.Get("content"); content.IsArray() {
+					scanBlocks(content)
+				}
+			}
+			return true
+		})
+	}
+	root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+		if content := msg.Get("content"); content.IsArray() {
+			scanBlocks(content)
+		}
+		return true
+	})
+	if len(root.Get("tools").Array()) > 0 {
+		needs[registry.CapabilityTools] = true
+	}
+	if root.Get("output_format.type").String() == "json_schema" || root.Get("output_config.format.type").String() == "json_schema" {
+		needs[registry.CapabilityJSONSchema] = true
+	}
+}
+
+func detectGeminiCapabilities(root gjson.Result, needs map[string]bool) {
+	for _, path := range []string{"systemInstruction.parts", "system_instruction.parts"} {
+		if len(root.Get(path).Array()) > 0 {
+			needs[registry.CapabilitySystemPrompt] = true
+		}
+	}
+	root.Get("contents").ForEach(func(_, content gjson.Result) bool {
+		content.Get("parts").ForEach(func(_, part gjson.Result) bool {
+			for _, path := range []string{"inlineData.mimeType", "inline_data.mime_type", "fileData.mimeType", "file_data.mime_type"} {
+				mimeType := strings.ToLower(part.Get(path).String())
+				switch {
+				case strings.HasPrefix(mimeType, "image/"):
+					needs[registry.CapabilityImageInput] = true
+				case mimeType == "application/pdf":
+					needs[registry.CapabilityPDFInput] = true
+				}
+			}
+			return true
+		})
+		return true
+	})
+	root.Get("tools").ForEach(func(_, tool gjson.Result) bool {
+		if tool.Get("functionDeclarations").Exists() || tool.Get("function_declarations").Exists() {
+			needs[registry.CapabilityTools] = true
+			return false
+		}
+		return true
+	})
+	for _, path := range []string{"generationConfig.responseSchema", "generationConfig.responseJsonSchema", "generationConfig.response_schema", "generationConfig.response_json_schema"} {
+		if root.Get(path).Exists() {
+			needs[registry.CapabilityJSONSchema] = true
+		}
+	}
+}
+
+// isPDFReference reports whether an attached file is a PDF, judged by its name or data URL.
+func isPDFReference(filename, fileData string) bool {
+	if idx := strings.IndexAny(filename, "?#"); idx >= 0 {
+		filename = filename[:idx]
+	}
+	if strings.HasSuffix(strings.ToLower(strings.TrimSpace(filename)), ".pdf") {
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(fileData)), "data:application/pdf")
+}
+
+// filterProvidersByCapabilities keeps the providers whose definition of modelID supports every
+// required capability. Providers without capability metadata for the model are kept.
+// missing lists the capabilities lacking on the first rejected provider.
+func filterProvidersByCapabilities(providers []string, modelID string, required []string) (supported []string, missing []string) {
+	reg := registry.GetGlobalRegistry()
+	supported = make([]string, 0, len(providers))
+	for _, provider := range providers {
+		lacking := reg.GetModelInfo(modelID, provider).MissingCapabilities(required)
+		if len(lacking) == 0 {
+			supported = append(supported, provider)
+			continue
+		}
+		if missing == nil {
+			missing = lacking
+		}
+	}
+	return supported, missing
+}
+
+// capabilityErrorMessage builds a 400 error whose body uses the handler's native error format.
+func capabilityErrorMessage(handlerType, modelName string, missing []string) *interfaces.ErrorMessage {
+	descriptions := make([]string, 0, len(missing))
+	for _, capability := range missing {
+		descriptions = append(descriptions, registry.CapabilityDescription(capability))
+	}
+	message := fmt.Sprintf("model %s does not support %s", modelName, strings.Join(descriptions, ", "))
+	return nativeErrorMessage(handlerType, http.StatusBadRequest, message)
+}
+
+// nativeErrorMessage wraps message in the error body format of the handler's API.
+func nativeErrorMessage(handlerType string, status int, message string) *interfaces.ErrorMessage {
+	var body []byte
+	switch handlerType {
+	case constant.Claude:
+		errType := "invalid_request_error"
+		if status >= http.StatusInternalServerError {
+			errType = "api_error"
+		}
+		body, _ = json.Marshal(map[string]any{
+			"type": "error",
+			"error": map[string]any{
+				"type":    errType,
+				"message": message,
+			},
+		})
+	case constant.Gemini, constant.GeminiCLI:
+		errStatus := "INVALID_ARGUMENT"
+		if status >= http.StatusInternalServerError {
+			errStatus = "UNAVAILABLE"
+		}
+		body, _ = json.Marshal(map[string]any{
+			"error": map[string]any{
+				"code":    status,
+				"message": message,
+				"status":  errStatus,
+			},
+		})
+	default:
+		body = BuildErrorResponseBody(status, message)
+	}
+	return &interfaces.ErrorMessage{StatusCode: status, Error: errors.New(string(body))}
+}
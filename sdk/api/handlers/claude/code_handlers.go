@@ -264,6 +264,7 @@ func (h *ClaudeCodeAPIHandler) handleStreamingResponse(c *gin.Context, rawJSON [
 
 			// Success! Set headers now.
 			setSSEHeaders()
+			h.BeginResumableStream(c)
 
 			// Write the first chunk
 			if len(chunk) > 0 {
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescedRequests shares in-flight non-streaming executions among identical requests.
+var coalescedRequests singleflight.Group
+
+// coalescedRequestTimeout bounds a shared execution, which no single client can cancel.
+const coalescedRequestTimeout = 10 * time.Minute
+
+// States of a caller's own execution in a coalesced call.
+const (
+	coalescePending int32 = iota
+	coalesceRunning
+	coalesceAbandoned
+)
+
+type coalescedOutcome struct {
+	payload []byte
+	errMsg  *interfaces.ErrorMessage
+	// abandoned is set when the leading client went away before the execution started.
+	abandoned bool
+}
+
+// coalesce runs execute once for identical concurrent requests when coalescing applies to
+// model. The shared execution runs detached from the leading client so that neither its
+// disconnect nor its cancellation reaches the followers; they receive the leader's response
+// or error and are recorded as coalesced hits.
+func (h *BaseAPIHandler) coalesce(ctx context.Context, kind, handlerType, model string, rawJSON []byte, alt string, execute func(context.Context) ([]byte, *interfaces.ErrorMessage)) ([]byte, *interfaces.ErrorMessage) {
+	if h.Cfg == nil || !h.Cfg.Coalescing.Applies(model) {
+		return execute(ctx)
+	}
+	apiKey := clientAPIKey(ctx)
+	key := strings.Join([]string{kind, apiKey, handlerType, model, alt, h.coalesceHeaders(ctx), canonicalBodyHash(rawJSON)}, "\x00")
+	var state atomic.Int32
+	results := coalescedRequests.DoChan(key, func() (any, error) {
+		if !state.CompareAndSwap(coalescePending, coalesceRunning) {
+			return coalescedOutcome{abandoned: true}, nil
+		}
+		sharedCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), coalescedRequestTimeout)
+		defer cancel()
+		payload, errMsg := execute(sharedCtx)
+		return coalescedOutcome{payload: payload, errMsg: errMsg}, nil
+	})
+	var result singleflight.Result
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		if state.CompareAndSwap(coalescePending, coalesceAbandoned) {
+			return nil, &interfaces.ErrorMessage{StatusCode: http.StatusInternalServerError, Error: ctx.Err()}
+		}
+		// The shared execution still uses this request's context values; wait for it.
+		result = <-results
+	}
+	outcome := result.Val.(coalescedOutcome)
+	if state.Load() == coalesceRunning {
+		return outcome.payload, outcome.errMsg
+	}
+	if outcome.abandoned || (outcome.errMsg != nil && errors.Is(outcome.errMsg.Error, context.Canceled)) {
+		return execute(ctx)
+	}
+	log.Debugf("coalesced %s request for %s with an identical in-flight request", kind, model)
+	coreusage.PublishRecord(ctx, coreusage.Record{
+		Model:       model,
+		APIKey:      apiKey,
+		RequestedAt: time.Now(),
+		Failed:      outcome.errMsg != nil,
+		Coalesced:   true,
+	})
+	return bytes.Clone(outcome.payload), outcome.errMsg
+}
+
+// coalesceHeaders returns the client headers payload rules match on, in a stable form, so
+// requests that rules treat differently are not shared.
+func (h *BaseAPIHandler) coalesceHeaders(ctx context.Context) string {
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return ""
+	}
+	headers := selectHeaders(ginCtx.Request.Header, h.AuthManager.PayloadHeaderNames())
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		b.WriteString(name)
+		for _, value := range headers[name] {
+			b.WriteString("\x01")
+			b.WriteString(value)
+		}
+		b.WriteString("\x02")
+	}
+	return b.String()
+}
+
+// canonicalBodyHash hashes a JSON body independent of key order and whitespace. Bodies that
+// are not JSON are hashed as is.
+func canonicalBodyHash(rawJSON []byte) string {
+	body := rawJSON
+	decoder := json.NewDecoder(bytes.NewReader(rawJSON))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err == nil {
+		if canonical, errMarshal := json.Marshal(value); errMarshal == nil {
+			body = canonical
+		}
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	coreusage "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+// gatedExecutor answers every request once gate is closed.
+type gatedExecutor struct {
+	mu      sync.Mutex
+	calls   int
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (e *gatedExecutor) Identifier() string { return "codex" }
+
+func (e *gatedExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	e.mu.Lock()
+	e.calls++
+	e.mu.Unlock()
+	e.started <- struct{}{}
+	<-e.gate
+	return coreexecutor.Response{Payload: []byte(`{"id":"shared"}`)}, nil
+}
+
+func (e *gatedExecutor) ExecuteStream(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "ExecuteStream not implemented"}
+}
+
+func (e *gatedExecutor) Refresh(_ context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *gatedExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *gatedExecutor) HttpRequest(context.Context, *coreauth.Auth, *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+type coalescedUsage struct {
+	mu   sync.Mutex
+	hits int
+}
+
+func (c *coalescedUsage) HandleUsage(_ context.Context, record coreusage.Record) {
+	if record.Coalesced && record.Model == "coalesce-model" {
+		c.mu.Lock()
+		c.hits++
+		c.mu.Unlock()
+	}
+}
+
+func TestExecuteWithAuthManager_CoalescesIdenticalRequests(t *testing.T) {
+	usage := &coalescedUsage{}
+	coreusage.RegisterPlugin(usage)
+	executor := &gatedExecutor{started: make(chan struct{}, 2), gate: make(chan struct{})}
+	handler, _ := newCoalescingHandler(t, "coalesce-auth", "coalesce-model", executor)
+
+	bodies := []string{
+		`{"model":"coalesce-model","messages":[{"role":"user","content":"title"}]}`,
+		`{ "messages": [{"content":"title","role":"user"}], "model": "coalesce-model" }`,
+	}
+	results := make(chan string, len(bodies))
+	run := func(body string) {
+		payload, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "coalesce-model", []byte(body), "")
+		if errMsg != nil {
+			results <- errMsg.Error.Error()
+			return
+		}
+		results <- string(payload)
+	}
+	go run(bodies[0])
+	<-executor.started
+	go run(bodies[1])
+	time.Sleep(50 * time.Millisecond)
+	close(executor.gate)
+
+	for range bodies {
+		if got := <-results; got != `{"id":"shared"}` {
+			t.Fatalf("unexpected result %q", got)
+		}
+	}
+	if executor.calls != 1 {
+		t.Fatalf("expected one upstream call, got %d", executor.calls)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		usage.mu.Lock()
+		hits := usage.hits
+		usage.mu.Unlock()
+		if hits == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("coalesced hits = %d, want 1", hits)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func newCoalescingHandler(t *testing.T, authID, model string, executor *gatedExecutor) (*BaseAPIHandler, *coreauth.Manager) {
+	t.Helper()
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	auth := &coreauth.Auth{ID: authID, Provider: "codex", Status: coreauth.StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("manager.Register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: model}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	return NewBaseAPIHandlers(&sdkconfig.SDKConfig{Coalescing: sdkconfig.CoalescingConfig{Enabled: true}}, manager), manager
+}
+
+func TestCoalesce_ClientCancellationIsNotShared(t *testing.T) {
+	executor := &gatedExecutor{started: make(chan struct{}, 2), gate: make(chan struct{})}
+	handler, _ := newCoalescingHandler(t, "coalesce-cancel-auth", "coalesce-cancel-model", executor)
+	body := []byte(`{"model":"coalesce-cancel-model","messages":[{"role":"user","content":"title"}]}`)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderDone := make(chan struct{})
+	go func() {
+		defer close(leaderDone)
+		_, _ = handler.ExecuteWithAuthManager(leaderCtx, "openai", "coalesce-cancel-model", body, "")
+	}()
+	<-executor.started
+
+	// A follower whose client leaves stops waiting at once.
+	followerCtx, cancelFollower := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelFollower()
+	if _, errMsg := handler.ExecuteWithAuthManager(followerCtx, "openai", "coalesce-cancel-model", body, ""); errMsg == nil {
+		t.Fatal("expected the departed follower to fail")
+	}
+
+	// The leader's client leaving does not stop the shared execution.
+	results := make(chan string, 1)
+	go func() {
+		payload, errMsg := handler.ExecuteWithAuthManager(context.Background(), "openai", "coalesce-cancel-model", body, "")
+		if errMsg != nil {
+			results <- errMsg.Error.Error()
+			return
+		}
+		results <- string(payload)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+	time.Sleep(20 * time.Millisecond)
+	close(executor.gate)
+	if got := <-results; got != `{"id":"shared"}` {
+		t.Fatalf("follower result = %q", got)
+	}
+	<-leaderDone
+	if executor.calls != 1 {
+		t.Fatalf("expected one upstream call, got %d", executor.calls)
+	}
+}
+
+func TestCoalesce_KeysOnPayloadRuleHeaders(t *testing.T) {
+	executor := &gatedExecutor{started: make(chan struct{}, 2), gate: make(chan struct{})}
+	handler, manager := newCoalescingHandler(t, "coalesce-header-auth", "coalesce-header-model", executor)
+	manager.SetConfig(&internalconfig.Config{Payload: internalconfig.PayloadConfig{Override: []internalconfig.PayloadRule{{
+		Models: []internalconfig.PayloadModelRule{{Name: "coalesce-header-model"}},
+		Match:  internalconfig.PayloadMatch{Headers: map[string]string{"X-Team": "research"}},
+		Params: map[string]any{"temperature": 0},
+	}}}})
+	body := []byte(`{"model":"coalesce-header-model","messages":[{"role":"user","content":"title"}]}`)
+
+	var wg sync.WaitGroup
+	for _, team := range []string{"research", "support"} {
+		ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ginCtx.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		ginCtx.Request.Header.Set("X-Team", team)
+		ctx := context.WithValue(context.Background(), "gin", ginCtx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = handler.ExecuteWithAuthManager(ctx, "openai", "coalesce-header-model", body, "")
+		}()
+	}
+	for range 2 {
+		select {
+		case <-executor.started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("requests with different rule headers were coalesced")
+		}
+	}
+	close(executor.gate)
+	wg.Wait()
+}
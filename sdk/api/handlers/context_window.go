@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// ContextTrimmedHeader reports which conversation turns a context policy removed.
+	ContextTrimmedHeader = "X-Context-Trimmed"
+
+	contextSummaryMaxTokens = 1024
+	contextSummaryPrompt    = "Summarize the following conversation excerpt so it can replace the original messages. Keep facts, decisions, open questions, file names, identifiers and tool results that later turns may rely on. Answer with the summary only."
+	contextSummaryPreamble  = "Summary of earlier conversation turns removed to fit the context window:\n"
+)
+
+// contextPolicyFor returns the first context policy matching the model and the client's API key.
+func contextPolicyFor(cfg *config.SDKConfig, baseModel, apiKey string) *config.ContextPolicy {
+	if cfg == nil {
+		return nil
+	}
+	for i := range cfg.ContextPolicies {
+		policy := &cfg.ContextPolicies[i]
+		if len(policy.Models) > 0 && !matchAnyPattern(policy.Models, baseModel) {
+			continue
+		}
+		if len(policy.APIKeys) > 0 && !matchAnyPattern(policy.APIKeys, apiKey) {
+			continue
+		}
+		return policy
+	}
+	return nil
+}
+
+func matchAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchModelPattern(strings.TrimSpace(pattern), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchModelPattern matches value against pattern, where '*' matches any run of characters.
+func matchModelPattern(pattern, value string) bool {
+	if pattern == "" {
+		return false
+	}
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return strings.HasSuffix(value, last)
+}
+
+func clientAPIKey(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil {
+		return ""
+	}
+	if v, exists := ginCtx.Get("apiKey"); exists {
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// inputTokenLimit returns the smallest input token limit among the providers' definitions of
+// the model, since the request may be routed to any of them. Zero means no limit is known.
+func inputTokenLimit(baseModel string, providers []string) int {
+	reg := registry.GetGlobalRegistry()
+	limit := 0
+	for _, provider := range providers {
+		info := reg.GetModelInfo(baseModel, provider)
+		if info == nil || info.InputTokenLimit <= 0 {
+			continue
+		}
+		if limit == 0 || info.InputTokenLimit < limit {
+			limit = info.InputTokenLimit
+		}
+	}
+	return limit
+}
+
+// conversation is a request's message list split into turns. A turn starts with a user
+// message that is not only tool results, so tool calls always stay with their results.
+type conversation struct {
+	path string
+	// items are the raw messages in request order.
+	items []gjson.Result
+	// pinned marks system messages that are never dropped.
+	pinned []bool
+	// turns holds the index of the first item of each turn.
+	turns []int
+}
+
+func parseConversation(handlerType string, rawJSON []byte) (*conversation, bool) {
+	var path string
+	switch handlerType {
+	case constant.OpenAI, constant.Claude:
+		path = "messages"
+	case constant.OpenaiResponse:
+		path = "input"
+	case constant.Gemini:
+		path = "contents"
+	case constant.GeminiCLI:
+		path = "request.contents"
+	default:
+		return nil, false
+	}
+	list := gjson.GetBytes(rawJSON, path)
+	if !list.IsArray() {
+		return nil, false
+	}
+	conv := &conversation{path: path, items: list.Array()}
+	conv.pinned = make([]bool, len(conv.items))
+	for i, item := range conv.items {
+		role := item.Get("role").String()
+		if (handlerType == constant.OpenAI || handlerType == constant.OpenaiResponse) && (role == "system" || role == "developer") {
+			conv.pinned[i] = true
+			continue
+		}
+		if len(conv.turns) == 0 || startsTurn(handlerType, item) {
+			conv.turns = append(conv.turns, i)
+		}
+	}
+	return conv, true
+}
+
+func startsTurn(handlerType string, item gjson.Result) bool {
+	switch handlerType {
+	case constant.OpenAI:
+		return item.Get("role").String() == "user"
+	case constant.OpenaiResponse:
+		itemType := item.Get("type").String()
+		return (itemType == "" || itemType == "message") && item.Get("role").String() == "user"
+	case constant.Claude:
+		if item.Get("role").String() != "user" {
+			return false
+		}
+		content := item.Get("content")
+		if !content.IsArray() {
+			return true
+		}
+		for _, block := range content.Array() {
+			if block.Get("type").String() != "tool_result" {
+				return true
+			}
+		}
+		return false
+	case constant.Gemini, constant.GeminiCLI:
+		if role := item.Get("role").String(); role != "" && role != "user" {
+			return false
+		}
+		for _, part := range item.Get("parts").Array() {
+			if !part.Get("functionResponse").Exists() && !part.Get("function_response").Exists() {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// without returns rawJSON with the first n turns removed.
+func (c *conversation) without(rawJSON []byte, n int) []byte {
+	cut := c.cut(n)
+	kept := make([]string, 0, len(c.items))
+	for i, item := range c.items {
+		if i >= cut || c.pinned[i] {
+			kept = append(kept, item.Raw)
+		}
+	}
+	out, _ := sjson.SetRawBytes(rawJSON, c.path, []byte("["+strings.Join(kept, ",")+"]"))
+	return out
+}
+
+// dropped returns the items removed by without(rawJSON, n).
+func (c *conversation) dropped(n int) []gjson.Result {
+	cut := c.cut(n)
+	out := make([]gjson.Result, 0, cut)
+	for i := 0; i < cut; i++ {
+		if !c.pinned[i] {
+			out = append(out, c.items[i])
+		}
+	}
+	return out
+}
+
+func (c *conversation) cut(n int) int {
+	if n < len(c.turns) {
+		return c.turns[n]
+	}
+	return len(c.items)
+}
+
+// applyContextPolicy enforces the matching context policy on a request whose prompt exceeds
+// the input token limit of the model. Trimmed requests are described in ContextTrimmedHeader.
+func (h *BaseAPIHandler) applyContextPolicy(ctx context.Context, handlerType, modelName string, providers []string, rawJSON []byte) ([]byte, *interfaces.ErrorMessage) {
+	if h.Cfg == nil || len(h.Cfg.ContextPolicies) == 0 {
+		return rawJSON, nil
+	}
+	baseModel := thinking.ParseSuffix(modelName).ModelName
+	policy := contextPolicyFor(h.Cfg, baseModel, clientAPIKey(ctx))
+	if policy == nil {
+		return rawJSON, nil
+	}
+	limit := inputTokenLimit(baseModel, providers)
+	if limit <= 0 {
+		return rawJSON, nil
+	}
+	format := sdktranslator.FromString(handlerType)
+	count := func(payload []byte) int64 {
+		n, err := executor.CountRequestTokens(format, baseModel, payload)
+		if err != nil {
+			log.Debugf("context policy: token counting failed: %v", err)
+		}
+		return n
+	}
+	fits := int64(limit - policy.ReserveTokens)
+	total := count(rawJSON)
+	if total <= fits {
+		return rawJSON, nil
+	}
+	overLimit := func() *interfaces.ErrorMessage {
+		return nativeErrorMessage(handlerType, http.StatusBadRequest, fmt.Sprintf("prompt is about %d tokens, which exceeds the %d-token input limit of model %s", total, limit, baseModel))
+	}
+	if policy.Mode == config.ContextPolicyReject {
+		return nil, overLimit()
+	}
+	conv, ok := parseConversation(handlerType, rawJSON)
+	if !ok || len(conv.turns) < 2 {
+		return nil, overLimit()
+	}
+
+	// Dropping more turns never adds tokens, so search for the fewest that fit. The newest
+	// turn is always kept, and a summary needs room of its own.
+	budget := fits
+	if policy.Mode == config.ContextPolicySummarize {
+		budget -= contextSummaryMaxTokens
+	}
+	lo, hi := 1, len(conv.turns)-1
+	if count(conv.without(rawJSON, hi)) > budget {
+		return nil, overLimit()
+	}
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if count(conv.without(rawJSON, mid)) <= budget {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	out, dropped := conv.without(rawJSON, lo), conv.dropped(lo)
+	mode := config.ContextPolicyTruncate
+	if policy.Mode == config.ContextPolicySummarize {
+		if summary, errSummary := h.summarizeTurns(ctx, format, policy.SummaryModel, conv.path, dropped); errSummary != nil {
+			log.Warnf("context policy: summarising %d messages with %s failed, truncating instead: %v", len(dropped), policy.SummaryModel, errSummary)
+		} else {
+			out = appendSystemInstruction(handlerType, out, contextSummaryPreamble+summary)
+			mode = config.ContextPolicySummarize
+		}
+	}
+	trimmed := fmt.Sprintf("mode=%s; turns=%d; messages=%d; tokens=%d->%d", mode, lo, len(dropped), total, count(out))
+	if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil {
+		ginCtx.Header(ContextTrimmedHeader, trimmed)
+	}
+	log.Debugf("context policy for %s: %s", baseModel, trimmed)
+	return out, nil
+}
+
+// summarizeTurns asks model for a summary of the dropped messages, rendered as a plain-text
+// transcript so tool calls need no matching tool definitions.
+func (h *BaseAPIHandler) summarizeTurns(ctx context.Context, format sdktranslator.Format, model, path string, dropped []gjson.Result) (string, error) {
+	items := make([]string, 0, len(dropped))
+	for _, item := range dropped {
+		items = append(items, item.Raw)
+	}
+	excerpt, _ := sjson.SetRawBytes([]byte(`{}`), strings.TrimPrefix(path, "request."), []byte("["+strings.Join(items, ",")+"]"))
+	if format == sdktranslator.FormatGeminiCLI {
+		format = sdktranslator.FormatGemini
+	}
+	translated := excerpt
+	if format != sdktranslator.FormatOpenAI {
+		translated = sdktranslator.TranslateRequest(format, sdktranslator.FormatOpenAI, model, excerpt, false)
+	}
+	transcript := renderTranscript(gjson.GetBytes(translated, "messages"))
+	if strings.TrimSpace(transcript) == "" {
+		return "", fmt.Errorf("nothing to summarise")
+	}
+
+	request := []byte(`{"model":"","messages":[{"role":"system","content":""},{"role":"user","content":""}]}`)
+	request, _ = sjson.SetBytes(request, "model", model)
+	request, _ = sjson.SetBytes(request, "max_tokens", contextSummaryMaxTokens)
+	request, _ = sjson.SetBytes(request, "messages.0.content", contextSummaryPrompt)
+	request, _ = sjson.SetBytes(request, "messages.1.content", transcript)
+	providers, normalizedModel, errMsg := h.getRequestDetails(constant.OpenAI, model, request)
+	if errMsg != nil {
+		return "", errMsg.Error
+	}
+	resp, errMsg := h.executeNonStream(ctx, constant.OpenAI, providers, normalizedModel, request, "")
+	if errMsg != nil {
+		return "", errMsg.Error
+	}
+	summary := strings.TrimSpace(gjson.GetBytes(resp, "choices.0.message.content").String())
+	if summary == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return summary, nil
+}
+
+// renderTranscript formats OpenAI chat messages as "role: text" lines.
+func renderTranscript(messages gjson.Result) string {
+	var b strings.Builder
+	messages.ForEach(func(_, msg gjson.Result) bool {
+		role := msg.Get("role").String()
+		var text strings.Builder
+		content := msg.Get("content")
+		if content.IsArray() {
+			content.ForEach(func(_, part gjson.Result) bool {
+				switch part.Get("type").String() {
+				case "text":
+					text.WriteString(part.Get("text").String())
+				case "image_url":
+					text.WriteString("[image]")
+				case "file":
+					text.WriteString("[file]")
+				}
+				return true
+			})
+		} else {
+			text.WriteString(content.String())
+		}
+		msg.Get("tool_calls").ForEach(func(_, call gjson.Result) bool {
+			fmt.Fprintf(&text, "\n[called %s with %s]", call.Get("function.name").String(), call.Get("function.arguments").String())
+			return true
+		})
+		if strings.TrimSpace(text.String()) == "" {
+			return true
+		}
+		if role == "tool" {
+			role = "tool result"
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", role, strings.TrimSpace(text.String()))
+		return true
+	})
+	return b.String()
+}
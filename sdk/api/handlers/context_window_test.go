@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestApplyContextPolicy(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-context-policy", "small", []*registry.ModelInfo{
+		{ID: "context-policy-model", InputTokenLimit: 300},
+	})
+	t.Cleanup(func() { modelRegistry.UnregisterClient("test-context-policy") })
+
+	words := strings.Repeat("lorem ", 100)
+	request := []byte(`{"system":"be brief","messages":[` +
+		`{"role":"user","content":"first ` + words + `"},` +
+		`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"read","input":{}}]},` +
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"` + words + `"}]},` +
+		`{"role":"assistant","content":"done"},` +
+		`{"role":"user","content":"second ` + words + `"},` +
+		`{"role":"assistant","content":"ok"},` +
+		`{"role":"user","content":"third"}]}`)
+
+	newContext := func() (context.Context, *httptest.ResponseRecorder) {
+		recorder := httptest.NewRecorder()
+		ginCtx, _ := gin.CreateTestContext(recorder)
+		ginCtx.Set("apiKey", "client-a")
+		return context.WithValue(context.Background(), "gin", ginCtx), recorder
+	}
+
+	policies := []sdkconfig.ContextPolicy{
+		{APIKeys: []string{"client-b"}, Mode: sdkconfig.ContextPolicyReject},
+		{Models: []string{"context-policy-*"}, Mode: sdkconfig.ContextPolicyTruncate},
+	}
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{ContextPolicies: policies}, coreauth.NewManager(nil, nil, nil))
+	ctx, recorder := newContext()
+	out, errMsg := handler.applyContextPolicy(ctx, "claude", "context-policy-model", []string{"small"}, request)
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	// The first turn, including its tool call and result, is removed as a whole.
+	if gjson.GetBytes(out, "messages.#").Int() != 3 || !strings.HasPrefix(gjson.GetBytes(out, "messages.0.content").String(), "second") {
+		t.Fatalf("unexpected trimmed request: %s", out)
+	}
+	if gjson.GetBytes(out, "system").String() != "be brief" {
+		t.Fatalf("system prompt changed: %s", out)
+	}
+	if header := recorder.Header().Get(ContextTrimmedHeader); !strings.Contains(header, "mode=truncate; turns=1; messages=4") {
+		t.Fatalf("unexpected %s header %q", ContextTrimmedHeader, header)
+	}
+
+	// A request that fits is left alone.
+	short := []byte(`{"messages":[{"role":"user","content":"hi"}]}`)
+	if out, errMsg = handler.applyContextPolicy(ctx, "claude", "context-policy-model", []string{"small"}, short); errMsg != nil || string(out) != string(short) {
+		t.Fatalf("expected the short request unchanged, got %s (%v)", out, errMsg)
+	}
+
+	policies[0].APIKeys = []string{"client-a"}
+	ctx, _ = newContext()
+	_, errMsg = handler.applyContextPolicy(ctx, "claude", "context-policy-model", []string{"small"}, request)
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadRequest || gjson.Get(errMsg.Error.Error(), "error.type").String() != "invalid_request_error" {
+		t.Fatalf("expected a Claude-native 400 from the reject policy, got %v", errMsg)
+	}
+}
+
+func TestApplyContextPolicy_SummaryFailureTruncates(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-context-summary", "small", []*registry.ModelInfo{
+		{ID: "context-summary-model", InputTokenLimit: 1300},
+	})
+	t.Cleanup(func() { modelRegistry.UnregisterClient("test-context-summary") })
+
+	words := strings.Repeat("lorem ", 700)
+	request := []byte(`{"messages":[{"role":"system","content":"be brief"},` +
+		`{"role":"user","content":"` + words + `"},{"role":"assistant","content":"a"},` +
+		`{"role":"user","content":"` + words + `"},{"role":"assistant","content":"b"},` +
+		`{"role":"user","content":"last"}]}`)
+	cfg := &sdkconfig.SDKConfig{ContextPolicies: []sdkconfig.ContextPolicy{
+		{Mode: sdkconfig.ContextPolicySummarize, SummaryModel: "no-such-summary-model"},
+	}}
+	handler := NewBaseAPIHandlers(cfg, coreauth.NewManager(nil, nil, nil))
+	out, errMsg := handler.applyContextPolicy(context.Background(), "openai", "context-summary-model", []string{"small"}, request)
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if gjson.GetBytes(out, "messages.0.role").String() != "system" || gjson.GetBytes(out, "messages.1.content").String() != "last" {
+		t.Fatalf("expected the system message kept and old turns dropped, got %s", out)
+	}
+}
@@ -231,6 +231,7 @@ func (h *GeminiAPIHandler) handleStreamGenerateContent(c *gin.Context, modelName
 			// Success! Set headers.
 			if alt == "" {
 				setSSEHeaders()
+				h.BeginResumableStream(c)
 			}
 
 			// Write first chunk
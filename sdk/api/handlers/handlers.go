@@ -6,8 +6,10 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,12 +18,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/util"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
 	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
@@ -167,6 +171,14 @@ func NonStreamingKeepAliveInterval(cfg *config.SDKConfig) time.Duration {
 	return time.Duration(seconds) * time.Second
 }
 
+// StreamingContinuationRetries returns how many times a stream that failed after bytes were sent may be continued.
+func StreamingContinuationRetries(cfg *config.SDKConfig) int {
+	if cfg == nil || cfg.Streaming.ContinuationRetries < 0 {
+		return 0
+	}
+	return cfg.Streaming.ContinuationRetries
+}
+
 // StreamingBootstrapRetries returns how many times a streaming request may be retried before any bytes are sent.
 func StreamingBootstrapRetries(cfg *config.SDKConfig) int {
 	retries := defaultStreamingBootstrapRetries
@@ -179,12 +191,14 @@ func StreamingBootstrapRetries(cfg *config.SDKConfig) int {
 	return retries
 }
 
-func requestExecutionMetadata(ctx context.Context) map[string]any {
+func requestExecutionMetadata(ctx context.Context, headerNames []string) map[string]any {
 	// Idempotency-Key is an optional client-supplied header used to correlate retries.
 	// It is forwarded as execution metadata; when absent we generate a UUID.
 	key := ""
+	var ginCtx *gin.Context
 	if ctx != nil {
-		if ginCtx, ok := ctx.Value("gin").(*gin.Context); ok && ginCtx != nil && ginCtx.Request != nil {
+		if c, ok := ctx.Value("gin").(*gin.Context); ok && c != nil && c.Request != nil {
+			ginCtx = c
 			key = strings.TrimSpace(ginCtx.GetHeader("Idempotency-Key"))
 		}
 	}
@@ -193,6 +207,16 @@ func requestExecutionMetadata(ctx context.Context) map[string]any {
 	}
 
 	meta := map[string]any{idempotencyKeyMetadataKey: key}
+	if ginCtx != nil {
+		// Client identity and headers let payload rules target specific callers. Only the
+		// headers those rules match on are kept, so client credentials stay out of metadata.
+		if headers := selectHeaders(ginCtx.Request.Header, headerNames); len(headers) > 0 {
+			meta[coreexecutor.ClientHeadersMetadataKey] = headers
+		}
+		if apiKey := clientAPIKey(ctx); apiKey != "" {
+			meta[coreexecutor.ClientAPIKeyMetadataKey] = apiKey
+		}
+	}
 	if pinnedAuthID := pinnedAuthIDFromContext(ctx); pinnedAuthID != "" {
 		meta[coreexecutor.PinnedAuthMetadataKey] = pinnedAuthID
 	}
@@ -205,6 +229,17 @@ func requestExecutionMetadata(ctx context.Context) map[string]any {
 	return meta
 }
 
+// selectHeaders copies the values of the named headers present in src.
+func selectHeaders(src http.Header, names []string) http.Header {
+	out := make(http.Header, len(names))
+	for _, name := range names {
+		if values := src.Values(name); len(values) > 0 {
+			out[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+		}
+	}
+	return out
+}
+
 func pinnedAuthIDFromContext(ctx context.Context) string {
 	if ctx == nil {
 		return ""
@@ -337,6 +372,12 @@ func (h *BaseAPIHandler) GetContextWithCancel(handler interfaces.APIHandler, c *
 		go func() {
 			select {
 			case <-requestCtx.Done():
+				// A resumable stream keeps consuming the upstream for a client that may reattach;
+				// ForwardStream cancels it once the resume grace period runs out.
+				if resumableStreamFor(c) != nil {
+					<-newCtx.Done()
+					return
+				}
 				cancel()
 			case <-newCtx.Done():
 			}
@@ -462,11 +503,26 @@ func appendAPIResponse(c *gin.Context, data []byte) {
 // ExecuteWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, errMsg := h.getRequestDetails(handlerType, modelName, rawJSON)
 	if errMsg != nil {
 		return nil, errMsg
 	}
-	reqMeta := requestExecutionMetadata(ctx)
+	return h.coalesce(ctx, "execute", handlerType, normalizedModel, rawJSON, alt, func(ctx context.Context) ([]byte, *interfaces.ErrorMessage) {
+		body, errMsg := h.applyContextPolicy(ctx, handlerType, normalizedModel, providers, rawJSON)
+		if errMsg != nil {
+			return nil, errMsg
+		}
+		if plan := h.planStructuredOutput(handlerType, normalizedModel, providers, body, false); plan != nil {
+			return plan.execute(func(payload []byte) ([]byte, *interfaces.ErrorMessage) {
+				return h.executeNonStream(ctx, handlerType, providers, normalizedModel, payload, alt)
+			})
+		}
+		return h.executeNonStream(ctx, handlerType, providers, normalizedModel, body, alt)
+	})
+}
+
+func (h *BaseAPIHandler) executeNonStream(ctx context.Context, handlerType string, providers []string, normalizedModel string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
+	reqMeta := requestExecutionMetadata(ctx, h.AuthManager.PayloadHeaderNames())
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	payload := rawJSON
 	if len(payload) == 0 {
@@ -505,11 +561,23 @@ func (h *BaseAPIHandler) ExecuteWithAuthManager(ctx context.Context, handlerType
 // ExecuteCountWithAuthManager executes a non-streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) ([]byte, *interfaces.ErrorMessage) {
-	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, errMsg := h.getRequestDetails(handlerType, modelName, rawJSON)
 	if errMsg != nil {
 		return nil, errMsg
 	}
-	reqMeta := requestExecutionMetadata(ctx)
+	mode := TokenCountingMode(h.Cfg)
+	if mode == config.TokenCountingLocal && !exactTokenCountRequested(ctx) {
+		if local, ok := localTokenCount(handlerType, normalizedModel, rawJSON); ok {
+			return local, nil
+		}
+	}
+	return h.coalesce(ctx, "count", handlerType, normalizedModel, rawJSON, alt, func(ctx context.Context) ([]byte, *interfaces.ErrorMessage) {
+		return h.executeCount(ctx, handlerType, providers, normalizedModel, rawJSON, alt, mode)
+	})
+}
+
+func (h *BaseAPIHandler) executeCount(ctx context.Context, handlerType string, providers []string, normalizedModel string, rawJSON []byte, alt, mode string) ([]byte, *interfaces.ErrorMessage) {
+	reqMeta := requestExecutionMetadata(ctx, h.AuthManager.PayloadHeaderNames())
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	payload := rawJSON
 	if len(payload) == 0 {
@@ -528,6 +596,12 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 	opts.Metadata = reqMeta
 	resp, err := h.AuthManager.ExecuteCount(ctx, providers, req, opts)
 	if err != nil {
+		if mode == config.TokenCountingFallback {
+			if local, ok := localTokenCount(handlerType, normalizedModel, rawJSON); ok {
+				log.Debugf("token count for %s answered locally after upstream failure: %v", normalizedModel, err)
+				return local, nil
+			}
+		}
 		status := http.StatusInternalServerError
 		if se, ok := err.(interface{ StatusCode() int }); ok && se != nil {
 			if code := se.StatusCode(); code > 0 {
@@ -548,14 +622,25 @@ func (h *BaseAPIHandler) ExecuteCountWithAuthManager(ctx context.Context, handle
 // ExecuteStreamWithAuthManager executes a streaming request via the core auth manager.
 // This path is the only supported execution route.
 func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handlerType, modelName string, rawJSON []byte, alt string) (<-chan []byte, <-chan *interfaces.ErrorMessage) {
-	providers, normalizedModel, errMsg := h.getRequestDetails(modelName)
+	providers, normalizedModel, errMsg := h.getRequestDetails(handlerType, modelName, rawJSON)
 	if errMsg != nil {
 		errChan := make(chan *interfaces.ErrorMessage, 1)
 		errChan <- errMsg
 		close(errChan)
 		return nil, errChan
 	}
-	reqMeta := requestExecutionMetadata(ctx)
+	if rawJSON, errMsg = h.applyContextPolicy(ctx, handlerType, normalizedModel, providers, rawJSON); errMsg != nil {
+		errChan := make(chan *interfaces.ErrorMessage, 1)
+		errChan <- errMsg
+		close(errChan)
+		return nil, errChan
+	}
+	// Streams cannot be validated before they reach the client, so only the prompt
+	// emulation applies here.
+	if plan := h.planStructuredOutput(handlerType, normalizedModel, providers, rawJSON, true); plan != nil {
+		rawJSON = plan.request
+	}
+	reqMeta := requestExecutionMetadata(ctx, h.AuthManager.PayloadHeaderNames())
 	reqMeta[coreexecutor.RequestedModelMetadataKey] = normalizedModel
 	payload := rawJSON
 	if len(payload) == 0 {
@@ -599,6 +684,12 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 		sentPayload := false
 		bootstrapRetries := 0
 		maxBootstrapRetries := StreamingBootstrapRetries(h.Cfg)
+		continuations := 0
+		maxContinuations := StreamingContinuationRetries(h.Cfg)
+		var continuation *streamContinuation
+		if maxContinuations > 0 {
+			continuation = newStreamContinuation(handlerType, alt)
+		}
 
 		sendErr := func(msg *interfaces.ErrorMessage) bool {
 			if ctx == nil {
@@ -672,6 +763,35 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 							streamErr = retryErr
 						}
 					}
+					// Mid-stream recovery: continue the partial answer on another credential and
+					// splice the new stream into the one the client is already reading.
+					for sentPayload && continuation != nil && continuations < maxContinuations && bootstrapEligible(streamErr) {
+						contPayload, ok := continuation.request(req.Payload)
+						if !ok {
+							break
+						}
+						continuations++
+						excludeSelectedAuth(reqMeta)
+						contReq := req
+						contReq.Payload = contPayload
+						contOpts := opts
+						contOpts.OriginalRequest = contReq.Payload
+						log.Warnf("stream for model %s failed after partial output, continuing (attempt %d/%d): %v", normalizedModel, continuations, maxContinuations, streamErr)
+						retryChunks, retryErr := h.AuthManager.ExecuteStream(ctx, providers, contReq, contOpts)
+						if retryErr != nil && isAuthNotFound(retryErr) {
+							// No other credential serves the model; continue on the one that failed.
+							delete(reqMeta, coreexecutor.ExcludedAuthsMetadataKey)
+							retryChunks, retryErr = h.AuthManager.ExecuteStream(ctx, providers, contReq, contOpts)
+						}
+						if retryErr == nil {
+							if prefix := continuation.begin(); len(prefix) > 0 && !sendData(prefix) {
+								return
+							}
+							chunks = retryChunks
+							continue outer
+						}
+						streamErr = retryErr
+					}
 
 					status := http.StatusInternalServerError
 					if se, ok := streamErr.(interface{ StatusCode() int }); ok && se != nil {
@@ -689,8 +809,14 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 					return
 				}
 				if len(chunk.Payload) > 0 {
+					payload := cloneBytes(chunk.Payload)
+					if continuation != nil {
+						if payload = continuation.observe(payload); len(payload) == 0 {
+							continue
+						}
+					}
 					sentPayload = true
-					if okSendData := sendData(cloneBytes(chunk.Payload)); !okSendData {
+					if okSendData := sendData(payload); !okSendData {
 						return
 					}
 				}
@@ -700,6 +826,23 @@ func (h *BaseAPIHandler) ExecuteStreamWithAuthManager(ctx context.Context, handl
 	return dataChan, errChan
 }
 
+// excludeSelectedAuth adds the auth that served the last attempt to the excluded auth IDs.
+func excludeSelectedAuth(meta map[string]any) {
+	authID, _ := meta[coreexecutor.SelectedAuthMetadataKey].(string)
+	if authID == "" {
+		return
+	}
+	excluded, _ := meta[coreexecutor.ExcludedAuthsMetadataKey].([]string)
+	if !slices.Contains(excluded, authID) {
+		meta[coreexecutor.ExcludedAuthsMetadataKey] = append(excluded, authID)
+	}
+}
+
+func isAuthNotFound(err error) bool {
+	var authErr *coreauth.Error
+	return errors.As(err, &authErr) && authErr.Code == "auth_not_found"
+}
+
 func statusFromError(err error) int {
 	if err == nil {
 		return 0
@@ -712,7 +855,10 @@ func statusFromError(err error) int {
 	return 0
 }
 
-func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string, normalizedModel string, err *interfaces.ErrorMessage) {
+// getRequestDetails resolves the providers serving modelName and the normalized model name.
+// Providers whose model definition lacks a capability the request needs are skipped; when
+// none remain, a 400 error in the handler's native format is returned.
+func (h *BaseAPIHandler) getRequestDetails(handlerType, modelName string, rawJSON []byte) (providers []string, normalizedModel string, err *interfaces.ErrorMessage) {
 	resolvedModelName := modelName
 	initialSuffix := thinking.ParseSuffix(modelName)
 	if initialSuffix.ModelName == "auto" {
@@ -743,6 +889,21 @@ func (h *BaseAPIHandler) getRequestDetails(modelName string) (providers []string
 		return nil, "", &interfaces.ErrorMessage{StatusCode: http.StatusBadGateway, Error: fmt.Errorf("unknown provider for model %s", modelName)}
 	}
 
+	required := requiredCapabilities(handlerType, rawJSON)
+	if !structuredOutputDisabled(h.Cfg) {
+		// Structured output is emulated for models without native support.
+		required = slices.DeleteFunc(required, func(capability string) bool {
+			return capability == registry.CapabilityJSONSchema
+		})
+	}
+	if len(required) > 0 {
+		supported, missing := filterProvidersByCapabilities(providers, baseModel, required)
+		if len(supported) == 0 {
+			return nil, "", capabilityErrorMessage(handlerType, modelName, missing)
+		}
+		providers = supported
+	}
+
 	// The thinking suffix is preserved in the model name itself, so no
 	// metadata-based configuration passing is needed.
 	return providers, resolvedModelName, nil
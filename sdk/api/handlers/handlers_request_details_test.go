@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
 )
 
 func TestGetRequestDetails_PreservesSuffix(t *testing.T) {
@@ -100,7 +107,7 @@ func TestGetRequestDetails_PreservesSuffix(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			providers, model, errMsg := handler.getRequestDetails(tt.inputModel)
+			providers, model, errMsg := handler.getRequestDetails("openai", tt.inputModel, nil)
 			if (errMsg != nil) != tt.wantErr {
 				t.Fatalf("getRequestDetails() error = %v, wantErr %v", errMsg, tt.wantErr)
 			}
@@ -116,3 +123,73 @@ func TestGetRequestDetails_PreservesSuffix(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRequestDetails_FiltersProvidersByCapabilities(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-capabilities-text-only", "text-only", []*registry.ModelInfo{
+		{ID: "capability-test-model", Capabilities: []string{registry.CapabilityTools}},
+	})
+	modelRegistry.RegisterClient("test-capabilities-vision", "vision", []*registry.ModelInfo{
+		{ID: "capability-test-model", Capabilities: []string{registry.CapabilityTools, registry.CapabilityImageInput}},
+	})
+	modelRegistry.RegisterClient("test-capabilities-strict", "strict", []*registry.ModelInfo{
+		{ID: "capability-strict-model", Capabilities: []string{}},
+	})
+	for _, clientID := range []string{"test-capabilities-text-only", "test-capabilities-vision", "test-capabilities-strict"} {
+		id := clientID
+		t.Cleanup(func() {
+			modelRegistry.UnregisterClient(id)
+		})
+	}
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+
+	imageRequest := []byte(`{"model":"capability-test-model","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]}]}`)
+	providers, _, errMsg := handler.getRequestDetails("openai", "capability-test-model", imageRequest)
+	if errMsg != nil {
+		t.Fatalf("getRequestDetails() unexpected error: %v", errMsg.Error)
+	}
+	if !reflect.DeepEqual(providers, []string{"vision"}) {
+		t.Fatalf("getRequestDetails() providers = %v, want [vision]", providers)
+	}
+
+	claudeRequest := []byte(`{"model":"capability-strict-model","system":"be brief","messages":[{"role":"user","content":"hi"}]}`)
+	_, _, errMsg = handler.getRequestDetails("claude", "capability-strict-model", claudeRequest)
+	if errMsg == nil {
+		t.Fatal("getRequestDetails() expected capability error")
+	}
+	if errMsg.StatusCode != 400 {
+		t.Fatalf("status = %d, want 400", errMsg.StatusCode)
+	}
+	body := errMsg.Error.Error()
+	if gjson.Get(body, "type").String() != "error" || gjson.Get(body, "error.type").String() != "invalid_request_error" {
+		t.Fatalf("expected Claude-native error body, got %s", body)
+	}
+	if !strings.Contains(gjson.Get(body, "error.message").String(), "system prompts") {
+		t.Fatalf("expected missing capability in message, got %s", body)
+	}
+
+	geminiRequest := []byte(`{"contents":[{"role":"user","parts":[{"inlineData":{"mimeType":"application/pdf","data":"AA=="}}]}]}`)
+	_, _, errMsg = handler.getRequestDetails("gemini", "capability-strict-model", geminiRequest)
+	if errMsg == nil || gjson.Get(errMsg.Error.Error(), "error.status").String() != "INVALID_ARGUMENT" {
+		t.Fatalf("expected Gemini-native capability error, got %v", errMsg)
+	}
+}
+
+func TestRequestExecutionMetadata_KeepsOnlyPayloadRuleHeaders(t *testing.T) {
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	ginCtx.Request.Header.Set("Authorization", "Bearer client-secret")
+	ginCtx.Request.Header.Set("X-Api-Key", "client-secret")
+	ginCtx.Request.Header.Set("X-Team", "research")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+
+	meta := requestExecutionMetadata(ctx, []string{"x-team"})
+	headers, _ := meta[coreexecutor.ClientHeadersMetadataKey].(http.Header)
+	if want := (http.Header{"X-Team": {"research"}}); !reflect.DeepEqual(headers, want) {
+		t.Fatalf("client headers = %v, want %v", headers, want)
+	}
+	if _, ok := requestExecutionMetadata(ctx, nil)[coreexecutor.ClientHeadersMetadataKey]; ok {
+		t.Fatal("client headers forwarded without payload rules matching on them")
+	}
+}
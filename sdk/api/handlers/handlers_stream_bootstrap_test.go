@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
 	coreexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
 	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
 )
 
 type failOnceStreamExecutor struct {
@@ -453,3 +455,108 @@ func TestExecuteStreamWithAuthManager_SelectedAuthCallbackReceivesAuthID(t *test
 		t.Fatalf("selectedAuthID = %q, want %q", selectedAuthID, "auth2")
 	}
 }
+
+type brokenThenContinuedStreamExecutor struct {
+	mu       sync.Mutex
+	authIDs  []string
+	payloads [][]byte
+}
+
+func (e *brokenThenContinuedStreamExecutor) Identifier() string { return "codex" }
+
+func (e *brokenThenContinuedStreamExecutor) Execute(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "Execute not implemented"}
+}
+
+func (e *brokenThenContinuedStreamExecutor) ExecuteStream(_ context.Context, auth *coreauth.Auth, req coreexecutor.Request, _ coreexecutor.Options) (<-chan coreexecutor.StreamChunk, error) {
+	e.mu.Lock()
+	e.authIDs = append(e.authIDs, auth.ID)
+	e.payloads = append(e.payloads, req.Payload)
+	call := len(e.authIDs)
+	e.mu.Unlock()
+
+	ch := make(chan coreexecutor.StreamChunk, 8)
+	if call == 1 {
+		ch <- coreexecutor.StreamChunk{Payload: []byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":10,\"output_tokens\":1}}}\n\n" +
+			"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")}
+		ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hello, wor\"}}\n\n")}
+		ch <- coreexecutor.StreamChunk{Err: &coreauth.Error{Code: "upstream_closed", Message: "upstream closed", HTTPStatus: http.StatusBadGateway}}
+		close(ch)
+		return ch, nil
+	}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"usage\":{\"input_tokens\":14,\"output_tokens\":1}}}\n\n")}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}\n\n")}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"ld!\"}}\n\n")}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}\n\n")}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"end_turn\"},\"usage\":{\"output_tokens\":2}}\n\n")}
+	ch <- coreexecutor.StreamChunk{Payload: []byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")}
+	close(ch)
+	return ch, nil
+}
+
+func (e *brokenThenContinuedStreamExecutor) Refresh(ctx context.Context, auth *coreauth.Auth) (*coreauth.Auth, error) {
+	return auth, nil
+}
+
+func (e *brokenThenContinuedStreamExecutor) CountTokens(context.Context, *coreauth.Auth, coreexecutor.Request, coreexecutor.Options) (coreexecutor.Response, error) {
+	return coreexecutor.Response{}, &coreauth.Error{Code: "not_implemented", Message: "CountTokens not implemented"}
+}
+
+func (e *brokenThenContinuedStreamExecutor) HttpRequest(ctx context.Context, auth *coreauth.Auth, req *http.Request) (*http.Response, error) {
+	return nil, &coreauth.Error{Code: "not_implemented", Message: "HttpRequest not implemented", HTTPStatus: http.StatusNotImplemented}
+}
+
+func TestExecuteStreamWithAuthManager_ContinuesAfterFirstByte(t *testing.T) {
+	executor := &brokenThenContinuedStreamExecutor{}
+	manager := coreauth.NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+
+	for _, id := range []string{"auth1", "auth2"} {
+		auth := &coreauth.Auth{ID: id, Provider: "codex", Status: coreauth.StatusActive}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("manager.Register(%s): %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "codex", []*registry.ModelInfo{{ID: "test-model"}})
+	}
+	t.Cleanup(func() {
+		registry.GetGlobalRegistry().UnregisterClient("auth1")
+		registry.GetGlobalRegistry().UnregisterClient("auth2")
+	})
+
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{
+		Streaming: sdkconfig.StreamingConfig{ContinuationRetries: 1},
+	}, manager)
+	dataChan, errChan := handler.ExecuteStreamWithAuthManager(context.Background(), "claude", "test-model", []byte(`{"model":"test-model","messages":[{"role":"user","content":"hi"}]}`), "")
+
+	var got strings.Builder
+	for chunk := range dataChan {
+		got.Write(chunk)
+	}
+	for msg := range errChan {
+		if msg != nil {
+			t.Fatalf("unexpected error: %+v", msg)
+		}
+	}
+
+	if len(executor.authIDs) != 2 || executor.authIDs[0] == executor.authIDs[1] {
+		t.Fatalf("expected continuation on another credential, got %v", executor.authIDs)
+	}
+	if prefill := gjson.GetBytes(executor.payloads[1], "messages.1"); prefill.Get("role").String() != "assistant" || prefill.Get("content.0.text").String() != "Hello, wor" {
+		t.Fatalf("continuation request lacks the partial answer: %s", executor.payloads[1])
+	}
+	out := got.String()
+	if strings.Count(out, `"type":"message_start"`) != 1 {
+		t.Fatalf("continuation preamble not dropped:\n%s", out)
+	}
+	for _, want := range []string{
+		`{"type":"content_block_stop","index":0}`,
+		`"index":1,"content_block":{"type":"text"`,
+		`"index":1,"delta":{"type":"text_delta","text":"ld!"}`,
+		`"output_tokens":3`,
+		`"input_tokens":24`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("spliced stream missing %s:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// ReasoningOutputHeader overrides the configured reasoning output mode for a single request.
+const ReasoningOutputHeader = "X-Reasoning-Output"
+
+const (
+	thinkOpenTag  = "<think>\n"
+	thinkCloseTag = "\n</think>\n\n"
+)
+
+// reasoningOutputMode resolves the reasoning output mode of a request: the request header wins
+// over the client key's mode, which wins over the configured default. It returns "" when no
+// mode is set, leaving responses as the translators produce them.
+func reasoningOutputMode(cfg *config.SDKConfig, c *gin.Context) string {
+	candidates := make([]string, 0, 3)
+	if c != nil && c.Request != nil {
+		candidates = append(candidates, c.GetHeader(ReasoningOutputHeader))
+	}
+	if cfg != nil {
+		if c != nil {
+			if apiKey, exists := c.Get("apiKey"); exists {
+				candidates = append(candidates, cfg.ReasoningOutput.APIKeys[fmt.Sprintf("%v", apiKey)])
+			}
+		}
+		candidates = append(candidates, cfg.ReasoningOutput.Mode)
+	}
+	for _, candidate := range candidates {
+		switch mode := strings.ToLower(strings.TrimSpace(candidate)); mode {
+		case config.ReasoningOutputContent, config.ReasoningOutputOmit, config.ReasoningOutputSummary, config.ReasoningOutputThinkTags:
+			return mode
+		}
+	}
+	return ""
+}
+
+// reasoningFormatter rewrites the reasoning of one chat completion response, streamed or not,
+// into the requested mode. Translators emit reasoning as reasoning_content or, for some
+// non-streaming responses, as a reasoning string; both are accepted. A nil formatter leaves
+// responses unchanged.
+type reasoningFormatter struct {
+	mode string
+	// open holds the choice indexes whose <think> block is still open.
+	open map[int64]bool
+	// last is the most recent chunk, used to build the chunk that closes a dangling block.
+	last []byte
+}
+
+func newReasoningFormatter(cfg *config.SDKConfig, c *gin.Context) *reasoningFormatter {
+	mode := reasoningOutputMode(cfg, c)
+	if mode == "" {
+		return nil
+	}
+	return &reasoningFormatter{mode: mode, open: make(map[int64]bool)}
+}
+
+func reasoningText(node gjson.Result) string {
+	if text := node.Get("reasoning_content"); text.Type == gjson.String {
+		return text.String()
+	}
+	if text := node.Get("reasoning"); text.Type == gjson.String {
+		return text.String()
+	}
+	return ""
+}
+
+// rewrite applies the mode to the message or delta at path. closing reports whether the
+// choice moves past its reasoning in this message, which closes an open <think> block.
+func (f *reasoningFormatter) rewrite(out []byte, path string, index int64, node gjson.Result, closing bool) []byte {
+	text := reasoningText(node)
+	out, _ = sjson.DeleteBytes(out, path+".reasoning_content")
+	if node.Get("reasoning").Type == gjson.String {
+		out, _ = sjson.DeleteBytes(out, path+".reasoning")
+	}
+	switch f.mode {
+	case config.ReasoningOutputContent:
+		if text != "" {
+			out, _ = sjson.SetBytes(out, path+".reasoning_content", text)
+		}
+	case config.ReasoningOutputOmit:
+		out, _ = sjson.DeleteBytes(out, path+".reasoning_details")
+	case config.ReasoningOutputSummary:
+		if text != "" {
+			out, _ = sjson.SetBytes(out, path+".reasoning", text)
+			item := `{"type":"reasoning.summary","summary":"","index":0}`
+			item, _ = sjson.Set(item, "summary", text)
+			item, _ = sjson.Set(item, "index", index)
+			out, _ = sjson.SetRawBytes(out, path+".reasoning_details", []byte("["+item+"]"))
+		}
+	case config.ReasoningOutputThinkTags:
+		var prefix strings.Builder
+		if text != "" {
+			if !f.open[index] {
+				prefix.WriteString(thinkOpenTag)
+				f.open[index] = true
+			}
+			prefix.WriteString(text)
+		}
+		if closing && f.open[index] {
+			prefix.WriteString(thinkCloseTag)
+			delete(f.open, index)
+		}
+		if prefix.Len() > 0 {
+			out, _ = sjson.SetBytes(out, path+".content", prefix.String()+node.Get("content").String())
+		}
+	}
+	return out
+}
+
+// formatResponse rewrites a non-streaming chat completion.
+func (f *reasoningFormatter) formatResponse(resp []byte) []byte {
+	if f == nil {
+		return resp
+	}
+	out := resp
+	gjson.GetBytes(resp, "choices").ForEach(func(key, choice gjson.Result) bool {
+		out = f.rewrite(out, "choices."+key.String()+".message", choice.Get("index").Int(), choice.Get("message"), true)
+		return true
+	})
+	return out
+}
+
+// formatChunk rewrites one streamed chat completion chunk.
+func (f *reasoningFormatter) formatChunk(chunk []byte) []byte {
+	if f == nil || !gjson.ValidBytes(chunk) {
+		return chunk
+	}
+	out := chunk
+	gjson.GetBytes(chunk, "choices").ForEach(func(key, choice gjson.Result) bool {
+		delta := choice.Get("delta")
+		closing := delta.Get("content").String() != "" || len(delta.Get("tool_calls").Array()) > 0 ||
+			(choice.Get("finish_reason").Exists() && choice.Get("finish_reason").Type != gjson.Null)
+		out = f.rewrite(out, "choices."+key.String()+".delta", choice.Get("index").Int(), delta, closing)
+		return true
+	})
+	f.last = out
+	return out
+}
+
+// finish returns a chunk closing any <think> block the stream left open, or nil.
+func (f *reasoningFormatter) finish() []byte {
+	if f == nil || len(f.open) == 0 || f.last == nil {
+		return nil
+	}
+	out, _ := sjson.SetRawBytes(f.last, "choices", []byte("[]"))
+	out, _ = sjson.DeleteBytes(out, "usage")
+	i := 0
+	for index := range f.open {
+		choice := `{"index":0,"delta":{"content":""},"finish_reason":null}`
+		choice, _ = sjson.Set(choice, "index", index)
+		choice, _ = sjson.Set(choice, "delta.content", thinkCloseTag)
+		out, _ = sjson.SetRawBytes(out, fmt.Sprintf("choices.%d", i), []byte(choice))
+		i++
+	}
+	f.open = make(map[int64]bool)
+	return out
+}
@@ -0,0 +1,123 @@
+package openai
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestReasoningOutputModePrecedence(t *testing.T) {
+	cfg := &sdkconfig.SDKConfig{ReasoningOutput: sdkconfig.ReasoningOutputConfig{
+		Mode:    sdkconfig.ReasoningOutputOmit,
+		APIKeys: map[string]string{"client-a": sdkconfig.ReasoningOutputThinkTags},
+	}}
+	newContext := func(apiKey, header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		if header != "" {
+			c.Request.Header.Set(ReasoningOutputHeader, header)
+		}
+		if apiKey != "" {
+			c.Set("apiKey", apiKey)
+		}
+		return c
+	}
+
+	cases := []struct {
+		apiKey, header, want string
+	}{
+		{"", "", sdkconfig.ReasoningOutputOmit},
+		{"client-a", "", sdkconfig.ReasoningOutputThinkTags},
+		{"client-a", "Reasoning", sdkconfig.ReasoningOutputSummary},
+		{"client-b", "bogus", sdkconfig.ReasoningOutputOmit},
+	}
+	for _, tc := range cases {
+		if got := reasoningOutputMode(cfg, newContext(tc.apiKey, tc.header)); got != tc.want {
+			t.Errorf("key %q header %q: mode = %q, want %q", tc.apiKey, tc.header, got, tc.want)
+		}
+	}
+	if got := reasoningOutputMode(nil, nil); got != "" {
+		t.Errorf("default mode = %q, want none", got)
+	}
+}
+
+func TestReasoningFormatterResponse(t *testing.T) {
+	resp := []byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"42","reasoning_content":"think hard"}}]}`)
+
+	omit := (&reasoningFormatter{mode: sdkconfig.ReasoningOutputOmit, open: map[int64]bool{}}).formatResponse(resp)
+	if gjson.GetBytes(omit, "choices.0.message.reasoning_content").Exists() || gjson.GetBytes(omit, "choices.0.message.content").String() != "42" {
+		t.Fatalf("omit: %s", omit)
+	}
+
+	summary := (&reasoningFormatter{mode: sdkconfig.ReasoningOutputSummary, open: map[int64]bool{}}).formatResponse(resp)
+	if gjson.GetBytes(summary, "choices.0.message.reasoning").String() != "think hard" ||
+		gjson.GetBytes(summary, "choices.0.message.reasoning_details.0.summary").String() != "think hard" ||
+		gjson.GetBytes(summary, "choices.0.message.reasoning_content").Exists() {
+		t.Fatalf("summary: %s", summary)
+	}
+
+	think := (&reasoningFormatter{mode: sdkconfig.ReasoningOutputThinkTags, open: map[int64]bool{}}).formatResponse(resp)
+	if got := gjson.GetBytes(think, "choices.0.message.content").String(); got != "<think>\nthink hard\n</think>\n\n42" {
+		t.Fatalf("think content = %q", got)
+	}
+
+	// The reasoning_content mode is opt-in and moves a reasoning string into reasoning_content.
+	legacy := []byte(`{"choices":[{"index":0,"message":{"role":"assistant","content":"42","reasoning":"think hard"}}]}`)
+	content := (&reasoningFormatter{mode: sdkconfig.ReasoningOutputContent, open: map[int64]bool{}}).formatResponse(legacy)
+	if gjson.GetBytes(content, "choices.0.message.reasoning_content").String() != "think hard" || gjson.GetBytes(content, "choices.0.message.reasoning").Exists() {
+		t.Fatalf("reasoning_content: %s", content)
+	}
+
+	var nilFormatter *reasoningFormatter
+	if string(nilFormatter.formatResponse(resp)) != string(resp) {
+		t.Fatal("nil formatter changed the response")
+	}
+}
+
+func TestReasoningFormatterStreamThinkTags(t *testing.T) {
+	f := &reasoningFormatter{mode: sdkconfig.ReasoningOutputThinkTags, open: map[int64]bool{}}
+	chunks := []string{
+		`{"id":"c1","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"a"}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"reasoning_content":"b"}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{"content":"done"}}]}`,
+		`{"id":"c1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	var content string
+	for _, chunk := range chunks {
+		out := f.formatChunk([]byte(chunk))
+		if gjson.GetBytes(out, "choices.0.delta.reasoning_content").Exists() {
+			t.Fatalf("reasoning_content left in %s", out)
+		}
+		content += gjson.GetBytes(out, "choices.0.delta.content").String()
+	}
+	if content != "<think>\nab\n</think>\n\ndone" {
+		t.Fatalf("streamed content = %q", content)
+	}
+	if f.finish() != nil {
+		t.Fatal("expected no closing chunk after the block was closed")
+	}
+
+	// A stream that ends inside its reasoning gets a synthetic closing chunk.
+	f = &reasoningFormatter{mode: sdkconfig.ReasoningOutputThinkTags, open: map[int64]bool{}}
+	f.formatChunk([]byte(`{"id":"c2","model":"m","choices":[{"index":0,"delta":{"reasoning_content":"a"}}]}`))
+	closing := f.finish()
+	if gjson.GetBytes(closing, "id").String() != "c2" || gjson.GetBytes(closing, "choices.0.delta.content").String() != "\n</think>\n\n" {
+		t.Fatalf("closing chunk = %s", closing)
+	}
+}
+
+func TestReasoningFormatterStreamSummary(t *testing.T) {
+	f := &reasoningFormatter{mode: sdkconfig.ReasoningOutputSummary, open: map[int64]bool{}}
+	out := f.formatChunk([]byte(`{"choices":[{"index":1,"delta":{"reasoning_content":"step"}}]}`))
+	if gjson.GetBytes(out, "choices.0.delta.reasoning").String() != "step" ||
+		gjson.GetBytes(out, "choices.0.delta.reasoning_details.0.type").String() != "reasoning.summary" ||
+		gjson.GetBytes(out, "choices.0.delta.reasoning_details.0.index").Int() != 1 {
+		t.Fatalf("summary chunk = %s", out)
+	}
+	if f.finish() != nil {
+		t.Fatal("summary mode should not synthesize a closing chunk")
+	}
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// continuationPrompt asks the model to resume an answer carried over as an assistant turn.
+const continuationPrompt = "Your previous response was cut off. Continue exactly where it stopped, without repeating any text or adding a preamble."
+
+// streamContinuation splices a stream that broke mid-answer with its continuation on another
+// attempt. It records the assistant text and usage the client already received, builds the
+// continuation request in the client's format, and rewrites the continuation's chunks so the
+// client sees a single response: the new attempt's preamble is dropped, Claude content block
+// indexes are shifted past the blocks already sent, and usage is summed across attempts.
+// Only answer text can be carried over, so once reasoning, tool calls or other non-text output
+// reached the client the stream is no longer continued.
+type streamContinuation struct {
+	format string
+	text   strings.Builder
+	// nonText is set once output other than answer text was sent to the client.
+	nonText bool
+
+	// attempt is 0 for the original stream and counts continuations after it.
+	attempt int
+	// responseID is the id of the original stream's first chunk, reused for continuations.
+	responseID string
+
+	// Claude content block bookkeeping across attempts.
+	blocks    int
+	offset    int
+	openBlock int
+
+	// prior sums the usage reported by earlier attempts; current holds the latest usage of
+	// the attempt being streamed.
+	prior   map[string]int64
+	current map[string]int64
+}
+
+// newStreamContinuation returns nil for formats whose streams cannot be spliced.
+func newStreamContinuation(handlerType, alt string) *streamContinuation {
+	switch handlerType {
+	case "openai", "claude":
+	case "gemini":
+		if alt != "" {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &streamContinuation{format: handlerType, openBlock: -1, prior: map[string]int64{}, current: map[string]int64{}}
+}
+
+// observe records a chunk about to be sent and returns it rewritten for the client. An empty
+// result means the chunk must be skipped.
+func (s *streamContinuation) observe(chunk []byte) []byte {
+	switch s.format {
+	case "claude":
+		return s.observeClaude(chunk)
+	case "gemini":
+		return s.observeJSON(chunk, "usageMetadata", func(data []byte) {
+			gjson.GetBytes(data, "candidates.0.content.parts").ForEach(func(_, part gjson.Result) bool {
+				if part.Get("thought").Bool() || !part.Get("text").Exists() {
+					s.nonText = true
+				} else {
+					s.text.WriteString(part.Get("text").String())
+				}
+				return true
+			})
+		})
+	default:
+		return s.observeJSON(chunk, "usage", func(data []byte) {
+			delta := gjson.GetBytes(data, "choices.0.delta")
+			for _, field := range []string{"reasoning_content", "reasoning", "reasoning_details", "tool_calls", "function_call"} {
+				if value := delta.Get(field); value.Exists() && value.Type != gjson.Null && value.String() != "" {
+					s.nonText = true
+				}
+			}
+			s.text.WriteString(delta.Get("content").String())
+		})
+	}
+}
+
+// begin prepares the next attempt and returns the bytes to send before its first chunk.
+func (s *streamContinuation) begin() []byte {
+	for field, value := range s.current {
+		s.prior[field] += value
+	}
+	s.current = map[string]int64{}
+	s.attempt++
+	s.offset = s.blocks
+	if s.format != "claude" || s.openBlock < 0 {
+		return nil
+	}
+	// The broken block never got its stop event; close it so the continuation opens a new one.
+	closing, _ := sjson.SetBytes([]byte(`{"type":"content_block_stop"}`), "index", s.openBlock)
+	s.openBlock = -1
+	return []byte("event: content_block_stop\ndata: " + string(closing) + "\n\n")
+}
+
+// request builds the continuation of original carrying the text already sent to the client. It
+// reports false when the output sent so far cannot be carried over.
+func (s *streamContinuation) request(original []byte) ([]byte, bool) {
+	if s.nonText {
+		return nil, false
+	}
+	partial := s.text.String()
+	if strings.TrimSpace(partial) == "" {
+		return original, true
+	}
+	var out []byte
+	var err error
+	switch s.format {
+	case "claude":
+		// Claude continues a trailing assistant turn verbatim, except while thinking is enabled.
+		thinkingType := gjson.GetBytes(original, "thinking.type").String()
+		prefill := thinkingType == "" || thinkingType == "disabled"
+		assistant, _ := sjson.SetBytes([]byte(`{"role":"assistant","content":[{"type":"text"}]}`), "content.0.text", strings.TrimRight(partial, " \t\r\n"))
+		if out, err = sjson.SetRawBytes(original, "messages.-1", assistant); err == nil && !prefill {
+			user, _ := sjson.SetBytes([]byte(`{"role":"user"}`), "content", continuationPrompt)
+			out, err = sjson.SetRawBytes(out, "messages.-1", user)
+		}
+	case "gemini":
+		model, _ := sjson.SetBytes([]byte(`{"role":"model","parts":[{}]}`), "parts.0.text", partial)
+		if out, err = sjson.SetRawBytes(original, "contents.-1", model); err == nil {
+			user, _ := sjson.SetBytes([]byte(`{"role":"user","parts":[{}]}`), "parts.0.text", continuationPrompt)
+			out, err = sjson.SetRawBytes(out, "contents.-1", user)
+		}
+	default:
+		assistant, _ := sjson.SetBytes([]byte(`{"role":"assistant"}`), "content", partial)
+		if out, err = sjson.SetRawBytes(original, "messages.-1", assistant); err == nil {
+			user, _ := sjson.SetBytes([]byte(`{"role":"user"}`), "content", continuationPrompt)
+			out, err = sjson.SetRawBytes(out, "messages.-1", user)
+		}
+	}
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// observeJSON handles formats streaming one JSON object per chunk with usage at usagePath.
+func (s *streamContinuation) observeJSON(chunk []byte, usagePath string, collect func([]byte)) []byte {
+	data := bytes.TrimSpace(chunk)
+	if len(data) == 0 || data[0] != '{' {
+		return chunk
+	}
+	collect(data)
+	if s.attempt == 0 {
+		if s.responseID == "" {
+			s.responseID = gjson.GetBytes(data, "id").String()
+		}
+		s.recordUsage(gjson.GetBytes(data, usagePath))
+		return chunk
+	}
+	out := data
+	if s.responseID != "" && gjson.GetBytes(out, "id").Exists() {
+		out, _ = sjson.SetBytes(out, "id", s.responseID)
+	}
+	if gjson.GetBytes(out, "choices.0.delta.role").Exists() {
+		out, _ = sjson.DeleteBytes(out, "choices.0.delta.role")
+	}
+	if usage := gjson.GetBytes(out, usagePath); usage.IsObject() {
+		s.recordUsage(usage)
+		out = s.mergeUsage(out, usagePath)
+	}
+	return out
+}
+
+// observeClaude handles Claude SSE chunks, which may carry several events.
+func (s *streamContinuation) observeClaude(chunk []byte) []byte {
+	events := bytes.SplitAfter(chunk, []byte("\n\n"))
+	out := make([]byte, 0, len(chunk))
+	for _, event := range events {
+		if len(bytes.TrimSpace(event)) == 0 {
+			out = append(out, event...)
+			continue
+		}
+		rewritten, keep := s.observeClaudeEvent(event)
+		if keep {
+			out = append(out, rewritten...)
+		}
+	}
+	return out
+}
+
+func (s *streamContinuation) observeClaudeEvent(event []byte) ([]byte, bool) {
+	lines := bytes.Split(event, []byte("\n"))
+	dataLine := -1
+	for i, line := range lines {
+		if bytes.HasPrefix(line, []byte("data:")) {
+			dataLine = i
+			break
+		}
+	}
+	if dataLine < 0 {
+		return event, true
+	}
+	data := bytes.TrimSpace(bytes.TrimPrefix(lines[dataLine], []byte("data:")))
+	if !gjson.ValidBytes(data) {
+		return event, true
+	}
+	switch gjson.GetBytes(data, "type").String() {
+	case "message_start":
+		s.recordUsage(gjson.GetBytes(data, "message.usage"))
+		if s.attempt > 0 {
+			return nil, false
+		}
+		return event, true
+	case "content_block_start", "content_block_delta", "content_block_stop":
+		index := int(gjson.GetBytes(data, "index").Int()) + s.offset
+		if s.offset > 0 {
+			data, _ = sjson.SetBytes(data, "index", index)
+		}
+		switch gjson.GetBytes(data, "type").String() {
+		case "content_block_start":
+			s.openBlock = index
+			s.blocks = max(s.blocks, index+1)
+			if gjson.GetBytes(data, "content_block.type").String() != "text" {
+				s.nonText = true
+			}
+		case "content_block_stop":
+			s.openBlock = -1
+		default:
+			if gjson.GetBytes(data, "delta.type").String() == "text_delta" {
+				s.text.WriteString(gjson.GetBytes(data, "delta.text").String())
+			} else {
+				s.nonText = true
+			}
+		}
+	case "message_delta":
+		s.recordUsage(gjson.GetBytes(data, "usage"))
+		if s.attempt > 0 {
+			data = s.mergeUsage(data, "usage")
+		}
+	default:
+		return event, true
+	}
+	if s.attempt == 0 {
+		return event, true
+	}
+	lines[dataLine] = append([]byte("data: "), data...)
+	return bytes.Join(lines, []byte("\n")), true
+}
+
+// recordUsage keeps the largest value of each integer usage field seen in the current attempt.
+func (s *streamContinuation) recordUsage(usage gjson.Result) {
+	usage.ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.Number {
+			s.current[key.String()] = max(s.current[key.String()], value.Int())
+		}
+		return true
+	})
+}
+
+// mergeUsage writes the usage summed across attempts into the object at path.
+func (s *streamContinuation) mergeUsage(data []byte, path string) []byte {
+	for field, value := range s.prior {
+		data, _ = sjson.SetBytes(data, path+"."+field, value+s.current[field])
+	}
+	return data
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestStreamContinuation_RefusesAfterNonTextOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		handlerType string
+		chunks      []string
+	}{
+		{
+			name:        "openai reasoning",
+			handlerType: "openai",
+			chunks: []string{
+				`{"id":"c1","choices":[{"delta":{"role":"assistant","reasoning_content":"Let me think"}}]}`,
+			},
+		},
+		{
+			name:        "openai tool call",
+			handlerType: "openai",
+			chunks: []string{
+				`{"id":"c1","choices":[{"delta":{"content":"Checking."}}]}`,
+				`{"id":"c1","choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"lookup","arguments":"{\"q\""}}]}}]}`,
+			},
+		},
+		{
+			name:        "claude thinking",
+			handlerType: "claude",
+			chunks: []string{
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}\n\n",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"Let me think\"}}\n\n",
+			},
+		},
+		{
+			name:        "claude tool use",
+			handlerType: "claude",
+			chunks: []string{
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"lookup\",\"input\":{}}}\n\n",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"q\\\"\"}}\n\n",
+			},
+		},
+		{
+			name:        "gemini thought",
+			handlerType: "gemini",
+			chunks: []string{
+				`{"candidates":[{"content":{"role":"model","parts":[{"text":"Let me think","thought":true}]}}]}`,
+			},
+		},
+		{
+			name:        "gemini function call",
+			handlerType: "gemini",
+			chunks: []string{
+				`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"lookup","args":{}}}]}}]}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStreamContinuation(tt.handlerType, "")
+			for _, chunk := range tt.chunks {
+				s.observe([]byte(chunk))
+			}
+			if out, ok := s.request([]byte(`{"messages":[{"role":"user","content":"hi"}]}`)); ok {
+				t.Fatalf("expected continuation to be refused, got %s", out)
+			}
+		})
+	}
+}
+
+func TestStreamContinuation_CarriesOpenAIText(t *testing.T) {
+	s := newStreamContinuation("openai", "")
+	s.observe([]byte(`{"id":"c1","choices":[{"delta":{"role":"assistant","content":"Hello, ","reasoning_content":null}}]}`))
+	s.observe([]byte(`{"id":"c1","choices":[{"delta":{"content":"wor"}}]}`))
+
+	out, ok := s.request([]byte(`{"messages":[{"role":"user","content":"hi"}]}`))
+	if !ok {
+		t.Fatal("expected continuation request")
+	}
+	if got := gjson.GetBytes(out, "messages.1.content").String(); got != "Hello, wor" {
+		t.Fatalf("assistant turn = %q, want %q", got, "Hello, wor")
+	}
+	if got := gjson.GetBytes(out, "messages.2.role").String(); got != "user" {
+		t.Fatalf("expected continuation prompt as user turn, got %s", out)
+	}
+}
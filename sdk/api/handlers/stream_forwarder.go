@@ -61,12 +61,35 @@ func (h *BaseAPIHandler) ForwardStream(c *gin.Context, flusher http.Flusher, can
 		keepAliveC = keepAlive.C
 	}
 
+	// A resumable stream outlives its client: after a disconnect the upstream keeps being
+	// buffered until it ends or nobody reattached within the grace period.
+	stream := resumableStreamFor(c)
+	clientDone := c.Request.Context().Done()
+	var abandonC <-chan time.Time
+	if stream != nil {
+		defer stream.finish()
+	}
+
 	var terminalErr *interfaces.ErrorMessage
 	for {
 		select {
-		case <-c.Request.Context().Done():
-			cancel(c.Request.Context().Err())
-			return
+		case <-clientDone:
+			writer, ok := c.Writer.(*resumeWriter)
+			if stream == nil || !ok {
+				cancel(c.Request.Context().Err())
+				return
+			}
+			writer.disconnect()
+			clientDone = nil
+			keepAliveC = nil
+			abandon := time.NewTicker(time.Second)
+			defer abandon.Stop()
+			abandonC = abandon.C
+		case <-abandonC:
+			if stream.abandoned() {
+				cancel(c.Request.Context().Err())
+				return
+			}
 		case chunk, ok := <-data:
 			if !ok {
 				// Prefer surfacing a terminal error if one is pending.
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/logging"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// StreamIDHeader carries the ID under which a resumable stream can be reattached.
+const StreamIDHeader = "X-Stream-ID"
+
+// StreamTokenHeader carries the secret a client must present to reattach to a resumable stream.
+// It is sent only on the original response, so knowing the stream ID is not enough to read it.
+const StreamTokenHeader = "X-Stream-Token"
+
+const (
+	resumableStreamContextKey      = "resumable_stream"
+	defaultStreamResumeBufferBytes = 4 << 20
+)
+
+// resumableStreams holds live and recently finished resumable streams by ID.
+var resumableStreams sync.Map
+
+// StreamResumeGrace returns how long a stream outlives its client, or 0 when streams are not resumable.
+func StreamResumeGrace(cfg *config.SDKConfig) time.Duration {
+	if cfg == nil || cfg.Streaming.ResumeGraceSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.Streaming.ResumeGraceSeconds) * time.Second
+}
+
+// resumableStream buffers the SSE events of one response so that a client can reattach with
+// Last-Event-ID and receive what it missed. Event IDs are sequence numbers starting at 1.
+type resumableStream struct {
+	id     string
+	token  string
+	apiKey string
+	grace  time.Duration
+
+	mu         sync.Mutex
+	events     [][]byte
+	first      int
+	size       int
+	limit      int
+	done       bool
+	readers    int
+	detachedAt time.Time
+	changed    chan struct{}
+}
+
+// append stores a complete SSE event, evicting the oldest ones beyond the buffer limit, and
+// returns its sequence number.
+func (s *resumableStream) append(event []byte) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	s.size += len(event)
+	for s.size > s.limit && len(s.events) > 1 {
+		s.size -= len(s.events[0])
+		s.events[0] = nil
+		s.events = s.events[1:]
+		s.first++
+	}
+	s.notifyLocked()
+	return s.first + len(s.events) - 1
+}
+
+// since returns the buffered events after seq with the sequence number of the first one, the
+// channel closed on the next change, and whether the stream has finished. ok is false when
+// events after seq were already evicted.
+func (s *resumableStream) since(seq int) (events [][]byte, next int, changed <-chan struct{}, done, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if seq+1 < s.first {
+		return nil, 0, nil, s.done, false
+	}
+	start := seq + 1 - s.first
+	if start < len(s.events) {
+		events = append(events, s.events[start:]...)
+	}
+	return events, seq + 1, s.changed, s.done, true
+}
+
+func (s *resumableStream) attach() {
+	s.mu.Lock()
+	s.readers++
+	s.mu.Unlock()
+}
+
+func (s *resumableStream) detach() {
+	s.mu.Lock()
+	s.readers--
+	if s.readers <= 0 {
+		s.readers = 0
+		s.detachedAt = time.Now()
+	}
+	s.mu.Unlock()
+}
+
+// abandoned reports whether no client has been attached for the whole grace period.
+func (s *resumableStream) abandoned() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readers == 0 && time.Since(s.detachedAt) >= s.grace
+}
+
+// finish marks the stream complete and keeps it replayable for the grace period.
+func (s *resumableStream) finish() {
+	s.mu.Lock()
+	if s.done {
+		s.mu.Unlock()
+		return
+	}
+	s.done = true
+	s.notifyLocked()
+	s.mu.Unlock()
+	time.AfterFunc(s.grace, func() { resumableStreams.CompareAndDelete(s.id, s) })
+}
+
+func (s *resumableStream) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// BeginResumableStream makes the SSE response of c resumable when enabled in the config. It must
+// be called after the SSE headers are set and before the first event is written: from then on
+// writes to c.Writer are split into events, numbered, buffered and forwarded to the client while
+// it stays connected.
+func (h *BaseAPIHandler) BeginResumableStream(c *gin.Context) {
+	grace := StreamResumeGrace(h.Cfg)
+	if grace <= 0 || c == nil {
+		return
+	}
+	id := logging.GetGinRequestID(c)
+	if id == "" {
+		id = uuid.NewString()
+	}
+	limit := h.Cfg.Streaming.ResumeBufferBytes
+	if limit <= 0 {
+		limit = defaultStreamResumeBufferBytes
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Warnf("resumable stream disabled: generate token: %v", err)
+		return
+	}
+	token := hex.EncodeToString(secret)
+	stream := &resumableStream{id: id, token: token, apiKey: ginAPIKey(c), grace: grace, first: 1, limit: limit, readers: 1, changed: make(chan struct{})}
+	if _, loaded := resumableStreams.LoadOrStore(id, stream); loaded {
+		id = uuid.NewString()
+		stream.id = id
+		resumableStreams.Store(id, stream)
+	}
+	c.Header(StreamIDHeader, id)
+	c.Header(StreamTokenHeader, token)
+	c.Writer = &resumeWriter{ResponseWriter: c.Writer, stream: stream, live: true}
+	c.Set(resumableStreamContextKey, stream)
+}
+
+func resumableStreamFor(c *gin.Context) *resumableStream {
+	if c == nil {
+		return nil
+	}
+	value, ok := c.Get(resumableStreamContextKey)
+	if !ok {
+		return nil
+	}
+	stream, _ := value.(*resumableStream)
+	return stream
+}
+
+// resumeWriter splits the bytes a handler writes into SSE events, records them in the stream
+// buffer and forwards them with an "id:" line while the original client is connected. Comment
+// events such as keep-alives are forwarded but not buffered.
+type resumeWriter struct {
+	gin.ResponseWriter
+	stream  *resumableStream
+	pending []byte
+	live    bool
+}
+
+func (w *resumeWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		// Handlers separate events inconsistently; blank lines between events carry nothing.
+		w.pending = bytes.TrimLeft(w.pending, "\r\n")
+		end := bytes.Index(w.pending, []byte("\n\n"))
+		if end < 0 {
+			break
+		}
+		event := bytes.Clone(w.pending[:end+2])
+		w.pending = w.pending[end+2:]
+		if bytes.HasPrefix(event, []byte(":")) {
+			if w.live {
+				_, _ = w.ResponseWriter.Write(event)
+			}
+			continue
+		}
+		seq := w.stream.append(event)
+		if w.live {
+			_, _ = w.ResponseWriter.Write(formatResumeEvent(seq, event))
+		}
+	}
+	return len(p), nil
+}
+
+func (w *resumeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *resumeWriter) Flush() {
+	if w.live {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// disconnect stops forwarding to the original client; events keep being buffered.
+func (w *resumeWriter) disconnect() {
+	if w.live {
+		w.live = false
+		w.stream.detach()
+	}
+}
+
+func formatResumeEvent(seq int, event []byte) []byte {
+	out := make([]byte, 0, len(event)+16)
+	out = append(out, "id: "...)
+	out = strconv.AppendInt(out, int64(seq), 10)
+	out = append(out, '\n')
+	return append(out, event...)
+}
+
+// ResumeStream reattaches a client to a resumable stream: events after the Last-Event-ID header
+// (or last_event_id query parameter) are replayed, followed by the live tail. The request must
+// carry the stream's X-Stream-Token and come from the API key that started it.
+func (h *BaseAPIHandler) ResumeStream(c *gin.Context) {
+	value, ok := resumableStreams.Load(c.Param("id"))
+	stream, _ := value.(*resumableStream)
+	token := strings.TrimSpace(c.GetHeader(StreamTokenHeader))
+	if !ok || stream == nil || stream.apiKey != ginAPIKey(c) || subtle.ConstantTimeCompare([]byte(token), []byte(stream.token)) != 1 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: ErrorDetail{Message: "stream not found or expired", Type: "invalid_request_error", Code: "stream_not_found"}})
+		return
+	}
+	lastID := strings.TrimSpace(c.GetHeader("Last-Event-ID"))
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	seq := 0
+	if lastID != "" {
+		parsed, err := strconv.Atoi(lastID)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: ErrorDetail{Message: "invalid Last-Event-ID", Type: "invalid_request_error"}})
+			return
+		}
+		seq = parsed
+	}
+	if _, _, _, _, ok = stream.since(seq); !ok {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: ErrorDetail{Message: "events after Last-Event-ID are no longer buffered", Type: "invalid_request_error", Code: "stream_events_evicted"}})
+		return
+	}
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: ErrorDetail{Message: "Streaming not supported", Type: "server_error"}})
+		return
+	}
+
+	stream.attach()
+	defer stream.detach()
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header(StreamIDHeader, stream.id)
+	c.Status(http.StatusOK)
+	flusher.Flush()
+
+	var keepAliveC <-chan time.Time
+	if interval := StreamingKeepAliveInterval(h.Cfg); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		keepAliveC = ticker.C
+	}
+	for {
+		events, next, changed, done, ok := stream.since(seq)
+		if !ok {
+			log.Warnf("resumed stream %s fell behind its buffer, closing", stream.id)
+			return
+		}
+		for i, event := range events {
+			_, _ = c.Writer.Write(formatResumeEvent(next+i, event))
+		}
+		seq = next + len(events) - 1
+		flusher.Flush()
+		if done {
+			return
+		}
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-changed:
+		case <-keepAliveC:
+			_, _ = c.Writer.Write([]byte(": keep-alive\n\n"))
+			flusher.Flush()
+		}
+	}
+}
+
+func ginAPIKey(c *gin.Context) string {
+	if value, exists := c.Get("apiKey"); exists {
+		if key, ok := value.(string); ok {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestResumableStreamReplaysAfterDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewBaseAPIHandlers(&sdkconfig.SDKConfig{Streaming: sdkconfig.StreamingConfig{ResumeGraceSeconds: 30}}, nil)
+
+	clientCtx, disconnect := context.WithCancel(context.Background())
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil).WithContext(clientCtx)
+	c.Set("apiKey", "key-a")
+	flusher := c.Writer.(http.Flusher)
+
+	h.BeginResumableStream(c)
+	streamID := recorder.Header().Get(StreamIDHeader)
+	streamToken := recorder.Header().Get(StreamTokenHeader)
+	if streamID == "" || streamToken == "" {
+		t.Fatal("missing stream ID or token header")
+	}
+	_, _ = c.Writer.Write([]byte("data: one\n\n"))
+
+	data := make(chan []byte)
+	errs := make(chan *interfaces.ErrorMessage)
+	cancelled := make(chan error, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		h.ForwardStream(c, flusher, func(err error) { cancelled <- err }, data, errs, StreamForwardOptions{
+			WriteChunk: func(chunk []byte) { _, _ = c.Writer.Write(append(append([]byte("data: "), chunk...), "\n\n"...)) },
+			WriteDone:  func() { _, _ = c.Writer.Write([]byte("data: [DONE]\n\n")) },
+		})
+	}()
+
+	disconnect()
+	stream := resumableStreamFor(c)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stream.mu.Lock()
+		readers := stream.readers
+		stream.mu.Unlock()
+		if readers == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream was not detached after the client disconnected")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	data <- []byte("two")
+	close(data)
+	<-finished
+	if err := <-cancelled; err != nil {
+		t.Fatalf("upstream cancelled after disconnect: %v", err)
+	}
+	if body := recorder.Body.String(); body != "id: 1\ndata: one\n\n" {
+		t.Fatalf("live body = %q", body)
+	}
+
+	resume := func(apiKey, token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rc, _ := gin.CreateTestContext(rec)
+		rc.Request = httptest.NewRequest(http.MethodGet, "/v1/streams/"+streamID, nil)
+		rc.Request.Header.Set("Last-Event-ID", "1")
+		rc.Request.Header.Set(StreamTokenHeader, token)
+		rc.Params = gin.Params{{Key: "id", Value: streamID}}
+		rc.Set("apiKey", apiKey)
+		h.ResumeStream(rc)
+		return rec
+	}
+	if rec := resume("key-b", streamToken); rec.Code != http.StatusNotFound {
+		t.Fatalf("other client resumed the stream: %d", rec.Code)
+	}
+	if rec := resume("key-a", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("stream resumed without its token: %d", rec.Code)
+	}
+	if rec := resume("key-a", strings.Repeat("0", len(streamToken))); rec.Code != http.StatusNotFound {
+		t.Fatalf("stream resumed with a wrong token: %d", rec.Code)
+	}
+	rec := resume("key-a", streamToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("resume status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if body := rec.Body.String(); !strings.HasPrefix(body, "id: 2\ndata: two\n\nid: 3\ndata: [DONE]\n\n") || strings.Contains(body, "one") {
+		t.Fatalf("replayed body = %q", body)
+	}
+}
@@ -0,0 +1,498 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/structured"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const (
+	// structuredOutputToolName is the function a model is forced to call when structured
+	// output is emulated with the tool strategy.
+	structuredOutputToolName = "structured_output"
+	structuredOutputToolDesc = "Return the final answer. The arguments of this call are the answer."
+
+	structuredStrategyTool   = "tool"
+	structuredStrategyPrompt = "prompt"
+
+	defaultStructuredOutputRepairRetries = 1
+)
+
+// StructuredOutputRepairRetries returns how many times a response failing schema validation
+// is requested again.
+func StructuredOutputRepairRetries(cfg *config.SDKConfig) int {
+	retries := defaultStructuredOutputRepairRetries
+	if cfg != nil && cfg.StructuredOutput.RepairRetries != 0 {
+		retries = cfg.StructuredOutput.RepairRetries
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	return retries
+}
+
+func structuredOutputDisabled(cfg *config.SDKConfig) bool {
+	return cfg != nil && cfg.StructuredOutput.Disable
+}
+
+// structuredOutputPlan carries a request's structured-output spec through execution.
+type structuredOutputPlan struct {
+	handlerType string
+	spec        structured.Spec
+	// strategy is the emulation strategy, or empty when the schema is forwarded natively.
+	strategy string
+	retries  int
+	// request is the payload to execute, rewritten when the schema is emulated.
+	request []byte
+}
+
+// planStructuredOutput returns nil when rawJSON does not ask for structured output. When any
+// provider serving the model lacks native support, the request is rewritten in the client's
+// own format so the schema survives translation.
+func (h *BaseAPIHandler) planStructuredOutput(handlerType, modelName string, providers []string, rawJSON []byte, stream bool) *structuredOutputPlan {
+	if structuredOutputDisabled(h.Cfg) {
+		return nil
+	}
+	spec, ok := structured.Parse(handlerType, rawJSON)
+	if !ok {
+		return nil
+	}
+	plan := &structuredOutputPlan{
+		handlerType: handlerType,
+		spec:        spec,
+		retries:     StructuredOutputRepairRetries(h.Cfg),
+		request:     rawJSON,
+	}
+	baseModel := thinking.ParseSuffix(modelName).ModelName
+	if !needsStructuredOutputEmulation(handlerType, baseModel, providers) {
+		return plan
+	}
+	var strategy string
+	if h.Cfg != nil {
+		strategy = h.Cfg.StructuredOutput.Strategy
+	}
+	plan.strategy = chooseStructuredStrategy(strategy, handlerType, spec, modelName, rawJSON, stream)
+	plan.request = emulateStructuredOutput(handlerType, spec, plan.strategy, rawJSON)
+	return plan
+}
+
+// needsStructuredOutputEmulation reports whether any provider would lose the schema. Claude and
+// Bedrock only accept it from Claude-format clients, AI Studio strips it, Antigravity rejects
+// it, and model definitions may declare that a model lacks JSON schema support.
+func needsStructuredOutputEmulation(handlerType, baseModel string, providers []string) bool {
+	reg := registry.GetGlobalRegistry()
+	for _, provider := range providers {
+		switch provider {
+		case "claude", "bedrock":
+			if handlerType != constant.Claude {
+				return true
+			}
+		case "aistudio", "antigravity":
+			return true
+		}
+		if !reg.GetModelInfo(baseModel, provider).SupportsCapability(registry.CapabilityJSONSchema) {
+			return true
+		}
+	}
+	return false
+}
+
+// chooseStructuredStrategy picks the tool strategy only for non-streaming requests whose
+// schema describes an object; "auto" additionally avoids it when the request brings its own
+// tools or reasoning, since forcing a tool call disables both.
+func chooseStructuredStrategy(configured, handlerType string, spec structured.Spec, modelName string, rawJSON []byte, stream bool) string {
+	if stream {
+		return structuredStrategyPrompt
+	}
+	if !spec.JSONOnly() && gjson.Get(spec.Schema, "type").String() != "object" {
+		return structuredStrategyPrompt
+	}
+	switch strings.ToLower(strings.TrimSpace(configured)) {
+	case structuredStrategyTool:
+		return structuredStrategyTool
+	case structuredStrategyPrompt:
+		return structuredStrategyPrompt
+	}
+	root, _ := structuredRequestRoot(handlerType, rawJSON)
+	if thinking.ParseSuffix(modelName).HasSuffix {
+		return structuredStrategyPrompt
+	}
+	switch handlerType {
+	case constant.OpenAI:
+		if len(root.Get("tools").Array()) > 0 || root.Get("reasoning_effort").Exists() {
+			return structuredStrategyPrompt
+		}
+	case constant.OpenaiResponse:
+		if len(root.Get("tools").Array()) > 0 || root.Get("reasoning").Exists() {
+			return structuredStrategyPrompt
+		}
+	case constant.Claude:
+		if len(root.Get("tools").Array()) > 0 || (root.Get("thinking").Exists() && root.Get("thinking.type").String() != "disabled") {
+			return structuredStrategyPrompt
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		if len(root.Get("tools").Array()) > 0 || root.Get("generationConfig.thinkingConfig").Exists() {
+			return structuredStrategyPrompt
+		}
+	}
+	return structuredStrategyTool
+}
+
+// structuredRequestRoot returns the request object of rawJSON and the sjson path prefix that
+// addresses it; Gemini CLI requests are wrapped in "request".
+func structuredRequestRoot(handlerType string, rawJSON []byte) (gjson.Result, string) {
+	if handlerType == constant.GeminiCLI {
+		return gjson.GetBytes(rawJSON, "request"), "request."
+	}
+	return gjson.ParseBytes(rawJSON), ""
+}
+
+// emulateStructuredOutput removes the native structured-output field from a request and asks
+// for the same JSON through a forced tool call or the system prompt.
+func emulateStructuredOutput(handlerType string, spec structured.Spec, strategy string, rawJSON []byte) []byte {
+	out := rawJSON
+	root, prefix := structuredRequestRoot(handlerType, rawJSON)
+	schema := spec.Schema
+	if spec.JSONOnly() {
+		schema = `{"type":"object"}`
+	}
+
+	switch handlerType {
+	case constant.OpenAI:
+		out, _ = sjson.DeleteBytes(out, "response_format")
+		if strategy == structuredStrategyTool {
+			tool := `{"type":"function","function":{"name":"","description":""}}`
+			tool, _ = sjson.Set(tool, "function.name", structuredOutputToolName)
+			tool, _ = sjson.Set(tool, "function.description", structuredOutputToolDesc)
+			tool, _ = sjson.SetRaw(tool, "function.parameters", schema)
+			out, _ = sjson.SetRawBytes(out, "tools.-1", []byte(tool))
+			out, _ = sjson.SetRawBytes(out, "tool_choice", []byte(`{"type":"function","function":{"name":"`+structuredOutputToolName+`"}}`))
+			return out
+		}
+
+	case constant.OpenaiResponse:
+		out, _ = sjson.DeleteBytes(out, "text.format")
+		if len(root.Get("text").Map()) == 1 {
+			out, _ = sjson.DeleteBytes(out, "text")
+		}
+		if strategy == structuredStrategyTool {
+			tool := `{"type":"function","name":"","description":""}`
+			tool, _ = sjson.Set(tool, "name", structuredOutputToolName)
+			tool, _ = sjson.Set(tool, "description", structuredOutputToolDesc)
+			tool, _ = sjson.SetRaw(tool, "parameters", schema)
+			out, _ = sjson.SetRawBytes(out, "tools.-1", []byte(tool))
+			out, _ = sjson.SetRawBytes(out, "tool_choice", []byte(`{"type":"function","name":"`+structuredOutputToolName+`"}`))
+			return out
+		}
+
+	case constant.Claude:
+		out, _ = sjson.DeleteBytes(out, "output_format")
+		out, _ = sjson.DeleteBytes(out, "output_config.format")
+		if strategy == structuredStrategyTool {
+			tool := `{"name":"","description":""}`
+			tool, _ = sjson.Set(tool, "name", structuredOutputToolName)
+			tool, _ = sjson.Set(tool, "description", structuredOutputToolDesc)
+			tool, _ = sjson.SetRaw(tool, "input_schema", schema)
+			out, _ = sjson.SetRawBytes(out, "tools.-1", []byte(tool))
+			out, _ = sjson.SetRawBytes(out, "tool_choice", []byte(`{"type":"tool","name":"`+structuredOutputToolName+`"}`))
+			return out
+		}
+
+	case constant.Gemini, constant.GeminiCLI:
+		configPath := prefix + "generationConfig"
+		if !root.Get("generationConfig").Exists() && root.Get("generation_config").Exists() {
+			configPath = prefix + "generation_config"
+		}
+		for _, field := range []string{"responseMimeType", "response_mime_type", "responseJsonSchema", "response_json_schema", "responseSchema", "response_schema"} {
+			out, _ = sjson.DeleteBytes(out, configPath+"."+field)
+		}
+		if strategy == structuredStrategyTool {
+			decl := `{"name":"","description":""}`
+			decl, _ = sjson.Set(decl, "name", structuredOutputToolName)
+			decl, _ = sjson.Set(decl, "description", structuredOutputToolDesc)
+			decl, _ = sjson.SetRaw(decl, "parametersJsonSchema", schema)
+			out, _ = sjson.SetRawBytes(out, prefix+"tools.-1", []byte(`{"functionDeclarations":[`+decl+`]}`))
+			out, _ = sjson.SetRawBytes(out, prefix+"toolConfig", []byte(`{"functionCallingConfig":{"mode":"ANY","allowedFunctionNames":["`+structuredOutputToolName+`"]}}`))
+			return out
+		}
+	}
+	return appendSystemInstruction(handlerType, out, spec.Instruction())
+}
+
+// appendSystemInstruction adds text to the system prompt of a request in the client's format.
+func appendSystemInstruction(handlerType string, rawJSON []byte, text string) []byte {
+	out := rawJSON
+	root, prefix := structuredRequestRoot(handlerType, rawJSON)
+	switch handlerType {
+	case constant.OpenAI:
+		messages := []string{`{"role":"system","content":""}`}
+		messages[0], _ = sjson.Set(messages[0], "content", text)
+		root.Get("messages").ForEach(func(_, msg gjson.Result) bool {
+			messages = append(messages, msg.Raw)
+			return true
+		})
+		out, _ = sjson.SetRawBytes(out, "messages", []byte("["+strings.Join(messages, ",")+"]"))
+	case constant.OpenaiResponse:
+		out, _ = sjson.SetBytes(out, "instructions", joinInstruction(root.Get("instructions").String(), text))
+	case constant.Claude:
+		system := root.Get("system")
+		if system.IsArray() {
+			block := `{"type":"text","text":""}`
+			block, _ = sjson.Set(block, "text", text)
+			out, _ = sjson.SetRawBytes(out, "system.-1", []byte(block))
+		} else {
+			out, _ = sjson.SetBytes(out, "system", joinInstruction(system.String(), text))
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		systemPath := prefix + "systemInstruction"
+		if !root.Get("systemInstruction").Exists() && root.Get("system_instruction").Exists() {
+			systemPath = prefix + "system_instruction"
+		}
+		part := `{"text":""}`
+		part, _ = sjson.Set(part, "text", text)
+		out, _ = sjson.SetRawBytes(out, systemPath+".parts.-1", []byte(part))
+	}
+	return out
+}
+
+func joinInstruction(existing, instruction string) string {
+	if strings.TrimSpace(existing) == "" {
+		return instruction
+	}
+	return existing + "\n\n" + instruction
+}
+
+// execute runs the request and validates the answer against the schema, asking the model to
+// repair an invalid answer up to the configured number of times. Answers produced through the
+// forced tool call are returned as ordinary text in the client's response format.
+func (p *structuredOutputPlan) execute(run func(payload []byte) ([]byte, *interfaces.ErrorMessage)) ([]byte, *interfaces.ErrorMessage) {
+	request := p.request
+	for attempt := 0; ; attempt++ {
+		resp, errMsg := run(request)
+		if errMsg != nil {
+			return nil, errMsg
+		}
+		answer, ok := structuredAnswer(p.handlerType, resp)
+		if !ok {
+			// The model called one of the client's own tools; there is no answer to check yet.
+			return resp, nil
+		}
+		cleaned := structured.StripCodeFence(answer)
+		errValidate := p.spec.Validate(cleaned)
+		if errValidate == nil {
+			if cleaned != answer || p.strategy == structuredStrategyTool {
+				resp = replaceStructuredAnswer(p.handlerType, resp, strings.TrimSpace(cleaned))
+			}
+			return resp, nil
+		}
+		if attempt >= p.retries {
+			return nil, nativeErrorMessage(p.handlerType, http.StatusBadGateway, fmt.Sprintf("model response does not match the requested JSON schema: %v", errValidate))
+		}
+		log.Debugf("structured output: response failed validation (attempt %d): %v", attempt+1, errValidate)
+		request = appendStructuredRepair(p.handlerType, request, answer, errValidate.Error())
+	}
+}
+
+// structuredAnswer extracts the answer from a non-streaming response in the client's format:
+// the arguments of the forced tool call if present, otherwise the answer text. ok is false
+// when the response only calls other tools.
+func structuredAnswer(handlerType string, resp []byte) (answer string, ok bool) {
+	var text strings.Builder
+	otherTools := false
+	switch handlerType {
+	case constant.OpenAI:
+		message := gjson.GetBytes(resp, "choices.0.message")
+		for _, call := range message.Get("tool_calls").Array() {
+			if call.Get("function.name").String() == structuredOutputToolName {
+				return call.Get("function.arguments").String(), true
+			}
+			otherTools = true
+		}
+		content := message.Get("content")
+		if content.IsArray() {
+			content.ForEach(func(_, part gjson.Result) bool {
+				if part.Get("type").String() == "text" {
+					text.WriteString(part.Get("text").String())
+				}
+				return true
+			})
+		} else {
+			text.WriteString(content.String())
+		}
+	case constant.OpenaiResponse:
+		for _, item := range gjson.GetBytes(resp, "output").Array() {
+			switch item.Get("type").String() {
+			case "function_call":
+				if item.Get("name").String() == structuredOutputToolName {
+					return item.Get("arguments").String(), true
+				}
+				otherTools = true
+			case "message":
+				item.Get("content").ForEach(func(_, part gjson.Result) bool {
+					if part.Get("type").String() == "output_text" {
+						text.WriteString(part.Get("text").String())
+					}
+					return true
+				})
+			}
+		}
+	case constant.Claude:
+		for _, block := range gjson.GetBytes(resp, "content").Array() {
+			switch block.Get("type").String() {
+			case "tool_use":
+				if block.Get("name").String() == structuredOutputToolName {
+					return block.Get("input").Raw, true
+				}
+				otherTools = true
+			case "text":
+				text.WriteString(block.Get("text").String())
+			}
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		path := "candidates.0.content.parts"
+		if handlerType == constant.GeminiCLI {
+			path = "response." + path
+		}
+		for _, part := range gjson.GetBytes(resp, path).Array() {
+			if call := part.Get("functionCall"); call.Exists() {
+				if call.Get("name").String() == structuredOutputToolName {
+					return call.Get("args").Raw, true
+				}
+				otherTools = true
+				continue
+			}
+			if !part.Get("thought").Bool() {
+				text.WriteString(part.Get("text").String())
+			}
+		}
+	default:
+		return "", false
+	}
+	if text.Len() == 0 && otherTools {
+		return "", false
+	}
+	return text.String(), true
+}
+
+// replaceStructuredAnswer makes text the only answer of a non-streaming response, dropping the
+// forced tool call and any earlier answer text while keeping reasoning content.
+func replaceStructuredAnswer(handlerType string, resp []byte, text string) []byte {
+	out := resp
+	switch handlerType {
+	case constant.OpenAI:
+		out, _ = sjson.DeleteBytes(out, "choices.0.message.tool_calls")
+		out, _ = sjson.SetBytes(out, "choices.0.message.content", text)
+		if gjson.GetBytes(out, "choices.0.finish_reason").String() == "tool_calls" {
+			out, _ = sjson.SetBytes(out, "choices.0.finish_reason", "stop")
+		}
+	case constant.OpenaiResponse:
+		items := make([]string, 0)
+		messageID := ""
+		for _, item := range gjson.GetBytes(resp, "output").Array() {
+			switch item.Get("type").String() {
+			case "message":
+				if messageID == "" {
+					messageID = item.Get("id").String()
+				}
+				continue
+			case "function_call":
+				if item.Get("name").String() == structuredOutputToolName {
+					if messageID == "" {
+						messageID = item.Get("id").String()
+					}
+					continue
+				}
+			}
+			items = append(items, item.Raw)
+		}
+		message := `{"type":"message","id":"","status":"completed","role":"assistant","content":[{"type":"output_text","annotations":[],"text":""}]}`
+		message, _ = sjson.Set(message, "id", messageID)
+		message, _ = sjson.Set(message, "content.0.text", text)
+		items = append(items, message)
+		out, _ = sjson.SetRawBytes(out, "output", []byte("["+strings.Join(items, ",")+"]"))
+	case constant.Claude:
+		blocks := make([]string, 0)
+		for _, block := range gjson.GetBytes(resp, "content").Array() {
+			switch block.Get("type").String() {
+			case "text":
+				continue
+			case "tool_use":
+				if block.Get("name").String() == structuredOutputToolName {
+					continue
+				}
+			}
+			blocks = append(blocks, block.Raw)
+		}
+		block := `{"type":"text","text":""}`
+		block, _ = sjson.Set(block, "text", text)
+		blocks = append(blocks, block)
+		out, _ = sjson.SetRawBytes(out, "content", []byte("["+strings.Join(blocks, ",")+"]"))
+		if gjson.GetBytes(out, "stop_reason").String() == "tool_use" {
+			out, _ = sjson.SetBytes(out, "stop_reason", "end_turn")
+		}
+	case constant.Gemini, constant.GeminiCLI:
+		path := "candidates.0.content.parts"
+		if handlerType == constant.GeminiCLI {
+			path = "response." + path
+		}
+		parts := make([]string, 0)
+		for _, part := range gjson.GetBytes(resp, path).Array() {
+			if part.Get("thought").Bool() {
+				parts = append(parts, part.Raw)
+				continue
+			}
+			if call := part.Get("functionCall"); call.Exists() && call.Get("name").String() != structuredOutputToolName {
+				parts = append(parts, part.Raw)
+			}
+		}
+		part := `{"text":""}`
+		part, _ = sjson.Set(part, "text", text)
+		parts = append(parts, part)
+		out, _ = sjson.SetRawBytes(out, path, []byte("["+strings.Join(parts, ",")+"]"))
+	}
+	return out
+}
+
+// appendStructuredRepair adds the rejected answer and a correction request to the conversation.
+func appendStructuredRepair(handlerType string, request []byte, answer, reason string) []byte {
+	repair := fmt.Sprintf("Your previous response did not satisfy the required JSON format: %s. Respond again with only the corrected JSON.", reason)
+	out := request
+	switch handlerType {
+	case constant.OpenAI:
+		assistant, _ := sjson.Set(`{"role":"assistant","content":""}`, "content", answer)
+		user, _ := sjson.Set(`{"role":"user","content":""}`, "content", repair)
+		out, _ = sjson.SetRawBytes(out, "messages.-1", []byte(assistant))
+		out, _ = sjson.SetRawBytes(out, "messages.-1", []byte(user))
+	case constant.OpenaiResponse:
+		if input := gjson.GetBytes(out, "input"); input.Type == gjson.String {
+			first, _ := sjson.Set(`{"role":"user","content":""}`, "content", input.String())
+			out, _ = sjson.SetRawBytes(out, "input", []byte("["+first+"]"))
+		}
+		assistant, _ := sjson.Set(`{"type":"message","role":"assistant","content":[{"type":"output_text","text":""}]}`, "content.0.text", answer)
+		user, _ := sjson.Set(`{"type":"message","role":"user","content":[{"type":"input_text","text":""}]}`, "content.0.text", repair)
+		out, _ = sjson.SetRawBytes(out, "input.-1", []byte(assistant))
+		out, _ = sjson.SetRawBytes(out, "input.-1", []byte(user))
+	case constant.Claude:
+		assistant, _ := sjson.Set(`{"role":"assistant","content":[{"type":"text","text":""}]}`, "content.0.text", answer)
+		user, _ := sjson.Set(`{"role":"user","content":[{"type":"text","text":""}]}`, "content.0.text", repair)
+		out, _ = sjson.SetRawBytes(out, "messages.-1", []byte(assistant))
+		out, _ = sjson.SetRawBytes(out, "messages.-1", []byte(user))
+	case constant.Gemini, constant.GeminiCLI:
+		_, prefix := structuredRequestRoot(handlerType, request)
+		model, _ := sjson.Set(`{"role":"model","parts":[{"text":""}]}`, "parts.0.text", answer)
+		user, _ := sjson.Set(`{"role":"user","parts":[{"text":""}]}`, "parts.0.text", repair)
+		out, _ = sjson.SetRawBytes(out, prefix+"contents.-1", []byte(model))
+		out, _ = sjson.SetRawBytes(out, prefix+"contents.-1", []byte(user))
+	}
+	return out
+}
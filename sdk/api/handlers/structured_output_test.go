@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/interfaces"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+const structuredTestSchema = `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"],"additionalProperties":false}`
+
+func TestPlanStructuredOutput_Emulation(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-structured-native", "openai-native", []*registry.ModelInfo{
+		{ID: "structured-native-model", Capabilities: []string{registry.CapabilityJSONSchema}},
+	})
+	modelRegistry.RegisterClient("test-structured-plain", "plain", []*registry.ModelInfo{
+		{ID: "structured-plain-model", Capabilities: []string{registry.CapabilityTools}},
+	})
+	for _, clientID := range []string{"test-structured-native", "test-structured-plain"} {
+		id := clientID
+		t.Cleanup(func() { modelRegistry.UnregisterClient(id) })
+	}
+	handler := NewBaseAPIHandlers(&sdkconfig.SDKConfig{}, coreauth.NewManager(nil, nil, nil))
+	request := []byte(`{"messages":[{"role":"user","content":"where?"}],"response_format":{"type":"json_schema","json_schema":{"name":"place","schema":` + structuredTestSchema + `}}}`)
+
+	plan := handler.planStructuredOutput("openai", "structured-native-model", []string{"openai-native"}, request, false)
+	if plan == nil || plan.strategy != "" || string(plan.request) != string(request) {
+		t.Fatalf("expected the schema to be forwarded natively, got %+v", plan)
+	}
+
+	plan = handler.planStructuredOutput("openai", "structured-plain-model", []string{"plain"}, request, false)
+	if plan == nil || plan.strategy != structuredStrategyTool {
+		t.Fatalf("expected tool emulation, got %+v", plan)
+	}
+	if gjson.GetBytes(plan.request, "response_format").Exists() ||
+		gjson.GetBytes(plan.request, "tools.0.function.name").String() != structuredOutputToolName ||
+		gjson.GetBytes(plan.request, "tool_choice.function.name").String() != structuredOutputToolName {
+		t.Fatalf("unexpected tool rewrite: %s", plan.request)
+	}
+
+	plan = handler.planStructuredOutput("openai", "structured-plain-model", []string{"plain"}, request, true)
+	if plan == nil || plan.strategy != structuredStrategyPrompt || gjson.GetBytes(plan.request, "messages.0.role").String() != "system" || gjson.GetBytes(plan.request, "tools").Exists() {
+		t.Fatalf("expected prompt emulation for streams, got %s", plan.request)
+	}
+
+	claudeRequest := []byte(`{"system":"be brief","messages":[{"role":"user","content":"where?"}],"output_format":{"type":"json_schema","schema":` + structuredTestSchema + `}}`)
+	plan = handler.planStructuredOutput("claude", "structured-plain-model", []string{"plain"}, claudeRequest, true)
+	if plan == nil || gjson.GetBytes(plan.request, "output_format").Exists() || gjson.GetBytes(plan.request, "system").String() == "be brief" {
+		t.Fatalf("expected the instruction appended to the Claude system prompt, got %s", plan.request)
+	}
+
+	disabled := NewBaseAPIHandlers(&sdkconfig.SDKConfig{StructuredOutput: sdkconfig.StructuredOutputConfig{Disable: true}}, coreauth.NewManager(nil, nil, nil))
+	if plan = disabled.planStructuredOutput("openai", "structured-plain-model", []string{"plain"}, request, false); plan != nil {
+		t.Fatalf("expected no plan when disabled, got %+v", plan)
+	}
+}
+
+func TestStructuredOutputPlan_ConvertsToolAnswer(t *testing.T) {
+	plan := &structuredOutputPlan{handlerType: "claude", strategy: structuredStrategyTool, request: []byte(`{}`)}
+	plan.spec.Schema = structuredTestSchema
+	resp := []byte(`{"content":[{"type":"thinking","thinking":"hm"},{"type":"tool_use","id":"t1","name":"structured_output","input":{"city":"Oslo"}}],"stop_reason":"tool_use"}`)
+
+	out, errMsg := plan.execute(func([]byte) ([]byte, *interfaces.ErrorMessage) { return resp, nil })
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if gjson.GetBytes(out, "content.#").Int() != 2 || gjson.GetBytes(out, "content.1.text").String() != `{"city":"Oslo"}` || gjson.GetBytes(out, "stop_reason").String() != "end_turn" {
+		t.Fatalf("unexpected converted response: %s", out)
+	}
+}
+
+func TestStructuredOutputPlan_RepairsInvalidAnswer(t *testing.T) {
+	plan := &structuredOutputPlan{handlerType: "openai", strategy: structuredStrategyPrompt, retries: 1, request: []byte(`{"messages":[{"role":"user","content":"where?"}]}`)}
+	plan.spec.Schema = structuredTestSchema
+
+	var requests [][]byte
+	answers := []string{`{"town":"Oslo"}`, "```json\n{\"city\":\"Oslo\"}\n```"}
+	run := func(payload []byte) ([]byte, *interfaces.ErrorMessage) {
+		requests = append(requests, payload)
+		resp := []byte(`{"choices":[{"message":{"role":"assistant","content":""},"finish_reason":"stop"}]}`)
+		resp, _ = sjson.SetBytes(resp, "choices.0.message.content", answers[len(requests)-1])
+		return resp, nil
+	}
+	out, errMsg := plan.execute(run)
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if len(requests) != 2 || gjson.GetBytes(requests[1], "messages.#").Int() != 3 || gjson.GetBytes(requests[1], "messages.1.role").String() != "assistant" {
+		t.Fatalf("expected a repair request, got %q", requests)
+	}
+	if gjson.GetBytes(out, "choices.0.message.content").String() != `{"city":"Oslo"}` {
+		t.Fatalf("expected the code fence to be stripped, got %s", out)
+	}
+
+	requests = nil
+	answers = []string{`{"town":"Oslo"}`, `{"town":"Bergen"}`}
+	_, errMsg = plan.execute(run)
+	if errMsg == nil || errMsg.StatusCode != http.StatusBadGateway || len(requests) != 2 {
+		t.Fatalf("expected a 502 after exhausting repairs, got %v after %d requests", errMsg, len(requests))
+	}
+}
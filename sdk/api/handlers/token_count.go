@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/constant"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	log "github.com/sirupsen/logrus"
+)
+
+// TokenCountPrecisionHeader lets a client demand an upstream token count ("exact") when
+// counting is otherwise answered locally.
+const TokenCountPrecisionHeader = "X-Token-Count-Precision"
+
+// TokenCountingMode returns the configured token counting mode, defaulting to upstream.
+func TokenCountingMode(cfg *config.SDKConfig) string {
+	if cfg == nil {
+		return config.TokenCountingUpstream
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(cfg.TokenCounting.Mode)); mode {
+	case config.TokenCountingLocal, config.TokenCountingFallback:
+		return mode
+	default:
+		return config.TokenCountingUpstream
+	}
+}
+
+func exactTokenCountRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	ginCtx, ok := ctx.Value("gin").(*gin.Context)
+	if !ok || ginCtx == nil || ginCtx.Request == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(ginCtx.GetHeader(TokenCountPrecisionHeader)), "exact")
+}
+
+// localTokenCount answers a Claude or Gemini count request with the offline tokenizer, in the
+// response format of the handler. ok is false for other formats or when counting fails.
+func localTokenCount(handlerType, modelName string, rawJSON []byte) ([]byte, bool) {
+	var template string
+	switch handlerType {
+	case constant.Claude:
+		template = `{"input_tokens":%d}`
+	case constant.Gemini, constant.GeminiCLI:
+		template = `{"totalTokens":%d}`
+	default:
+		return nil, false
+	}
+	baseModel := thinking.ParseSuffix(modelName).ModelName
+	count, err := executor.CountRequestTokens(sdktranslator.FromString(handlerType), baseModel, rawJSON)
+	if err != nil {
+		log.Debugf("local token count for %s failed: %v", baseModel, err)
+		return nil, false
+	}
+	return []byte(fmt.Sprintf(template, count)), true
+}
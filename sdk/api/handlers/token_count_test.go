@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	sdkconfig "github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+	"github.com/tidwall/gjson"
+)
+
+func TestExecuteCountWithAuthManager_Local(t *testing.T) {
+	modelRegistry := registry.GetGlobalRegistry()
+	modelRegistry.RegisterClient("test-token-count", "claude", []*registry.ModelInfo{{ID: "token-count-model"}})
+	t.Cleanup(func() { modelRegistry.UnregisterClient("test-token-count") })
+
+	cfg := &sdkconfig.SDKConfig{TokenCounting: sdkconfig.TokenCountingConfig{Mode: sdkconfig.TokenCountingLocal}}
+	handler := NewBaseAPIHandlers(cfg, coreauth.NewManager(nil, nil, nil))
+	request := []byte(`{"model":"token-count-model","messages":[{"role":"user","content":"count these words please"}]}`)
+
+	resp, errMsg := handler.ExecuteCountWithAuthManager(context.Background(), "claude", "token-count-model", request, "")
+	if errMsg != nil {
+		t.Fatalf("unexpected error: %v", errMsg.Error)
+	}
+	if n := gjson.GetBytes(resp, "input_tokens").Int(); n <= 0 {
+		t.Fatalf("expected a local count, got %s", resp)
+	}
+
+	// An exact count goes upstream, where no credential is available.
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("POST", "/v1/messages/count_tokens", nil)
+	ginCtx.Request.Header.Set(TokenCountPrecisionHeader, "exact")
+	ctx := context.WithValue(context.Background(), "gin", ginCtx)
+	if _, errMsg = handler.ExecuteCountWithAuthManager(ctx, "claude", "token-count-model", request, ""); errMsg == nil {
+		t.Fatal("expected the exact count to be forwarded upstream and fail")
+	}
+
+	cfg.TokenCounting.Mode = sdkconfig.TokenCountingFallback
+	resp, errMsg = handler.ExecuteCountWithAuthManager(ctx, "gemini", "token-count-model", []byte(`{"contents":[{"parts":[{"text":"hi"}]}]}`), "")
+	if errMsg != nil || gjson.GetBytes(resp, "totalTokens").Int() <= 0 {
+		t.Fatalf("expected a local fallback count, got %s (%v)", resp, errMsg)
+	}
+}
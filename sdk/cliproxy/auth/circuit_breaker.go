@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// BreakerStatus is a snapshot of the breaker guarding one upstream endpoint.
+type BreakerStatus struct {
+	Key                 string    `json:"key"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive-failures"`
+	OpenedAt            time.Time `json:"opened-at,omitzero"`
+	RetryAt             time.Time `json:"retry-at,omitzero"`
+	LastError           string    `json:"last-error,omitempty"`
+	Auths               int       `json:"auths"`
+}
+
+type circuitBreaker struct {
+	state     string
+	failures  int
+	successes int
+	openedAt  time.Time
+	retryAt   time.Time
+	probing   bool
+	// probe identifies the admitted probe so a stale release cannot clear a later one.
+	probe     uint64
+	lastError string
+}
+
+// circuitBreakers tracks breakers by endpoint key. Credentials without a custom base URL have
+// no breaker.
+type circuitBreakers struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// breakerConfig returns the breaker settings, or false when breakers are disabled.
+func (m *Manager) breakerConfig() (internalconfig.CircuitBreakerConfig, bool) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || !cfg.CircuitBreaker.Enabled() {
+		return internalconfig.CircuitBreakerConfig{}, false
+	}
+	return cfg.CircuitBreaker, true
+}
+
+// breakerKey returns the endpoint key of auth, or "" when it has no custom base URL.
+func breakerKey(auth *Auth, keyMode string) string {
+	if auth == nil {
+		return ""
+	}
+	base := strings.TrimSpace(auth.Attributes["base_url"])
+	if base == "" {
+		return ""
+	}
+	if keyMode == internalconfig.CircuitBreakerKeyHost {
+		if parsed, err := url.Parse(base); err == nil && parsed.Host != "" {
+			return strings.ToLower(parsed.Host)
+		}
+	}
+	return normalizeBreakerKey(base)
+}
+
+// normalizeBreakerKey lowercases the scheme and host of an endpoint URL and drops trailing
+// slashes, keeping the case of the path. Values that are not URLs, such as host keys, are
+// lowercased as a whole.
+func normalizeBreakerKey(key string) string {
+	key = strings.TrimSpace(key)
+	parsed, err := url.Parse(key)
+	if err != nil || parsed.Host == "" {
+		return strings.TrimRight(strings.ToLower(key), "/")
+	}
+	return strings.ToLower(parsed.Scheme+"://"+parsed.Host) + strings.TrimRight(parsed.Path, "/")
+}
+
+// breakerBlocks reports whether auth sits behind an open breaker, or a half-open one whose
+// probe is still in flight.
+func (m *Manager) breakerBlocks(auth *Auth, now time.Time) bool {
+	cfg, ok := m.breakerConfig()
+	if !ok {
+		return false
+	}
+	key := breakerKey(auth, cfg.Key)
+	if key == "" {
+		return false
+	}
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	b := m.breakers.breakers[key]
+	if b == nil {
+		return false
+	}
+	switch b.state {
+	case BreakerOpen:
+		return now.Before(b.retryAt)
+	case BreakerHalfOpen:
+		return b.probing
+	default:
+		return false
+	}
+}
+
+// admitBreaker records that auth was selected; an expired open breaker turns half-open and the
+// request becomes its probe. It reports false when another request already probes the endpoint.
+// The returned function releases a probe that ends without a result and must be called once
+// the attempt is over.
+func (m *Manager) admitBreaker(auth *Auth, now time.Time) (func(), bool) {
+	cfg, ok := m.breakerConfig()
+	if !ok {
+		return func() {}, true
+	}
+	key := breakerKey(auth, cfg.Key)
+	if key == "" {
+		return func() {}, true
+	}
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	b := m.breakers.breakers[key]
+	if b == nil {
+		return func() {}, true
+	}
+	if b.state == BreakerOpen && !now.Before(b.retryAt) {
+		b.state = BreakerHalfOpen
+		b.successes = 0
+		log.Infof("circuit breaker %s half-open, probing upstream", key)
+	}
+	if b.state != BreakerHalfOpen {
+		return func() {}, true
+	}
+	if b.probing {
+		return nil, false
+	}
+	b.probing = true
+	b.probe++
+	probe := b.probe
+	return func() { m.releaseProbe(key, probe) }, true
+}
+
+// releaseProbe lets another request probe key when probe is still in flight, i.e. it ended
+// without recording a result.
+func (m *Manager) releaseProbe(key string, probe uint64) {
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	if b := m.breakers.breakers[key]; b != nil && b.state == BreakerHalfOpen && b.probing && b.probe == probe {
+		b.probing = false
+	}
+}
+
+// recordBreakerResult feeds an attempt result into the breaker of the endpoint behind auth.
+func (m *Manager) recordBreakerResult(auth *Auth, result Result, now time.Time) {
+	cfg, ok := m.breakerConfig()
+	if !ok {
+		return
+	}
+	key := breakerKey(auth, cfg.Key)
+	if key == "" {
+		return
+	}
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	b := m.breakers.breakers[key]
+	if result.Success || !isEndpointFailure(result.Error) {
+		// Client and per-credential errors (4xx) show the endpoint is reachable.
+		if b == nil {
+			return
+		}
+		b.failures = 0
+		if b.state == BreakerHalfOpen {
+			b.probing = false
+			b.successes++
+			if b.successes >= cfg.ProbesToClose() {
+				b.state = BreakerClosed
+				b.openedAt, b.retryAt = time.Time{}, time.Time{}
+				log.Infof("circuit breaker %s closed", key)
+			}
+		}
+		return
+	}
+	if b == nil {
+		b = &circuitBreaker{state: BreakerClosed}
+		if m.breakers.breakers == nil {
+			m.breakers.breakers = make(map[string]*circuitBreaker)
+		}
+		m.breakers.breakers[key] = b
+	}
+	b.failures++
+	if result.Error != nil {
+		b.lastError = result.Error.Message
+	}
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= cfg.FailureThreshold) {
+		b.state = BreakerOpen
+		b.probing = false
+		b.openedAt = now
+		b.retryAt = now.Add(cfg.OpenDuration())
+		log.Warnf("circuit breaker %s opened after %d consecutive failures", key, b.failures)
+	}
+}
+
+// isEndpointFailure reports whether an error points at the upstream endpoint rather than the
+// credential or the request.
+func isEndpointFailure(err *Error) bool {
+	status := statusCodeFromResult(err)
+	switch {
+	case status == 0:
+		return err != nil
+	case status == http.StatusRequestTimeout:
+		return true
+	default:
+		return status >= http.StatusInternalServerError && status != http.StatusNotImplemented
+	}
+}
+
+// CircuitBreakers returns the state of every tracked upstream endpoint, sorted by key.
+func (m *Manager) CircuitBreakers() []BreakerStatus {
+	if m == nil {
+		return nil
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	keyMode := ""
+	if cfg != nil {
+		keyMode = cfg.CircuitBreaker.Key
+	}
+	auths := make(map[string]int)
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if key := breakerKey(auth, keyMode); key != "" {
+			auths[key]++
+		}
+	}
+	m.mu.RUnlock()
+
+	now := time.Now()
+	m.breakers.mu.Lock()
+	out := make([]BreakerStatus, 0, len(m.breakers.breakers))
+	for key, b := range m.breakers.breakers {
+		state := b.state
+		if state == BreakerOpen && !now.Before(b.retryAt) {
+			state = BreakerHalfOpen
+		}
+		out = append(out, BreakerStatus{
+			Key:                 key,
+			State:               state,
+			ConsecutiveFailures: b.failures,
+			OpenedAt:            b.openedAt,
+			RetryAt:             b.retryAt,
+			LastError:           b.lastError,
+			Auths:               auths[key],
+		})
+	}
+	m.breakers.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ResetCircuitBreaker closes the breaker of key, or every breaker when key is empty. It
+// reports whether anything was reset.
+func (m *Manager) ResetCircuitBreaker(key string) bool {
+	if m == nil {
+		return false
+	}
+	key = normalizeBreakerKey(key)
+	m.breakers.mu.Lock()
+	defer m.breakers.mu.Unlock()
+	if key == "" {
+		reset := len(m.breakers.breakers) > 0
+		m.breakers.breakers = nil
+		return reset
+	}
+	if _, ok := m.breakers.breakers[key]; !ok {
+		return false
+	}
+	delete(m.breakers.breakers, key)
+	return true
+}
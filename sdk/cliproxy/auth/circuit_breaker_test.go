@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestCircuitBreakerSkipsEndpointAndProbes(t *testing.T) {
+	executor := &scriptedExecutor{provider: "openrouter", statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{CircuitBreaker: internalconfig.CircuitBreakerConfig{FailureThreshold: 2}})
+	for _, id := range []string{"breaker-a", "breaker-b", "breaker-c"} {
+		auth := &Auth{ID: id, Provider: "openrouter", Status: StatusActive, Attributes: map[string]string{"base_url": "https://OpenRouter.ai/api/v1/"}}
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "openrouter", []*registry.ModelInfo{{ID: "breaker-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	execute := func() error {
+		_, err := manager.Execute(context.Background(), []string{"openrouter"}, cliproxyexecutor.Request{Model: "breaker-model"}, cliproxyexecutor.Options{})
+		return err
+	}
+
+	if err := execute(); err == nil {
+		t.Fatal("expected the failing endpoint to surface an error")
+	}
+	if len(executor.calls) != 2 {
+		t.Fatalf("open breaker should skip the third credential, got calls %v", executor.calls)
+	}
+	breakers := manager.CircuitBreakers()
+	if len(breakers) != 1 || breakers[0].Key != "https://openrouter.ai/api/v1" || breakers[0].State != BreakerOpen || breakers[0].Auths != 3 {
+		t.Fatalf("unexpected breakers: %+v", breakers)
+	}
+
+	// Let the open period elapse; the next request probes and closes the breaker.
+	manager.breakers.mu.Lock()
+	manager.breakers.breakers[breakers[0].Key].retryAt = time.Now().Add(-time.Second)
+	manager.breakers.mu.Unlock()
+	if err := execute(); err != nil {
+		t.Fatalf("probe request failed: %v", err)
+	}
+	if state := manager.CircuitBreakers()[0].State; state != BreakerClosed {
+		t.Fatalf("breaker state after successful probe = %s", state)
+	}
+
+	if !manager.ResetCircuitBreaker("https://openrouter.ai/api/v1/") || len(manager.CircuitBreakers()) != 0 {
+		t.Fatal("reset did not drop the breaker")
+	}
+}
+
+func TestCircuitBreakerReleasesCancelledProbe(t *testing.T) {
+	executor := &stallingExecutor{scriptedExecutor{provider: "openrouter"}}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{CircuitBreaker: internalconfig.CircuitBreakerConfig{FailureThreshold: 1}})
+	auth := &Auth{ID: "breaker-probe", Provider: "openrouter", Status: StatusActive, Attributes: map[string]string{"base_url": "https://probe.example.com/v1"}}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, "openrouter", []*registry.ModelInfo{{ID: "breaker-probe-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	manager.breakers.breakers = map[string]*circuitBreaker{
+		"https://probe.example.com/v1": {state: BreakerOpen, failures: 1, retryAt: time.Now().Add(-time.Second)},
+	}
+	req := cliproxyexecutor.Request{Model: "breaker-probe-model"}
+
+	// The probe stalls until its client goes away, leaving no result for the breaker.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := manager.Execute(ctx, []string{"openrouter"}, req, cliproxyexecutor.Options{}); err == nil {
+		t.Fatal("expected the cancelled probe to fail")
+	}
+	if _, err := manager.Execute(context.Background(), []string{"openrouter"}, req, cliproxyexecutor.Options{}); err != nil {
+		t.Fatalf("request after a cancelled probe: %v", err)
+	}
+	if state := manager.CircuitBreakers()[0].State; state != BreakerClosed {
+		t.Fatalf("breaker state after the second probe = %s", state)
+	}
+}
+
+func TestResetCircuitBreakerMatchesMixedCaseKey(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	auth := &Auth{ID: "breaker-case", Provider: "openai-compatibility", Attributes: map[string]string{"base_url": "https://Gateway.example.com/Tenant/V1/"}}
+	key := breakerKey(auth, "")
+	if key != "https://gateway.example.com/Tenant/V1" {
+		t.Fatalf("breaker key = %q", key)
+	}
+	manager.breakers.breakers = map[string]*circuitBreaker{key: {state: BreakerOpen, failures: 3}}
+
+	if !manager.ResetCircuitBreaker("HTTPS://GATEWAY.example.com/Tenant/V1/") {
+		t.Fatal("reset did not match the breaker of the mixed-case endpoint")
+	}
+	if len(manager.CircuitBreakers()) != 0 {
+		t.Fatal("reset did not drop the breaker")
+	}
+}
@@ -0,0 +1,355 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ConcurrencyStats reports in-flight requests per credential and the wait queue.
+type ConcurrencyStats struct {
+	QueueDepth    int                     `json:"queue-depth"`
+	QueueCapacity int                     `json:"queue-capacity"`
+	Waits         int64                   `json:"waits"`
+	Timeouts      int64                   `json:"timeouts"`
+	Rejected      int64                   `json:"rejected"`
+	AvgWaitMs     int64                   `json:"avg-wait-ms"`
+	MaxWaitMs     int64                   `json:"max-wait-ms"`
+	Credentials   []CredentialConcurrency `json:"credentials"`
+}
+
+// CredentialConcurrency is the in-flight request count of one limited credential.
+type CredentialConcurrency struct {
+	AuthID   string `json:"auth-id"`
+	Provider string `json:"provider"`
+	InFlight int    `json:"in-flight"`
+	Limit    int    `json:"limit"`
+}
+
+// credentialSlots counts in-flight requests of limited credentials and queues requests that
+// found every eligible credential saturated. Waiters are ordered by client priority, then by
+// arrival; a released slot wakes the first waiter that could use that credential, while a
+// change to the credentials or their limits wakes every waiter to pick again.
+type credentialSlots struct {
+	mu       sync.Mutex
+	inflight map[string]int
+	waiters  list.List
+	// seq numbers waiters in order of arrival.
+	seq uint64
+
+	waits     int64
+	timeouts  int64
+	rejected  int64
+	waitTotal time.Duration
+	waitMax   time.Duration
+}
+
+type slotWaiter struct {
+	authIDs  map[string]struct{}
+	priority int
+	// seq is the arrival number of the request, kept when it rejoins the queue.
+	seq   uint64
+	ready chan struct{}
+	// woken is set, under credentialSlots.mu, when a wake-up removed the waiter from the queue.
+	// grantID names the released credential, or is empty when every waiter was woken.
+	woken   bool
+	grantID string
+}
+
+// saturatedError is returned by the picker when every eligible credential is at its limit or
+// held back for higher-priority clients. retryAt is set when other eligible credentials are
+// cooling down until then.
+type saturatedError struct {
+	authIDs []string
+	retryAt time.Time
+}
+
+func (e *saturatedError) Error() string {
+	return "all credentials are at their concurrency limit"
+}
+
+// concurrencyConfig returns the current concurrency settings.
+func (m *Manager) concurrencyConfig() internalconfig.ConcurrencyConfig {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return internalconfig.ConcurrencyConfig{}
+	}
+	return cfg.Concurrency
+}
+
+// maxConcurrency returns the concurrent request limit of auth, or 0 when unlimited.
+func (m *Manager) maxConcurrency(auth *Auth) int {
+	if limit, ok := auth.MaxConcurrencyOverride(); ok {
+		return limit
+	}
+	if auth == nil {
+		return 0
+	}
+	return m.concurrencyConfig().Defaults[strings.ToLower(auth.Provider)]
+}
+
+// saturated reports whether auth already runs its maximum number of requests.
+func (m *Manager) saturated(auth *Auth) bool {
+	limit := m.maxConcurrency(auth)
+	if limit <= 0 {
+		return false
+	}
+	m.slots.mu.Lock()
+	defer m.slots.mu.Unlock()
+	return m.slots.inflight[auth.ID] >= limit
+}
+
+// acquireNextMixed picks a credential like pickNextMixed, takes one of its concurrency slots
+// and consumes its pacing budget for a request of tokens estimated input tokens. While every
+// eligible credential is saturated the request waits in the FIFO queue; while every one is
+// paced it waits up to the pacing limit for the first to regain capacity. The returned
+// release function must be called once the attempt is over.
+func (m *Manager) acquireNextMixed(ctx context.Context, providers []string, model string, tokens int, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, func(), error) {
+	var queuedAt, deadline, pacingDeadline time.Time
+	var seq uint64
+	priority, _ := m.requestPriority(opts)
+	for {
+		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, model, tokens, opts, tried)
+		if errPick == nil {
+			release, ok := m.slots.acquire(auth.ID, m.maxConcurrency(auth))
+			if !ok {
+				// Another request took the last slot since the pick; pick again.
+				continue
+			}
+			if !m.takePacing(auth, model, tokens, time.Now()) {
+				// Another request used the pacing budget since the pick; pick again.
+				release()
+				continue
+			}
+			releaseProbe, admitted := m.admitBreaker(auth, time.Now())
+			if !admitted {
+				// Another request became the probe of a half-open breaker since the pick.
+				release()
+				continue
+			}
+			releaseSlot := release
+			release = func() {
+				releaseSlot()
+				releaseProbe()
+			}
+			if !queuedAt.IsZero() {
+				m.slots.recordWait(time.Since(queuedAt))
+			}
+			return auth, executor, provider, release, nil
+		}
+		if paced, ok := errPick.(*pacedError); ok {
+			if pacingDeadline.IsZero() {
+				pacingDeadline = time.Now().Add(m.pacingConfig().MaxWait())
+			}
+			if time.Now().Add(paced.wait).After(pacingDeadline) {
+				return nil, nil, "", nil, &Error{Code: "pacing_wait_exceeded", Message: "all credentials are paced to their rate limits", Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+			}
+			if errWait := waitForCooldown(ctx, paced.wait); errWait != nil {
+				return nil, nil, "", nil, errWait
+			}
+			continue
+		}
+		saturated, ok := errPick.(*saturatedError)
+		if !ok {
+			return nil, nil, "", nil, errPick
+		}
+		cfg := m.concurrencyConfig()
+		if queuedAt.IsZero() {
+			queuedAt = time.Now()
+			deadline = queuedAt.Add(cfg.QueueTimeout())
+		}
+		var errWait error
+		if seq, errWait = m.slots.wait(ctx, saturated.authIDs, priority, seq, cfg.QueueCapacity(), deadline, saturated.retryAt); errWait != nil {
+			return nil, nil, "", nil, errWait
+		}
+	}
+}
+
+// wakeSlotWaiters lets every queued request pick again after credentials, their limits or the
+// config changed, since a credential it could not use before may now have room.
+func (m *Manager) wakeSlotWaiters() {
+	m.slots.mu.Lock()
+	defer m.slots.mu.Unlock()
+	m.slots.wakeAllLocked()
+}
+
+// acquire takes a slot of authID when it has fewer than limit in-flight requests. Unlimited
+// credentials are not tracked.
+func (s *credentialSlots) acquire(authID string, limit int) (func(), bool) {
+	if limit <= 0 {
+		return func() {}, true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight[authID] >= limit {
+		return nil, false
+	}
+	if s.inflight == nil {
+		s.inflight = make(map[string]int)
+	}
+	s.inflight[authID]++
+	var once sync.Once
+	return func() { once.Do(func() { s.release(authID) }) }, true
+}
+
+func (s *credentialSlots) release(authID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight[authID] <= 1 {
+		delete(s.inflight, authID)
+	} else {
+		s.inflight[authID]--
+	}
+	s.wakeLocked(authID)
+}
+
+// wakeLocked wakes the first queued waiter that could use a slot of authID.
+func (s *credentialSlots) wakeLocked(authID string) {
+	for e := s.waiters.Front(); e != nil; e = e.Next() {
+		w := e.Value.(*slotWaiter)
+		if _, ok := w.authIDs[authID]; !ok {
+			continue
+		}
+		s.waiters.Remove(e)
+		w.woken = true
+		w.grantID = authID
+		close(w.ready)
+		return
+	}
+}
+
+// wakeAllLocked wakes every queued waiter.
+func (s *credentialSlots) wakeAllLocked() {
+	for e := s.waiters.Front(); e != nil; e = s.waiters.Front() {
+		w := e.Value.(*slotWaiter)
+		s.waiters.Remove(e)
+		w.woken = true
+		w.grantID = ""
+		close(w.ready)
+	}
+}
+
+// wait queues the caller behind waiters of higher priority and earlier arrival until a slot
+// of one of authIDs is released, the credentials change, or retryAt passes. seq is zero for a
+// new request; a request woken earlier that lost its slot to another request passes the
+// returned number again to keep its place.
+func (s *credentialSlots) wait(ctx context.Context, authIDs []string, priority int, seq uint64, capacity int, deadline, retryAt time.Time) (uint64, error) {
+	w := &slotWaiter{authIDs: make(map[string]struct{}, len(authIDs)), priority: priority, seq: seq, ready: make(chan struct{})}
+	for _, id := range authIDs {
+		w.authIDs[id] = struct{}{}
+	}
+	s.mu.Lock()
+	if seq == 0 {
+		if s.waiters.Len() >= capacity {
+			s.rejected++
+			s.mu.Unlock()
+			return 0, &Error{Code: "concurrency_queue_full", Message: "all credentials are busy and the wait queue is full", Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+		}
+		s.seq++
+		w.seq = s.seq
+	}
+	elem := s.enqueueLocked(w)
+	s.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	var cooldownEnd <-chan time.Time
+	if !retryAt.IsZero() && retryAt.Before(deadline) {
+		cooldown := time.NewTimer(time.Until(retryAt))
+		defer cooldown.Stop()
+		cooldownEnd = cooldown.C
+	}
+	var err error
+	select {
+	case <-w.ready:
+		return w.seq, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timer.C:
+		err = &Error{Code: "concurrency_queue_timeout", Message: "timed out waiting for a free credential", Retryable: true, HTTPStatus: http.StatusTooManyRequests}
+	case <-cooldownEnd:
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.woken {
+		if err == nil {
+			return w.seq, nil
+		}
+		// The wake-up raced with the timeout; hand it to the next waiter.
+		if w.grantID != "" {
+			s.wakeLocked(w.grantID)
+		}
+	} else {
+		s.waiters.Remove(elem)
+	}
+	if err == nil {
+		// A cooling credential is usable again; pick again.
+		return w.seq, nil
+	}
+	if _, timedOut := err.(*Error); timedOut {
+		s.timeouts++
+	}
+	return w.seq, err
+}
+
+// enqueueLocked inserts w after the waiters that outrank it: those of higher priority, and
+// those of equal priority that arrived earlier.
+func (s *credentialSlots) enqueueLocked(w *slotWaiter) *list.Element {
+	for e := s.waiters.Back(); e != nil; e = e.Prev() {
+		queued := e.Value.(*slotWaiter)
+		if queued.priority > w.priority || (queued.priority == w.priority && queued.seq < w.seq) {
+			return s.waiters.InsertAfter(w, e)
+		}
+	}
+	return s.waiters.PushFront(w)
+}
+
+func (s *credentialSlots) recordWait(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waits++
+	s.waitTotal += d
+	s.waitMax = max(s.waitMax, d)
+}
+
+// ConcurrencyStats returns the queue state and the in-flight requests of limited credentials.
+func (m *Manager) ConcurrencyStats() ConcurrencyStats {
+	if m == nil {
+		return ConcurrencyStats{}
+	}
+	var credentials []CredentialConcurrency
+	m.mu.RLock()
+	for _, auth := range m.auths {
+		if limit := m.maxConcurrency(auth); limit > 0 {
+			credentials = append(credentials, CredentialConcurrency{AuthID: auth.ID, Provider: auth.Provider, Limit: limit})
+		}
+	}
+	m.mu.RUnlock()
+
+	m.slots.mu.Lock()
+	stats := ConcurrencyStats{
+		QueueDepth:    m.slots.waiters.Len(),
+		QueueCapacity: m.concurrencyConfig().QueueCapacity(),
+		Waits:         m.slots.waits,
+		Timeouts:      m.slots.timeouts,
+		Rejected:      m.slots.rejected,
+		MaxWaitMs:     m.slots.waitMax.Milliseconds(),
+	}
+	if m.slots.waits > 0 {
+		stats.AvgWaitMs = (m.slots.waitTotal / time.Duration(m.slots.waits)).Milliseconds()
+	}
+	for i := range credentials {
+		credentials[i].InFlight = m.slots.inflight[credentials[i].AuthID]
+	}
+	m.slots.mu.Unlock()
+	sort.Slice(credentials, func(i, j int) bool { return credentials[i].AuthID < credentials[j].AuthID })
+	stats.Credentials = credentials
+	return stats
+}
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// gatedExecutor blocks every request until a value is sent on gate.
+type gatedExecutor struct {
+	scriptedExecutor
+	started chan string
+	gate    chan struct{}
+}
+
+func (e *gatedExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.started <- auth.ID
+	<-e.gate
+	return e.scriptedExecutor.Execute(ctx, auth, req, opts)
+}
+
+func TestConcurrencyLimitQueuesRequests(t *testing.T) {
+	executor := &gatedExecutor{scriptedExecutor: scriptedExecutor{provider: "claude"}, started: make(chan string, 4), gate: make(chan struct{})}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{Concurrency: internalconfig.ConcurrencyConfig{
+		Defaults:            map[string]int{"claude": 1},
+		QueueSize:           1,
+		QueueTimeoutSeconds: 5,
+	}})
+	for _, auth := range []*Auth{
+		{ID: "slot-a", Provider: "claude", Status: StatusActive},
+		{ID: "slot-b", Provider: "claude", Status: StatusActive, Metadata: map[string]any{"max_concurrency": float64(1)}},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: "slot-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	}
+	results := make(chan error, 4)
+	execute := func() {
+		_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "slot-model"}, cliproxyexecutor.Options{})
+		results <- err
+	}
+
+	go execute()
+	go execute()
+	first, second := <-executor.started, <-executor.started
+	if first == second {
+		t.Fatalf("both requests ran on %s despite its limit", first)
+	}
+
+	go execute()
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.ConcurrencyStats().QueueDepth != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("third request did not queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "slot-model"}, cliproxyexecutor.Options{})
+	if statusCodeFromError(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected a full queue to reject with 429, got %v", err)
+	}
+
+	executor.gate <- struct{}{}
+	if err := <-results; err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	<-executor.started
+	executor.gate <- struct{}{}
+	executor.gate <- struct{}{}
+	for range 2 {
+		if err := <-results; err != nil {
+			t.Fatalf("request: %v", err)
+		}
+	}
+
+	stats := manager.ConcurrencyStats()
+	if stats.QueueDepth != 0 || stats.Waits != 1 || stats.Rejected != 1 || len(stats.Credentials) != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	for _, credential := range stats.Credentials {
+		if credential.InFlight != 0 || credential.Limit != 1 {
+			t.Fatalf("slot not released: %+v", credential)
+		}
+	}
+}
+
+func TestConcurrencyQueueWakesOnCredentialChanges(t *testing.T) {
+	executor := &gatedExecutor{scriptedExecutor: scriptedExecutor{provider: "claude"}, started: make(chan string, 4), gate: make(chan struct{}, 4)}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{Concurrency: internalconfig.ConcurrencyConfig{
+		Defaults:            map[string]int{"claude": 1},
+		QueueSize:           4,
+		QueueTimeoutSeconds: 5,
+	}})
+	register := func(auth *Auth) {
+		t.Helper()
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: "wake-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+	}
+	cooling := map[string]*ModelState{"wake-model": {Unavailable: true, Status: StatusError, NextRetryAfter: time.Now().Add(200 * time.Millisecond), Quota: QuotaState{Exceeded: true}}}
+	register(&Auth{ID: "wake-a", Provider: "claude", Status: StatusActive})
+	register(&Auth{ID: "wake-cooling", Provider: "claude", Status: StatusActive, ModelStates: cooling})
+
+	results := make(chan error, 4)
+	execute := func() {
+		_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "wake-model"}, cliproxyexecutor.Options{})
+		results <- err
+	}
+	expectStart := func(want string) {
+		t.Helper()
+		select {
+		case got := <-executor.started:
+			if got != want {
+				t.Fatalf("request ran on %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("queued request did not start on %s", want)
+		}
+	}
+
+	go execute()
+	expectStart("wake-a")
+
+	// The only other credential is cooling down; the queued request runs once it recovers.
+	go execute()
+	expectStart("wake-cooling")
+
+	// A credential added while requests wait is used right away.
+	go execute()
+	time.Sleep(20 * time.Millisecond)
+	register(&Auth{ID: "wake-new", Provider: "claude", Status: StatusActive})
+	expectStart("wake-new")
+
+	// So is room made by raising a credential's limit.
+	go execute()
+	time.Sleep(20 * time.Millisecond)
+	if _, err := manager.Update(context.Background(), &Auth{ID: "wake-a", Provider: "claude", Status: StatusActive, Metadata: map[string]any{"max_concurrency": float64(2)}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	expectStart("wake-a")
+
+	for range 4 {
+		executor.gate <- struct{}{}
+	}
+	for range 4 {
+		if err := <-results; err != nil {
+			t.Fatalf("request: %v", err)
+		}
+	}
+}
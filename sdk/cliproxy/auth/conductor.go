@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -153,6 +155,18 @@ type Manager struct {
 	// Optional HTTP RoundTripper provider injected by host.
 	rtProvider RoundTripperProvider
 
+	// Optional response rewriter provider applying response-side payload rules.
+	rwProvider ResponseRewriterProvider
+
+	// breakers guards upstream endpoints shared by credentials with a custom base URL.
+	breakers circuitBreakers
+
+	// slots tracks in-flight requests of credentials with a concurrency limit.
+	slots credentialSlots
+
+	// pacer spaces requests of credentials with request or token rate limits.
+	pacer requestPacer
+
 	// Auto refresh state
 	refreshCancel context.CancelFunc
 }
@@ -216,6 +230,16 @@ func (m *Manager) SetConfig(cfg *internalconfig.Config) {
 	}
 	m.runtimeConfig.Store(cfg)
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
+	m.wakeSlotWaiters()
+}
+
+// PayloadHeaderNames returns the client headers that payload rules of the runtime config match on.
+func (m *Manager) PayloadHeaderNames() []string {
+	if m == nil {
+		return nil
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	return cfg.PayloadHeaderNames()
 }
 
 func (m *Manager) lookupAPIKeyUpstreamModel(authID, requestedModel string) string {
@@ -443,6 +467,7 @@ func (m *Manager) Register(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
+	m.wakeSlotWaiters()
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthRegistered(ctx, auth.Clone())
 	return auth.Clone(), nil
@@ -462,6 +487,7 @@ func (m *Manager) Update(ctx context.Context, auth *Auth) (*Auth, error) {
 	m.auths[auth.ID] = auth.Clone()
 	m.mu.Unlock()
 	m.rebuildAPIKeyModelAliasFromRuntimeConfig()
+	m.wakeSlotWaiters()
 	_ = m.persist(ctx, auth)
 	m.hook.OnAuthUpdated(ctx, auth.Clone())
 	return auth.Clone(), nil
@@ -491,6 +517,7 @@ func (m *Manager) Load(ctx context.Context) error {
 		cfg = &internalconfig.Config{}
 	}
 	m.rebuildAPIKeyModelAliasLocked(cfg)
+	m.wakeSlotWaiters()
 	return nil
 }
 
@@ -504,14 +531,18 @@ func (m *Manager) Execute(ctx context.Context, providers []string, req cliproxye
 
 	_, maxWait := m.retrySettings()
 
+	state := &retryState{}
 	var lastErr error
 	for attempt := 0; ; attempt++ {
-		resp, errExec := m.executeMixedOnce(ctx, normalized, req, opts)
+		resp, errExec := m.executeMixedOnce(ctx, normalized, req, opts, state)
 		if errExec == nil {
 			return resp, nil
 		}
 		lastErr = errExec
-		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, normalized, req.Model, maxWait)
+		if state.stop {
+			break
+		}
+		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, normalized, req.Model, maxWait, state)
 		if !shouldRetry {
 			break
 		}
@@ -535,14 +566,18 @@ func (m *Manager) ExecuteCount(ctx context.Context, providers []string, req clip
 
 	_, maxWait := m.retrySettings()
 
+	state := &retryState{}
 	var lastErr error
 	for attempt := 0; ; attempt++ {
-		resp, errExec := m.executeCountMixedOnce(ctx, normalized, req, opts)
+		resp, errExec := m.executeCountMixedOnce(ctx, normalized, req, opts, state)
 		if errExec == nil {
 			return resp, nil
 		}
 		lastErr = errExec
-		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, normalized, req.Model, maxWait)
+		if state.stop {
+			break
+		}
+		wait, shouldRetry := m.shouldRetryAfterError(errExec, attempt, normalized, req.Model, maxWait, state)
 		if !shouldRetry {
 			break
 		}
@@ -566,14 +601,18 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 
 	_, maxWait := m.retrySettings()
 
+	state := &retryState{}
 	var lastErr error
 	for attempt := 0; ; attempt++ {
-		chunks, errStream := m.executeStreamMixedOnce(ctx, normalized, req, opts)
+		chunks, errStream := m.executeStreamMixedOnce(ctx, normalized, req, opts, state)
 		if errStream == nil {
 			return chunks, nil
 		}
 		lastErr = errStream
-		wait, shouldRetry := m.shouldRetryAfterError(errStream, attempt, normalized, req.Model, maxWait)
+		if state.stop {
+			break
+		}
+		wait, shouldRetry := m.shouldRetryAfterError(errStream, attempt, normalized, req.Model, maxWait, state)
 		if !shouldRetry {
 			break
 		}
@@ -587,16 +626,17 @@ func (m *Manager) ExecuteStream(ctx context.Context, providers []string, req cli
 	return nil, &Error{Code: "auth_not_found", Message: "no auth available"}
 }
 
-func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, state *retryState) (cliproxyexecutor.Response, error) {
 	if len(providers) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
 	routeModel := req.Model
 	opts = ensureRequestedModelMetadata(opts, routeModel)
 	tried := make(map[string]struct{})
+	hedgeDelay := m.hedgeDelay(routeModel, opts)
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, release, errPick := m.acquireNextMixed(ctx, providers, routeModel, estimatePacingTokens(req.Payload), state.pickOptions(opts, tried), tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -609,16 +649,22 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
-		}
-		execReq := req
-		execReq.Model = rewriteModelForAuth(routeModel, auth)
-		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
-		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		resp, errExec := executor.Execute(execCtx, auth, execReq, opts)
+		execCtx := m.attemptContext(ctx, auth, routeModel)
+		execReq := m.requestForAuth(req, routeModel, auth)
+		var resp cliproxyexecutor.Response
+		var errExec error
+		if hedgeDelay > 0 {
+			primary := &hedgeAttempt{auth: auth, executor: executor, provider: provider, release: release, ctx: execCtx, req: execReq}
+			decided := m.executeHedged(ctx, hedgeDelay, primary, providers, routeModel, req, opts, tried)
+			if decided != primary {
+				publishSelectedAuthMetadata(opts.Metadata, decided.auth.ID)
+			}
+			auth, provider, execCtx, execReq = decided.auth, decided.provider, decided.ctx, decided.req
+			resp, errExec = decided.resp, decided.err
+		} else {
+			resp, errExec = executor.Execute(execCtx, auth, execReq, opts)
+			release()
+		}
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
@@ -636,14 +682,39 @@ func (m *Manager) executeMixedOnce(ctx context.Context, providers []string, req
 				return cliproxyexecutor.Response{}, errExec
 			}
 			lastErr = errExec
+			if !m.continueAfterFailure(ctx, state, auth, provider, errExec) {
+				return cliproxyexecutor.Response{}, errExec
+			}
 			continue
 		}
 		m.MarkResult(execCtx, result)
+		if rw := m.responseRewriterFor(auth, provider, execReq.Model, opts); rw != nil {
+			resp.Payload = rw.RewriteResponse(resp.Payload)
+		}
 		return resp, nil
 	}
 }
 
-func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+// attemptContext returns ctx carrying the round tripper and response observers of an attempt
+// on auth.
+func (m *Manager) attemptContext(ctx context.Context, auth *Auth, routeModel string) context.Context {
+	if rt := m.roundTripperFor(auth); rt != nil {
+		ctx = context.WithValue(ctx, roundTripperContextKey{}, rt)
+		ctx = context.WithValue(ctx, "cliproxy.roundtripper", rt)
+	}
+	return m.withPacingObserver(ctx, auth, routeModel)
+}
+
+// requestForAuth returns req with the route model rewritten to the upstream model of auth.
+func (m *Manager) requestForAuth(req cliproxyexecutor.Request, routeModel string, auth *Auth) cliproxyexecutor.Request {
+	execReq := req
+	execReq.Model = rewriteModelForAuth(routeModel, auth)
+	execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
+	execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+	return execReq
+}
+
+func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, state *retryState) (cliproxyexecutor.Response, error) {
 	if len(providers) == 0 {
 		return cliproxyexecutor.Response{}, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
@@ -652,7 +723,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, release, errPick := m.acquireNextMixed(ctx, providers, routeModel, estimatePacingTokens(req.Payload), state.pickOptions(opts, tried), tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return cliproxyexecutor.Response{}, lastErr
@@ -665,16 +736,10 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
-		}
-		execReq := req
-		execReq.Model = rewriteModelForAuth(routeModel, auth)
-		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
-		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
+		execCtx := m.attemptContext(ctx, auth, routeModel)
+		execReq := m.requestForAuth(req, routeModel, auth)
 		resp, errExec := executor.CountTokens(execCtx, auth, execReq, opts)
+		release()
 		result := Result{AuthID: auth.ID, Provider: provider, Model: routeModel, Success: errExec == nil}
 		if errExec != nil {
 			if errCtx := execCtx.Err(); errCtx != nil {
@@ -692,6 +757,9 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 				return cliproxyexecutor.Response{}, errExec
 			}
 			lastErr = errExec
+			if !m.continueAfterFailure(ctx, state, auth, provider, errExec) {
+				return cliproxyexecutor.Response{}, errExec
+			}
 			continue
 		}
 		m.MarkResult(execCtx, result)
@@ -699,7 +767,7 @@ func (m *Manager) executeCountMixedOnce(ctx context.Context, providers []string,
 	}
 }
 
-func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, state *retryState) (<-chan cliproxyexecutor.StreamChunk, error) {
 	if len(providers) == 0 {
 		return nil, &Error{Code: "provider_not_found", Message: "no provider supplied"}
 	}
@@ -708,7 +776,7 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 	tried := make(map[string]struct{})
 	var lastErr error
 	for {
-		auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, opts, tried)
+		auth, executor, provider, release, errPick := m.acquireNextMixed(ctx, providers, routeModel, estimatePacingTokens(req.Payload), state.pickOptions(opts, tried), tried)
 		if errPick != nil {
 			if lastErr != nil {
 				return nil, lastErr
@@ -721,17 +789,17 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 		publishSelectedAuthMetadata(opts.Metadata, auth.ID)
 
 		tried[auth.ID] = struct{}{}
-		execCtx := ctx
-		if rt := m.roundTripperFor(auth); rt != nil {
-			execCtx = context.WithValue(execCtx, roundTripperContextKey{}, rt)
-			execCtx = context.WithValue(execCtx, "cliproxy.roundtripper", rt)
-		}
-		execReq := req
-		execReq.Model = rewriteModelForAuth(routeModel, auth)
-		execReq.Model = m.applyOAuthModelAlias(auth, execReq.Model)
-		execReq.Model = m.applyAPIKeyModelAlias(auth, execReq.Model)
-		chunks, errStream := executor.ExecuteStream(execCtx, auth, execReq, opts)
+		execCtx := m.attemptContext(ctx, auth, routeModel)
+		execReq := m.requestForAuth(req, routeModel, auth)
+		firstChunkTimeout, idleTimeout := m.streamStallTimeouts(provider, routeModel, execReq.Model)
+		attemptCtx, cancelAttempt := execCtx, context.CancelFunc(func() {})
+		if firstChunkTimeout > 0 || idleTimeout > 0 {
+			attemptCtx, cancelAttempt = context.WithCancel(execCtx)
+		}
+		chunks, errStream := executor.ExecuteStream(attemptCtx, auth, execReq, opts)
 		if errStream != nil {
+			cancelAttempt()
+			release()
 			if errCtx := execCtx.Err(); errCtx != nil {
 				return nil, errCtx
 			}
@@ -746,14 +814,56 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 				return nil, errStream
 			}
 			lastErr = errStream
+			if !m.continueAfterFailure(ctx, state, auth, provider, errStream) {
+				return nil, errStream
+			}
 			continue
 		}
+		rewriter := m.responseRewriterFor(auth, provider, execReq.Model, opts)
 		out := make(chan cliproxyexecutor.StreamChunk)
 		go func(streamCtx context.Context, streamAuth *Auth, streamProvider string, streamChunks <-chan cliproxyexecutor.StreamChunk) {
 			defer close(out)
+			defer release()
+			defer cancelAttempt()
 			var failed bool
 			forward := true
-			for chunk := range streamChunks {
+			stall := &stallTimer{}
+			defer stall.stop()
+			wait := firstChunkTimeout
+			for {
+				var chunk cliproxyexecutor.StreamChunk
+				var ok bool
+				// Only time spent waiting on the upstream counts, not time blocked on the caller.
+				stall.reset(wait)
+				select {
+				case chunk, ok = <-streamChunks:
+				case <-stall.C():
+					// The upstream went quiet: abort it, penalise the credential like a gateway
+					// timeout and let the caller retry while nothing has been sent.
+					cancelAttempt()
+					go func() {
+						for range streamChunks {
+						}
+					}()
+					stallErr := &Error{Code: "stream_stalled", Message: fmt.Sprintf("no data from upstream for %s", wait), Retryable: true, HTTPStatus: http.StatusGatewayTimeout}
+					entry.Warnf("stream stalled for auth %s (%s): %s", streamAuth.ID, streamProvider, stallErr.Message)
+					if !failed {
+						m.MarkResult(streamCtx, Result{AuthID: streamAuth.ID, Provider: streamProvider, Model: routeModel, Success: false, Error: stallErr})
+					}
+					if forward && streamCtx == nil {
+						out <- cliproxyexecutor.StreamChunk{Err: stallErr}
+					} else if forward {
+						select {
+						case <-streamCtx.Done():
+						case out <- cliproxyexecutor.StreamChunk{Err: stallErr}:
+						}
+					}
+					return
+				}
+				if !ok {
+					break
+				}
+				wait = idleTimeout
 				if chunk.Err != nil && !failed {
 					failed = true
 					rerr := &Error{Message: chunk.Err.Error()}
@@ -765,6 +875,11 @@ func (m *Manager) executeStreamMixedOnce(ctx context.Context, providers []string
 				if !forward {
 					continue
 				}
+				if rewriter != nil && chunk.Err == nil && len(chunk.Payload) > 0 {
+					if chunk.Payload = rewriter.RewriteStreamChunk(chunk.Payload); len(chunk.Payload) == 0 {
+						continue
+					}
+				}
 				if streamCtx == nil {
 					out <- chunk
 					continue
@@ -840,6 +955,14 @@ func pinnedAuthIDFromMetadata(meta map[string]any) string {
 	}
 }
 
+func excludedAuthIDsFromMetadata(meta map[string]any) []string {
+	if len(meta) == 0 {
+		return nil
+	}
+	ids, _ := meta[cliproxyexecutor.ExcludedAuthsMetadataKey].([]string)
+	return ids
+}
+
 func publishSelectedAuthMetadata(meta map[string]any, authID string) {
 	if len(meta) == 0 {
 		return
@@ -1165,7 +1288,7 @@ func (m *Manager) closestCooldownWait(providers []string, model string, attempt
 	return minWait, found
 }
 
-func (m *Manager) shouldRetryAfterError(err error, attempt int, providers []string, model string, maxWait time.Duration) (time.Duration, bool) {
+func (m *Manager) shouldRetryAfterError(err error, attempt int, providers []string, model string, maxWait time.Duration, state *retryState) (time.Duration, bool) {
 	if err == nil {
 		return 0, false
 	}
@@ -1178,6 +1301,9 @@ func (m *Manager) shouldRetryAfterError(err error, attempt int, providers []stri
 	if isRequestInvalidError(err) {
 		return 0, false
 	}
+	if policy := m.retryPolicyForRequest(state, providers); policy.Classifies() && !policy.Retryable(statusCodeFromError(err), err.Error()) {
+		return 0, false
+	}
 	wait, found := m.closestCooldownWait(providers, model, attempt)
 	if !found || wait > maxWait {
 		return 0, false
@@ -1214,6 +1340,15 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 	m.mu.Lock()
 	if auth, ok := m.auths[result.AuthID]; ok && auth != nil {
 		now := time.Now()
+		retryAfter := result.RetryAfter
+		if retryAfter == nil && result.Error != nil && statusCodeFromResult(result.Error) == http.StatusTooManyRequests {
+			retryAfter = retryDelayFromMessage(result.Error.Message)
+		}
+		policy := m.retryPolicyFor(auth, result.Provider)
+		m.recordBreakerResult(auth, result, now)
+		if retryAfter != nil && statusCodeFromResult(result.Error) == http.StatusTooManyRequests {
+			m.blockPacing(auth.ID, result.Model, now.Add(*retryAfter))
+		}
 
 		if result.Success {
 			if result.Model != "" {
@@ -1245,27 +1380,33 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				}
 
 				statusCode := statusCodeFromResult(result.Error)
-				switch statusCode {
-				case 401:
+				policyCooldown, policyTransient := policyTransientCooldown(policy, result.Error)
+				switch {
+				case policyTransient:
+					state.NextRetryAfter = time.Time{}
+					if policyCooldown > 0 && !quotaCooldownDisabledForAuth(auth) {
+						state.NextRetryAfter = now.Add(policyCooldown)
+					}
+				case statusCode == 401:
 					next := now.Add(30 * time.Minute)
 					state.NextRetryAfter = next
 					suspendReason = "unauthorized"
 					shouldSuspendModel = true
-				case 402, 403:
+				case statusCode == 402, statusCode == 403:
 					next := now.Add(30 * time.Minute)
 					state.NextRetryAfter = next
 					suspendReason = "payment_required"
 					shouldSuspendModel = true
-				case 404:
+				case statusCode == 404:
 					next := now.Add(12 * time.Hour)
 					state.NextRetryAfter = next
 					suspendReason = "not_found"
 					shouldSuspendModel = true
-				case 429:
+				case statusCode == 429:
 					var next time.Time
 					backoffLevel := state.Quota.BackoffLevel
-					if result.RetryAfter != nil {
-						next = now.Add(*result.RetryAfter)
+					if retryAfter != nil {
+						next = now.Add(*retryAfter)
 					} else {
 						cooldown, nextLevel := nextQuotaCooldown(backoffLevel, quotaCooldownDisabledForAuth(auth))
 						if cooldown > 0 {
@@ -1283,7 +1424,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 					suspendReason = "quota"
 					shouldSuspendModel = true
 					setModelQuota = true
-				case 408, 500, 502, 503, 504:
+				case statusCode == 408, statusCode == 500, statusCode == 502, statusCode == 503, statusCode == 504:
 					if quotaCooldownDisabledForAuth(auth) {
 						state.NextRetryAfter = time.Time{}
 					} else {
@@ -1298,7 +1439,7 @@ func (m *Manager) MarkResult(ctx context.Context, result Result) {
 				auth.UpdatedAt = now
 				updateAggregatedAvailability(auth, now)
 			} else {
-				applyAuthFailureState(auth, result.Error, result.RetryAfter, now)
+				applyAuthFailureState(auth, result.Error, retryAfter, policy, now)
 			}
 		}
 
@@ -1512,7 +1653,9 @@ func isRequestInvalidError(err error) bool {
 	return strings.Contains(err.Error(), "invalid_request_error")
 }
 
-func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Duration, now time.Time) {
+// applyAuthFailureState records a failed result on an auth without model state. Errors the
+// retry policy treats as retryable cool the auth down for the policy's cooldown.
+func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Duration, policy *internalconfig.RetryPolicy, now time.Time) {
 	if auth == nil {
 		return
 	}
@@ -1526,6 +1669,14 @@ func applyAuthFailureState(auth *Auth, resultErr *Error, retryAfter *time.Durati
 		}
 	}
 	statusCode := statusCodeFromResult(resultErr)
+	if cooldown, transient := policyTransientCooldown(policy, resultErr); transient {
+		auth.StatusMessage = "transient upstream error"
+		auth.NextRetryAfter = time.Time{}
+		if cooldown > 0 && !quotaCooldownDisabledForAuth(auth) {
+			auth.NextRetryAfter = now.Add(cooldown)
+		}
+		return
+	}
 	switch statusCode {
 	case 401:
 		auth.StatusMessage = "unauthorized"
@@ -1659,6 +1810,7 @@ func (m *Manager) CloseExecutionSession(sessionID string) {
 
 func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, error) {
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
+	excludedAuthIDs := excludedAuthIDsFromMetadata(opts.Metadata)
 
 	m.mu.RLock()
 	executor, okExecutor := m.executors[provider]
@@ -1676,6 +1828,7 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		}
 	}
 	registryRef := registry.GetGlobalRegistry()
+	now := time.Now()
 	for _, candidate := range m.auths {
 		if candidate.Provider != provider || candidate.Disabled {
 			continue
@@ -1683,12 +1836,21 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 		if pinnedAuthID != "" && candidate.ID != pinnedAuthID {
 			continue
 		}
+		if slices.Contains(excludedAuthIDs, candidate.ID) {
+			continue
+		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
+		if m.breakerBlocks(candidate, now) {
+			continue
+		}
 		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
 			continue
 		}
+		if m.saturated(candidate) || m.pacingWait(candidate, modelKey, 0, now) > 0 {
+			continue
+		}
 		candidates = append(candidates, candidate)
 	}
 	if len(candidates) == 0 {
@@ -1717,8 +1879,9 @@ func (m *Manager) pickNext(ctx context.Context, provider, model string, opts cli
 	return authCopy, executor, nil
 }
 
-func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model string, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
+func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model string, tokens int, opts cliproxyexecutor.Options, tried map[string]struct{}) (*Auth, ProviderExecutor, string, error) {
 	pinnedAuthID := pinnedAuthIDFromMetadata(opts.Metadata)
+	excludedAuthIDs := excludedAuthIDsFromMetadata(opts.Metadata)
 
 	providerSet := make(map[string]struct{}, len(providers))
 	for _, provider := range providers {
@@ -1743,6 +1906,11 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		}
 	}
 	registryRef := registry.GetGlobalRegistry()
+	now := time.Now()
+	priority, reserving := m.requestPriority(opts)
+	var pool []*Auth
+	var saturated []string
+	var pacedWait time.Duration
 	for _, candidate := range m.auths {
 		if candidate == nil || candidate.Disabled {
 			continue
@@ -1750,6 +1918,9 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		if pinnedAuthID != "" && candidate.ID != pinnedAuthID {
 			continue
 		}
+		if slices.Contains(excludedAuthIDs, candidate.ID) {
+			continue
+		}
 		providerKey := strings.TrimSpace(strings.ToLower(candidate.Provider))
 		if providerKey == "" {
 			continue
@@ -1757,24 +1928,69 @@ func (m *Manager) pickNextMixed(ctx context.Context, providers []string, model s
 		if _, ok := providerSet[providerKey]; !ok {
 			continue
 		}
+		if _, ok := m.executors[providerKey]; !ok {
+			continue
+		}
+		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
+			continue
+		}
+		if reserving {
+			pool = append(pool, candidate)
+		}
 		if _, used := tried[candidate.ID]; used {
 			continue
 		}
-		if _, ok := m.executors[providerKey]; !ok {
+		if m.breakerBlocks(candidate, now) {
 			continue
 		}
-		if modelKey != "" && registryRef != nil && !registryRef.ClientSupportsModel(candidate.ID, modelKey) {
+		if m.saturated(candidate) {
+			saturated = append(saturated, candidate.ID)
+			continue
+		}
+		if wait := m.pacingWait(candidate, modelKey, tokens, now); wait > 0 {
+			if pacedWait == 0 || wait < pacedWait {
+				pacedWait = wait
+			}
 			continue
 		}
 		candidates = append(candidates, candidate)
 	}
+	var reserved []string
+	var reservedRetryAt time.Time
+	if denied, retryAt := m.reservedProviders(pool, model, priority, now); len(denied) > 0 {
+		// Leave the remaining credentials of these providers to higher-priority clients.
+		before := len(candidates)
+		candidates = slices.DeleteFunc(candidates, func(candidate *Auth) bool {
+			return denied[strings.ToLower(candidate.Provider)]
+		})
+		if len(candidates) < before {
+			for _, candidate := range pool {
+				if denied[strings.ToLower(candidate.Provider)] {
+					reserved = append(reserved, candidate.ID)
+				}
+			}
+			reservedRetryAt = retryAt
+		}
+	}
 	if len(candidates) == 0 {
 		m.mu.RUnlock()
+		if pacedWait > 0 {
+			return nil, nil, "", &pacedError{wait: pacedWait}
+		}
+		if len(saturated) > 0 || len(reserved) > 0 {
+			// Queue behind higher-priority clients until a slot is released or a reserved
+			// credential recovers.
+			return nil, nil, "", &saturatedError{authIDs: append(saturated, reserved...), retryAt: reservedRetryAt}
+		}
 		return nil, nil, "", &Error{Code: "auth_not_found", Message: "no auth available"}
 	}
 	selected, errPick := m.selector.Pick(ctx, "mixed", model, opts, candidates)
 	if errPick != nil {
 		m.mu.RUnlock()
+		if cooldown, ok := errors.AsType[*modelCooldownError](errPick); ok && len(saturated) > 0 {
+			// Wait for a slot, or for the cooling credentials to become usable again.
+			return nil, nil, "", &saturatedError{authIDs: saturated, retryAt: now.Add(cooldown.resetIn)}
+		}
 		return nil, nil, "", errPick
 	}
 	if selected == nil {
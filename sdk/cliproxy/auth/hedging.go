@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+	log "github.com/sirupsen/logrus"
+)
+
+// hedgeAttempt is one of the racing upstream attempts of a hedged request.
+type hedgeAttempt struct {
+	auth     *Auth
+	executor ProviderExecutor
+	provider string
+	release  func()
+	// ctx is the attempt context without the cancellation used to stop a losing attempt.
+	ctx context.Context
+	req cliproxyexecutor.Request
+
+	cancel  context.CancelFunc
+	hold    *usage.Hold
+	started chan struct{}
+	resp    cliproxyexecutor.Response
+	err     error
+	// cancelled is set when the attempt was stopped before it returned.
+	cancelled bool
+}
+
+// hedgeDelay returns how long a non-streaming request waits for the upstream to respond
+// before it is hedged, or 0 when hedging does not apply.
+func (m *Manager) hedgeDelay(routeModel string, opts cliproxyexecutor.Options) time.Duration {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	apiKey, _ := opts.Metadata[cliproxyexecutor.ClientAPIKeyMetadataKey].(string)
+	return cfg.HedgeDelay(routeModel, apiKey)
+}
+
+// executeHedged runs primary and, when its upstream has not started responding within delay,
+// a duplicate on another credential. It returns the attempt that decides the request: the
+// first to succeed, or the last to fail. The other attempt is cancelled; its usage is
+// discarded so only the winner is charged, and it is marked only for what the upstream did
+// before the race was decided.
+func (m *Manager) executeHedged(ctx context.Context, delay time.Duration, primary *hedgeAttempt, providers []string, routeModel string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, tried map[string]struct{}) *hedgeAttempt {
+	results := make(chan *hedgeAttempt, 2)
+	m.startHedgeAttempt(primary, opts, results)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case done := <-results:
+		done.hold.Release()
+		return done
+	case <-primary.started:
+		done := <-results
+		done.hold.Release()
+		return done
+	case <-timer.C:
+	}
+
+	hedge := m.acquireHedge(ctx, providers, routeModel, req, opts, tried)
+	if hedge == nil {
+		done := <-results
+		done.hold.Release()
+		return done
+	}
+	tried[hedge.auth.ID] = struct{}{}
+	log.Debugf("hedging %s: no upstream response from %s after %s, retrying on %s", routeModel, primary.auth.ID, delay, hedge.auth.ID)
+	m.startHedgeAttempt(hedge, opts, results)
+
+	first := <-results
+	if first.err != nil {
+		// The first failure is an ordinary failed attempt; the other attempt decides.
+		first.hold.Release()
+		m.markHedgeResult(first, routeModel)
+		second := <-results
+		second.hold.Release()
+		return second
+	}
+	first.hold.Release()
+	loser := hedge
+	if first == hedge {
+		loser = primary
+	}
+	loser.cancel()
+	go m.settleHedgeLoser(results, routeModel)
+	return first
+}
+
+// acquireHedge takes a second credential for a hedged request without waiting for capacity.
+func (m *Manager) acquireHedge(ctx context.Context, providers []string, routeModel string, req cliproxyexecutor.Request, opts cliproxyexecutor.Options, tried map[string]struct{}) *hedgeAttempt {
+	tokens := estimatePacingTokens(req.Payload)
+	auth, executor, provider, errPick := m.pickNextMixed(ctx, providers, routeModel, tokens, opts, tried)
+	if errPick != nil {
+		return nil
+	}
+	release, ok := m.slots.acquire(auth.ID, m.maxConcurrency(auth))
+	if !ok {
+		return nil
+	}
+	if !m.takePacing(auth, routeModel, tokens, time.Now()) {
+		release()
+		return nil
+	}
+	releaseProbe, admitted := m.admitBreaker(auth, time.Now())
+	if !admitted {
+		release()
+		return nil
+	}
+	return &hedgeAttempt{
+		auth:     auth,
+		executor: executor,
+		provider: provider,
+		release: func() {
+			release()
+			releaseProbe()
+		},
+		ctx: m.attemptContext(ctx, auth, routeModel),
+		req: m.requestForAuth(req, routeModel, auth),
+	}
+}
+
+// startHedgeAttempt executes a in the background and sends it to results once done. The
+// attempt gets its own metadata so both attempts can record their selection.
+func (m *Manager) startHedgeAttempt(a *hedgeAttempt, opts cliproxyexecutor.Options, results chan<- *hedgeAttempt) {
+	attemptCtx, cancel := context.WithCancel(a.ctx)
+	a.cancel = cancel
+	a.hold = &usage.Hold{}
+	a.started = make(chan struct{})
+	var startOnce sync.Once
+	attemptCtx = cliproxyexecutor.WithResponseObserver(attemptCtx, func(int, http.Header) {
+		startOnce.Do(func() { close(a.started) })
+	})
+	attemptCtx = usage.WithHold(attemptCtx, a.hold)
+	attemptOpts := opts
+	attemptOpts.Metadata = maps.Clone(opts.Metadata)
+	if attemptOpts.Metadata != nil {
+		attemptOpts.Metadata[cliproxyexecutor.SelectedAuthMetadataKey] = a.auth.ID
+	}
+	go func() {
+		defer cancel()
+		a.resp, a.err = a.executor.Execute(attemptCtx, a.auth, a.req, attemptOpts)
+		a.cancelled = attemptCtx.Err() != nil && a.ctx.Err() == nil
+		a.release()
+		results <- a
+	}()
+}
+
+// settleHedgeLoser waits for the losing attempt, drops its usage and records its outcome
+// unless it merely stopped because it was cancelled.
+func (m *Manager) settleHedgeLoser(results <-chan *hedgeAttempt, routeModel string) {
+	loser := <-results
+	loser.hold.Discard()
+	if loser.err != nil && loser.cancelled {
+		return
+	}
+	m.markHedgeResult(loser, routeModel)
+}
+
+// markHedgeResult records the outcome of an attempt that does not decide the request. A
+// failure caused by the client going away says nothing about the credential and is skipped.
+func (m *Manager) markHedgeResult(a *hedgeAttempt, routeModel string) {
+	if a.err != nil && a.ctx.Err() != nil {
+		return
+	}
+	result := Result{AuthID: a.auth.ID, Provider: a.provider, Model: routeModel, Success: a.err == nil}
+	if a.err != nil {
+		result.Error = &Error{Message: a.err.Error()}
+		if se, ok := errors.AsType[cliproxyexecutor.StatusError](a.err); ok && se != nil {
+			result.Error.HTTPStatus = se.StatusCode()
+		}
+		result.RetryAfter = retryAfterFromError(a.err)
+	}
+	m.MarkResult(a.ctx, result)
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/usage"
+)
+
+// stallingExecutor hangs on its first call until cancelled and answers later calls at once.
+// Every call publishes a usage record, as a real executor charging the upstream would.
+type stallingExecutor struct {
+	scriptedExecutor
+}
+
+func (e *stallingExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	first := len(e.calls) == 0
+	e.mu.Unlock()
+	usage.PublishRecord(ctx, usage.Record{Provider: e.provider, Model: req.Model, AuthID: auth.ID, Detail: usage.Detail{TotalTokens: 10}})
+	if first {
+		e.mu.Lock()
+		e.calls = append(e.calls, auth.ID)
+		e.mu.Unlock()
+		<-ctx.Done()
+		return cliproxyexecutor.Response{}, ctx.Err()
+	}
+	return e.scriptedExecutor.Execute(ctx, auth, req, opts)
+}
+
+type usageCollector struct {
+	mu      sync.Mutex
+	model   string
+	records []usage.Record
+}
+
+func (c *usageCollector) HandleUsage(_ context.Context, record usage.Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if record.Model == c.model {
+		c.records = append(c.records, record)
+	}
+}
+
+func (c *usageCollector) snapshot() []usage.Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]usage.Record(nil), c.records...)
+}
+
+func TestHedgedRequestChargesOnlyTheWinner(t *testing.T) {
+	collector := &usageCollector{model: "hedge-model"}
+	usage.RegisterPlugin(collector)
+	executor := &stallingExecutor{scriptedExecutor{provider: "claude"}}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{Hedging: []internalconfig.HedgingRule{{Models: []string{"hedge-*"}, DelayMs: 20}}})
+	for _, id := range []string{"hedge-a", "hedge-b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "claude", Status: StatusActive}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: "hedge-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	var selected string
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.SelectedAuthCallbackMetadataKey: func(id string) { selected = id }}}
+	if _, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "hedge-model"}, opts); err != nil {
+		t.Fatalf("hedged request: %v", err)
+	}
+	if len(executor.calls) != 2 || executor.calls[0] == executor.calls[1] {
+		t.Fatalf("expected a hedge on the other credential, got calls %v", executor.calls)
+	}
+	winner, loser := executor.calls[1], executor.calls[0]
+	if selected != winner {
+		t.Fatalf("selected auth = %s, want the winner %s", selected, winner)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(collector.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	records := collector.snapshot()
+	if len(records) != 1 || records[0].AuthID != winner {
+		t.Fatalf("expected one usage record for %s, got %+v", winner, records)
+	}
+	loserAuth, _ := manager.GetByID(loser)
+	if state := loserAuth.ModelStates["hedge-model"]; state != nil && state.Unavailable {
+		t.Fatalf("cancelled hedge loser was marked failed: %+v", state)
+	}
+}
+
+// hangingExecutor blocks every call until its context is cancelled.
+type hangingExecutor struct {
+	scriptedExecutor
+	started chan string
+}
+
+func (e *hangingExecutor) Execute(ctx context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.started <- auth.ID
+	<-ctx.Done()
+	return cliproxyexecutor.Response{}, ctx.Err()
+}
+
+func TestHedgedRequestCancelledByClientMarksNoCredential(t *testing.T) {
+	executor := &hangingExecutor{scriptedExecutor: scriptedExecutor{provider: "claude"}, started: make(chan string, 2)}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{Hedging: []internalconfig.HedgingRule{{Models: []string{"hedge-cancel-*"}, DelayMs: 10}}})
+	ids := []string{"hedge-cancel-a", "hedge-cancel-b"}
+	for _, id := range ids {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "claude", Status: StatusActive}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: "hedge-cancel-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-executor.started
+		<-executor.started
+		cancel()
+	}()
+	if _, err := manager.Execute(ctx, []string{"claude"}, cliproxyexecutor.Request{Model: "hedge-cancel-model"}, cliproxyexecutor.Options{}); err == nil {
+		t.Fatal("expected the cancelled request to fail")
+	}
+	for _, id := range ids {
+		auth, _ := manager.GetByID(id)
+		if state := auth.ModelStates["hedge-cancel-model"]; state != nil && state.Unavailable {
+			t.Fatalf("%s was marked failed after the client went away: %+v", id, state)
+		}
+		if auth.LastError != nil {
+			t.Fatalf("%s recorded an error after the client went away: %+v", id, auth.LastError)
+		}
+	}
+}
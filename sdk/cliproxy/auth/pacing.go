@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/thinking"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// pacingLimits are the per-minute rates of one credential and model.
+type pacingLimits struct {
+	rpm, tpm, burst int
+}
+
+// pacingBucket is a token bucket refilled continuously at rate units per second.
+type pacingBucket struct {
+	rate     float64
+	capacity float64
+	level    float64
+	updated  time.Time
+}
+
+func newPacingBucket(perMinute, capacity int, now time.Time) *pacingBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &pacingBucket{rate: float64(perMinute) / 60, capacity: float64(capacity), level: float64(capacity), updated: now}
+}
+
+func (b *pacingBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.level = min(b.capacity, b.level+elapsed*b.rate)
+		b.updated = now
+	}
+}
+
+// wait returns how long until n units are available. Requests larger than the bucket only
+// wait for a full bucket and leave it in debt.
+func (b *pacingBucket) wait(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.refill(now)
+	need := min(n, b.capacity)
+	if b.level >= need {
+		return 0
+	}
+	return time.Duration((need - b.level) / b.rate * float64(time.Second))
+}
+
+// idle reports whether the bucket went untouched longer than a full refill takes and is full
+// again. A missing bucket is always idle.
+func (b *pacingBucket) idle(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	elapsed := now.Sub(b.updated).Seconds()
+	return elapsed > b.capacity/b.rate && b.level+elapsed*b.rate >= b.capacity
+}
+
+func (b *pacingBucket) take(n float64, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.refill(now)
+	b.level -= n
+}
+
+// drain lowers the bucket to the remaining capacity the upstream reported.
+func (b *pacingBucket) drain(remaining float64, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.refill(now)
+	b.level = min(b.level, remaining)
+}
+
+type pacingState struct {
+	limits   pacingLimits
+	requests *pacingBucket
+	tokens   *pacingBucket
+	// blockedUntil holds every request back after the upstream signalled an exhausted limit.
+	blockedUntil time.Time
+}
+
+func (s *pacingState) wait(tokens int, now time.Time) time.Duration {
+	wait := max(s.requests.wait(1, now), s.tokens.wait(float64(tokens), now))
+	if now.Before(s.blockedUntil) {
+		wait = max(wait, s.blockedUntil.Sub(now))
+	}
+	return wait
+}
+
+// idle reports whether the state can be dropped without changing how requests are paced.
+func (s *pacingState) idle(now time.Time) bool {
+	return !now.Before(s.blockedUntil) && s.requests.idle(now) && s.tokens.idle(now)
+}
+
+// pacingPruneInterval is how often the pacer looks for idle states to drop.
+const pacingPruneInterval = time.Minute
+
+// requestPacer holds the buckets of paced credentials, keyed by auth ID and base model.
+type requestPacer struct {
+	mu     sync.Mutex
+	states map[string]*pacingState
+	// pruned is when idle states were last dropped.
+	pruned time.Time
+}
+
+// pruneLocked drops the states of credentials and models that stayed unused until their
+// buckets refilled, such as those of removed credentials, at most once per
+// pacingPruneInterval.
+func (p *requestPacer) pruneLocked(now time.Time) {
+	if now.Sub(p.pruned) < pacingPruneInterval {
+		return
+	}
+	p.pruned = now
+	for key, state := range p.states {
+		if state.idle(now) {
+			delete(p.states, key)
+		}
+	}
+}
+
+// pacedError is returned by the picker when every eligible credential must wait for pacing.
+type pacedError struct {
+	wait time.Duration
+}
+
+func (e *pacedError) Error() string {
+	return "all credentials are paced"
+}
+
+func pacingKey(authID, model string) string {
+	model = strings.TrimSpace(model)
+	if parsed := thinking.ParseSuffix(model); parsed.ModelName != "" {
+		model = strings.TrimSpace(parsed.ModelName)
+	}
+	return authID + "\x00" + model
+}
+
+// estimatePacingTokens approximates the input tokens of payload for TPM pacing.
+func estimatePacingTokens(payload []byte) int {
+	return len(payload) / 4
+}
+
+// pacingConfig returns the current pacing settings.
+func (m *Manager) pacingConfig() internalconfig.PacingConfig {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return internalconfig.PacingConfig{}
+	}
+	return cfg.Pacing
+}
+
+// pacingLimitsFor returns the rates of auth for model, or false when it is not paced.
+func (m *Manager) pacingLimitsFor(auth *Auth, model string) (pacingLimits, bool) {
+	if auth == nil {
+		return pacingLimits{}, false
+	}
+	names := []string{auth.Provider}
+	if compat := auth.Attributes["compat_name"]; compat != "" {
+		names = append(names, compat, "openai-compatibility")
+	}
+	if parsed := thinking.ParseSuffix(model); parsed.ModelName != "" {
+		model = parsed.ModelName
+	}
+	var limits pacingLimits
+	cfg := m.pacingConfig()
+	for _, name := range names {
+		if rule, ok := cfg.RuleFor(name, model); ok {
+			limits = pacingLimits{rpm: rule.RPM, tpm: rule.TPM, burst: rule.Burst}
+			break
+		}
+	}
+	rpm, rpmOK, tpm, tpmOK := auth.PacingOverride()
+	if rpmOK {
+		limits.rpm = rpm
+	}
+	if tpmOK {
+		limits.tpm = tpm
+	}
+	limits.burst = max(limits.burst, 1)
+	return limits, limits.rpm > 0 || limits.tpm > 0
+}
+
+// stateLocked returns the pacing state of key, rebuilding it when the limits changed.
+func (p *requestPacer) stateLocked(key string, limits pacingLimits, now time.Time) *pacingState {
+	p.pruneLocked(now)
+	state := p.states[key]
+	if state != nil && state.limits == limits {
+		return state
+	}
+	if p.states == nil {
+		p.states = make(map[string]*pacingState)
+	}
+	next := &pacingState{
+		limits:   limits,
+		requests: newPacingBucket(limits.rpm, limits.burst, now),
+		tokens:   newPacingBucket(limits.tpm, limits.tpm, now),
+	}
+	if state != nil {
+		next.blockedUntil = state.blockedUntil
+	}
+	p.states[key] = next
+	return next
+}
+
+// pacingWait returns how long auth must wait before it may send a request of tokens
+// estimated input tokens for model. Unpaced credentials never wait.
+func (m *Manager) pacingWait(auth *Auth, model string, tokens int, now time.Time) time.Duration {
+	limits, ok := m.pacingLimitsFor(auth, model)
+	if !ok {
+		return 0
+	}
+	m.pacer.mu.Lock()
+	defer m.pacer.mu.Unlock()
+	return m.pacer.stateLocked(pacingKey(auth.ID, model), limits, now).wait(tokens, now)
+}
+
+// takePacing consumes one request and tokens from the buckets of auth, reporting false when
+// another request used the capacity since the pick.
+func (m *Manager) takePacing(auth *Auth, model string, tokens int, now time.Time) bool {
+	limits, ok := m.pacingLimitsFor(auth, model)
+	if !ok {
+		return true
+	}
+	m.pacer.mu.Lock()
+	defer m.pacer.mu.Unlock()
+	state := m.pacer.stateLocked(pacingKey(auth.ID, model), limits, now)
+	if state.wait(tokens, now) > 0 {
+		return false
+	}
+	state.requests.take(1, now)
+	state.tokens.take(float64(tokens), now)
+	return true
+}
+
+// blockPacing holds requests of a paced credential and model back until until.
+func (m *Manager) blockPacing(authID, model string, until time.Time) {
+	m.pacer.mu.Lock()
+	defer m.pacer.mu.Unlock()
+	if state := m.pacer.states[pacingKey(authID, model)]; state != nil && until.After(state.blockedUntil) {
+		state.blockedUntil = until
+	}
+}
+
+// withPacingObserver lets the buckets of a paced credential adapt to the rate-limit headers of
+// its upstream responses.
+func (m *Manager) withPacingObserver(ctx context.Context, auth *Auth, model string) context.Context {
+	if _, ok := m.pacingLimitsFor(auth, model); !ok {
+		return ctx
+	}
+	authID := auth.ID
+	return cliproxyexecutor.WithResponseObserver(ctx, func(status int, headers http.Header) {
+		m.adaptPacing(authID, model, status, headers, time.Now())
+	})
+}
+
+// rateLimitHeaders pairs remaining-capacity headers with their reset headers.
+var rateLimitHeaders = []struct {
+	remaining, reset string
+	tokens           bool
+}{
+	{"x-ratelimit-remaining-requests", "x-ratelimit-reset-requests", false},
+	{"x-ratelimit-remaining-tokens", "x-ratelimit-reset-tokens", true},
+	{"anthropic-ratelimit-requests-remaining", "anthropic-ratelimit-requests-reset", false},
+	{"anthropic-ratelimit-tokens-remaining", "anthropic-ratelimit-tokens-reset", true},
+	{"anthropic-ratelimit-input-tokens-remaining", "anthropic-ratelimit-input-tokens-reset", true},
+}
+
+// adaptPacing applies Retry-After and rate-limit headers of an upstream response to the
+// buckets of authID and model.
+func (m *Manager) adaptPacing(authID, model string, status int, headers http.Header, now time.Time) {
+	if len(headers) == 0 {
+		return
+	}
+	m.pacer.mu.Lock()
+	defer m.pacer.mu.Unlock()
+	state := m.pacer.states[pacingKey(authID, model)]
+	if state == nil {
+		return
+	}
+	block := func(until time.Time) {
+		if until.After(state.blockedUntil) {
+			state.blockedUntil = until
+		}
+	}
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		if until, ok := parseRateLimitReset(headers.Get("Retry-After"), now); ok {
+			block(until)
+		}
+	}
+	for _, h := range rateLimitHeaders {
+		remaining, err := strconv.ParseFloat(strings.TrimSpace(headers.Get(h.remaining)), 64)
+		if err != nil {
+			continue
+		}
+		if remaining <= 0 {
+			if until, ok := parseRateLimitReset(headers.Get(h.reset), now); ok {
+				block(until)
+			}
+			continue
+		}
+		if h.tokens {
+			state.tokens.drain(remaining, now)
+		} else {
+			state.requests.drain(remaining, now)
+		}
+	}
+}
+
+// parseRateLimitReset reads a reset header given as seconds, a Go-style duration ("6m0s",
+// "20ms"), an RFC 3339 timestamp or an HTTP date.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return now.Add(time.Duration(seconds * float64(time.Second))), seconds > 0
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d), d > 0
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, t.After(now)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t, t.After(now)
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// headerExecutor reports headers for every upstream response before answering like its
+// embedded scriptedExecutor.
+type headerExecutor struct {
+	scriptedExecutor
+	headers http.Header
+}
+
+func (e *headerExecutor) Execute(ctx context.Context, auth *Auth, req cliproxyexecutor.Request, opts cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	cliproxyexecutor.ObserveResponse(ctx, http.StatusOK, e.headers)
+	return e.scriptedExecutor.Execute(ctx, auth, req, opts)
+}
+
+func TestPacingSpacesRequestsAndAdaptsToHeaders(t *testing.T) {
+	executor := &headerExecutor{scriptedExecutor: scriptedExecutor{provider: "claude"}}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{Pacing: internalconfig.PacingConfig{
+		Rules:     []internalconfig.PacingRule{{Providers: []string{"claude"}, RPM: 600, Burst: 1}},
+		MaxWaitMs: 1000,
+	}})
+	for _, id := range []string{"paced-a", "paced-b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "claude", Status: StatusActive}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: "paced-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	execute := func() error {
+		_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "paced-model"}, cliproxyexecutor.Options{})
+		return err
+	}
+
+	start := time.Now()
+	for range 2 {
+		if err := execute(); err != nil {
+			t.Fatalf("request: %v", err)
+		}
+	}
+	if executor.calls[0] == executor.calls[1] {
+		t.Fatalf("second request should rotate to the unpaced credential, got %v", executor.calls)
+	}
+	if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+		t.Fatalf("requests with capacity were delayed by %v", elapsed)
+	}
+	if err := execute(); err != nil {
+		t.Fatalf("paced request: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("third request was not paced (elapsed %v)", elapsed)
+	}
+
+	// An exhausted upstream limit holds both credentials back longer than the wait limit.
+	executor.headers = http.Header{"Anthropic-Ratelimit-Requests-Remaining": {"0"}, "Anthropic-Ratelimit-Requests-Reset": {time.Now().Add(time.Minute).UTC().Format(time.RFC3339)}}
+	for range 2 {
+		time.Sleep(110 * time.Millisecond)
+		if err := execute(); err != nil {
+			t.Fatalf("request: %v", err)
+		}
+	}
+	err := execute()
+	if statusCodeFromError(err) != http.StatusTooManyRequests {
+		t.Fatalf("expected paced credentials to reject with 429, got %v", err)
+	}
+	if len(executor.calls) != 5 {
+		t.Fatalf("blocked credentials were called: %v", executor.calls)
+	}
+}
+
+func TestPacerDropsIdleStates(t *testing.T) {
+	var pacer requestPacer
+	now := time.Now()
+	limits := pacingLimits{rpm: 60, burst: 2}
+	pacer.stateLocked(pacingKey("removed", "m"), limits, now).requests.take(2, now)
+	blocked := pacer.stateLocked(pacingKey("blocked", "m"), limits, now)
+	blocked.blockedUntil = now.Add(time.Hour)
+
+	// At one request per second the bucket refills in two seconds, well within the prune interval.
+	later := now.Add(pacingPruneInterval)
+	pacer.stateLocked(pacingKey("active", "m"), limits, later)
+	if _, ok := pacer.states[pacingKey("removed", "m")]; ok {
+		t.Fatal("idle state of a refilled bucket was kept")
+	}
+	if _, ok := pacer.states[pacingKey("blocked", "m")]; !ok {
+		t.Fatal("state blocked by the upstream was dropped")
+	}
+	if len(pacer.states) != 2 {
+		t.Fatalf("states = %d, want 2", len(pacer.states))
+	}
+}
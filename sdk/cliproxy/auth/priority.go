@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// priorityConfig returns the current priority class settings.
+func (m *Manager) priorityConfig() internalconfig.PriorityConfig {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return internalconfig.PriorityConfig{}
+	}
+	return cfg.Priority
+}
+
+// requestPriority returns the priority of the client class that sent the request, and whether
+// any capacity reservation outranks it.
+func (m *Manager) requestPriority(opts cliproxyexecutor.Options) (int, bool) {
+	cfg := m.priorityConfig()
+	if len(cfg.Classes) == 0 {
+		return 0, false
+	}
+	apiKey, _ := opts.Metadata[cliproxyexecutor.ClientAPIKeyMetadataKey].(string)
+	class, _ := cfg.ClassFor(apiKey)
+	for _, reservation := range cfg.Reservations {
+		if reserved, ok := cfg.Class(reservation.Class); ok && reserved.Priority > class.Priority {
+			return class.Priority, true
+		}
+	}
+	return class.Priority, false
+}
+
+// reservedProviders returns the providers whose available credentials for model are all held
+// back for classes above priority. A credential is available when it is not cooling down,
+// behind an open breaker or at its concurrency limit. retryAt is the earliest time a cooling
+// credential of those providers becomes available again, or zero. The caller holds m.mu.
+func (m *Manager) reservedProviders(pool []*Auth, model string, priority int, now time.Time) (denied map[string]bool, retryAt time.Time) {
+	cfg := m.priorityConfig()
+	for _, reservation := range cfg.Reservations {
+		class, ok := cfg.Class(reservation.Class)
+		if !ok || class.Priority <= priority {
+			continue
+		}
+		total, available := 0, 0
+		var recoverAt time.Time
+		for _, auth := range pool {
+			if !reservation.AppliesTo(auth.Provider) {
+				continue
+			}
+			total++
+			if blocked, _, next := isAuthBlockedForModel(auth, model, now); blocked {
+				if !next.IsZero() && (recoverAt.IsZero() || next.Before(recoverAt)) {
+					recoverAt = next
+				}
+				continue
+			}
+			if m.breakerBlocks(auth, now) || m.saturated(auth) {
+				continue
+			}
+			available++
+		}
+		if total == 0 || available > reservation.Reserved(total) {
+			continue
+		}
+		if denied == nil {
+			denied = make(map[string]bool)
+		}
+		if !recoverAt.IsZero() && (retryAt.IsZero() || recoverAt.Before(retryAt)) {
+			retryAt = recoverAt
+		}
+		for _, auth := range pool {
+			if reservation.AppliesTo(auth.Provider) {
+				denied[strings.ToLower(auth.Provider)] = true
+			}
+		}
+	}
+	return denied, retryAt
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+func TestReservationKeepsLastCredentialForInteractiveClients(t *testing.T) {
+	executor := &scriptedExecutor{provider: "claude"}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	cfg := &internalconfig.Config{Priority: internalconfig.PriorityConfig{
+		DefaultClass: "Interactive",
+		Classes: []internalconfig.PriorityClass{
+			{Name: "interactive", Priority: 100},
+			{Name: "batch", Priority: 10, APIKeys: []string{"batch-key"}},
+		},
+		Reservations: []internalconfig.CapacityReservation{{Class: "interactive", Providers: []string{"claude"}, MinCredentials: 1}},
+	}}
+	cfg.SanitizePriority()
+	manager.SetConfig(cfg)
+	cooling := map[string]*ModelState{"prio-model": {Unavailable: true, Status: StatusError, NextRetryAfter: time.Now().Add(time.Hour), Quota: QuotaState{Exceeded: true}}}
+	for _, auth := range []*Auth{
+		{ID: "prio-a", Provider: "claude", Status: StatusActive},
+		{ID: "prio-b", Provider: "claude", Status: StatusActive},
+		{ID: "prio-c", Provider: "claude", Status: StatusActive, ModelStates: cooling},
+	} {
+		if _, err := manager.Register(context.Background(), auth); err != nil {
+			t.Fatalf("register %s: %v", auth.ID, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(auth.ID, "claude", []*registry.ModelInfo{{ID: "prio-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+	}
+	execute := func(apiKey string) error {
+		opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ClientAPIKeyMetadataKey: apiKey}}
+		_, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "prio-model"}, opts)
+		return err
+	}
+
+	if err := execute("batch-key"); err != nil {
+		t.Fatalf("batch request with spare capacity: %v", err)
+	}
+
+	// With one more credential cooling down only the reserved one is left.
+	manager.mu.Lock()
+	manager.auths["prio-a"].ModelStates = cooling
+	manager.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ClientAPIKeyMetadataKey: "batch-key"}}
+	if _, err := manager.Execute(ctx, []string{"claude"}, cliproxyexecutor.Request{Model: "prio-model"}, opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected batch to wait off the reserved credential, got %v", err)
+	}
+	if err := execute("ide-key"); err != nil {
+		t.Fatalf("interactive request: %v", err)
+	}
+	if len(executor.calls) != 2 || executor.calls[1] != "prio-b" {
+		t.Fatalf("unexpected calls: %v", executor.calls)
+	}
+}
+
+func TestReservationQueuesLowerPriorityRequests(t *testing.T) {
+	executor := &gatedExecutor{scriptedExecutor: scriptedExecutor{provider: "claude"}, started: make(chan string, 2), gate: make(chan struct{})}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	cfg := &internalconfig.Config{
+		Concurrency: internalconfig.ConcurrencyConfig{Defaults: map[string]int{"claude": 1}, QueueSize: 2, QueueTimeoutSeconds: 5},
+		Priority: internalconfig.PriorityConfig{
+			DefaultClass: "interactive",
+			Classes: []internalconfig.PriorityClass{
+				{Name: "interactive", Priority: 100},
+				{Name: "batch", Priority: 10, APIKeys: []string{"batch-key"}},
+			},
+			Reservations: []internalconfig.CapacityReservation{{Class: "interactive", Providers: []string{"claude"}, MinCredentials: 1}},
+		},
+	}
+	cfg.SanitizePriority()
+	manager.SetConfig(cfg)
+	for _, id := range []string{"queue-a", "queue-b"} {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: "claude", Status: StatusActive}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, "claude", []*registry.ModelInfo{{ID: "queue-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	results := make(chan string, 2)
+	execute := func(apiKey string) {
+		opts := cliproxyexecutor.Options{Metadata: map[string]any{cliproxyexecutor.ClientAPIKeyMetadataKey: apiKey}}
+		if _, err := manager.Execute(context.Background(), []string{"claude"}, cliproxyexecutor.Request{Model: "queue-model"}, opts); err != nil {
+			results <- apiKey + ": " + err.Error()
+			return
+		}
+		results <- apiKey
+	}
+
+	// The interactive request leaves only the reserved credential free.
+	go execute("ide-key")
+	<-executor.started
+	go execute("batch-key")
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.ConcurrencyStats().QueueDepth != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("batch request did not queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	executor.gate <- struct{}{}
+	if got := <-results; got != "ide-key" {
+		t.Fatalf("interactive request: %s", got)
+	}
+	<-executor.started
+	executor.gate <- struct{}{}
+	if got := <-results; got != "batch-key" {
+		t.Fatalf("batch request: %s", got)
+	}
+}
+
+func TestSlotQueueOrdersWaitersByPriority(t *testing.T) {
+	var slots credentialSlots
+	// "d" rejoins the queue after losing its slot and keeps its earlier arrival number.
+	for _, w := range []struct {
+		priority int
+		seq      uint64
+	}{{10, 2}, {100, 3}, {10, 4}, {100, 1}, {50, 5}} {
+		slots.enqueueLocked(&slotWaiter{priority: w.priority, seq: w.seq, grantID: string(rune('a' + slots.waiters.Len()))})
+	}
+	var order string
+	for e := slots.waiters.Front(); e != nil; e = e.Next() {
+		order += e.Value.(*slotWaiter).grantID
+	}
+	if order != "dbeac" {
+		t.Fatalf("queue order = %q, want %q", order, "dbeac")
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// ResponseRewriter edits the output of one execution attempt before it is returned to the caller.
+type ResponseRewriter interface {
+	// RewriteResponse edits a non-streaming response body.
+	RewriteResponse(payload []byte) []byte
+	// RewriteStreamChunk edits one streamed chunk. An empty result drops the chunk.
+	RewriteStreamChunk(chunk []byte) []byte
+}
+
+// ResponseRewriterProvider builds response rewriters from the runtime config.
+type ResponseRewriterProvider interface {
+	// ResponseRewriterFor returns the rewriter for an attempt served by auth through provider
+	// for the upstream model, or nil when no rule can apply.
+	ResponseRewriterFor(cfg *internalconfig.Config, auth *Auth, provider, model string, opts cliproxyexecutor.Options) ResponseRewriter
+}
+
+// SetResponseRewriterProvider registers the provider used to post-process executor output.
+func (m *Manager) SetResponseRewriterProvider(p ResponseRewriterProvider) {
+	m.mu.Lock()
+	m.rwProvider = p
+	m.mu.Unlock()
+}
+
+func (m *Manager) responseRewriterFor(auth *Auth, provider, model string, opts cliproxyexecutor.Options) ResponseRewriter {
+	m.mu.RLock()
+	p := m.rwProvider
+	m.mu.RUnlock()
+	if p == nil || auth == nil {
+		return nil
+	}
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.ResponsePayload) == 0 {
+		return nil
+	}
+	return p.ResponseRewriterFor(cfg, auth, provider, model, opts)
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+	"github.com/tidwall/gjson"
+)
+
+// retryState tracks the upstream attempts of one request across credentials and cooldown waits.
+type retryState struct {
+	attempts int
+	// stop is set once a retry policy rules out further attempts.
+	stop bool
+	// sameAuthID pins the next pick to the credential that just failed.
+	sameAuthID string
+	// lastAuth and lastProvider identify the credential of the last failed attempt.
+	lastAuth     *Auth
+	lastProvider string
+}
+
+// retryPolicyFor returns the configured retry policy for the provider of auth, or nil.
+func (m *Manager) retryPolicyFor(auth *Auth, provider string) *internalconfig.RetryPolicy {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil || len(cfg.RetryPolicies) == 0 {
+		return nil
+	}
+	names := []string{provider}
+	if auth != nil {
+		names = append(names, auth.Provider)
+		if compat := auth.Attributes["compat_name"]; compat != "" {
+			names = append(names, compat, "openai-compatibility")
+		}
+	}
+	return cfg.RetryPolicyFor(names...)
+}
+
+// retryPolicyForRequest returns the policy of the credential whose attempt failed last, or,
+// before any attempt failed, the policy of the first provider that has one.
+func (m *Manager) retryPolicyForRequest(state *retryState, providers []string) *internalconfig.RetryPolicy {
+	if state != nil && state.lastAuth != nil {
+		return m.retryPolicyFor(state.lastAuth, state.lastProvider)
+	}
+	for _, provider := range providers {
+		if policy := m.retryPolicyFor(nil, provider); policy != nil {
+			return policy
+		}
+	}
+	return nil
+}
+
+// continueAfterFailure applies the retry policy of the provider whose attempt failed with err.
+// It reports whether the request may try again; before returning true it waits out the policy
+// backoff and, for same-credential policies, pins the next attempt to auth.
+func (m *Manager) continueAfterFailure(ctx context.Context, state *retryState, auth *Auth, provider string, err error) bool {
+	if state == nil {
+		return true
+	}
+	state.attempts++
+	state.lastAuth, state.lastProvider = auth, provider
+	policy := m.retryPolicyFor(auth, provider)
+	if policy == nil {
+		return true
+	}
+	if policy.Classifies() && !policy.Retryable(statusCodeFromError(err), err.Error()) {
+		state.stop = true
+		return false
+	}
+	if limit := policy.AttemptLimit(); limit > 0 && state.attempts >= limit {
+		state.stop = true
+		return false
+	}
+	if policy.SameCredential && auth != nil {
+		state.sameAuthID = auth.ID
+	}
+	if errWait := waitForCooldown(ctx, policy.Backoff.Delay(state.attempts)); errWait != nil {
+		state.stop = true
+		return false
+	}
+	return true
+}
+
+// pickOptions returns opts pinned to the credential a same-credential policy retries, if any.
+func (state *retryState) pickOptions(opts cliproxyexecutor.Options, tried map[string]struct{}) cliproxyexecutor.Options {
+	if state == nil || state.sameAuthID == "" {
+		return opts
+	}
+	authID := state.sameAuthID
+	state.sameAuthID = ""
+	delete(tried, authID)
+	meta := make(map[string]any, len(opts.Metadata)+1)
+	for key, value := range opts.Metadata {
+		meta[key] = value
+	}
+	meta[cliproxyexecutor.PinnedAuthMetadataKey] = authID
+	opts.Metadata = meta
+	return opts
+}
+
+// policyTransientCooldown resolves the cooldown for a failed result when policy treats its
+// status or body as retryable. ok is false when the built-in handling applies; 401, 402, 404
+// and 429 always keep it.
+func policyTransientCooldown(policy *internalconfig.RetryPolicy, resultErr *Error) (time.Duration, bool) {
+	status := statusCodeFromResult(resultErr)
+	switch status {
+	case http.StatusUnauthorized, http.StatusPaymentRequired, http.StatusNotFound, http.StatusTooManyRequests:
+		return 0, false
+	}
+	if !policy.Classifies() || resultErr == nil || !policy.Retryable(status, resultErr.Message) {
+		return 0, false
+	}
+	if cooldown, override := policy.Cooldown(); override {
+		return cooldown, true
+	}
+	return time.Minute, true
+}
+
+// retryDelayFromMessage extracts the google.rpc.RetryInfo delay some providers send with 429
+// errors ("retryDelay": "12.5s").
+func retryDelayFromMessage(message string) *time.Duration {
+	start := strings.IndexByte(message, '{')
+	if start < 0 || !strings.Contains(message, "retryDelay") {
+		return nil
+	}
+	var delay *time.Duration
+	gjson.Get(message[start:], "error.details").ForEach(func(_, detail gjson.Result) bool {
+		value := detail.Get("retryDelay").String()
+		if value == "" {
+			return true
+		}
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			delay = &parsed
+			return false
+		}
+		return true
+	})
+	return delay
+}
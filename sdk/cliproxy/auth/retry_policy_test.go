@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// scriptedExecutor fails with the queued statuses (0 means success) and records the auths it ran on.
+type scriptedExecutor struct {
+	mu       sync.Mutex
+	provider string
+	statuses []int
+	calls    []string
+}
+
+func (e *scriptedExecutor) Identifier() string { return e.provider }
+
+func (e *scriptedExecutor) Execute(_ context.Context, auth *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, auth.ID)
+	status := 0
+	if len(e.statuses) > 0 {
+		status, e.statuses = e.statuses[0], e.statuses[1:]
+	}
+	if status != 0 {
+		return cliproxyexecutor.Response{}, &Error{HTTPStatus: status, Message: "upstream overloaded"}
+	}
+	return cliproxyexecutor.Response{Payload: []byte("ok")}, nil
+}
+
+func (e *scriptedExecutor) ExecuteStream(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	return nil, &Error{HTTPStatus: http.StatusNotImplemented, Message: "not implemented"}
+}
+
+func (e *scriptedExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	return auth, nil
+}
+
+func (e *scriptedExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *scriptedExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+func newRetryPolicyManager(t *testing.T, executor *scriptedExecutor, policies []internalconfig.RetryPolicy, authIDs ...string) *Manager {
+	t.Helper()
+	cfg := &internalconfig.Config{RetryPolicies: policies}
+	cfg.SanitizeRetryPolicies()
+	manager := NewManager(nil, &FillFirstSelector{}, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(cfg)
+	for _, id := range authIDs {
+		if _, err := manager.Register(context.Background(), &Auth{ID: id, Provider: executor.provider, Status: StatusActive}); err != nil {
+			t.Fatalf("register %s: %v", id, err)
+		}
+		registry.GetGlobalRegistry().RegisterClient(id, executor.provider, []*registry.ModelInfo{{ID: "retry-model"}})
+		t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(id) })
+	}
+	return manager
+}
+
+func TestRetryPolicyStopsOnNonRetryableStatus(t *testing.T) {
+	executor := &scriptedExecutor{provider: "codex", statuses: []int{http.StatusForbidden}}
+	manager := newRetryPolicyManager(t, executor, []internalconfig.RetryPolicy{
+		{Providers: []string{"CODEX"}, StatusCodes: []int{http.StatusInternalServerError}},
+	}, "retry-a", "retry-b")
+
+	_, err := manager.Execute(context.Background(), []string{"codex"}, cliproxyexecutor.Request{Model: "retry-model"}, cliproxyexecutor.Options{})
+	if statusCodeFromError(err) != http.StatusForbidden {
+		t.Fatalf("expected the 403 to reach the caller, got %v", err)
+	}
+	if len(executor.calls) != 1 {
+		t.Fatalf("non-retryable status rotated credentials: %v", executor.calls)
+	}
+}
+
+func TestRetryPolicyRetriesSameCredential(t *testing.T) {
+	executor := &scriptedExecutor{provider: "codex", statuses: []int{529, 529, 0}}
+	manager := newRetryPolicyManager(t, executor, []internalconfig.RetryPolicy{
+		{
+			Providers:      []string{"codex"},
+			ErrorPatterns:  []string{"overloaded"},
+			SameCredential: true,
+			Backoff:        internalconfig.RetryBackoff{Strategy: internalconfig.RetryBackoffExponential, InitialMs: 1, Jitter: 0.5},
+		},
+	}, "retry-a", "retry-b")
+
+	resp, err := manager.Execute(context.Background(), []string{"codex"}, cliproxyexecutor.Request{Model: "retry-model"}, cliproxyexecutor.Options{})
+	if err != nil || string(resp.Payload) != "ok" {
+		t.Fatalf("Execute: %v %q", err, resp.Payload)
+	}
+	if len(executor.calls) != 3 || executor.calls[0] != executor.calls[1] || executor.calls[1] != executor.calls[2] {
+		t.Fatalf("expected three attempts on one credential, got %v", executor.calls)
+	}
+	if auth, _ := manager.GetByID(executor.calls[0]); auth.ModelStates["retry-model"] != nil && !auth.ModelStates["retry-model"].NextRetryAfter.IsZero() {
+		t.Fatal("same-credential retries should not cool the credential down")
+	}
+
+	executor.statuses = []int{529, 529, 529, 0}
+	executor.calls = nil
+	if _, err = manager.Execute(context.Background(), []string{"codex"}, cliproxyexecutor.Request{Model: "retry-model"}, cliproxyexecutor.Options{}); statusCodeFromError(err) != 529 {
+		t.Fatalf("expected the attempt limit to surface the 529, got %v", err)
+	}
+	if len(executor.calls) != 3 {
+		t.Fatalf("expected the default limit of three attempts, got %v", executor.calls)
+	}
+}
+
+func TestRetryDelayFromMessage(t *testing.T) {
+	message := `{"error":{"code":429,"details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo"},{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"12.5s"}]}}`
+	delay := retryDelayFromMessage(message)
+	if delay == nil || delay.Seconds() != 12.5 {
+		t.Fatalf("retryDelay = %v", delay)
+	}
+	if retryDelayFromMessage("rate limited") != nil {
+		t.Fatal("plain message yielded a delay")
+	}
+}
+
+func TestShouldRetryAfterErrorUsesPolicyOfFailedCredential(t *testing.T) {
+	manager := NewManager(nil, nil, nil)
+	manager.SetRetryConfig(3, 30*time.Second)
+	cfg := &internalconfig.Config{RetryPolicies: []internalconfig.RetryPolicy{
+		{Providers: []string{"openai-compatibility"}, StatusCodes: []int{http.StatusInternalServerError}},
+	}}
+	cfg.SanitizeRetryPolicies()
+	manager.SetConfig(cfg)
+	auth := &Auth{
+		ID:         "retry-compat",
+		Provider:   "openrouter",
+		Attributes: map[string]string{"compat_name": "openrouter"},
+		ModelStates: map[string]*ModelState{
+			"retry-model": {Unavailable: true, Status: StatusError, NextRetryAfter: time.Now().Add(5 * time.Second)},
+		},
+	}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	state := &retryState{lastAuth: auth, lastProvider: "openrouter"}
+	_, maxWait := manager.retrySettings()
+
+	if _, retry := manager.shouldRetryAfterError(&Error{HTTPStatus: http.StatusForbidden, Message: "denied"}, 0, []string{"openrouter"}, "retry-model", maxWait, state); retry {
+		t.Fatal("compatibility policy did not stop a non-retryable status")
+	}
+	if _, retry := manager.shouldRetryAfterError(&Error{HTTPStatus: http.StatusInternalServerError, Message: "boom"}, 0, []string{"openrouter"}, "retry-model", maxWait, state); !retry {
+		t.Fatal("compatibility policy did not retry a listed status")
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+)
+
+// streamStallTimeouts resolves the first-chunk and inter-chunk limits configured for a stream
+// served by provider for the routed or upstream model.
+func (m *Manager) streamStallTimeouts(provider, routeModel, upstreamModel string) (time.Duration, time.Duration) {
+	cfg, _ := m.runtimeConfig.Load().(*internalconfig.Config)
+	if cfg == nil {
+		return 0, 0
+	}
+	return cfg.Streaming.StallTimeouts(provider, routeModel, upstreamModel)
+}
+
+// stallTimer fires when a stream waits longer than the duration of its last reset.
+type stallTimer struct {
+	timer *time.Timer
+}
+
+// reset restarts the timer for d; d <= 0 disarms it.
+func (t *stallTimer) reset(d time.Duration) {
+	if d <= 0 {
+		t.stop()
+		return
+	}
+	if t.timer == nil {
+		t.timer = time.NewTimer(d)
+		return
+	}
+	t.timer.Reset(d)
+}
+
+// C returns the expiry channel, or nil (never ready) while disarmed.
+func (t *stallTimer) C() <-chan time.Time {
+	if t.timer == nil {
+		return nil
+	}
+	return t.timer.C
+}
+
+func (t *stallTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	internalconfig "github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	cliproxyexecutor "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/executor"
+)
+
+// hangingStreamExecutor sends one chunk and then stays silent until its context is cancelled.
+type hangingStreamExecutor struct {
+	aborted chan struct{}
+}
+
+func (e *hangingStreamExecutor) Identifier() string { return "antigravity" }
+
+func (e *hangingStreamExecutor) Execute(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *hangingStreamExecutor) ExecuteStream(ctx context.Context, _ *Auth, _ cliproxyexecutor.Request, _ cliproxyexecutor.Options) (<-chan cliproxyexecutor.StreamChunk, error) {
+	ch := make(chan cliproxyexecutor.StreamChunk)
+	go func() {
+		defer close(ch)
+		ch <- cliproxyexecutor.StreamChunk{Payload: []byte("first")}
+		<-ctx.Done()
+		close(e.aborted)
+		ch <- cliproxyexecutor.StreamChunk{Err: ctx.Err()}
+	}()
+	return ch, nil
+}
+
+func (e *hangingStreamExecutor) Refresh(_ context.Context, auth *Auth) (*Auth, error) {
+	return auth, nil
+}
+
+func (e *hangingStreamExecutor) CountTokens(context.Context, *Auth, cliproxyexecutor.Request, cliproxyexecutor.Options) (cliproxyexecutor.Response, error) {
+	return cliproxyexecutor.Response{}, nil
+}
+
+func (e *hangingStreamExecutor) HttpRequest(context.Context, *Auth, *http.Request) (*http.Response, error) {
+	return nil, nil
+}
+
+func TestExecuteStreamAbortsStalledUpstream(t *testing.T) {
+	executor := &hangingStreamExecutor{aborted: make(chan struct{})}
+	manager := NewManager(nil, nil, nil)
+	manager.RegisterExecutor(executor)
+	manager.SetConfig(&internalconfig.Config{SDKConfig: internalconfig.SDKConfig{Streaming: internalconfig.StreamingConfig{
+		IdleTimeoutSeconds: 300,
+		Timeouts: []internalconfig.StreamTimeoutRule{
+			{Providers: []string{"gemini-cli"}, IdleTimeoutSeconds: 600},
+			{Providers: []string{"antigravity"}, Models: []string{"stall-*"}, IdleTimeoutSeconds: 1},
+		},
+	}}})
+
+	auth := &Auth{ID: "stall-auth", Provider: "antigravity", Status: StatusActive}
+	if _, err := manager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	registry.GetGlobalRegistry().RegisterClient(auth.ID, auth.Provider, []*registry.ModelInfo{{ID: "stall-model"}})
+	t.Cleanup(func() { registry.GetGlobalRegistry().UnregisterClient(auth.ID) })
+
+	chunks, err := manager.ExecuteStream(context.Background(), []string{"antigravity"}, cliproxyexecutor.Request{Model: "stall-model"}, cliproxyexecutor.Options{Stream: true})
+	if err != nil {
+		t.Fatalf("ExecuteStream: %v", err)
+	}
+	started := time.Now()
+	var payloads int
+	var streamErr error
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			streamErr = chunk.Err
+			continue
+		}
+		payloads++
+	}
+	if payloads != 1 {
+		t.Fatalf("expected the chunk sent before the stall, got %d", payloads)
+	}
+	var stallErr *Error
+	if !errors.As(streamErr, &stallErr) || stallErr.Code != "stream_stalled" || stallErr.HTTPStatus != http.StatusGatewayTimeout {
+		t.Fatalf("expected stream_stalled error, got %v", streamErr)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("stall detected after %s, expected the 1s model override", elapsed)
+	}
+	select {
+	case <-executor.aborted:
+	case <-time.After(time.Second):
+		t.Fatal("upstream call was not cancelled")
+	}
+	updated, _ := manager.GetByID(auth.ID)
+	if state := updated.ModelStates["stall-model"]; state == nil || state.NextRetryAfter.IsZero() {
+		t.Fatalf("stalled credential not cooled down: %+v", updated.ModelStates)
+	}
+}
@@ -255,6 +255,38 @@ func (a *Auth) RequestRetryOverride() (int, bool) {
 	return 0, false
 }
 
+// MaxConcurrencyOverride returns the auth-file scoped max_concurrency limit when present.
+// The value is read from metadata key "max_concurrency" (or "max-concurrency"); 0 lifts the
+// provider default.
+func (a *Auth) MaxConcurrencyOverride() (int, bool) {
+	if a == nil || a.Metadata == nil {
+		return 0, false
+	}
+	for _, key := range []string{"max_concurrency", "max-concurrency"} {
+		if val, ok := a.Metadata[key]; ok {
+			if parsed, okParse := parseIntAny(val); okParse {
+				return max(parsed, 0), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// PacingOverride returns the auth-file scoped requests and tokens per minute from metadata keys
+// "rpm" and "tpm". Each value reports ok separately; 0 leaves that dimension unpaced.
+func (a *Auth) PacingOverride() (rpm int, rpmOK bool, tpm int, tpmOK bool) {
+	if a == nil || a.Metadata == nil {
+		return 0, false, 0, false
+	}
+	if val, ok := a.Metadata["rpm"]; ok {
+		rpm, rpmOK = parseIntAny(val)
+	}
+	if val, ok := a.Metadata["tpm"]; ok {
+		tpm, tpmOK = parseIntAny(val)
+	}
+	return max(rpm, 0), rpmOK, max(tpm, 0), tpmOK
+}
+
 func parseBoolAny(val any) (bool, bool) {
 	switch typed := val.(type) {
 	case bool:
@@ -1,9 +1,17 @@
 package executor
 
-import "context"
+import (
+	"context"
+	"net/http"
+)
 
 type downstreamWebsocketContextKey struct{}
 
+type responseObserverContextKey struct{}
+
+// ResponseObserver receives the status and headers of an upstream response.
+type ResponseObserver func(status int, headers http.Header)
+
 // WithDownstreamWebsocket marks the current request as coming from a downstream websocket connection.
 func WithDownstreamWebsocket(ctx context.Context) context.Context {
 	if ctx == nil {
@@ -21,3 +29,30 @@ func DownstreamWebsocket(ctx context.Context) bool {
 	enabled, ok := raw.(bool)
 	return ok && enabled
 }
+
+// WithResponseObserver registers fn to be told about upstream responses of the current attempt.
+// Observers registered on parent contexts keep being called.
+func WithResponseObserver(ctx context.Context, fn ResponseObserver) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if parent, ok := ctx.Value(responseObserverContextKey{}).(ResponseObserver); ok && parent != nil {
+		next := fn
+		fn = func(status int, headers http.Header) {
+			parent(status, headers)
+			next(status, headers)
+		}
+	}
+	return context.WithValue(ctx, responseObserverContextKey{}, fn)
+}
+
+// ObserveResponse passes an upstream response status and headers to the observer registered
+// on ctx, if any. Executors call it once per upstream HTTP response.
+func ObserveResponse(ctx context.Context, status int, headers http.Header) {
+	if ctx == nil {
+		return
+	}
+	if fn, ok := ctx.Value(responseObserverContextKey{}).(ResponseObserver); ok && fn != nil {
+		fn(status, headers)
+	}
+}
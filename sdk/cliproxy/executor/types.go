@@ -13,12 +13,18 @@ const RequestedModelMetadataKey = "requested_model"
 const (
 	// PinnedAuthMetadataKey locks execution to a specific auth ID.
 	PinnedAuthMetadataKey = "pinned_auth_id"
+	// ExcludedAuthsMetadataKey lists auth IDs ([]string) the scheduler must not select.
+	ExcludedAuthsMetadataKey = "excluded_auth_ids"
 	// SelectedAuthMetadataKey stores the auth ID selected by the scheduler.
 	SelectedAuthMetadataKey = "selected_auth_id"
 	// SelectedAuthCallbackMetadataKey carries an optional callback invoked with the selected auth ID.
 	SelectedAuthCallbackMetadataKey = "selected_auth_callback"
 	// ExecutionSessionMetadataKey identifies a long-lived downstream execution session.
 	ExecutionSessionMetadataKey = "execution_session_id"
+	// ClientAPIKeyMetadataKey carries the API key the downstream client authenticated with.
+	ClientAPIKeyMetadataKey = "client_api_key"
+	// ClientHeadersMetadataKey carries a copy of the downstream client's request headers (http.Header).
+	ClientHeadersMetadataKey = "client_headers"
 )
 
 // Request encapsulates the translated payload that will be sent to a provider executor.
@@ -0,0 +1,196 @@
+package cliproxy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/config"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	"github.com/router-for-me/CLIProxyAPI/v6/internal/runtime/executor"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	log "github.com/sirupsen/logrus"
+)
+
+// modelDiscoveryCheckInterval is how often the background loop looks for stale discovered model lists.
+const modelDiscoveryCheckInterval = time.Minute
+
+// discoverCompatModels returns the cached models discovered from the provider's /models
+// endpoint, filtered by the provider's include/exclude patterns. Once the cache is older than
+// the configured refresh interval it is refreshed in the background through the given auth;
+// on failure the previous list is kept and the next attempt waits for another interval.
+func (s *Service) discoverCompatModels(a *coreauth.Auth, compat *config.OpenAICompatibility) []*ModelInfo {
+	if a == nil || compat == nil || !compat.DiscoverModels {
+		return nil
+	}
+	channel := strings.ToLower(strings.TrimSpace(compat.Name))
+
+	s.modelDiscoveryMu.Lock()
+	due := s.modelDiscoveryDueLocked(compat)
+	if due {
+		if s.modelDiscoveryAttempts == nil {
+			s.modelDiscoveryAttempts = make(map[string]time.Time)
+		}
+		s.modelDiscoveryAttempts[channel] = time.Now()
+	}
+	s.modelDiscoveryMu.Unlock()
+	if due {
+		go s.fetchCompatModels(a, s.cfg, compat.Name)
+	}
+
+	models, _, _ := registry.GetDiscoveredModels(channel)
+	return filterDiscoveredModels(models, compat.DiscoverInclude, compat.DiscoverExclude)
+}
+
+// fetchCompatModels refreshes the discovered model list of the named provider through a and
+// re-registers the provider's auths so they expose the new list.
+func (s *Service) fetchCompatModels(a *coreauth.Auth, cfg *config.Config, name string) {
+	channel := strings.ToLower(strings.TrimSpace(name))
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	fetched, err := executor.FetchOpenAICompatModels(ctx, a, cfg, name)
+	cancel()
+	if err != nil {
+		log.Warnf("model discovery for %s failed: %v", name, err)
+		return
+	}
+	registry.SetDiscoveredModels(channel, fetched, time.Now())
+	log.Debugf("model discovery for %s found %d models", name, len(fetched))
+	if s.coreManager == nil {
+		return
+	}
+	for _, auth := range s.coreManager.List() {
+		if auth == nil || auth.Disabled || auth.Attributes == nil {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(auth.Attributes["compat_name"]), channel) {
+			s.registerModelsForAuth(auth)
+		}
+	}
+}
+
+// modelDiscoveryDueLocked reports whether the provider's discovered list is missing or stale
+// and no attempt was made within the refresh interval. Callers must hold modelDiscoveryMu.
+func (s *Service) modelDiscoveryDueLocked(compat *config.OpenAICompatibility) bool {
+	channel := strings.ToLower(strings.TrimSpace(compat.Name))
+	interval := time.Duration(compat.DiscoverRefreshInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Duration(config.DefaultModelDiscoveryRefreshInterval) * time.Second
+	}
+	if time.Since(s.modelDiscoveryAttempts[channel]) < interval {
+		return false
+	}
+	_, fetchedAt, ok := registry.GetDiscoveredModels(channel)
+	return !ok || time.Since(fetchedAt) >= interval
+}
+
+// filterDiscoveredModels keeps models matching any include pattern (all when include is empty)
+// and drops models matching any exclude pattern.
+func filterDiscoveredModels(models []*ModelInfo, include, exclude []string) []*ModelInfo {
+	if len(models) == 0 {
+		return nil
+	}
+	if len(include) > 0 {
+		filtered := make([]*ModelInfo, 0, len(models))
+		for _, model := range models {
+			if model == nil {
+				continue
+			}
+			modelID := strings.ToLower(strings.TrimSpace(model.ID))
+			for _, pattern := range include {
+				if matchWildcard(strings.ToLower(strings.TrimSpace(pattern)), modelID) {
+					filtered = append(filtered, model)
+					break
+				}
+			}
+		}
+		models = filtered
+	}
+	return applyExcludedModels(models, exclude)
+}
+
+// mergeDiscoveredModels appends discovered models that are not already exposed by the
+// configured models, either as an alias or as the upstream name behind an alias.
+func mergeDiscoveredModels(configured []*ModelInfo, compat *config.OpenAICompatibility, discovered []*ModelInfo) []*ModelInfo {
+	if len(discovered) == 0 {
+		return configured
+	}
+	seen := make(map[string]struct{}, len(configured)+len(compat.Models))
+	for _, model := range configured {
+		if model != nil {
+			seen[strings.ToLower(model.ID)] = struct{}{}
+		}
+	}
+	for i := range compat.Models {
+		if name := strings.ToLower(strings.TrimSpace(compat.Models[i].Name)); name != "" {
+			seen[name] = struct{}{}
+		}
+	}
+	out := configured
+	for _, model := range discovered {
+		if model == nil {
+			continue
+		}
+		key := strings.ToLower(model.ID)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, model)
+	}
+	return out
+}
+
+// startModelDiscovery periodically refreshes the discovered model lists of openai-compatibility
+// providers that are due, by re-registering one of their auths.
+func (s *Service) startModelDiscovery(parent context.Context) {
+	if s == nil || s.coreManager == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(parent)
+	s.modelDiscoveryCancel = cancel
+	go func() {
+		ticker := time.NewTicker(modelDiscoveryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.refreshDiscoveredModels()
+			}
+		}
+	}()
+}
+
+func (s *Service) refreshDiscoveredModels() {
+	s.cfgMu.RLock()
+	cfg := s.cfg
+	s.cfgMu.RUnlock()
+	if cfg == nil {
+		return
+	}
+	due := make(map[string]struct{})
+	s.modelDiscoveryMu.Lock()
+	for i := range cfg.OpenAICompatibility {
+		compat := &cfg.OpenAICompatibility[i]
+		if compat.DiscoverModels && s.modelDiscoveryDueLocked(compat) {
+			due[strings.ToLower(strings.TrimSpace(compat.Name))] = struct{}{}
+		}
+	}
+	s.modelDiscoveryMu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+	for _, a := range s.coreManager.List() {
+		if a == nil || a.Disabled || a.Attributes == nil {
+			continue
+		}
+		channel := strings.ToLower(strings.TrimSpace(a.Attributes["compat_name"]))
+		if _, ok := due[channel]; !ok {
+			continue
+		}
+		// One registration starts the refresh, which re-registers the provider's other auths.
+		delete(due, channel)
+		s.registerModelsForAuth(a)
+	}
+}
@@ -89,6 +89,15 @@ type Service struct {
 
 	// wsGateway manages websocket Gemini providers.
 	wsGateway *wsrelay.Manager
+
+	// modelDiscoveryMu guards the discovery attempts of openai-compatibility providers.
+	modelDiscoveryMu sync.Mutex
+
+	// modelDiscoveryAttempts records the last discovery attempt per provider to pace retries.
+	modelDiscoveryAttempts map[string]time.Time
+
+	// modelDiscoveryCancel stops the background model discovery refresh loop.
+	modelDiscoveryCancel context.CancelFunc
 }
 
 // RegisterUsagePlugin registers a usage plugin on the global usage manager.
@@ -412,6 +421,10 @@ func (s *Service) ensureExecutorsForAuthWithMode(a *coreauth.Auth, forceReplace
 		s.coreManager.RegisterExecutor(executor.NewAntigravityExecutor(s.cfg))
 	case "claude":
 		s.coreManager.RegisterExecutor(executor.NewClaudeExecutor(s.cfg))
+	case "bedrock":
+		s.coreManager.RegisterExecutor(executor.NewBedrockExecutor(s.cfg))
+	case "azure-openai":
+		s.coreManager.RegisterExecutor(executor.NewAzureOpenAIExecutor(s.cfg))
 	case "qwen":
 		s.coreManager.RegisterExecutor(executor.NewQwenExecutor(s.cfg))
 	case "iflow":
@@ -631,6 +644,7 @@ func (s *Service) Run(ctx context.Context) error {
 		s.coreManager.StartAutoRefresh(context.Background(), interval)
 		log.Infof("core auth auto-refresh started (interval=%s)", interval)
 	}
+	s.startModelDiscovery(context.Background())
 
 	select {
 	case <-ctx.Done():
@@ -665,6 +679,9 @@ func (s *Service) Shutdown(ctx context.Context) error {
 		if s.watcherCancel != nil {
 			s.watcherCancel()
 		}
+		if s.modelDiscoveryCancel != nil {
+			s.modelDiscoveryCancel()
+		}
 		if s.coreManager != nil {
 			s.coreManager.StopAutoRefresh()
 		}
@@ -816,6 +833,17 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 			}
 		}
 		models = applyExcludedModels(models, excluded)
+	case "bedrock":
+		models = registry.GetClaudeModels()
+		if entry := s.cfg.BedrockKeyFor(a.Attributes); entry != nil {
+			if len(entry.Models) > 0 {
+				models = buildBedrockConfigModels(entry)
+			}
+			if authKind == "apikey" {
+				excluded = entry.ExcludedModels
+			}
+		}
+		models = applyExcludedModels(models, excluded)
 	case "codex":
 		models = registry.GetOpenAIModels()
 		if entry := s.resolveConfigCodexKey(a); entry != nil {
@@ -827,6 +855,17 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 			}
 		}
 		models = applyExcludedModels(models, excluded)
+	case "azure-openai":
+		models = registry.GetOpenAIModels()
+		if entry := s.resolveConfigAzureOpenAIKey(a); entry != nil {
+			if len(entry.Models) > 0 {
+				models = buildAzureOpenAIConfigModels(entry)
+			}
+			if authKind == "apikey" {
+				excluded = entry.ExcludedModels
+			}
+		}
+		models = applyExcludedModels(models, excluded)
 	case "qwen":
 		models = registry.GetQwenModels()
 		models = applyExcludedModels(models, excluded)
@@ -889,15 +928,19 @@ func (s *Service) registerModelsForAuth(a *coreauth.Auth) {
 							modelID = m.Name
 						}
 						ms = append(ms, &ModelInfo{
-							ID:          modelID,
-							Object:      "model",
-							Created:     time.Now().Unix(),
-							OwnedBy:     compat.Name,
-							Type:        "openai-compatibility",
-							DisplayName: modelID,
-							UserDefined: true,
+							ID:           modelID,
+							Object:       "model",
+							Created:      time.Now().Unix(),
+							OwnedBy:      compat.Name,
+							Type:         "openai-compatibility",
+							DisplayName:  modelID,
+							Capabilities: normalizeModelCapabilities(m.Capabilities),
+							UserDefined:  true,
 						})
 					}
+					if compat.DiscoverModels {
+						ms = mergeDiscoveredModels(ms, compat, s.discoverCompatModels(a, compat))
+					}
 					// Register and return
 					if len(ms) > 0 {
 						if providerKey == "" {
@@ -970,6 +1013,21 @@ func (s *Service) resolveConfigClaudeKey(auth *coreauth.Auth) *config.ClaudeKey
 	return nil
 }
 
+func (s *Service) resolveConfigAzureOpenAIKey(auth *coreauth.Auth) *config.AzureOpenAIKey {
+	if auth == nil || s.cfg == nil || auth.Attributes == nil {
+		return nil
+	}
+	attrKey := strings.TrimSpace(auth.Attributes["api_key"])
+	attrBase := strings.TrimSpace(auth.Attributes["base_url"])
+	for i := range s.cfg.AzureOpenAIKey {
+		entry := &s.cfg.AzureOpenAIKey[i]
+		if strings.TrimSpace(entry.APIKey) == attrKey && strings.EqualFold(strings.TrimSpace(entry.BaseURL), attrBase) {
+			return entry
+		}
+	}
+	return nil
+}
+
 func (s *Service) resolveConfigGeminiKey(auth *coreauth.Auth) *config.GeminiKey {
 	if auth == nil || s.cfg == nil {
 		return nil
@@ -1193,6 +1251,7 @@ func matchWildcard(pattern, value string) bool {
 type modelEntry interface {
 	GetName() string
 	GetAlias() string
+	GetCapabilities() []string
 }
 
 func buildConfigModels[T modelEntry](models []T, ownedBy, modelType string) []*ModelInfo {
@@ -1231,15 +1290,44 @@ func buildConfigModels[T modelEntry](models []T, ownedBy, modelType string) []*M
 			UserDefined: true,
 		}
 		if name != "" {
-			if upstream := registry.LookupStaticModelInfo(name); upstream != nil && upstream.Thinking != nil {
+			if upstream := registry.LookupStaticModelInfo(name); upstream != nil {
 				info.Thinking = upstream.Thinking
+				info.Capabilities = upstream.Capabilities
 			}
 		}
+		if caps := normalizeModelCapabilities(model.GetCapabilities()); caps != nil {
+			info.Capabilities = caps
+		}
 		out = append(out, info)
 	}
 	return out
 }
 
+// normalizeModelCapabilities lower-cases and de-duplicates configured capabilities.
+// It returns nil when none are configured.
+func normalizeModelCapabilities(capabilities []string) []string {
+	if len(capabilities) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(capabilities))
+	seen := make(map[string]struct{}, len(capabilities))
+	for _, c := range capabilities {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if key == "" {
+			continue
+		}
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, key)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
 func buildVertexCompatConfigModels(entry *config.VertexCompatKey) []*ModelInfo {
 	if entry == nil {
 		return nil
@@ -1261,6 +1349,20 @@ func buildClaudeConfigModels(entry *config.ClaudeKey) []*ModelInfo {
 	return buildConfigModels(entry.Models, "anthropic", "claude")
 }
 
+func buildBedrockConfigModels(entry *config.BedrockKey) []*ModelInfo {
+	if entry == nil {
+		return nil
+	}
+	return buildConfigModels(entry.Models, "anthropic", "claude")
+}
+
+func buildAzureOpenAIConfigModels(entry *config.AzureOpenAIKey) []*ModelInfo {
+	if entry == nil {
+		return nil
+	}
+	return buildConfigModels(entry.Models, "openai", "openai")
+}
+
 func buildCodexConfigModels(entry *config.CodexKey) []*ModelInfo {
 	if entry == nil {
 		return nil
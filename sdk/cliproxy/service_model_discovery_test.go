@@ -0,0 +1,114 @@
+package cliproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	internalregistry "github.com/router-for-me/CLIProxyAPI/v6/internal/registry"
+	coreauth "github.com/router-for-me/CLIProxyAPI/v6/sdk/cliproxy/auth"
+	"github.com/router-for-me/CLIProxyAPI/v6/sdk/config"
+)
+
+func TestRegisterModelsForAuth_MergesDiscoveredCompatModels(t *testing.T) {
+	var mu sync.Mutex
+	var gotAuthorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		gotAuthorization = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"vendor/alpha","name":"Alpha","context_length":128000,"top_provider":{"max_completion_tokens":8192},"pricing":{"prompt":"0.000001","completion":"0.000002"}},
+			{"id":"vendor/beta-preview","context_length":32000},
+			{"id":"other/gamma"},
+			{"id":"vendor/upstream-name"}
+		]}`))
+	}))
+	defer server.Close()
+
+	service := &Service{
+		coreManager: coreauth.NewManager(nil, nil, nil),
+		cfg: &config.Config{
+			OpenAICompatibility: []config.OpenAICompatibility{{
+				Name:            "discovery-test",
+				BaseURL:         server.URL + "/v1",
+				Models:          []config.OpenAICompatibilityModel{{Name: "vendor/upstream-name", Alias: "configured"}},
+				DiscoverModels:  true,
+				DiscoverInclude: []string{"vendor/*"},
+				DiscoverExclude: []string{"*-preview"},
+			}},
+		},
+	}
+	auth := &coreauth.Auth{
+		ID:       "auth-discovery-test",
+		Provider: "discovery-test",
+		Status:   coreauth.StatusActive,
+		Attributes: map[string]string{
+			"api_key":      "sk-test",
+			"base_url":     server.URL + "/v1",
+			"compat_name":  "discovery-test",
+			"provider_key": "discovery-test",
+		},
+	}
+
+	modelRegistry := GlobalModelRegistry()
+	modelRegistry.UnregisterClient(auth.ID)
+	t.Cleanup(func() {
+		modelRegistry.UnregisterClient(auth.ID)
+		internalregistry.ClearDiscoveredModels("discovery-test")
+	})
+
+	if _, err := service.coreManager.Register(context.Background(), auth); err != nil {
+		t.Fatalf("register auth: %v", err)
+	}
+
+	// Registration does not wait for discovery: the configured models come first and the
+	// discovered ones once the background refresh re-registers the auth.
+	service.registerModelsForAuth(auth)
+	registered := func() map[string]*ModelInfo {
+		ids := make(map[string]*ModelInfo)
+		for _, model := range modelRegistry.GetAvailableModelsByProvider("discovery-test") {
+			ids[model.ID] = model
+		}
+		return ids
+	}
+	ids := registered()
+	for deadline := time.Now().Add(2 * time.Second); len(ids) < 2 && time.Now().Before(deadline); ids = registered() {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotAuthorization != "Bearer sk-test" {
+		t.Fatalf("Authorization = %q, want %q", gotAuthorization, "Bearer sk-test")
+	}
+	if len(ids) != 2 {
+		t.Fatalf("registered models = %v, want configured and vendor/alpha", ids)
+	}
+	if _, ok := ids["configured"]; !ok {
+		t.Fatalf("expected configured alias to be registered")
+	}
+	alpha, ok := ids["vendor/alpha"]
+	if !ok {
+		t.Fatalf("expected discovered vendor/alpha to be registered")
+	}
+	if alpha.ContextLength != 128000 || alpha.MaxCompletionTokens != 8192 {
+		t.Fatalf("vendor/alpha limits = %d/%d", alpha.ContextLength, alpha.MaxCompletionTokens)
+	}
+	if alpha.Pricing == nil || alpha.Pricing.Prompt != "0.000001" {
+		t.Fatalf("vendor/alpha pricing = %+v", alpha.Pricing)
+	}
+
+	discovered, _, found := internalregistry.GetDiscoveredModels("discovery-test")
+	if !found || len(discovered) != 4 {
+		t.Fatalf("discovered models = %d (found=%t), want 4", len(discovered), found)
+	}
+}
@@ -0,0 +1,82 @@
+package usage
+
+import (
+	"context"
+	"sync"
+)
+
+type holdContextKey struct{}
+
+// Hold defers the usage records published under a context until its owner decides whether
+// they are charged. Records arriving after the decision follow it.
+type Hold struct {
+	mu      sync.Mutex
+	pending []heldRecord
+	settled bool
+	keep    bool
+}
+
+type heldRecord struct {
+	manager *Manager
+	ctx     context.Context
+	record  Record
+}
+
+// WithHold returns a context whose usage records are held by h.
+func WithHold(ctx context.Context, h *Hold) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, holdContextKey{}, h)
+}
+
+func holdFromContext(ctx context.Context) *Hold {
+	if ctx == nil {
+		return nil
+	}
+	h, _ := ctx.Value(holdContextKey{}).(*Hold)
+	return h
+}
+
+// capture keeps record until the hold is settled and reports whether the caller must not
+// publish it itself.
+func (h *Hold) capture(m *Manager, ctx context.Context, record Record) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.settled {
+		return !h.keep
+	}
+	h.pending = append(h.pending, heldRecord{manager: m, ctx: ctx, record: record})
+	return true
+}
+
+// Release publishes the held records and lets later ones through.
+func (h *Hold) Release() {
+	h.settle(true)
+}
+
+// Discard drops the held records and any published later.
+func (h *Hold) Discard() {
+	h.settle(false)
+}
+
+func (h *Hold) settle(keep bool) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	if h.settled {
+		h.mu.Unlock()
+		return
+	}
+	h.settled, h.keep = true, keep
+	pending := h.pending
+	h.pending = nil
+	h.mu.Unlock()
+	if !keep {
+		return
+	}
+	for _, held := range pending {
+		held.manager.enqueue(held.ctx, held.record)
+	}
+}
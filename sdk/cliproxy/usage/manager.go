@@ -18,7 +18,10 @@ type Record struct {
 	Source      string
 	RequestedAt time.Time
 	Failed      bool
-	Detail      Detail
+	// Coalesced marks a request answered with the response of an identical in-flight request;
+	// it made no upstream call of its own.
+	Coalesced bool
+	Detail    Detail
 }
 
 // Detail holds the token usage breakdown.
@@ -104,11 +107,18 @@ func (m *Manager) Register(plugin Plugin) {
 }
 
 // Publish enqueues a usage record for processing. If no plugin is registered
-// the record will be discarded downstream.
+// the record will be discarded downstream. Records published under a Hold wait for it.
 func (m *Manager) Publish(ctx context.Context, record Record) {
 	if m == nil {
 		return
 	}
+	if hold := holdFromContext(ctx); hold != nil && hold.capture(m, ctx, record) {
+		return
+	}
+	m.enqueue(ctx, record)
+}
+
+func (m *Manager) enqueue(ctx context.Context, record Record) {
 	// ensure worker is running even if Start was not called explicitly
 	m.Start(context.Background())
 	m.mu.Lock()
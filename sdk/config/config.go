@@ -11,6 +11,22 @@ type SDKConfig = internalconfig.SDKConfig
 type Config = internalconfig.Config
 
 type StreamingConfig = internalconfig.StreamingConfig
+type StreamTimeoutRule = internalconfig.StreamTimeoutRule
+type RetryPolicy = internalconfig.RetryPolicy
+type RetryBackoff = internalconfig.RetryBackoff
+type CircuitBreakerConfig = internalconfig.CircuitBreakerConfig
+type ConcurrencyConfig = internalconfig.ConcurrencyConfig
+type PacingConfig = internalconfig.PacingConfig
+type PacingRule = internalconfig.PacingRule
+type PriorityConfig = internalconfig.PriorityConfig
+type PriorityClass = internalconfig.PriorityClass
+type CapacityReservation = internalconfig.CapacityReservation
+type HedgingRule = internalconfig.HedgingRule
+type StructuredOutputConfig = internalconfig.StructuredOutputConfig
+type ContextPolicy = internalconfig.ContextPolicy
+type TokenCountingConfig = internalconfig.TokenCountingConfig
+type ReasoningOutputConfig = internalconfig.ReasoningOutputConfig
+type CoalescingConfig = internalconfig.CoalescingConfig
 type TLSConfig = internalconfig.TLSConfig
 type RemoteManagement = internalconfig.RemoteManagement
 type AmpCode = internalconfig.AmpCode
@@ -19,10 +35,19 @@ type PayloadConfig = internalconfig.PayloadConfig
 type PayloadRule = internalconfig.PayloadRule
 type PayloadFilterRule = internalconfig.PayloadFilterRule
 type PayloadModelRule = internalconfig.PayloadModelRule
+type PayloadMatch = internalconfig.PayloadMatch
+type PayloadOperationRule = internalconfig.PayloadOperationRule
+type PayloadOperation = internalconfig.PayloadOperation
+type ModelDefinitionsConfig = internalconfig.ModelDefinitionsConfig
+type RemoteMediaConfig = internalconfig.RemoteMediaConfig
+type ContextCacheConfig = internalconfig.ContextCacheConfig
 
 type GeminiKey = internalconfig.GeminiKey
 type CodexKey = internalconfig.CodexKey
 type ClaudeKey = internalconfig.ClaudeKey
+type BedrockKey = internalconfig.BedrockKey
+type AzureOpenAIKey = internalconfig.AzureOpenAIKey
+type AzureOpenAIModel = internalconfig.AzureOpenAIModel
 type VertexCompatKey = internalconfig.VertexCompatKey
 type VertexCompatModel = internalconfig.VertexCompatModel
 type OpenAICompatibility = internalconfig.OpenAICompatibility
@@ -33,6 +58,29 @@ type TLS = internalconfig.TLSConfig
 
 const (
 	DefaultPanelGitHubRepository = internalconfig.DefaultPanelGitHubRepository
+
+	ContextPolicyReject    = internalconfig.ContextPolicyReject
+	ContextPolicyTruncate  = internalconfig.ContextPolicyTruncate
+	ContextPolicySummarize = internalconfig.ContextPolicySummarize
+
+	TokenCountingUpstream = internalconfig.TokenCountingUpstream
+	TokenCountingLocal    = internalconfig.TokenCountingLocal
+	TokenCountingFallback = internalconfig.TokenCountingFallback
+
+	ReasoningOutputContent   = internalconfig.ReasoningOutputContent
+	ReasoningOutputOmit      = internalconfig.ReasoningOutputOmit
+	ReasoningOutputSummary   = internalconfig.ReasoningOutputSummary
+	ReasoningOutputThinkTags = internalconfig.ReasoningOutputThinkTags
+
+	PayloadOpSet           = internalconfig.PayloadOpSet
+	PayloadOpSetRaw        = internalconfig.PayloadOpSetRaw
+	PayloadOpDelete        = internalconfig.PayloadOpDelete
+	PayloadOpAppend        = internalconfig.PayloadOpAppend
+	PayloadOpPrepend       = internalconfig.PayloadOpPrepend
+	PayloadOpRename        = internalconfig.PayloadOpRename
+	PayloadOpPrependSystem = internalconfig.PayloadOpPrependSystem
+	PayloadOpRemoveItems   = internalconfig.PayloadOpRemoveItems
+	PayloadOpDropEvent     = internalconfig.PayloadOpDropEvent
 )
 
 func LoadConfig(configFile string) (*Config, error) { return internalconfig.LoadConfig(configFile) }
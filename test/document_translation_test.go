@@ -0,0 +1,153 @@
+package test
+
+import (
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+const testPDFData = "JVBERi0xLjQK"
+
+// findDocument returns the first element of arr (a JSON array of parts or blocks) matching pred.
+func findDocument(arr gjson.Result, pred func(gjson.Result) bool) gjson.Result {
+	var found gjson.Result
+	arr.ForEach(func(_, v gjson.Result) bool {
+		if pred(v) {
+			found = v
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func isGeminiPDFPart(p gjson.Result) bool {
+	return p.Get("inlineData.mime_type").String() == "application/pdf" && p.Get("inlineData.data").String() == testPDFData
+}
+
+func TestDocumentTranslation_ToClaude(t *testing.T) {
+	cases := []struct {
+		name string
+		from sdktranslator.Format
+		in   string
+	}{
+		{
+			name: "openai file part",
+			from: sdktranslator.FormatOpenAI,
+			in:   `{"model":"m","messages":[{"role":"user","content":[{"type":"text","text":"review"},{"type":"file","file":{"filename":"contract.pdf","file_data":"data:application/pdf;base64,` + testPDFData + `"}}]}]}`,
+		},
+		{
+			name: "responses input_file",
+			from: sdktranslator.FormatOpenAIResponse,
+			in:   `{"model":"m","input":[{"role":"user","content":[{"type":"input_text","text":"review"},{"type":"input_file","filename":"contract.pdf","file_data":"data:application/pdf;base64,` + testPDFData + `"}]}]}`,
+		},
+		{
+			name: "gemini inlineData",
+			from: sdktranslator.FormatGemini,
+			in:   `{"contents":[{"role":"user","parts":[{"text":"review"},{"inlineData":{"mimeType":"application/pdf","data":"` + testPDFData + `"}}]}]}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := sdktranslator.TranslateRequest(tc.from, sdktranslator.FormatClaude, "claude-sonnet-4-5", []byte(tc.in), false)
+			doc := findDocument(gjson.GetBytes(out, "messages.0.content"), func(b gjson.Result) bool {
+				return b.Get("type").String() == "document"
+			})
+			if !doc.Exists() {
+				t.Fatalf("expected a document block: %s", out)
+			}
+			if doc.Get("source.type").String() != "base64" || doc.Get("source.media_type").String() != "application/pdf" || doc.Get("source.data").String() != testPDFData {
+				t.Fatalf("unexpected document block: %s", doc.Raw)
+			}
+		})
+	}
+}
+
+func TestDocumentTranslation_ToGeminiFamily(t *testing.T) {
+	sources := []struct {
+		name string
+		from sdktranslator.Format
+		in   string
+	}{
+		{
+			name: "openai file part",
+			from: sdktranslator.FormatOpenAI,
+			in:   `{"model":"m","messages":[{"role":"user","content":[{"type":"file","file":{"filename":"contract.pdf","file_data":"data:application/pdf;base64,` + testPDFData + `"}}]}]}`,
+		},
+		{
+			name: "responses input_file",
+			from: sdktranslator.FormatOpenAIResponse,
+			in:   `{"model":"m","input":[{"role":"user","content":[{"type":"input_file","filename":"contract.pdf","file_data":"data:application/pdf;base64,` + testPDFData + `"}]}]}`,
+		},
+		{
+			name: "claude document",
+			from: sdktranslator.FormatClaude,
+			in:   `{"model":"m","messages":[{"role":"user","content":[{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"` + testPDFData + `"}}]}]}`,
+		},
+	}
+	targets := []struct {
+		to     sdktranslator.Format
+		prefix string
+	}{
+		{to: sdktranslator.FormatGemini, prefix: ""},
+		{to: sdktranslator.FormatGeminiCLI, prefix: "request."},
+		{to: sdktranslator.FormatAntigravity, prefix: "request."},
+	}
+	for _, src := range sources {
+		for _, target := range targets {
+			t.Run(src.name+"->"+target.to.String(), func(t *testing.T) {
+				out := sdktranslator.TranslateRequest(src.from, target.to, "gemini-2.5-pro", []byte(src.in), false)
+				var part gjson.Result
+				gjson.GetBytes(out, target.prefix+"contents").ForEach(func(_, content gjson.Result) bool {
+					part = findDocument(content.Get("parts"), isGeminiPDFPart)
+					return !part.Exists()
+				})
+				if !part.Exists() {
+					t.Fatalf("expected an application/pdf inlineData part: %s", out)
+				}
+			})
+		}
+	}
+}
+
+func TestDocumentTranslation_ToOpenAIAndCodex(t *testing.T) {
+	claudeIn := []byte(`{"model":"m","messages":[{"role":"user","content":[{"type":"document","title":"contract.pdf","source":{"type":"base64","media_type":"application/pdf","data":"` + testPDFData + `"}}]}]}`)
+	geminiIn := []byte(`{"contents":[{"role":"user","parts":[{"inlineData":{"mimeType":"application/pdf","data":"` + testPDFData + `"}}]}]}`)
+	wantDataURL := "data:application/pdf;base64," + testPDFData
+
+	for name, in := range map[sdktranslator.Format][]byte{sdktranslator.FormatClaude: claudeIn, sdktranslator.FormatGemini: geminiIn} {
+		out := sdktranslator.TranslateRequest(name, sdktranslator.FormatOpenAI, "gpt-5", in, false)
+		var part gjson.Result
+		gjson.GetBytes(out, "messages").ForEach(func(_, msg gjson.Result) bool {
+			part = findDocument(msg.Get("content"), func(p gjson.Result) bool { return p.Get("type").String() == "file" })
+			return !part.Exists()
+		})
+		if part.Get("file.file_data").String() != wantDataURL || part.Get("file.filename").String() == "" {
+			t.Fatalf("%s -> openai: expected a file part, got %s", name, out)
+		}
+
+		out = sdktranslator.TranslateRequest(name, sdktranslator.FormatCodex, "gpt-5", in, false)
+		gjson.GetBytes(out, "input").ForEach(func(_, item gjson.Result) bool {
+			part = findDocument(item.Get("content"), func(p gjson.Result) bool { return p.Get("type").String() == "input_file" })
+			return !part.Exists()
+		})
+		if part.Get("file_data").String() != wantDataURL {
+			t.Fatalf("%s -> codex: expected an input_file part, got %s", name, out)
+		}
+	}
+
+	responsesIn := []byte(`{"model":"gpt-5","input":[{"role":"user","content":[{"type":"input_file","filename":"contract.pdf","file_data":"` + wantDataURL + `"}]}]}`)
+	out := sdktranslator.TranslateRequest(sdktranslator.FormatOpenAIResponse, sdktranslator.FormatOpenAI, "gpt-5", responsesIn, false)
+	if got := gjson.GetBytes(out, "messages.0.content.0.file.file_data").String(); got != wantDataURL {
+		t.Fatalf("responses -> openai: expected a file part, got %s", out)
+	}
+
+	chatIn := []byte(`{"model":"gpt-5","messages":[{"role":"user","content":[{"type":"file","file":{"filename":"contract.pdf","file_data":"` + wantDataURL + `"}}]}]}`)
+	out = sdktranslator.TranslateRequest(sdktranslator.FormatOpenAI, sdktranslator.FormatCodex, "gpt-5", chatIn, false)
+	if got := gjson.GetBytes(out, "input.0.content.0.type").String(); got != "input_file" {
+		t.Fatalf("openai -> codex: expected an input_file part, got %s", out)
+	}
+}
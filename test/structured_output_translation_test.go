@@ -0,0 +1,71 @@
+package test
+
+import (
+	"testing"
+
+	_ "github.com/router-for-me/CLIProxyAPI/v6/internal/translator"
+
+	sdktranslator "github.com/router-for-me/CLIProxyAPI/v6/sdk/translator"
+	"github.com/tidwall/gjson"
+)
+
+const testStructuredSchema = `{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`
+
+func TestStructuredOutputTranslation(t *testing.T) {
+	cases := []struct {
+		name       string
+		from, to   sdktranslator.Format
+		in         string
+		schemaPath string
+		check      func(gjson.Result) bool
+	}{
+		{
+			name:       "openai to gemini",
+			from:       sdktranslator.FormatOpenAI,
+			to:         sdktranslator.FormatGemini,
+			in:         `{"model":"m","messages":[{"role":"user","content":"where?"}],"response_format":{"type":"json_schema","json_schema":{"name":"place","schema":` + testStructuredSchema + `}}}`,
+			schemaPath: "generationConfig.responseJsonSchema",
+			check: func(out gjson.Result) bool {
+				return out.Get("generationConfig.responseMimeType").String() == "application/json"
+			},
+		},
+		{
+			name:       "claude to codex",
+			from:       sdktranslator.FormatClaude,
+			to:         sdktranslator.FormatCodex,
+			in:         `{"model":"m","messages":[{"role":"user","content":"where?"}],"output_format":{"type":"json_schema","schema":` + testStructuredSchema + `}}`,
+			schemaPath: "text.format.schema",
+			check: func(out gjson.Result) bool {
+				return out.Get("text.format.type").String() == "json_schema" && out.Get("text.format.name").String() != ""
+			},
+		},
+		{
+			name:       "responses to openai",
+			from:       sdktranslator.FormatOpenAIResponse,
+			to:         sdktranslator.FormatOpenAI,
+			in:         `{"model":"m","input":"where?","text":{"format":{"type":"json_schema","name":"place","strict":true,"schema":` + testStructuredSchema + `}}}`,
+			schemaPath: "response_format.json_schema.schema",
+			check: func(out gjson.Result) bool {
+				return out.Get("response_format.json_schema.name").String() == "place" && out.Get("response_format.json_schema.strict").Bool()
+			},
+		},
+		{
+			name:       "gemini openapi schema to openai",
+			from:       sdktranslator.FormatGemini,
+			to:         sdktranslator.FormatOpenAI,
+			in:         `{"contents":[{"role":"user","parts":[{"text":"where?"}]}],"generationConfig":{"responseMimeType":"application/json","responseSchema":{"type":"OBJECT","properties":{"city":{"type":"STRING"}},"required":["city"]}}}`,
+			schemaPath: "response_format.json_schema.schema",
+			check: func(out gjson.Result) bool {
+				return out.Get("response_format.type").String() == "json_schema"
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := gjson.ParseBytes(sdktranslator.TranslateRequest(tc.from, tc.to, "m", []byte(tc.in), false))
+			if out.Get(tc.schemaPath+".properties.city.type").String() != "string" || !tc.check(out) {
+				t.Fatalf("structured output not translated: %s", out.Raw)
+			}
+		})
+	}
+}